@@ -0,0 +1,241 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+	"github.com/telepresenceio/telepresence/v2/pkg/dnsproxy"
+)
+
+// dnsLookupTimeout is the max time that a lookup that is shared by several requests may take.
+const dnsLookupTimeout = 8 * time.Second
+
+// dnsCacheKey identifies a cached answer. The scope is the namespaces of the agents that resolve the name, or
+// empty when the name is resolved by the traffic-manager.
+type dnsCacheKey struct {
+	scope string
+	name  string
+	qType uint16
+}
+
+// dnsCacheEntry is an answer that is being resolved, or that has been resolved. The wait channel is closed
+// when the answer is available.
+type dnsCacheEntry struct {
+	wait    chan struct{}
+	created time.Time
+	ttl     time.Duration
+	rrs     dnsproxy.RRs
+	rCode   int
+	err     error
+}
+
+func (e *dnsCacheEntry) done() bool {
+	select {
+	case <-e.wait:
+		return true
+	default:
+		return false
+	}
+}
+
+// answer returns copies of the resolved records, with TTLs that don't exceed the time that remains
+// until the entry expires.
+func (e *dnsCacheEntry) answer(now time.Time) dnsproxy.RRs {
+	if len(e.rrs) == 0 {
+		return e.rrs
+	}
+	remaining := uint32((e.ttl - now.Sub(e.created) + time.Second - 1) / time.Second)
+	rrs := make(dnsproxy.RRs, len(e.rrs))
+	for i, rr := range e.rrs {
+		rr = dns.Copy(rr)
+		if h := rr.Header(); h.Ttl > remaining {
+			h.Ttl = remaining
+		}
+		rrs[i] = rr
+	}
+	return rrs
+}
+
+// dnsCache is a cache of the answers to DNS lookups that is shared by all clients. Concurrent lookups of the
+// same name are coalesced into one.
+type dnsCache struct {
+	sync.Mutex
+	ctx         context.Context
+	entries     map[dnsCacheKey]*dnsCacheEntry
+	minTTL      time.Duration
+	maxTTL      time.Duration
+	negativeTTL time.Duration
+	maxEntries  int
+
+	hits      prometheus.Counter
+	misses    prometheus.Counter
+	coalesced prometheus.Counter
+	latency   prometheus.Histogram
+}
+
+func newDNSCache(ctx context.Context, env *managerutil.Env) *dnsCache {
+	return &dnsCache{
+		ctx:         ctx,
+		entries:     make(map[dnsCacheKey]*dnsCacheEntry),
+		minTTL:      env.DnsCacheMinTTL,
+		maxTTL:      env.DnsCacheMaxTTL,
+		negativeTTL: env.DnsCacheNegativeTTL,
+		maxEntries:  env.DnsCacheMaxEntries,
+		hits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "dns_cache_hits_total",
+			Help: "Number of DNS lookups answered from the cache",
+		}),
+		misses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "dns_cache_misses_total",
+			Help: "Number of DNS lookups that were resolved by agents or by the traffic-manager",
+		}),
+		coalesced: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "dns_lookups_coalesced_total",
+			Help: "Number of DNS lookups that waited for an identical lookup in progress",
+		}),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "dns_lookup_duration_seconds",
+			Help:    "Duration of the DNS lookups that were resolved by agents or by the traffic-manager",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 15),
+		}),
+	}
+}
+
+// collectors returns the Prometheus collectors of the cache.
+func (c *dnsCache) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.hits,
+		c.misses,
+		c.coalesced,
+		c.latency,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "dns_cache_entries",
+			Help: "Number of entries in the DNS cache",
+		}, func() float64 {
+			c.Lock()
+			defer c.Unlock()
+			return float64(len(c.entries))
+		}),
+	}
+}
+
+// ttl returns the time to cache an answer with the given records and result code. The lowest TTL of the records
+// decides, within the configured bounds.
+func (c *dnsCache) ttl(rrs dnsproxy.RRs, rCode int) time.Duration {
+	switch {
+	case c.maxTTL <= 0:
+		return 0
+	case rCode == dns.RcodeNameError:
+		return c.negativeTTL
+	case rCode != dns.RcodeSuccess:
+		return 0
+	}
+	ttl := c.maxTTL
+	for _, rr := range rrs {
+		if rt := time.Duration(rr.Header().Ttl) * time.Second; rt < ttl {
+			ttl = rt
+		}
+	}
+	if ttl < c.minTTL {
+		ttl = c.minTTL
+	}
+	return ttl
+}
+
+// lookup returns the cached answer for the given key, or calls resolve to get it. A lookup that is in progress
+// is shared by all requests for the same key, so resolve is called with a context that isn't cancelled when
+// the request that started it is.
+func (c *dnsCache) lookup(
+	ctx context.Context,
+	key dnsCacheKey,
+	resolve func(context.Context) (dnsproxy.RRs, int, error),
+) (dnsproxy.RRs, int, error) {
+	now := time.Now()
+	c.Lock()
+	e, ok := c.entries[key]
+	if ok && e.done() && now.Sub(e.created) > e.ttl {
+		delete(c.entries, key)
+		ok = false
+	}
+	if ok {
+		c.Unlock()
+		if e.done() {
+			c.hits.Inc()
+		} else {
+			c.coalesced.Inc()
+		}
+	} else {
+		e = &dnsCacheEntry{wait: make(chan struct{}), created: now}
+		c.entries[key] = e
+		c.Unlock()
+		c.misses.Inc()
+		go c.resolve(key, e, resolve)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, dns.RcodeServerFailure, ctx.Err()
+	case <-e.wait:
+		return e.answer(time.Now()), e.rCode, e.err
+	}
+}
+
+func (c *dnsCache) resolve(key dnsCacheKey, e *dnsCacheEntry, resolve func(context.Context) (dnsproxy.RRs, int, error)) {
+	ctx, cancel := context.WithTimeout(c.ctx, dnsLookupTimeout)
+	defer cancel()
+	start := time.Now()
+	e.rrs, e.rCode, e.err = resolve(ctx)
+	c.latency.Observe(time.Since(start).Seconds())
+	if e.err == nil {
+		e.ttl = c.ttl(e.rrs, e.rCode)
+	}
+	e.created = time.Now()
+	close(e.wait)
+
+	c.Lock()
+	defer c.Unlock()
+	if e.ttl <= 0 {
+		// Not cached. The answer was still shared with the concurrent lookups that waited for it.
+		if c.entries[key] == e {
+			delete(c.entries, key)
+		}
+		return
+	}
+	if len(c.entries) > c.maxEntries {
+		c.evict()
+	}
+}
+
+// evict removes the expired entries, and then the oldest entries until the cache is 90% full. Entries that
+// are being resolved are never evicted. Must be called with the lock held.
+func (c *dnsCache) evict() {
+	now := time.Now()
+	type agedKey struct {
+		key     dnsCacheKey
+		created time.Time
+	}
+	var done []agedKey
+	for key, e := range c.entries {
+		if !e.done() {
+			continue
+		}
+		if now.Sub(e.created) > e.ttl {
+			delete(c.entries, key)
+		} else {
+			done = append(done, agedKey{key: key, created: e.created})
+		}
+	}
+	target := c.maxEntries * 9 / 10
+	if excess := len(c.entries) - target; excess > 0 {
+		sort.Slice(done, func(i, j int) bool { return done[i].created.Before(done[j].created) })
+		for i := 0; i < excess && i < len(done); i++ {
+			delete(c.entries, done[i].key)
+		}
+	}
+}
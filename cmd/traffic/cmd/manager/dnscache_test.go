@@ -0,0 +1,143 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+	"github.com/telepresenceio/telepresence/v2/pkg/dnsproxy"
+)
+
+func newTestDNSCache(t *testing.T, env managerutil.Env) *dnsCache {
+	return newDNSCache(dlog.NewTestContext(t, false), &env)
+}
+
+func aRecord(name string, ttl uint32) dnsproxy.RRs {
+	return dnsproxy.RRs{&dns.A{
+		Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+		A:   net.IP{10, 0, 0, 1},
+	}}
+}
+
+func TestDNSCache_coalesce(t *testing.T) {
+	c := newTestDNSCache(t, managerutil.Env{DnsCacheMaxTTL: time.Minute, DnsCacheMaxEntries: 100})
+	ctx := c.ctx
+	key := dnsCacheKey{name: "web.default.", qType: dns.TypeA}
+
+	var lookups int32
+	release := make(chan struct{})
+	resolve := func(context.Context) (dnsproxy.RRs, int, error) {
+		atomic.AddInt32(&lookups, 1)
+		<-release
+		return aRecord("web.default.", 30), dns.RcodeSuccess, nil
+	}
+
+	const n = 10
+	wg := sync.WaitGroup{}
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			rrs, rCode, err := c.lookup(ctx, key, resolve)
+			assert.NoError(t, err)
+			assert.Equal(t, dns.RcodeSuccess, rCode)
+			assert.Len(t, rrs, 1)
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(c.misses)+testutil.ToFloat64(c.coalesced) == n
+	}, 5*time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+	assert.Equal(t, float64(n-1), testutil.ToFloat64(c.coalesced))
+
+	// The answer is cached for the TTL of its records
+	rrs, _, err := c.lookup(ctx, key, resolve)
+	require.NoError(t, err)
+	require.Len(t, rrs, 1)
+	assert.LessOrEqual(t, rrs[0].Header().Ttl, uint32(30))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.hits))
+
+	// Another scope isn't shared
+	_, _, _ = c.lookup(ctx, dnsCacheKey{scope: "default", name: "web.default.", qType: dns.TypeA}, resolve)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&lookups))
+}
+
+func TestDNSCache_ttl(t *testing.T) {
+	c := newTestDNSCache(t, managerutil.Env{DnsCacheMinTTL: 10 * time.Second, DnsCacheMaxTTL: time.Minute, DnsCacheMaxEntries: 100})
+	assert.Equal(t, 10*time.Second, c.ttl(aRecord("a.", 4), dns.RcodeSuccess))
+	assert.Equal(t, 30*time.Second, c.ttl(aRecord("a.", 30), dns.RcodeSuccess))
+	assert.Equal(t, time.Minute, c.ttl(aRecord("a.", 3600), dns.RcodeSuccess))
+	assert.Equal(t, time.Duration(0), c.ttl(nil, dns.RcodeNameError))
+	assert.Equal(t, time.Duration(0), c.ttl(nil, dns.RcodeServerFailure))
+
+	c.negativeTTL = 5 * time.Second
+	assert.Equal(t, 5*time.Second, c.ttl(nil, dns.RcodeNameError))
+
+	c.maxTTL = 0
+	assert.Equal(t, time.Duration(0), c.ttl(aRecord("a.", 30), dns.RcodeSuccess))
+}
+
+func TestDNSCache_notCached(t *testing.T) {
+	c := newTestDNSCache(t, managerutil.Env{DnsCacheMaxTTL: time.Minute, DnsCacheMaxEntries: 100})
+	ctx := c.ctx
+	var lookups int32
+	failing := func(context.Context) (dnsproxy.RRs, int, error) {
+		atomic.AddInt32(&lookups, 1)
+		return nil, dns.RcodeServerFailure, errors.New("boom")
+	}
+	key := dnsCacheKey{name: "web.default.", qType: dns.TypeA}
+	for i := 0; i < 2; i++ {
+		_, rCode, err := c.lookup(ctx, key, failing)
+		assert.Error(t, err)
+		assert.Equal(t, dns.RcodeServerFailure, rCode)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&lookups))
+
+	// A cancelled request doesn't cancel the lookup that it started
+	cctx, cancel := context.WithCancel(ctx)
+	release := make(chan struct{})
+	key = dnsCacheKey{name: "db.default.", qType: dns.TypeA}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, _, err := c.lookup(cctx, key, func(rc context.Context) (dnsproxy.RRs, int, error) {
+		<-release
+		return aRecord("db.default.", 30), dns.RcodeSuccess, rc.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	close(release)
+	rrs, _, err := c.lookup(ctx, key, failing)
+	require.NoError(t, err)
+	assert.Len(t, rrs, 1)
+}
+
+func TestDNSCache_evict(t *testing.T) {
+	c := newTestDNSCache(t, managerutil.Env{DnsCacheMaxTTL: time.Minute, DnsCacheMaxEntries: 10})
+	for i := 0; i < 25; i++ {
+		name := string(rune('a'+i)) + "."
+		_, _, err := c.lookup(c.ctx, dnsCacheKey{name: name, qType: dns.TypeA}, func(context.Context) (dnsproxy.RRs, int, error) {
+			return aRecord(name, 30), dns.RcodeSuccess, nil
+		})
+		require.NoError(t, err)
+	}
+	c.Lock()
+	defer c.Unlock()
+	assert.LessOrEqual(t, len(c.entries), 10)
+	assert.Contains(t, c.entries, dnsCacheKey{name: "y.", qType: dns.TypeA})
+	assert.NotContains(t, c.entries, dnsCacheKey{name: "a.", qType: dns.TypeA})
+}
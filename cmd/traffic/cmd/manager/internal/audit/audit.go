@@ -0,0 +1,314 @@
+// Package audit records who did what to which workload, and when, in an audit log that is written as JSON
+// lines to a configurable sink.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/datawire/dlib/dcontext"
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+// Event is the kind of event that a Record describes.
+type Event string
+
+const (
+	SessionArrived   Event = "session-arrived"
+	SessionDeparted  Event = "session-departed"
+	SessionExpired   Event = "session-expired"
+	InterceptCreated Event = "intercept-created"
+	InterceptUpdated Event = "intercept-updated"
+	InterceptRemoved Event = "intercept-removed"
+	AgentInjected    Event = "agent-injected"
+	AgentUninstalled Event = "agent-uninstalled"
+)
+
+// The names of the sinks that the audit log can be written to.
+const (
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkEvents = "events"
+)
+
+// queueSize is the number of records that can be queued for the sink. Records are dropped when it is full.
+const queueSize = 1024
+
+// maxStarted is the max number of sessions and intercepts whose start time is remembered. The oldest are
+// forgotten when it is exceeded, which happens when their ends aren't recorded.
+const maxStarted = 10000
+
+// Record is an entry in the audit log.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Event     Event     `json:"event"`
+	SessionID string    `json:"session_id,omitempty"`
+	Client    string    `json:"client,omitempty"`
+	InstallID string    `json:"install_id,omitempty"`
+	// ReportedKubeUser is the kubeconfig user that the client reported. The traffic-manager can't verify it, so
+	// it must not be trusted as the identity of the user.
+	ReportedKubeUser string  `json:"reported_kube_user,omitempty"`
+	Namespace        string  `json:"namespace,omitempty"`
+	Workload         string  `json:"workload,omitempty"`
+	Intercept        string  `json:"intercept,omitempty"`
+	DurationSeconds  float64 `json:"duration_seconds,omitempty"`
+	Message          string  `json:"message,omitempty"`
+}
+
+// ClientRecord returns a Record of the given event for the given client session.
+func ClientRecord(event Event, sessionID string, client *rpc.ClientInfo) *Record {
+	return &Record{
+		Event:            event,
+		SessionID:        sessionID,
+		Client:           client.GetName(),
+		InstallID:        client.GetInstallId(),
+		ReportedKubeUser: client.GetKubeUser(),
+	}
+}
+
+// InterceptRecord returns a Record of the given event for the given intercept of the given client.
+func InterceptRecord(event Event, ii *rpc.InterceptInfo, client *rpc.ClientInfo) *Record {
+	r := ClientRecord(event, ii.GetClientSession().GetSessionId(), client)
+	if r.Client == "" {
+		r.Client = ii.GetSpec().GetClient()
+	}
+	r.Namespace = ii.GetSpec().GetNamespace()
+	r.Workload = ii.GetSpec().GetAgent()
+	r.Intercept = ii.GetSpec().GetName()
+	return r
+}
+
+// ToRPC returns the RPC representation of the record.
+func (r *Record) ToRPC() *rpc.AuditRecord {
+	ar := &rpc.AuditRecord{
+		Time:      timestamppb.New(r.Time),
+		Event:     string(r.Event),
+		SessionId: r.SessionID,
+		Client:    r.Client,
+		InstallId: r.InstallID,
+		KubeUser:  r.ReportedKubeUser,
+		Namespace: r.Namespace,
+		Workload:  r.Workload,
+		Intercept: r.Intercept,
+		Message:   r.Message,
+	}
+	if r.DurationSeconds > 0 {
+		ar.Duration = durationpb.New(time.Duration(r.DurationSeconds * float64(time.Second)))
+	}
+	return ar
+}
+
+// Matches returns true if the record is selected by the given request.
+func (r *Record) Matches(req *rpc.AuditLogRequest) bool {
+	if since := req.GetSince(); since != nil && r.Time.Before(since.AsTime()) {
+		return false
+	}
+	return (req.Namespace == "" || req.Namespace == r.Namespace) &&
+		(req.Workload == "" || req.Workload == r.Workload) &&
+		(req.Client == "" || req.Client == r.Client)
+}
+
+// sink is where the records of the audit log are written to and read back from.
+type sink interface {
+	write(ctx context.Context, r *Record) error
+	records(ctx context.Context) ([]*Record, error)
+	close() error
+}
+
+// Log is the audit log. Records are written to its sink asynchronously, in the order that they are recorded,
+// so that recording never waits for a slow sink while holding locks. Records are dropped, and counted, when
+// the sink can't keep up.
+type Log struct {
+	sink  sink
+	queue chan *Record
+	done  chan struct{}
+
+	// dropped is the total number of dropped records, and unreported is the number that hasn't been logged yet.
+	dropped    int64
+	unreported int64
+
+	// started holds the time that each live session or intercept started, so that its duration can be
+	// recorded when it ends.
+	mu      sync.Mutex
+	started map[string]time.Time
+}
+
+// NewLog returns a Log that writes to the sink with the given name. The fileName is only used by the file sink.
+func NewLog(ctx context.Context, sinkName, fileName string) (*Log, error) {
+	var s sink
+	var err error
+	switch sinkName {
+	case SinkStdout:
+		s = newStdoutSink()
+	case SinkFile:
+		s, err = newFileSink(fileName)
+	case SinkEvents:
+		s, err = newEventsSink(ctx)
+	default:
+		err = fmt.Errorf("invalid audit log sink %q, must be one of %q, %q, or %q", sinkName, SinkStdout, SinkFile, SinkEvents)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newLog(s), nil
+}
+
+func newLog(s sink) *Log {
+	return &Log{
+		sink:    s,
+		queue:   make(chan *Record, queueSize),
+		done:    make(chan struct{}),
+		started: make(map[string]time.Time),
+	}
+}
+
+type logKey struct{}
+
+// WithLog returns a context that carries the given Log.
+func WithLog(ctx context.Context, l *Log) context.Context {
+	return context.WithValue(ctx, logKey{}, l)
+}
+
+// GetLog returns the Log of the given context, or nil when the audit log isn't enabled.
+func GetLog(ctx context.Context) *Log {
+	l, _ := ctx.Value(logKey{}).(*Log)
+	return l
+}
+
+// Add adds a record to the Log of the given context. It's a no-op when the audit log isn't enabled.
+func Add(ctx context.Context, r *Record) {
+	if l := GetLog(ctx); l != nil {
+		l.Record(r)
+	}
+}
+
+// Record adds a record to the log. The time of the record is set to now unless it is already set, and the
+// duration of a departed session or removed intercept is set if the log saw it start. The record is dropped
+// if the queue is full. Record never blocks, so it can be called while holding locks.
+func (l *Log) Record(r *Record) {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	l.mu.Lock()
+	switch r.Event {
+	case SessionArrived:
+		l.start(r.SessionID, r.Time)
+	case SessionDeparted, SessionExpired:
+		if t, ok := l.started[r.SessionID]; ok {
+			delete(l.started, r.SessionID)
+			r.DurationSeconds = r.Time.Sub(t).Seconds()
+		}
+	case InterceptCreated:
+		l.start(r.SessionID+":"+r.Intercept, r.Time)
+	case InterceptRemoved:
+		key := r.SessionID + ":" + r.Intercept
+		if t, ok := l.started[key]; ok {
+			delete(l.started, key)
+			r.DurationSeconds = r.Time.Sub(t).Seconds()
+		}
+	}
+	l.mu.Unlock()
+	select {
+	case l.queue <- r:
+	case <-l.done:
+	default:
+		atomic.AddInt64(&l.dropped, 1)
+		atomic.AddInt64(&l.unreported, 1)
+	}
+}
+
+// start remembers the start time of the session or intercept with the given key. The oldest start times are
+// forgotten when there are more than maxStarted. Must be called with the lock held.
+func (l *Log) start(key string, t time.Time) {
+	l.started[key] = t
+	if len(l.started) <= maxStarted {
+		return
+	}
+	keys := make([]string, 0, len(l.started))
+	for k := range l.started {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return l.started[keys[i]].Before(l.started[keys[j]]) })
+	for _, k := range keys[:len(keys)-maxStarted*9/10] {
+		delete(l.started, k)
+	}
+}
+
+// Dropped returns the number of records that were dropped because the queue was full.
+func (l *Log) Dropped() int64 {
+	return atomic.LoadInt64(&l.dropped)
+}
+
+// Collectors returns the Prometheus collectors of the log.
+func (l *Log) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "audit_records_dropped_total",
+			Help: "Number of audit records that were dropped because the audit log sink couldn't keep up",
+		}, func() float64 {
+			return float64(l.Dropped())
+		}),
+	}
+}
+
+// Run writes the recorded records to the sink until the given context is cancelled. Records that are queued
+// at that time are written before the sink is closed.
+func (l *Log) Run(ctx context.Context) error {
+	defer func() {
+		close(l.done)
+		ctx := dcontext.WithoutCancel(ctx)
+		for {
+			select {
+			case r := <-l.queue:
+				l.write(ctx, r)
+			default:
+				if err := l.sink.close(); err != nil {
+					dlog.Errorf(ctx, "unable to close audit log: %v", err)
+				}
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case r := <-l.queue:
+			l.write(ctx, r)
+		}
+	}
+}
+
+func (l *Log) write(ctx context.Context, r *Record) {
+	if n := atomic.SwapInt64(&l.unreported, 0); n > 0 {
+		dlog.Warnf(ctx, "%d records were dropped from the audit log because its sink couldn't keep up", n)
+	}
+	if err := l.sink.write(ctx, r); err != nil {
+		dlog.Errorf(ctx, "unable to write %s record to audit log: %v", r.Event, err)
+	}
+}
+
+// Records returns the records that match the given request, oldest first.
+func (l *Log) Records(ctx context.Context, req *rpc.AuditLogRequest) ([]*Record, error) {
+	rs, err := l.sink.records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ms := rs[:0]
+	for _, r := range rs {
+		if r.Matches(req) {
+			ms = append(ms, r)
+		}
+	}
+	sort.SliceStable(ms, func(i, j int) bool { return ms[i].Time.Before(ms[j].Time) })
+	return ms, nil
+}
@@ -0,0 +1,189 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+)
+
+// runLog records the given records in a Log that writes to the given sink, and returns when they are written.
+func runLog(t *testing.T, s sink, records ...*Record) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	l := newLog(s)
+	done := make(chan error, 1)
+	go func() { done <- l.Run(ctx) }()
+	lctx := WithLog(ctx, l)
+	for _, r := range records {
+		Add(lctx, r)
+	}
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func testRecords(t0 time.Time) []*Record {
+	client := &rpc.ClientInfo{Name: "john@host", InstallId: "abc", KubeUser: "john"}
+	cept := &rpc.InterceptInfo{
+		Spec:          &rpc.InterceptSpec{Name: "api-http", Client: "john@host", Agent: "api", Namespace: "default"},
+		ClientSession: &rpc.SessionInfo{SessionId: "s1"},
+	}
+	at := func(r *Record, d time.Duration) *Record {
+		r.Time = t0.Add(d)
+		return r
+	}
+	return []*Record{
+		at(ClientRecord(SessionArrived, "s1", client), 0),
+		at(&Record{Event: AgentInjected, Namespace: "default", Workload: "api"}, time.Second),
+		at(InterceptRecord(InterceptCreated, cept, client), 2*time.Second),
+		at(InterceptRecord(InterceptRemoved, cept, nil), time.Minute),
+		at(ClientRecord(SessionExpired, "s1", client), time.Hour),
+	}
+}
+
+func TestLog_durations(t *testing.T) {
+	ss := newStdoutSink()
+	out := &bytes.Buffer{}
+	ss.out = out
+	t0 := time.Now().Truncate(time.Second)
+	runLog(t, ss, testRecords(t0)...)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 5)
+	var r Record
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &r))
+	assert.Equal(t, Record{Time: r.Time, Event: SessionArrived, SessionID: "s1", Client: "john@host", InstallID: "abc", ReportedKubeUser: "john"}, r)
+	assert.True(t, t0.Equal(r.Time))
+
+	rs, err := ss.records(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rs, 5)
+	assert.Zero(t, rs[2].DurationSeconds)
+	assert.Equal(t, InterceptRemoved, rs[3].Event)
+	assert.Equal(t, "john@host", rs[3].Client)
+	assert.Equal(t, "api", rs[3].Workload)
+	assert.Equal(t, float64(58), rs[3].DurationSeconds)
+	assert.Equal(t, float64(3600), rs[4].DurationSeconds)
+}
+
+func TestLog_dropsWhenFull(t *testing.T) {
+	l := newLog(&stdoutSink{out: &bytes.Buffer{}})
+
+	// Nothing reads the queue, so the records beyond its size are dropped instead of blocking.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < queueSize+10; i++ {
+			l.Record(&Record{Event: AgentInjected, Namespace: "default", Workload: "api"})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Record blocked on a full queue")
+	}
+	assert.Equal(t, int64(10), l.Dropped())
+}
+
+func TestLog_startedIsBounded(t *testing.T) {
+	l := newLog(&stdoutSink{out: &bytes.Buffer{}})
+	t0 := time.Now()
+	for i := 0; i <= maxStarted; i++ {
+		l.Record(&Record{Time: t0.Add(time.Duration(i) * time.Second), Event: SessionArrived, SessionID: fmt.Sprintf("s%d", i)})
+	}
+	l.mu.Lock()
+	n := len(l.started)
+	_, oldest := l.started["s0"]
+	_, newest := l.started[fmt.Sprintf("s%d", maxStarted)]
+	l.mu.Unlock()
+	assert.LessOrEqual(t, n, maxStarted)
+	assert.False(t, oldest)
+	assert.True(t, newest)
+}
+
+func TestLog_Records(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "audit", "audit.log")
+	fs, err := newFileSink(name)
+	require.NoError(t, err)
+	t0 := time.Now().Truncate(time.Second)
+	runLog(t, fs, testRecords(t0)...)
+
+	// A line that was cut short is skipped
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"time":"2023-`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	fs, err = newFileSink(name)
+	require.NoError(t, err)
+	defer fs.close()
+	l := newLog(fs)
+	ctx := context.Background()
+	rs, err := l.Records(ctx, &rpc.AuditLogRequest{})
+	require.NoError(t, err)
+	assert.Len(t, rs, 5)
+
+	rs, err = l.Records(ctx, &rpc.AuditLogRequest{Workload: "api", Namespace: "default", Since: timestamppb.New(t0.Add(2 * time.Second))})
+	require.NoError(t, err)
+	require.Len(t, rs, 2)
+	assert.Equal(t, InterceptCreated, rs[0].Event)
+	assert.Equal(t, InterceptRemoved, rs[1].Event)
+
+	rs, err = l.Records(ctx, &rpc.AuditLogRequest{Client: "jane@host"})
+	require.NoError(t, err)
+	assert.Empty(t, rs)
+}
+
+func TestEventsSink(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ki := fake.NewSimpleClientset()
+	ctx = k8sapi.WithK8sInterface(ctx, ki)
+	ctx = managerutil.WithEnv(ctx, &managerutil.Env{ManagerNamespace: "ambassador"})
+	// Emulate the API server, which generates the names of the Events.
+	ki.PrependReactor("create", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if ev := action.(k8stesting.CreateAction).GetObject().(*core.Event); ev.Name == "" {
+			ev.Name = ev.GenerateName + utilrand.String(5)
+		}
+		return false, nil, nil
+	})
+	es, err := newEventsSink(ctx)
+	require.NoError(t, err)
+
+	t0 := time.Now().Truncate(time.Second)
+	records := testRecords(t0)
+	for _, r := range records {
+		require.NoError(t, es.write(ctx, r))
+	}
+	el, err := ki.CoreV1().Events("ambassador").List(ctx, meta.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, el.Items, len(records))
+	ev := el.Items[0]
+	assert.True(t, strings.HasPrefix(ev.Name, "traffic-manager-audit-"))
+	assert.Equal(t, "true", ev.Labels[auditLabel])
+	assert.Equal(t, "traffic-manager", ev.InvolvedObject.Name)
+	assert.Contains(t, []string{"SessionArrived", "AgentInjected", "InterceptCreated", "InterceptRemoved", "SessionExpired"}, ev.Reason)
+
+	rs, err := es.records(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rs, len(records))
+}
@@ -0,0 +1,210 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typed "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+	"github.com/telepresenceio/telepresence/v2/pkg/install"
+)
+
+// maxStdoutRecords is the number of records that the stdout sink keeps in memory so that they can be queried.
+const maxStdoutRecords = 1000
+
+// stdoutSink writes the records to stdout. Stdout can't be read back, so the most recent records are also kept
+// in memory.
+type stdoutSink struct {
+	sync.Mutex
+	out    io.Writer
+	recent []*Record
+}
+
+func newStdoutSink() *stdoutSink {
+	return &stdoutSink{out: os.Stdout}
+}
+
+func (s *stdoutSink) write(_ context.Context, r *Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	if len(s.recent) == maxStdoutRecords {
+		copy(s.recent, s.recent[1:])
+		s.recent = s.recent[:maxStdoutRecords-1]
+	}
+	s.recent = append(s.recent, r)
+	_, err = s.out.Write(append(data, '\n'))
+	return err
+}
+
+func (s *stdoutSink) records(context.Context) ([]*Record, error) {
+	s.Lock()
+	defer s.Unlock()
+	rs := make([]*Record, len(s.recent))
+	copy(rs, s.recent)
+	return rs, nil
+}
+
+func (s *stdoutSink) close() error {
+	return nil
+}
+
+// fileSink appends the records to a file.
+type fileSink struct {
+	sync.Mutex
+	name string
+	file *os.File
+}
+
+func newFileSink(name string) (*fileSink, error) {
+	if name == "" {
+		return nil, fmt.Errorf("the %s audit log sink requires a file name", SinkFile)
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{name: name, file: f}, nil
+}
+
+func (s *fileSink) write(_ context.Context, r *Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	_, err = s.file.Write(append(data, '\n'))
+	return err
+}
+
+func (s *fileSink) records(context.Context) ([]*Record, error) {
+	f, err := os.Open(s.name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var rs []*Record
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		r := &Record{}
+		// A line that was cut short by a crash is skipped.
+		if json.Unmarshal(sc.Bytes(), r) == nil {
+			rs = append(rs, r)
+		}
+	}
+	return rs, sc.Err()
+}
+
+func (s *fileSink) close() error {
+	return s.file.Close()
+}
+
+// auditLabel is the label of the Kubernetes Events that the events sink creates.
+const auditLabel = "telepresence.io/audit"
+
+// eventsSink creates a Kubernetes Event in the namespace of the traffic-manager for each record. The message of
+// the Event is the JSON encoded record. The API server deletes Events when its --event-ttl (one hour by default)
+// has passed, so this sink only retains the recent records.
+type eventsSink struct {
+	api        typed.EventInterface
+	namespace  string
+	reportedBy string
+}
+
+func newEventsSink(ctx context.Context) (*eventsSink, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	ns := managerutil.GetEnv(ctx).ManagerNamespace
+	return &eventsSink{
+		api:        k8sapi.GetK8sInterface(ctx).CoreV1().Events(ns),
+		namespace:  ns,
+		reportedBy: host,
+	}, nil
+}
+
+var eventReasons = map[Event]string{ //nolint:gochecknoglobals // constant
+	SessionArrived:   "SessionArrived",
+	SessionDeparted:  "SessionDeparted",
+	SessionExpired:   "SessionExpired",
+	InterceptCreated: "InterceptCreated",
+	InterceptUpdated: "InterceptUpdated",
+	InterceptRemoved: "InterceptRemoved",
+	AgentInjected:    "AgentInjected",
+	AgentUninstalled: "AgentUninstalled",
+}
+
+func (s *eventsSink) write(ctx context.Context, r *Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	t := meta.NewTime(r.Time)
+	_, err = s.api.Create(ctx, &core.Event{
+		ObjectMeta: meta.ObjectMeta{
+			// The API server generates a unique name, so that the Events of several replicas never collide.
+			GenerateName: install.ManagerAppName + "-audit-",
+			Namespace:    s.namespace,
+			Labels:       map[string]string{auditLabel: "true"},
+		},
+		InvolvedObject: core.ObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Namespace:  s.namespace,
+			Name:       install.ManagerAppName,
+		},
+		Reason:              eventReasons[r.Event],
+		Message:             string(data),
+		Source:              core.EventSource{Component: install.ManagerAppName},
+		FirstTimestamp:      t,
+		LastTimestamp:       t,
+		Count:               1,
+		Type:                core.EventTypeNormal,
+		ReportingController: "telepresence.io/" + install.ManagerAppName,
+		ReportingInstance:   s.reportedBy,
+	}, meta.CreateOptions{})
+	return err
+}
+
+func (s *eventsSink) records(ctx context.Context) ([]*Record, error) {
+	var rs []*Record
+	opts := meta.ListOptions{LabelSelector: auditLabel + "=true"}
+	for {
+		el, err := s.api.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range el.Items {
+			r := &Record{}
+			if json.Unmarshal([]byte(el.Items[i].Message), r) == nil {
+				rs = append(rs, r)
+			}
+		}
+		if el.Continue == "" {
+			return rs, nil
+		}
+		opts.Continue = el.Continue
+	}
+}
+
+func (s *eventsSink) close() error {
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/datawire/dlib/dlog"
 	"github.com/datawire/k8sapi/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/internal/audit"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
 	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
 	"github.com/telepresenceio/telepresence/v2/pkg/agentmap"
@@ -190,6 +191,7 @@ func (a *agentInjector) inject(ctx context.Context, req *admission.AdmissionRequ
 	if len(patches) > 0 {
 		dlog.Infof(ctx, "Injecting %d patches into pod %s.%s", len(patches), pod.Name, pod.Namespace)
 		span.SetAttributes(attribute.Stringer("tel2.patches", patches))
+		audit.Add(ctx, &audit.Record{Event: audit.AgentInjected, Namespace: config.Namespace, Workload: config.WorkloadName})
 	}
 	return patches, nil
 }
@@ -0,0 +1,44 @@
+package mutator
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	admissions = prometheus.NewCounterVec(prometheus.CounterOpts{ //nolint:gochecknoglobals // metric
+		Name: "agent_injector_admissions_total",
+		Help: "Number of admission requests handled by the agent injector, by operation and result",
+	}, []string{"operation", "result"})
+
+	requestErrors = prometheus.NewCounter(prometheus.CounterOpts{ //nolint:gochecknoglobals // metric
+		Name: "agent_injector_request_errors_total",
+		Help: "Number of webhook requests that the agent injector was unable to handle",
+	})
+
+	agentMapRegenerations = prometheus.NewCounter(prometheus.CounterOpts{ //nolint:gochecknoglobals // metric
+		Name: "agent_map_regenerations_total",
+		Help: "Number of times that the agent configs were regenerated",
+	})
+
+	agentConfigsRegenerated = prometheus.NewCounterVec(prometheus.CounterOpts{ //nolint:gochecknoglobals // metric
+		Name: "agent_configs_regenerated_total",
+		Help: "Number of agent configs that were updated or removed when the agent configs were regenerated",
+	}, []string{"result"})
+)
+
+// Admission results.
+const (
+	admissionPatched   = "patched"
+	admissionUnchanged = "unchanged"
+	admissionFailed    = "failed"
+)
+
+// Collectors returns the Prometheus collectors of the agent injector.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		admissions,
+		requestErrors,
+		agentMapRegenerations,
+		agentConfigsRegenerated,
+	}
+}
@@ -78,6 +78,7 @@ func ServeMutator(ctx context.Context) error {
 		dlog.Debug(ctx, "Received webhook request...")
 		bytes, statusCode, err := serveMutatingFunc(ctx, r, ai.inject)
 		if err != nil {
+			requestErrors.Inc()
 			dlog.Errorf(ctx, "error handling webhook request: %v", err)
 			w.WriteHeader(statusCode)
 			bytes = []byte(err.Error())
@@ -223,9 +224,11 @@ func serveMutatingFunc(ctx context.Context, r *http.Request, mf mutatorFunc) ([]
 		patchOps, err = mf(ctx, request)
 	}
 
+	operation := string(request.Operation)
 	if err != nil {
 		// If the handler returned an error, still allow the object creation, and incorporate
 		// the error message into the response
+		admissions.WithLabelValues(operation, admissionFailed).Inc()
 		dlog.Errorf(ctx, "mutating function error: %v", err)
 		response.Allowed = false
 		response.Result = &meta.Status{
@@ -233,6 +236,11 @@ func serveMutatingFunc(ctx context.Context, r *http.Request, mf mutatorFunc) ([]
 		}
 	} else {
 		// Otherwise, encode the patch operations to JSON and return a positive response.
+		if len(patchOps) > 0 {
+			admissions.WithLabelValues(operation, admissionPatched).Inc()
+		} else {
+			admissions.WithLabelValues(operation, admissionUnchanged).Inc()
+		}
 		patchBytes, err := json.Marshal(patchOps)
 		if err != nil {
 			return nil, http.StatusInternalServerError, fmt.Errorf("could not marshal JSON patch: %v", err)
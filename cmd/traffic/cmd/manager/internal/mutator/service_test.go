@@ -0,0 +1,61 @@
+package mutator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admission "k8s.io/api/admission/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/datawire/dlib/dlog"
+)
+
+func admissionRequest(t *testing.T, namespace string) *http.Request {
+	body, err := json.Marshal(&admission.AdmissionReview{
+		TypeMeta: meta.TypeMeta{Kind: "AdmissionReview", APIVersion: "admission.k8s.io/v1"},
+		Request: &admission.AdmissionRequest{
+			UID:       "1234",
+			Namespace: namespace,
+			Operation: admission.Create,
+		},
+	})
+	require.NoError(t, err)
+	r := httptest.NewRequest(http.MethodPost, "/traffic-agent", bytes.NewReader(body))
+	r.Header.Set("Content-Type", jsonContentType)
+	return r
+}
+
+func TestServeMutatingFunc_metrics(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	count := func(result string) float64 {
+		return testutil.ToFloat64(admissions.WithLabelValues(string(admission.Create), result))
+	}
+	patched, unchanged, failed := count(admissionPatched), count(admissionUnchanged), count(admissionFailed)
+
+	patch := func(context.Context, *admission.AdmissionRequest) (patchOps, error) {
+		return patchOps{{Op: "add", Path: "/metadata/annotations", Value: map[string]string{}}}, nil
+	}
+	_, status, err := serveMutatingFunc(ctx, admissionRequest(t, "default"), patch)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, patched+1, count(admissionPatched))
+
+	// Namespaces that aren't of interest are never patched
+	_, _, err = serveMutatingFunc(ctx, admissionRequest(t, meta.NamespaceSystem), patch)
+	require.NoError(t, err)
+	assert.Equal(t, unchanged+1, count(admissionUnchanged))
+
+	_, _, err = serveMutatingFunc(ctx, admissionRequest(t, "default"), func(context.Context, *admission.AdmissionRequest) (patchOps, error) {
+		return nil, errors.New("boom")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, failed+1, count(admissionFailed))
+}
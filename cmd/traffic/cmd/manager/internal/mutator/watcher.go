@@ -23,6 +23,7 @@ import (
 	"github.com/datawire/dlib/dlog"
 	"github.com/datawire/dlib/dtime"
 	"github.com/datawire/k8sapi/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/internal/audit"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/internal/mutator/v25uninstall"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
 	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
@@ -155,6 +156,7 @@ func RegenerateAgentMaps(ctx context.Context, agentImage string) error {
 	if err != nil {
 		return err
 	}
+	agentMapRegenerations.Inc()
 	nss := env.ManagedNamespaces
 	if len(nss) == 0 {
 		return regenerateAgentMaps(ctx, "", gc)
@@ -190,6 +192,7 @@ func regenerateAgentMaps(ctx context.Context, ns string, gc *agentmap.GeneratorC
 					return err
 				}
 				delete(cm.Data, n) // Workload no longer exists
+				agentConfigsRegenerated.WithLabelValues("removed").Inc()
 				changed = true
 				continue
 			}
@@ -205,6 +208,7 @@ func regenerateAgentMaps(ctx context.Context, ns string, gc *agentmap.GeneratorC
 				return err
 			}
 			cm.Data[n] = string(js)
+			agentConfigsRegenerated.WithLabelValues("updated").Inc()
 			changed = true
 		}
 		if changed {
@@ -250,9 +254,13 @@ func (c *configWatcher) Run(ctx context.Context) error {
 		case <-ctx.Done():
 			return nil
 		case e := <-delCh:
-			c.handleDelete(ctx, e)
+			if managerutil.IsLeader(ctx) {
+				c.handleDelete(ctx, e)
+			}
 		case e := <-addCh:
-			c.handleAdd(ctx, e)
+			if managerutil.IsLeader(ctx) {
+				c.handleAdd(ctx, e)
+			}
 		}
 	}
 }
@@ -320,6 +328,7 @@ func (*configWatcher) handleDelete(ctx context.Context, e entry) {
 		return
 	}
 	triggerRollout(ctx, wl)
+	audit.Add(ctx, &audit.Record{Event: audit.AgentUninstalled, Namespace: ac.Namespace, Workload: ac.WorkloadName})
 }
 
 func (c *configWatcher) GetInto(key, ns string, into any) (bool, error) {
@@ -670,6 +679,9 @@ func (c *configWatcher) svcEventHandler(ctx context.Context, evCh <-chan watch.E
 			if !ok {
 				return // restart watcher
 			}
+			if !managerutil.IsLeader(ctx) {
+				continue
+			}
 			switch event.Type {
 			case watch.Deleted:
 				if svc, ok := event.Object.(*core.Service); ok {
@@ -757,6 +769,12 @@ func (c *configWatcher) DeleteMapsAndRolloutAll(ctx context.Context) {
 				continue
 			}
 			triggerRollout(ctx, wl)
+			audit.Add(ctx, &audit.Record{
+				Event:     audit.AgentUninstalled,
+				Namespace: ac.Namespace,
+				Workload:  ac.WorkloadName,
+				Message:   "the traffic-manager is uninstalled",
+			})
 		}
 		if err := api.ConfigMaps(ns).Delete(ctx, agentconfig.ConfigMap, *now); err != nil {
 			dlog.Errorf(ctx, "unable to delete ConfigMap %s-%s: %v", agentconfig.ConfigMap, ns, err)
@@ -0,0 +1,175 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/datawire/dlib/dcontext"
+	"github.com/datawire/dlib/dlog"
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+)
+
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// leaderLabel is the label that the leader puts on its pod. The Service that clients and agents connect to
+// selects it, so that the standby replicas can be ready without receiving their connections.
+const leaderLabel = "telepresence.io/leader"
+
+var errLeadershipLost = errors.New("leadership lost")
+
+// leadership is held by the replica of the traffic-manager that serves clients and agents. The other
+// replicas stand by, and one of them takes over when the leader terminates or fails to renew its Lease.
+type leadership struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	lost   atomic.Bool
+}
+
+// awaitLeadership blocks until the given identity has acquired the Lease with the given name in the namespace
+// of the traffic-manager. The returned context is cancelled if the leadership is lost, so that the replica
+// terminates and restarts as a standby.
+func awaitLeadership(ctx context.Context, leaseName, identity string) (context.Context, *leadership, error) {
+	env := managerutil.GetEnv(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	l := &leadership{cancel: cancel, done: make(chan struct{})}
+	started := make(chan struct{})
+	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: meta.ObjectMeta{
+				Name:      leaseName,
+				Namespace: env.ManagerNamespace,
+			},
+			Client:     k8sapi.GetK8sInterface(ctx).CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+		},
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Name:            leaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				dlog.Infof(ctx, "Acquired lease %s.%s as %s", leaseName, env.ManagerNamespace, identity)
+				close(started)
+			},
+			OnStoppedLeading: func() {
+				select {
+				case <-started:
+					if ctx.Err() == nil {
+						l.lost.Store(true)
+						dlog.Errorf(ctx, "Lost lease %s.%s", leaseName, env.ManagerNamespace)
+					}
+				default:
+				}
+				cancel()
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					dlog.Infof(ctx, "Standing by while %s holds lease %s.%s", leader, leaseName, env.ManagerNamespace)
+				}
+			},
+		},
+	})
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("unable to create leader elector: %w", err)
+	}
+	go func() {
+		defer close(l.done)
+		le.Run(ctx)
+	}()
+	select {
+	case <-started:
+		return ctx, l, nil
+	case <-l.done:
+		return nil, nil, ctx.Err()
+	}
+}
+
+// release releases the Lease so that a standby replica can take over without waiting for it to expire. It
+// returns errLeadershipLost if the leadership was lost before it was released.
+func (l *leadership) release() error {
+	l.cancel()
+	<-l.done
+	if l.lost.Load() {
+		return errLeadershipLost
+	}
+	return nil
+}
+
+// lead waits until the given identity has acquired the Lease with the given name, and then calls serve with a
+// context that is cancelled if the leadership is lost. The identity is the name of the pod, which is labeled
+// as the leader while serve runs. The Lease is released when serve returns.
+func lead(ctx context.Context, leaseName, identity string, serve func(context.Context) error) error {
+	// The label remains on the pod when the container restarts after a lost leadership.
+	if err := setLeaderLabel(ctx, identity, false); err != nil {
+		dlog.Errorf(ctx, "unable to remove label %s from pod %s: %v", leaderLabel, identity, err)
+	}
+	lctx, ldr, err := awaitLeadership(ctx, leaseName, identity)
+	if err != nil {
+		return err
+	}
+	if err = claimLeaderLabel(lctx, identity); err == nil {
+		err = serve(lctx)
+	}
+
+	// Remove the label before the Lease is released, so that the next leader is alone in having it.
+	uctx, cancel := context.WithTimeout(dcontext.WithoutCancel(ctx), renewDeadline)
+	defer cancel()
+	if lerr := setLeaderLabel(uctx, identity, false); lerr != nil {
+		dlog.Errorf(ctx, "unable to remove label %s from pod %s: %v", leaderLabel, identity, lerr)
+	}
+	if rerr := ldr.release(); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// claimLeaderLabel labels the pod with the given name as the leader, and removes the label from the pods of
+// previous leaders that didn't remove it themselves.
+func claimLeaderLabel(ctx context.Context, podName string) error {
+	env := managerutil.GetEnv(ctx)
+	pods, err := k8sapi.GetK8sInterface(ctx).CoreV1().Pods(env.ManagerNamespace).List(ctx, meta.ListOptions{
+		LabelSelector: leaderLabel + "=true",
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list the pods labeled %s: %w", leaderLabel, err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Name != podName {
+			if err = setLeaderLabel(ctx, pod.Name, false); err != nil {
+				return err
+			}
+		}
+	}
+	return setLeaderLabel(ctx, podName, true)
+}
+
+// setLeaderLabel adds the leader label to the pod with the given name, or removes it.
+func setLeaderLabel(ctx context.Context, podName string, leader bool) error {
+	value := "null"
+	if leader {
+		value = `"true"`
+	}
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:%s}}}`, leaderLabel, value)
+	env := managerutil.GetEnv(ctx)
+	_, err := k8sapi.GetK8sInterface(ctx).CoreV1().Pods(env.ManagerNamespace).Patch(
+		ctx, podName, types.MergePatchType, []byte(patch), meta.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to patch pod %s.%s: %w", podName, env.ManagerNamespace, err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/state"
+)
+
+func TestAwaitLeadership(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	ctx = k8sapi.WithK8sInterface(ctx, fake.NewSimpleClientset())
+	ctx = managerutil.WithEnv(ctx, &managerutil.Env{ManagerNamespace: "ambassador"})
+	const leaseName = "traffic-manager-leader"
+
+	ctx1, l1, err := awaitLeadership(ctx, leaseName, "replica-1")
+	require.NoError(t, err)
+
+	type result struct {
+		ctx context.Context
+		l   *leadership
+		err error
+	}
+	standby := make(chan result, 1)
+	go func() {
+		ctx2, l2, err := awaitLeadership(ctx, leaseName, "replica-2")
+		standby <- result{ctx: ctx2, l: l2, err: err}
+	}()
+
+	// The second replica stands by while the first one holds the lease
+	select {
+	case <-standby:
+		t.Fatal("second replica acquired a lease that is held by the first")
+	case <-time.After(3 * retryPeriod):
+	}
+
+	// Releasing the lease hands it over to the standby
+	require.NoError(t, l1.release())
+	assert.Error(t, ctx1.Err())
+	select {
+	case r := <-standby:
+		require.NoError(t, r.err)
+		assert.NoError(t, r.ctx.Err())
+		assert.NoError(t, r.l.release())
+	case <-time.After(3 * retryPeriod):
+		t.Fatal("second replica didn't take over the released lease")
+	}
+}
+
+func leaderPods(t *testing.T, ctx context.Context) []string {
+	pods, err := k8sapi.GetK8sInterface(ctx).CoreV1().Pods("ambassador").List(ctx, meta.ListOptions{})
+	require.NoError(t, err)
+	var names []string
+	for _, pod := range pods.Items {
+		if pod.Labels[leaderLabel] == "true" {
+			names = append(names, pod.Name)
+		}
+	}
+	return names
+}
+
+func TestLead_takeover(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	pod := func(name string, labels map[string]string) *core.Pod {
+		return &core.Pod{ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "ambassador", Labels: labels}}
+	}
+	ctx = k8sapi.WithK8sInterface(ctx, fake.NewSimpleClientset(
+		pod("replica-1", nil),
+		pod("replica-2", nil),
+		pod("replica-0", map[string]string{leaderLabel: "true"}), // a previous leader that didn't clean up
+	))
+	ctx = managerutil.WithEnv(ctx, &managerutil.Env{ManagerNamespace: "ambassador"})
+	const (
+		leaseName  = "traffic-manager-leader"
+		secretName = "traffic-manager-state"
+	)
+
+	// serve is what a replica does while it leads. It restores the state that the previous leader persisted,
+	// reports the restored clients, adds a client of its own, and persists the state until it stops leading.
+	serve := func(name string, restored chan<- []string) func(context.Context) error {
+		return func(ctx context.Context) error {
+			s := state.NewState(ctx)
+			if err := s.Restore(ctx, secretName); err != nil {
+				return err
+			}
+			var ids []string
+			for id := range s.GetAllClients() {
+				ids = append(ids, id)
+			}
+			s.AddClient(&rpc.ClientInfo{Name: name, Product: "telepresence"}, time.Now())
+			restored <- ids
+			return s.RunPersister(ctx, secretName)
+		}
+	}
+
+	restored1 := make(chan []string, 1)
+	ctx1, cancel1 := context.WithCancel(ctx)
+	done1 := make(chan error, 1)
+	go func() { done1 <- lead(ctx1, leaseName, "replica-1", serve("replica-1", restored1)) }()
+	select {
+	case ids := <-restored1:
+		assert.Empty(t, ids)
+	case <-time.After(3 * retryPeriod):
+		t.Fatal("first replica didn't acquire the lease")
+	}
+	assert.Equal(t, []string{"replica-1"}, leaderPods(t, ctx))
+
+	// The second replica stands by while the first one leads.
+	restored2 := make(chan []string, 1)
+	done2 := make(chan error, 1)
+	go func() { done2 <- lead(ctx, leaseName, "replica-2", serve("replica-2", restored2)) }()
+	select {
+	case <-restored2:
+		t.Fatal("second replica acquired a lease that is held by the first")
+	case <-time.After(3 * retryPeriod):
+	}
+
+	// When the first replica terminates, the second one takes over, with the state that the first persisted.
+	cancel1()
+	require.NoError(t, <-done1)
+	select {
+	case ids := <-restored2:
+		assert.Len(t, ids, 1)
+	case <-time.After(3 * retryPeriod):
+		t.Fatal("second replica didn't take over the released lease")
+	}
+	assert.Equal(t, []string{"replica-2"}, leaderPods(t, ctx))
+
+	cancel()
+	require.NoError(t, <-done2)
+	assert.Empty(t, leaderPods(t, ctx))
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
@@ -16,14 +17,15 @@ import (
 	"k8s.io/client-go/rest"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/datawire/dlib/dgroup"
 	"github.com/datawire/dlib/dhttp"
 	"github.com/datawire/dlib/dlog"
+	"github.com/datawire/dlib/dtime"
 	"github.com/datawire/k8sapi/pkg/k8sapi"
 	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/internal/audit"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/internal/mutator"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
 	"github.com/telepresenceio/telepresence/v2/pkg/tracing"
@@ -72,6 +74,20 @@ func Main(ctx context.Context, _ ...string) error {
 	}
 	ctx = k8sapi.WithK8sInterface(ctx, ki)
 
+	// Replicas that stand by serve the agent-injector too, so it must know whether this replica leads.
+	leader := &atomic.Bool{}
+	if env.LeaseName != "" {
+		ctx = managerutil.WithLeader(ctx, leader)
+	}
+
+	var auditLog *audit.Log
+	if env.AuditSink != "" {
+		if auditLog, err = audit.NewLog(ctx, env.AuditSink, env.AuditFile); err != nil {
+			return fmt.Errorf("unable to create the audit log: %w", err)
+		}
+		ctx = audit.WithLog(ctx, auditLog)
+	}
+
 	mgr, ctx, err := NewServiceFunc(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to initialize traffic manager: %w", err)
@@ -85,10 +101,9 @@ func Main(ctx context.Context, _ ...string) error {
 
 	g.Go("cli-config", mgr.RunConfigWatcher)
 
-	// Serve HTTP (including gRPC)
-	g.Go("httpd", func(ctx context.Context) error {
-		return serveHTTP(ctx, mgr)
-	})
+	if auditLog != nil {
+		g.Go("audit-log", auditLog.Run)
+	}
 
 	g.Go("prometheus", mgr.ServePrometheus)
 
@@ -98,7 +113,25 @@ func Main(ctx context.Context, _ ...string) error {
 		g.Go("agent-injector", mutator.ServeMutator)
 	}
 
-	g.Go("session-gc", mgr.RunSessionGCLoop)
+	// With more than one replica, only the one that holds the lease serves clients and agents. All replicas
+	// serve the agent-injector and the metrics.
+	if env.LeaseName != "" {
+		identity, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		g.Go("leader", func(ctx context.Context) error {
+			return lead(ctx, env.LeaseName, identity, func(ctx context.Context) error {
+				leader.Store(true)
+				defer leader.Store(false)
+				return serveLeader(ctx, mgr)
+			})
+		})
+	} else {
+		g.Go("leader", func(ctx context.Context) error {
+			return serveLeader(ctx, mgr)
+		})
+	}
 
 	if tracer != nil {
 		g.Go("tracer-grpc", func(c context.Context) error {
@@ -110,17 +143,72 @@ func Main(ctx context.Context, _ ...string) error {
 	return g.Wait()
 }
 
+// stateRestoreRetryInterval is the time to wait before retrying a failed attempt to restore the persisted state.
+const stateRestoreRetryInterval = 10 * time.Second
+
+// serveLeader serves clients and agents. The state that a previous leader persisted is restored first. If that
+// fails, the clients and agents are served anyway, but the state isn't persisted until a retried restore has
+// succeeded, because that would overwrite the state that couldn't be restored.
+func serveLeader(ctx context.Context, mgr Service) error {
+	env := managerutil.GetEnv(ctx)
+	restored := true
+	if err := restoreState(ctx, mgr); err != nil {
+		dlog.Errorf(ctx, "unable to restore state, it will not be persisted until it has been restored: %v", err)
+		restored = false
+	}
+
+	g := dgroup.NewGroup(ctx, dgroup.GroupConfig{})
+
+	// Serve HTTP (including gRPC)
+	g.Go("httpd", func(ctx context.Context) error {
+		return serveHTTP(ctx, mgr)
+	})
+
+	g.Go("session-gc", mgr.RunSessionGCLoop)
+
+	if env.StateSecret != "" {
+		g.Go("state-persister", func(ctx context.Context) error {
+			for !restored {
+				if dtime.SleepWithContext(ctx, stateRestoreRetryInterval); ctx.Err() != nil {
+					return nil
+				}
+				if err := restoreState(ctx, mgr); err != nil {
+					dlog.Errorf(ctx, "unable to restore state: %v", err)
+				} else {
+					restored = true
+				}
+			}
+			return mgr.State().RunPersister(ctx, env.StateSecret)
+		})
+	}
+	return g.Wait()
+}
+
+// restoreState restores the state that a previous leader persisted, and then removes the services that it
+// exposed for sessions that weren't restored.
+func restoreState(ctx context.Context, mgr Service) error {
+	if secret := managerutil.GetEnv(ctx).StateSecret; secret != "" {
+		if err := mgr.State().Restore(ctx, secret); err != nil {
+			return err
+		}
+	}
+	if err := mgr.State().RemoveStaleExposures(ctx); err != nil {
+		dlog.Errorf(ctx, "unable to remove stale exposed services: %v", err)
+	}
+	return nil
+}
+
 // ServePrometheus serves Prometheus metrics if env.PrometheusPort != 0.
 func (m *service) ServePrometheus(ctx context.Context) error {
 	env := managerutil.GetEnv(ctx)
 	port := env.PrometheusPort
 	if env.PrometheusPort != 0 {
-		promauto.NewGaugeFunc(prometheus.GaugeOpts{
-			Name: "client_count",
-			Help: "Number of Clients Connected",
-		}, func() float64 {
-			return float64(m.state.CountAllClients())
-		})
+		prometheus.MustRegister(m.state.Collectors()...)
+		prometheus.MustRegister(m.dnsCache.collectors()...)
+		prometheus.MustRegister(mutator.Collectors()...)
+		if auditLog := audit.GetLog(ctx); auditLog != nil {
+			prometheus.MustRegister(auditLog.Collectors()...)
+		}
 
 		sc := &dhttp.ServerConfig{
 			Handler: promhttp.Handler(),
@@ -2,6 +2,7 @@ package managerutil
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/datawire/dlib/dlog"
 	"github.com/telepresenceio/telepresence/rpc/v2/manager"
@@ -29,3 +30,20 @@ func GetSessionID(ctx context.Context) string {
 }
 
 type sessionContextKey struct{}
+
+// WithLeader returns a context with a flag that tells whether this replica of the traffic-manager holds the
+// leadership. Replicas that stand by serve the agent-injector, but leave the updates that it triggers to the leader.
+func WithLeader(ctx context.Context, leader *atomic.Bool) context.Context {
+	return context.WithValue(ctx, leaderContextKey{}, leader)
+}
+
+// IsLeader returns true if this replica of the traffic-manager holds the leadership. It is always true when
+// the traffic-manager runs without leader election.
+func IsLeader(ctx context.Context) bool {
+	if leader, ok := ctx.Value(leaderContextKey{}).(*atomic.Bool); ok {
+		return leader.Load()
+	}
+	return true
+}
+
+type leaderContextKey struct{}
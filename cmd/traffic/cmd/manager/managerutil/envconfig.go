@@ -18,6 +18,7 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
 	"github.com/telepresenceio/telepresence/v2/pkg/agentmap"
 	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
 )
 
 // Env is the traffic-manager's environment. It does not define any defaults because all
@@ -45,6 +46,31 @@ type Env struct {
 	TracingGrpcPort uint16            `env:"TRACING_GRPC_PORT,     parser=port-number,default=0"`
 	MaxReceiveSize  resource.Quantity `env:"GRPC_MAX_RECEIVE_SIZE, parser=quantity"`
 
+	// TunnelCompression, when set, overrides the compression requested by clients for their tunnel streams.
+	TunnelCompression *tunnel.Compression `env:"TUNNEL_COMPRESSION, parser=compression, default="`
+
+	// The TTL of the records in the answer to a DNS lookup decides how long the answer is cached, within the
+	// bounds of DnsCacheMinTTL and DnsCacheMaxTTL. A zero DnsCacheMaxTTL disables the cache, but concurrent lookups
+	// of the same name are still coalesced into one. DnsCacheNegativeTTL is the time that NXDOMAIN answers are cached.
+	DnsCacheMinTTL      time.Duration `env:"DNS_CACHE_MIN_TTL,      parser=time.ParseDuration, default=10s"`
+	DnsCacheMaxTTL      time.Duration `env:"DNS_CACHE_MAX_TTL,      parser=time.ParseDuration, default=30s"`
+	DnsCacheNegativeTTL time.Duration `env:"DNS_CACHE_NEGATIVE_TTL, parser=time.ParseDuration, default=0s"`
+	DnsCacheMaxEntries  int           `env:"DNS_CACHE_MAX_ENTRIES,  parser=strconv.ParseInt,   default=10000"`
+
+	// StateSecret is the name of the Secret that the client sessions and intercepts are persisted in, so that
+	// they survive a restart of the traffic-manager. The state isn't persisted when it's empty.
+	StateSecret string `env:"STATE_SECRET, parser=string, default="`
+
+	// LeaseName is the name of the Lease that replicas of the traffic-manager use to elect the one that serves
+	// clients and agents. The others stand by until they acquire the Lease. No election is made when it's empty.
+	LeaseName string `env:"LEASE_NAME, parser=string, default="`
+
+	// AuditSink is where the audit log of client sessions, intercepts, and agent injections and uninstalls is
+	// written: "stdout", "file", or "events". No audit log is kept when it's empty. AuditFile is the name of the
+	// file that the "file" sink appends to.
+	AuditSink string `env:"AUDIT_SINK, parser=string, default="`
+	AuditFile string `env:"AUDIT_FILE, parser=string, default="`
+
 	PodCIDRStrategy string       `env:"POD_CIDR_STRATEGY, parser=nonempty-string"`
 	PodCIDRs        []*net.IPNet `env:"POD_CIDRS,         parser=split-ipnet, default="`
 	PodIP           net.IP       `env:"POD_IP,            parser=ip"`
@@ -138,6 +164,21 @@ func fieldTypeHandlers() map[reflect.Type]envconfig.FieldTypeHandler {
 		},
 		Setter: func(dst reflect.Value, src interface{}) { dst.Set(reflect.ValueOf(src.(resource.Quantity))) },
 	}
+	fhs[reflect.TypeOf((*tunnel.Compression)(nil))] = envconfig.FieldTypeHandler{
+		Parsers: map[string]func(string) (any, error){
+			"compression": func(str string) (any, error) {
+				if str == "" {
+					return (*tunnel.Compression)(nil), nil
+				}
+				c, err := tunnel.ParseCompression(str)
+				if err != nil {
+					return nil, err
+				}
+				return &c, nil
+			},
+		},
+		Setter: func(dst reflect.Value, src interface{}) { dst.Set(reflect.ValueOf(src.(*tunnel.Compression))) },
+	}
 	fhs[reflect.TypeOf(net.IP{})] = envconfig.FieldTypeHandler{
 		Parsers: map[string]func(string) (any, error){
 			"ip": func(str string) (any, error) { //nolint:unparam // API requirement
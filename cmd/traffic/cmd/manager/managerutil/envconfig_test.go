@@ -13,6 +13,7 @@ import (
 	"github.com/datawire/k8sapi/pkg/k8sapi"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
 	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
 )
 
 func TestEnvconfig(t *testing.T) {
@@ -46,6 +47,9 @@ func TestEnvconfig(t *testing.T) {
 		AgentInjectorName:        "agent-injector",
 		ClientConnectionTTL:      24 * time.Hour,
 		ClientDnsExcludeSuffixes: []string{".com", ".io", ".net", ".org", ".ru"},
+		DnsCacheMinTTL:           10 * time.Second,
+		DnsCacheMaxTTL:           30 * time.Second,
+		DnsCacheMaxEntries:       10000,
 		LogLevel:                 "info",
 		MaxReceiveSize:           resource.MustParse("4Mi"),
 		PodCIDRStrategy:          "auto",
@@ -69,6 +73,29 @@ func TestEnvconfig(t *testing.T) {
 				e.SystemAHost = "app.getambassador.io"
 			},
 		},
+		"tunnel-compression": {
+			Input: map[string]string{
+				"TUNNEL_COMPRESSION": "zstd",
+			},
+			Output: func(e *managerutil.Env) {
+				c := tunnel.CompressionZstd
+				e.TunnelCompression = &c
+			},
+		},
+		"dns-cache": {
+			Input: map[string]string{
+				"DNS_CACHE_MIN_TTL":      "10s",
+				"DNS_CACHE_MAX_TTL":      "30s",
+				"DNS_CACHE_NEGATIVE_TTL": "5s",
+				"DNS_CACHE_MAX_ENTRIES":  "500",
+			},
+			Output: func(e *managerutil.Env) {
+				e.DnsCacheMinTTL = 10 * time.Second
+				e.DnsCacheMaxTTL = 30 * time.Second
+				e.DnsCacheNegativeTTL = 5 * time.Second
+				e.DnsCacheMaxEntries = 500
+			},
+		},
 		"complex": {
 			Input: map[string]string{
 				"CLIENT_ROUTING_NEVER_PROXY_SUBNETS": "10.20.30.0/24 10.20.40.0/24",
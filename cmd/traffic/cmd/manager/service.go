@@ -9,6 +9,7 @@ import (
 	"path"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,6 +25,7 @@ import (
 	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
 	"github.com/telepresenceio/telepresence/rpc/v2/systema"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/internal/ambassadoragent/cloudtoken"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/internal/audit"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/internal/cluster"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/internal/config"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/license"
@@ -62,6 +64,7 @@ type service struct {
 	cloudConfig   *rpc.AmbassadorCloudConfig
 	configWatcher config.Watcher
 	tokenService  cloudtoken.Service
+	dnsCache      *dnsCache
 
 	rpc.UnsafeManagerServer
 }
@@ -122,6 +125,7 @@ func NewService(ctx context.Context) (Service, context.Context, error) {
 	// These are context dependent so build them once the pool is up
 	ret.clusterInfo = cluster.NewInfo(ctx)
 	ret.state = state.NewState(ctx)
+	ret.dnsCache = newDNSCache(ctx, managerutil.GetEnv(ctx))
 	return ret, ctx, nil
 }
 
@@ -207,6 +211,7 @@ func (m *service) ArriveAsClient(ctx context.Context, client *rpc.ClientInfo) (*
 
 	sessionID := m.state.AddClient(client, m.clock.Now())
 	m.MaybeAddToken(ctx, client.GetApiKey())
+	audit.Add(m.ctx, audit.ClientRecord(audit.SessionArrived, sessionID, client))
 
 	installId := client.GetInstallId()
 	return &rpc.SessionInfo{
@@ -521,6 +526,7 @@ func (m *service) CreateIntercept(ctx context.Context, ciReq *rpc.CreateIntercep
 	if interceptInfo != nil {
 		tracing.RecordInterceptInfo(span, interceptInfo)
 	}
+	m.auditIntercept(audit.InterceptCreated, interceptInfo, "")
 	if m.cloudConfig == nil {
 		return interceptInfo, nil
 	}
@@ -557,6 +563,13 @@ func (m *service) CreateIntercept(ctx context.Context, ciReq *rpc.CreateIntercep
 	return interceptInfo, nil
 }
 
+// auditIntercept records the given event for the given intercept in the audit log.
+func (m *service) auditIntercept(event audit.Event, ii *rpc.InterceptInfo, message string) {
+	r := audit.InterceptRecord(event, ii, m.state.GetClient(ii.GetClientSession().GetSessionId()))
+	r.Message = message
+	audit.Add(m.ctx, r)
+}
+
 func (m *service) makeinterceptID(_ context.Context, sessionID string, name string) (string, error) {
 	// When something without a session ID (e.g. System A) calls this function,
 	// it is sending the intercept ID as the name, so we use that.
@@ -597,6 +610,7 @@ func (m *service) UpdateIntercept(ctx context.Context, req *rpc.UpdateInterceptR
 		if err != nil {
 			return nil, err
 		}
+		m.auditIntercept(audit.InterceptUpdated, intercept, "added preview domain "+intercept.PreviewDomain)
 		return intercept, nil
 	case *rpc.UpdateInterceptRequest_RemovePreviewDomain:
 		// Check if this is already done.
@@ -605,6 +619,7 @@ func (m *service) UpdateIntercept(ctx context.Context, req *rpc.UpdateInterceptR
 		if err != nil {
 			return nil, err
 		}
+		m.auditIntercept(audit.InterceptUpdated, intercept, "removed preview domain")
 		return intercept, nil
 	default:
 		panic(fmt.Errorf("unimplemented UpdateInterceptRequest action: %T", action))
@@ -824,11 +839,10 @@ func (m *service) ReviewIntercept(ctx context.Context, rIReq *rpc.ReviewIntercep
 
 func (m *service) Tunnel(server rpc.Manager_TunnelServer) error {
 	ctx := server.Context()
-	stream, err := tunnel.NewServerStream(ctx, server)
-	if err != nil {
-		return status.Errorf(codes.FailedPrecondition, "failed to connect stream: %v", err)
+	if c := managerutil.GetEnv(ctx).TunnelCompression; c != nil {
+		ctx = tunnel.WithCompressionOverride(ctx, *c)
 	}
-	return m.state.Tunnel(ctx, stream)
+	return tunnel.ServeStreams(ctx, server, m.state.Tunnel)
 }
 
 func (m *service) WatchDial(session *rpc.SessionInfo, stream rpc.Manager_WatchDialServer) error {
@@ -859,6 +873,55 @@ func (m *service) WatchDial(session *rpc.SessionInfo, stream rpc.Manager_WatchDi
 	}
 }
 
+func (m *service) SetEgressRules(ctx context.Context, request *rpc.EgressRulesRequest) (*empty.Empty, error) {
+	ctx = managerutil.WithSessionInfo(ctx, request.GetSession())
+	dlog.Debugf(ctx, "SetEgressRules called")
+	if err := m.state.EnsureEgressAgents(ctx, request.Rules); err != nil {
+		return nil, err
+	}
+	if err := m.state.SetEgressRules(ctx, request.GetSession().GetSessionId(), request.Rules); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+func (m *service) Expose(ctx context.Context, request *rpc.ExposeRequest) (*rpc.ExposeInfo, error) {
+	ctx = managerutil.WithSessionInfo(ctx, request.GetSession())
+	dlog.Debugf(ctx, "Expose called: %s:%d", request.Name, request.Port)
+	return m.state.Expose(ctx, request.GetSession().GetSessionId(), request)
+}
+
+func (m *service) Unexpose(ctx context.Context, request *rpc.UnexposeRequest) (*empty.Empty, error) {
+	ctx = managerutil.WithSessionInfo(ctx, request.GetSession())
+	dlog.Debugf(ctx, "Unexpose called: %s", request.Name)
+	if err := m.state.Unexpose(ctx, request.GetSession().GetSessionId(), request.Name); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+// GetAuditLog returns the records of the audit log that match the request.
+func (m *service) GetAuditLog(ctx context.Context, request *rpc.AuditLogRequest) (*rpc.AuditLog, error) {
+	ctx = managerutil.WithSessionInfo(ctx, request.GetSession())
+	dlog.Debug(ctx, "GetAuditLog called")
+	if m.state.GetClient(request.GetSession().GetSessionId()) == nil {
+		return nil, status.Errorf(codes.NotFound, "Client session %q not found", request.GetSession().GetSessionId())
+	}
+	al := audit.GetLog(m.ctx)
+	if al == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the traffic-manager has no audit log. It's enabled using the Helm chart value audit.sink")
+	}
+	rs, err := al.Records(ctx, request)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to read the audit log: %v", err)
+	}
+	records := make([]*rpc.AuditRecord, len(rs))
+	for i, r := range rs {
+		records[i] = r.ToRPC()
+	}
+	return &rpc.AuditLog{Records: records}, nil
+}
+
 // LookupHost
 // Deprecated: Use LookupDNS
 //
@@ -956,6 +1019,28 @@ func (m *service) LookupDNS(ctx context.Context, request *rpc.DNSRequest) (*rpc.
 	qtn := dns2.TypeToString[qType]
 	dlog.Debugf(ctx, "LookupDNS %s %s", request.Name, qtn)
 
+	// Lookups are cached and coalesced per set of namespaces that agents resolve names in, so that clients
+	// with intercepts in the same namespaces, and all clients without intercepts, share the answers.
+	sessionID := request.GetSession().GetSessionId()
+	key := dnsCacheKey{
+		scope: strings.Join(m.state.InterceptedNamespaces(sessionID), ","),
+		name:  request.Name,
+		qType: qType,
+	}
+	rrs, rCode, err := m.dnsCache.lookup(ctx, key, func(c context.Context) (dnsproxy.RRs, int, error) {
+		return m.lookupDNS(managerutil.WithSessionInfo(c, request.GetSession()), request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dnsproxy.ToRPC(rrs, rCode)
+}
+
+// lookupDNS resolves the given request using the agents of the intercepts of the requesting client, or
+// using the traffic-manager when no agents reply.
+func (m *service) lookupDNS(ctx context.Context, request *rpc.DNSRequest) (dnsproxy.RRs, int, error) {
+	qType := uint16(request.Type)
+	qtn := dns2.TypeToString[qType]
 	rrs, rCode, err := m.state.AgentsLookupDNS(ctx, request.GetSession().GetSessionId(), request)
 	if err != nil {
 		dlog.Errorf(ctx, "AgentsLookupDNS %s %s: %v", request.Name, qtn, err)
@@ -970,7 +1055,7 @@ func (m *service) LookupDNS(ctx context.Context, request *rpc.DNSRequest) (*rpc.
 		rrs, rCode, err = dnsproxy.Lookup(ctx, qType, request.Name)
 		if err != nil {
 			dlog.Debugf(ctx, "LookupDNS on traffic-manager: %s %s -> %s %s", request.Name, qtn, dns2.RcodeToString[rCode], err)
-			return nil, err
+			return nil, rCode, err
 		}
 		if len(rrs) == 0 {
 			dlog.Debugf(ctx, "LookupDNS on traffic-manager: %s %s -> %s", request.Name, qtn, dns2.RcodeToString[rCode])
@@ -978,7 +1063,7 @@ func (m *service) LookupDNS(ctx context.Context, request *rpc.DNSRequest) (*rpc.
 			dlog.Debugf(ctx, "LookupDNS on traffic-manager: %s %s -> %s", request.Name, qtn, rrs)
 		}
 	}
-	return dnsproxy.ToRPC(rrs, rCode)
+	return rrs, rCode, nil
 }
 
 func (m *service) AgentLookupDNSResponse(ctx context.Context, response *rpc.DNSAgentResponse) (*empty.Empty, error) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -41,6 +42,24 @@ func (s *State) AgentsLookupDNS(ctx context.Context, clientSessionID string, req
 	return bestRRs, bestRcode, nil
 }
 
+// InterceptedNamespaces returns the sorted namespaces of the intercepts of the client identified with the
+// clientSessionID. The agents that AgentsLookupDNS sends requests to are in those namespaces.
+func (s *State) InterceptedNamespaces(clientSessionID string) []string {
+	intercepts := s.intercepts.LoadAllMatching(func(_ string, ii *rpc.InterceptInfo) bool {
+		return ii.ClientSession.SessionId == clientSessionID
+	})
+	nsMap := make(map[string]struct{}, len(intercepts))
+	for _, ii := range intercepts {
+		nsMap[ii.Spec.Namespace] = struct{}{}
+	}
+	nss := make([]string, 0, len(nsMap))
+	for ns := range nsMap {
+		nss = append(nss, ns)
+	}
+	sort.Strings(nss)
+	return nss
+}
+
 // PostLookupDNSResponse receives lookup responses from an agent and places them in the channel
 // that corresponds to the lookup request.
 func (s *State) PostLookupDNSResponse(response *rpc.DNSAgentResponse) {
@@ -0,0 +1,274 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/datawire/dlib/dcontext"
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/tracing"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+const (
+	// egressHostTTL is the time that the addresses of a host name used in an egress rule are cached.
+	egressHostTTL = 30 * time.Second
+
+	// egressLookupTimeout is the max time spent resolving a host name used in an egress rule.
+	egressLookupTimeout = 5 * time.Second
+
+	// egressAgentTimeout is the max time spent waiting for a traffic-agent to arrive in an egress workload.
+	egressAgentTimeout = 30 * time.Second
+)
+
+// egressRule makes tunnels for matching destinations be extended to a traffic-agent of a workload.
+type egressRule struct {
+	workload  string
+	namespace string
+	subnets   []*net.IPNet
+	hosts     []string
+}
+
+func (r *egressRule) String() string {
+	return r.workload + "." + r.namespace
+}
+
+type resolvedHost struct {
+	ips        []net.IP
+	expires    time.Time
+	refreshing bool
+}
+
+// egressRules are the egress rules of a client session.
+type egressRules struct {
+	rules []*egressRule
+
+	// source are the rules that this instance was created from. They are persisted with the session.
+	source []*rpc.EgressRule
+
+	// lookupIP resolves the host names of the rules. It is net.DefaultResolver.LookupIP unless replaced by a test.
+	lookupIP func(ctx context.Context, network, host string) ([]net.IP, error)
+
+	sync.Mutex
+	resolved map[string]resolvedHost
+}
+
+func newEgressRules(rs []*rpc.EgressRule) (*egressRules, error) {
+	er := &egressRules{
+		rules:    make([]*egressRule, len(rs)),
+		source:   rs,
+		lookupIP: net.DefaultResolver.LookupIP,
+		resolved: make(map[string]resolvedHost),
+	}
+	for i, r := range rs {
+		if r.Workload == "" || r.Namespace == "" {
+			return nil, fmt.Errorf("egress rule %d must have both a workload and a namespace", i)
+		}
+		rule := &egressRule{workload: r.Workload, namespace: r.Namespace}
+		for _, d := range r.Destinations {
+			if _, sn, err := net.ParseCIDR(d); err == nil {
+				rule.subnets = append(rule.subnets, sn)
+			} else if ip := net.ParseIP(d); ip != nil {
+				rule.subnets = append(rule.subnets, hostNet(ip))
+			} else {
+				rule.hosts = append(rule.hosts, d)
+			}
+		}
+		er.rules[i] = rule
+	}
+	return er, nil
+}
+
+func hostNet(ip net.IP) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// resolveHosts resolves the host names of all rules concurrently, and returns when all of them are resolved.
+func (er *egressRules) resolveHosts(ctx context.Context) {
+	wg := sync.WaitGroup{}
+	for _, r := range er.rules {
+		for _, h := range r.hosts {
+			wg.Add(1)
+			go func(h string) {
+				defer wg.Done()
+				er.resolve(ctx, h)
+			}(h)
+		}
+	}
+	wg.Wait()
+}
+
+// resolve resolves the given host and caches the result. The addresses from the previous resolution are
+// retained if the lookup fails.
+func (er *egressRules) resolve(ctx context.Context, host string) {
+	ctx, cancel := context.WithTimeout(ctx, egressLookupTimeout)
+	defer cancel()
+	ips, err := er.lookupIP(ctx, "ip", host)
+	er.Lock()
+	defer er.Unlock()
+	if err != nil {
+		dlog.Errorf(ctx, "unable to resolve egress host %s: %v", host, err)
+		ips = er.resolved[host].ips
+	}
+	er.resolved[host] = resolvedHost{ips: ips, expires: time.Now().Add(egressHostTTL)}
+}
+
+// lookupHost returns the cached addresses of the given host. It never waits for a lookup. Addresses that
+// have expired are returned while they are refreshed in the background.
+func (er *egressRules) lookupHost(ctx context.Context, host string) []net.IP {
+	er.Lock()
+	defer er.Unlock()
+	rh := er.resolved[host]
+	if !rh.refreshing && !time.Now().Before(rh.expires) {
+		rh.refreshing = true
+		er.resolved[host] = rh
+		go er.resolve(dcontext.WithoutCancel(ctx), host)
+	}
+	return rh.ips
+}
+
+// match returns the first rule that matches the given destination, or nil if no rule matches.
+func (er *egressRules) match(ctx context.Context, dest net.IP) *egressRule {
+	for _, r := range er.rules {
+		if len(r.subnets) == 0 && len(r.hosts) == 0 {
+			return r
+		}
+		for _, sn := range r.subnets {
+			if sn.Contains(dest) {
+				return r
+			}
+		}
+		for _, h := range r.hosts {
+			for _, ip := range er.lookupHost(ctx, h) {
+				if ip.Equal(dest) {
+					return r
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// EnsureEgressAgents ensures that the workloads of the given egress rules have a traffic-agent, in the same
+// way as an intercept does. It returns when all agents have arrived.
+func (s *State) EnsureEgressAgents(ctx context.Context, rules []*rpc.EgressRule) error {
+	for _, r := range rules {
+		if err := s.ensureEgressAgent(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *State) ensureEgressAgent(ctx context.Context, r *rpc.EgressRule) error {
+	if r.Workload == "" || r.Namespace == "" {
+		return status.Error(codes.InvalidArgument, "egress rule must have both a workload and a namespace")
+	}
+	if s.hasAgent(r.Workload, r.Namespace) {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, egressAgentTimeout)
+	defer cancel()
+
+	egressError := func(err error) error {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Errorf(codes.FailedPrecondition, "unable to ensure a traffic-agent in egress workload %s.%s: %v", r.Workload, r.Namespace, err)
+	}
+	wl, err := tracing.GetWorkload(ctx, r.Workload, r.Namespace, "")
+	if err != nil {
+		return egressError(err)
+	}
+	failedCreateCh, err := watchFailedInjectionEvents(ctx, r.Workload, r.Namespace)
+	if err != nil {
+		return egressError(err)
+	}
+	ac, err := s.getOrCreateAgentConfig(ctx, wl, false)
+	if err != nil {
+		return egressError(err)
+	}
+	dlog.Infof(ctx, "Waiting for traffic-agent of egress workload %s.%s", r.Workload, r.Namespace)
+	if err = s.waitForAgent(ctx, ac.AgentName, ac.Namespace, failedCreateCh); err != nil {
+		return egressError(err)
+	}
+	return nil
+}
+
+// hasAgent returns true if a traffic-agent of the given workload has a session.
+func (s *State) hasAgent(workload, namespace string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, ai := range s.agentsByName[workload] {
+		if ai.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// SetEgressRules replaces the egress rules of the given client session. The host names used in the rules are
+// resolved before the rules are registered.
+func (s *State) SetEgressRules(ctx context.Context, sessionID string, rules []*rpc.EgressRule) error {
+	s.mu.RLock()
+	ss, ok := s.sessions[sessionID]
+	s.mu.RUnlock()
+	cs, isClient := ss.(*clientSessionState)
+	if !(ok && isClient) {
+		return status.Errorf(codes.NotFound, "Client session %q not found", sessionID)
+	}
+	var er *egressRules
+	if len(rules) > 0 {
+		var err error
+		if er, err = newEgressRules(rules); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		er.resolveHosts(ctx)
+	}
+	cs.Lock()
+	cs.egress = er
+	cs.Unlock()
+	s.notifyPersister()
+	return nil
+}
+
+// getEgressAgentSession returns the session of a traffic-agent that the tunnel with the given id, created by
+// the given client session, must be extended to, or nil if no egress rule matches the tunnel. An error is
+// returned when a rule matches, but its workload has no traffic-agent.
+func (s *State) getEgressAgentSession(ctx context.Context, ss SessionState, id tunnel.ConnID) (SessionState, error) {
+	cs, ok := ss.(*clientSessionState)
+	if !ok {
+		return nil, nil
+	}
+	cs.Lock()
+	er := cs.egress
+	cs.Unlock()
+	if er == nil {
+		return nil, nil
+	}
+	r := er.match(ctx, id.Destination())
+	if r == nil {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for agentID, ai := range s.agentsByName[r.workload] {
+		if ai.Namespace == r.namespace {
+			if as, ok := s.sessions[agentID]; ok {
+				dlog.Debugf(ctx, "   EGRESS %s via %s", id, r)
+				return as, nil
+			}
+		}
+	}
+	return nil, status.Errorf(codes.Unavailable, "no traffic-agent found for egress workload %s", r)
+}
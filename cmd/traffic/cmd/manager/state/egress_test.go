@@ -0,0 +1,133 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+func connTo(dst string) tunnel.ConnID {
+	return tunnel.NewConnID(ipproto.TCP, iputil.Parse("10.0.0.1"), iputil.Parse(dst), 34567, 5432)
+}
+
+func TestEgressRules(t *testing.T) {
+	ctx := context.Background()
+	s := NewState(ctx)
+	now := time.Now()
+	clientID := s.AddClient(&rpc.ClientInfo{Name: "john@host", Product: "telepresence"}, now)
+	apiID := s.AddAgent(&rpc.AgentInfo{Name: "api", Namespace: "staging", Product: "telepresence"}, now)
+
+	require.Equal(t, codes.NotFound, status.Code(s.SetEgressRules(ctx, apiID, nil)))
+	require.Equal(t, codes.InvalidArgument, status.Code(s.SetEgressRules(ctx, clientID, []*rpc.EgressRule{{Workload: "api"}})))
+	require.NoError(t, s.SetEgressRules(ctx, clientID, []*rpc.EgressRule{
+		{Workload: "api", Namespace: "staging", Destinations: []string{"10.100.0.0/16", "192.168.1.5"}},
+		{Workload: "db-client", Namespace: "staging", Destinations: []string{"10.101.0.0/16"}},
+	}))
+
+	cs := s.sessions[clientID]
+	as, err := s.getEgressAgentSession(ctx, cs, connTo("10.100.12.3"))
+	require.NoError(t, err)
+	assert.Equal(t, s.sessions[apiID], as)
+
+	as, err = s.getEgressAgentSession(ctx, cs, connTo("192.168.1.5"))
+	require.NoError(t, err)
+	assert.Equal(t, s.sessions[apiID], as)
+
+	as, err = s.getEgressAgentSession(ctx, cs, connTo("10.102.0.1"))
+	require.NoError(t, err)
+	assert.Nil(t, as)
+
+	// The rule matches, but the workload has no traffic-agent
+	_, err = s.getEgressAgentSession(ctx, cs, connTo("10.101.0.1"))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// A rule without destinations matches everything
+	require.NoError(t, s.SetEgressRules(ctx, clientID, []*rpc.EgressRule{{Workload: "api", Namespace: "staging"}}))
+	as, err = s.getEgressAgentSession(ctx, cs, connTo("8.8.8.8"))
+	require.NoError(t, err)
+	assert.Equal(t, s.sessions[apiID], as)
+
+	// Agents never use egress rules
+	as, err = s.getEgressAgentSession(ctx, s.sessions[apiID], connTo("8.8.8.8"))
+	require.NoError(t, err)
+	assert.Nil(t, as)
+
+	require.NoError(t, s.SetEgressRules(ctx, clientID, nil))
+	as, err = s.getEgressAgentSession(ctx, cs, connTo("8.8.8.8"))
+	require.NoError(t, err)
+	assert.Nil(t, as)
+}
+
+func TestEgressRules_hostsAreResolvedAhead(t *testing.T) {
+	ctx := context.Background()
+	er, err := newEgressRules([]*rpc.EgressRule{
+		{Workload: "api", Namespace: "staging", Destinations: []string{"db.example.com"}},
+	})
+	require.NoError(t, err)
+	lookups := make(chan string, 10)
+	var ip net.IP
+	er.lookupIP = func(_ context.Context, _, host string) ([]net.IP, error) {
+		lookups <- host
+		if ip == nil {
+			return nil, errors.New("no such host")
+		}
+		return []net.IP{ip}, nil
+	}
+	ip = net.IP{10, 0, 0, 5}
+	er.resolveHosts(ctx)
+	assert.Equal(t, "db.example.com", <-lookups)
+
+	// The tunnel path never waits for a lookup while the cached addresses are valid.
+	assert.NotNil(t, er.match(ctx, net.IP{10, 0, 0, 5}))
+	assert.Nil(t, er.match(ctx, net.IP{10, 0, 0, 6}))
+	assert.Empty(t, lookups)
+
+	// Expired addresses are used while they are refreshed in the background.
+	er.Lock()
+	rh := er.resolved["db.example.com"]
+	rh.expires = time.Now().Add(-time.Second)
+	er.resolved["db.example.com"] = rh
+	er.Unlock()
+	assert.NotNil(t, er.match(ctx, net.IP{10, 0, 0, 5}))
+	select {
+	case host := <-lookups:
+		assert.Equal(t, "db.example.com", host)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expired addresses were not refreshed")
+	}
+	require.Eventually(t, func() bool {
+		er.Lock()
+		defer er.Unlock()
+		return time.Now().Before(er.resolved["db.example.com"].expires)
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestEnsureEgressAgents(t *testing.T) {
+	ctx := k8sapi.WithK8sInterface(context.Background(), fake.NewSimpleClientset())
+	s := NewState(ctx)
+	s.AddAgent(&rpc.AgentInfo{Name: "api", Namespace: "staging", Product: "telepresence"}, time.Now())
+
+	// A workload that already has an agent needs no injection.
+	require.NoError(t, s.EnsureEgressAgents(ctx, []*rpc.EgressRule{{Workload: "api", Namespace: "staging"}}))
+
+	err := s.EnsureEgressAgents(ctx, []*rpc.EgressRule{{Workload: "api"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// A workload without an agent must exist so that the agent can be injected.
+	err = s.EnsureEgressAgents(ctx, []*rpc.EgressRule{{Workload: "db-client", Namespace: "staging"}})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -0,0 +1,351 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+const (
+	// exposedByLabel is the label of Services and Endpoints created by Expose. Its value is the
+	// ID of the client session that the connections are extended to.
+	exposedByLabel = "telepresence.io/exposed-by"
+
+	createdByLabel = "app.kubernetes.io/created-by"
+	createdByValue = "traffic-manager"
+)
+
+// exposure is a Service that points to a listener in the traffic-manager.
+type exposure struct {
+	*rpc.ExposeInfo
+	sessionID string
+	listener  net.Listener
+}
+
+// Expose creates a Service and Endpoints in the traffic-manager's namespace that point to a listener in this
+// traffic-manager. Connections accepted by that listener are extended to the given client session, which dials
+// the requested local port.
+func (s *State) Expose(ctx context.Context, sessionID string, er *rpc.ExposeRequest) (*rpc.ExposeInfo, error) {
+	if errs := validation.IsDNS1035Label(er.Name); len(errs) > 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid service name %q: %s", er.Name, strings.Join(errs, ", "))
+	}
+	if er.Port <= 0 || er.Port > 0xffff {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid port %d", er.Port)
+	}
+	localPort := er.LocalPort
+	if localPort == 0 {
+		localPort = er.Port
+	}
+	if localPort < 0 || localPort > 0xffff {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid local port %d", localPort)
+	}
+
+	s.mu.RLock()
+	ss, ok := s.sessions[sessionID]
+	s.mu.RUnlock()
+	if _, isClient := ss.(*clientSessionState); !(ok && isClient) {
+		return nil, status.Errorf(codes.NotFound, "Client session %q not found", sessionID)
+	}
+
+	env := managerutil.GetEnv(ctx)
+	info := &rpc.ExposeInfo{
+		Name:      er.Name,
+		Namespace: env.ManagerNamespace,
+		Port:      er.Port,
+		LocalPort: localPort,
+	}
+
+	s.exposuresLock.Lock()
+	defer s.exposuresLock.Unlock()
+	if _, ok := s.exposures[er.Name]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "service %q is already exposed", er.Name)
+	}
+
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to listen: %v", err)
+	}
+	ex := &exposure{ExposeInfo: info, sessionID: sessionID, listener: l}
+	if err = createExposedService(ctx, ex, env.PodIP); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+	s.exposures[er.Name] = ex
+	s.notifyPersister()
+	go s.serveExposure(s.ctx, ex, ss)
+	dlog.Infof(ctx, "Service %s.%s:%d exposed by session %s", info.Name, info.Namespace, info.Port, sessionID)
+	return info, nil
+}
+
+// Unexpose removes a Service created by Expose for the given session.
+func (s *State) Unexpose(ctx context.Context, sessionID, name string) error {
+	s.exposuresLock.Lock()
+	ex, ok := s.exposures[name]
+	if ok && ex.sessionID == sessionID {
+		delete(s.exposures, name)
+	}
+	s.exposuresLock.Unlock()
+	if !ok || ex.sessionID != sessionID {
+		return status.Errorf(codes.NotFound, "service %q is not exposed by session %q", name, sessionID)
+	}
+	s.notifyPersister()
+	return removeExposure(ctx, ex)
+}
+
+// gcSessionExposures removes all Services exposed by the given session.
+func (s *State) gcSessionExposures(sessionID string) {
+	var exs []*exposure
+	s.exposuresLock.Lock()
+	for name, ex := range s.exposures {
+		if ex.sessionID == sessionID {
+			delete(s.exposures, name)
+			exs = append(exs, ex)
+		}
+	}
+	s.exposuresLock.Unlock()
+	if len(exs) > 0 {
+		s.notifyPersister()
+		go func() {
+			for _, ex := range exs {
+				if err := removeExposure(s.ctx, ex); err != nil {
+					dlog.Error(s.ctx, err)
+				}
+			}
+		}()
+	}
+}
+
+// RemoveStaleExposures removes Services and Endpoints that were exposed by a previous incarnation of the
+// traffic-manager, unless they were restored with the session that exposed them. It must be called after
+// Restore.
+func (s *State) RemoveStaleExposures(ctx context.Context) error {
+	env := managerutil.GetEnv(ctx)
+	api := k8sapi.GetK8sInterface(ctx).CoreV1()
+	svcs, err := api.Services(env.ManagerNamespace).List(ctx, meta.ListOptions{LabelSelector: exposedByLabel})
+	if err != nil {
+		return err
+	}
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		s.exposuresLock.Lock()
+		ex, ok := s.exposures[svc.Name]
+		s.exposuresLock.Unlock()
+		if ok && ex.sessionID == svc.Labels[exposedByLabel] {
+			continue
+		}
+		dlog.Infof(ctx, "Removing stale exposed service %s.%s", svc.Name, env.ManagerNamespace)
+		if err = deleteExposedService(ctx, svc.Name, env.ManagerNamespace); err != nil {
+			dlog.Error(ctx, err)
+		}
+	}
+	return nil
+}
+
+// restoreExposure exposes a Service again for a restored client session. The Service and Endpoints that a
+// previous incarnation of the traffic-manager created are updated to point to a new listener in this
+// traffic-manager, so that the Service retains its cluster IP.
+func (s *State) restoreExposure(ctx context.Context, er *rpc.ExposeRequest) error {
+	sessionID := er.GetSession().GetSessionId()
+	s.mu.RLock()
+	ss, ok := s.sessions[sessionID]
+	s.mu.RUnlock()
+	if _, isClient := ss.(*clientSessionState); !(ok && isClient) {
+		return nil
+	}
+
+	env := managerutil.GetEnv(ctx)
+	s.exposuresLock.Lock()
+	defer s.exposuresLock.Unlock()
+	if _, ok := s.exposures[er.Name]; ok {
+		return nil
+	}
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return fmt.Errorf("unable to listen: %w", err)
+	}
+	ex := &exposure{
+		ExposeInfo: &rpc.ExposeInfo{
+			Name:      er.Name,
+			Namespace: env.ManagerNamespace,
+			Port:      er.Port,
+			LocalPort: er.LocalPort,
+		},
+		sessionID: sessionID,
+		listener:  l,
+	}
+	if err = updateExposedService(ctx, ex, env.PodIP); err != nil {
+		_ = l.Close()
+		return err
+	}
+	s.exposures[er.Name] = ex
+	go s.serveExposure(s.ctx, ex, ss)
+	dlog.Infof(ctx, "Service %s.%s:%d exposed by session %s restored", ex.Name, ex.Namespace, ex.Port, sessionID)
+	return nil
+}
+
+func (s *State) serveExposure(ctx context.Context, ex *exposure, ss SessionState) {
+	for {
+		conn, err := ex.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				dlog.Errorf(ctx, "exposed service %s failed to accept: %v", ex.Name, err)
+			}
+			return
+		}
+		go s.exposeConn(ctx, ex, ss, conn)
+	}
+}
+
+// exposeConn extends the given connection to the client session. The client receives a DialRequest for the
+// exposed local port on localhost.
+func (s *State) exposeConn(ctx context.Context, ex *exposure, ss SessionState, conn net.Conn) {
+	srcIP, srcPort, err := iputil.SplitToIPPort(conn.RemoteAddr())
+	if err != nil {
+		dlog.Errorf(ctx, "exposed service %s: %v", ex.Name, err)
+		_ = conn.Close()
+		return
+	}
+	id := tunnel.NewConnID(ipproto.TCP, srcIP, net.IPv4(127, 0, 0, 1), srcPort, uint16(ex.LocalPort))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	local, peer := tunnel.NewPipe(id, ex.sessionID)
+	ep := tunnel.NewConnEndpoint(local, conn, cancel)
+	ep.Start(ctx)
+	bidiPipe, err := ss.EstablishBidiPipe(ctx, peer)
+	if err != nil {
+		dlog.Errorf(ctx, "!! CONN %s, exposed service %s: %v", id, ex.Name, err)
+		cancel()
+		<-ep.Done()
+		return
+	}
+	<-bidiPipe.Done()
+	<-ep.Done()
+}
+
+func exposedObjectMeta(ex *exposure) meta.ObjectMeta {
+	return meta.ObjectMeta{
+		Name:      ex.Name,
+		Namespace: ex.Namespace,
+		Labels: map[string]string{
+			createdByLabel: createdByValue,
+			exposedByLabel: ex.sessionID,
+		},
+	}
+}
+
+func exposedServicePorts(ex *exposure) []core.ServicePort {
+	return []core.ServicePort{{
+		Name:       "tcp",
+		Protocol:   core.ProtocolTCP,
+		Port:       ex.Port,
+		TargetPort: intstr.FromInt(ex.listener.Addr().(*net.TCPAddr).Port),
+	}}
+}
+
+func exposedSubsets(ex *exposure, podIP net.IP) []core.EndpointSubset {
+	return []core.EndpointSubset{{
+		Addresses: []core.EndpointAddress{{IP: podIP.String()}},
+		Ports: []core.EndpointPort{{
+			Name:     "tcp",
+			Protocol: core.ProtocolTCP,
+			Port:     int32(ex.listener.Addr().(*net.TCPAddr).Port),
+		}},
+	}}
+}
+
+func createExposedService(ctx context.Context, ex *exposure, podIP net.IP) error {
+	api := k8sapi.GetK8sInterface(ctx).CoreV1()
+	_, err := api.Services(ex.Namespace).Create(ctx, &core.Service{
+		ObjectMeta: exposedObjectMeta(ex),
+		Spec:       core.ServiceSpec{Ports: exposedServicePorts(ex)},
+	}, meta.CreateOptions{})
+	if err != nil {
+		if k8serrors.IsAlreadyExists(err) {
+			return status.Errorf(codes.AlreadyExists, "service %s.%s already exists", ex.Name, ex.Namespace)
+		}
+		return status.Errorf(codes.Internal, "unable to create service %s.%s: %v", ex.Name, ex.Namespace, err)
+	}
+	_, err = api.Endpoints(ex.Namespace).Create(ctx, &core.Endpoints{
+		ObjectMeta: exposedObjectMeta(ex),
+		Subsets:    exposedSubsets(ex, podIP),
+	}, meta.CreateOptions{})
+	if err != nil {
+		_ = deleteExposedService(ctx, ex.Name, ex.Namespace)
+		return status.Errorf(codes.Internal, "unable to create endpoints %s.%s: %v", ex.Name, ex.Namespace, err)
+	}
+	return nil
+}
+
+// updateExposedService makes an existing Service and its Endpoints point to the listener of the given exposure.
+// They are created if they don't exist.
+func updateExposedService(ctx context.Context, ex *exposure, podIP net.IP) error {
+	api := k8sapi.GetK8sInterface(ctx).CoreV1()
+	svc, err := api.Services(ex.Namespace).Get(ctx, ex.Name, meta.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return createExposedService(ctx, ex, podIP)
+		}
+		return fmt.Errorf("unable to get service %s.%s: %w", ex.Name, ex.Namespace, err)
+	}
+	if by := svc.Labels[exposedByLabel]; by != ex.sessionID {
+		return fmt.Errorf("service %s.%s is not exposed by session %s", ex.Name, ex.Namespace, ex.sessionID)
+	}
+	svc.Spec.Ports = exposedServicePorts(ex)
+	if _, err = api.Services(ex.Namespace).Update(ctx, svc, meta.UpdateOptions{}); err != nil {
+		return fmt.Errorf("unable to update service %s.%s: %w", ex.Name, ex.Namespace, err)
+	}
+
+	ep, err := api.Endpoints(ex.Namespace).Get(ctx, ex.Name, meta.GetOptions{})
+	switch {
+	case err == nil:
+		ep.Subsets = exposedSubsets(ex, podIP)
+		_, err = api.Endpoints(ex.Namespace).Update(ctx, ep, meta.UpdateOptions{})
+	case k8serrors.IsNotFound(err):
+		_, err = api.Endpoints(ex.Namespace).Create(ctx, &core.Endpoints{
+			ObjectMeta: exposedObjectMeta(ex),
+			Subsets:    exposedSubsets(ex, podIP),
+		}, meta.CreateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("unable to update endpoints %s.%s: %w", ex.Name, ex.Namespace, err)
+	}
+	return nil
+}
+
+func removeExposure(ctx context.Context, ex *exposure) error {
+	_ = ex.listener.Close()
+	dlog.Infof(ctx, "Service %s.%s exposed by session %s removed", ex.Name, ex.Namespace, ex.sessionID)
+	return deleteExposedService(ctx, ex.Name, ex.Namespace)
+}
+
+// deleteExposedService deletes the Service and the Endpoints with the given name. Endpoints that belong to a
+// Service without a selector are not garbage collected by Kubernetes, so they are deleted explicitly.
+func deleteExposedService(ctx context.Context, name, namespace string) error {
+	api := k8sapi.GetK8sInterface(ctx).CoreV1()
+	if err := api.Services(namespace).Delete(ctx, name, meta.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return status.Errorf(codes.Internal, "unable to delete service %s.%s: %v", name, namespace, err)
+	}
+	if err := api.Endpoints(namespace).Delete(ctx, name, meta.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		return status.Errorf(codes.Internal, "unable to delete endpoints %s.%s: %v", name, namespace, err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package state
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+)
+
+func TestExpose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ki := fake.NewSimpleClientset()
+	ctx = k8sapi.WithK8sInterface(ctx, ki)
+	ctx = managerutil.WithEnv(ctx, &managerutil.Env{ManagerNamespace: "ambassador", PodIP: net.IP{10, 1, 2, 3}})
+	s := NewState(ctx)
+	now := time.Now()
+	clientID := s.AddClient(&rpc.ClientInfo{Name: "john@host", Product: "telepresence"}, now)
+	otherID := s.AddClient(&rpc.ClientInfo{Name: "jane@host", Product: "telepresence"}, now)
+	agentID := s.AddAgent(&rpc.AgentInfo{Name: "api", Namespace: "default", Product: "telepresence"}, now)
+
+	_, err := s.Expose(ctx, agentID, &rpc.ExposeRequest{Name: "newsvc", Port: 8080})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = s.Expose(ctx, clientID, &rpc.ExposeRequest{Name: "New_Svc", Port: 8080})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = s.Expose(ctx, clientID, &rpc.ExposeRequest{Name: "newsvc", Port: 0})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	ei, err := s.Expose(ctx, clientID, &rpc.ExposeRequest{Name: "newsvc", Port: 80, LocalPort: 8080})
+	require.NoError(t, err)
+	assert.Equal(t, &rpc.ExposeInfo{Name: "newsvc", Namespace: "ambassador", Port: 80, LocalPort: 8080}, ei)
+
+	api := ki.CoreV1()
+	svc, err := api.Services("ambassador").Get(ctx, "newsvc", meta.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, clientID, svc.Labels[exposedByLabel])
+	assert.Empty(t, svc.Spec.Selector)
+	require.Len(t, svc.Spec.Ports, 1)
+	assert.Equal(t, int32(80), svc.Spec.Ports[0].Port)
+
+	ep, err := api.Endpoints("ambassador").Get(ctx, "newsvc", meta.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, ep.Subsets, 1)
+	assert.Equal(t, "10.1.2.3", ep.Subsets[0].Addresses[0].IP)
+	assert.Equal(t, svc.Spec.Ports[0].TargetPort.IntVal, ep.Subsets[0].Ports[0].Port)
+
+	_, err = s.Expose(ctx, otherID, &rpc.ExposeRequest{Name: "newsvc", Port: 80})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	assert.Equal(t, codes.NotFound, status.Code(s.Unexpose(ctx, otherID, "newsvc")))
+
+	require.NoError(t, s.Unexpose(ctx, clientID, "newsvc"))
+	_, err = api.Services("ambassador").Get(ctx, "newsvc", meta.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err))
+	_, err = api.Endpoints("ambassador").Get(ctx, "newsvc", meta.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err))
+
+	// Services are removed when the session ends
+	_, err = s.Expose(ctx, clientID, &rpc.ExposeRequest{Name: "newsvc", Port: 8080})
+	require.NoError(t, err)
+	s.RemoveSession(ctx, clientID)
+	assert.Eventually(t, func() bool {
+		_, err := api.Services("ambassador").Get(ctx, "newsvc", meta.GetOptions{})
+		return k8serrors.IsNotFound(err)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Stale services are removed on startup
+	_, err = s.Expose(ctx, otherID, &rpc.ExposeRequest{Name: "othersvc", Port: 8080})
+	require.NoError(t, err)
+	require.NoError(t, NewState(ctx).RemoveStaleExposures(ctx))
+	_, err = api.Services("ambassador").Get(ctx, "othersvc", meta.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
@@ -0,0 +1,142 @@
+package state
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+// metrics are the Prometheus metrics of the tunnels that the State handles. The per-session series are removed
+// when the session ends.
+type metrics struct {
+	streams       *prometheus.CounterVec
+	activeStreams *prometheus.GaugeVec
+	bytes         *prometheus.CounterVec
+	dialFailures  *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		streams: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tunnel_streams_total",
+			Help: "Number of tunnel streams opened by a session",
+		}, []string{"session_id"}),
+		activeStreams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "tunnel_streams_active",
+			Help: "Number of open tunnel streams of a session",
+		}, []string{"session_id"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tunnel_bytes_total",
+			Help: "Number of bytes sent to, or received from, a session on its tunnel streams",
+		}, []string{"session_id", "direction"}),
+		dialFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tunnel_dial_failures_total",
+			Help: "Number of tunnel streams where the dialer failed to connect to the destination",
+		}, []string{"protocol"}),
+	}
+}
+
+// Collectors returns the Prometheus collectors of the sessions, intercepts, and tunnels of the State.
+func (s *State) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "client_count",
+			Help: "Number of Clients Connected",
+		}, func() float64 {
+			return float64(s.CountAllClients())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "agent_count",
+			Help: "Number of Agents Connected",
+		}, func() float64 {
+			return float64(s.agents.CountAll())
+		}),
+		&interceptCollector{
+			state: s,
+			desc: prometheus.NewDesc("active_intercepts",
+				"Number of active intercepts of a workload", []string{"namespace", "workload"}, nil),
+		},
+		s.metrics.streams,
+		s.metrics.activeStreams,
+		s.metrics.bytes,
+		s.metrics.dialFailures,
+	}
+}
+
+func (m *metrics) removeSession(sessionID string) {
+	m.streams.DeleteLabelValues(sessionID)
+	m.activeStreams.DeleteLabelValues(sessionID)
+	m.bytes.DeleteLabelValues(sessionID, "sent")
+	m.bytes.DeleteLabelValues(sessionID, "received")
+}
+
+// meter returns a Stream that updates the metrics with the messages of the given stream, and a function
+// that must be called when the stream ends.
+func (m *metrics) meter(stream tunnel.Stream) (tunnel.Stream, func()) {
+	sessionID := stream.SessionID()
+	m.streams.WithLabelValues(sessionID).Inc()
+	active := m.activeStreams.WithLabelValues(sessionID)
+	active.Inc()
+	return &meteredStream{
+		Stream:      stream,
+		sent:        m.bytes.WithLabelValues(sessionID, "sent"),
+		received:    m.bytes.WithLabelValues(sessionID, "received"),
+		dialFailure: m.dialFailures.WithLabelValues(stream.ID().ProtocolString()),
+	}, active.Dec
+}
+
+// meteredStream counts the payload bytes of the normal messages of a stream. A DialReject sent to the stream
+// is a dial failure, regardless of whether it originates from the traffic-manager or from a peer session.
+type meteredStream struct {
+	tunnel.Stream
+	sent        prometheus.Counter
+	received    prometheus.Counter
+	dialFailure prometheus.Counter
+}
+
+func (s *meteredStream) Receive(ctx context.Context) (tunnel.Message, error) {
+	m, err := s.Stream.Receive(ctx)
+	if err == nil && m.Code() == tunnel.Normal {
+		s.received.Add(float64(len(m.Payload())))
+	}
+	return m, err
+}
+
+func (s *meteredStream) Send(ctx context.Context, m tunnel.Message) error {
+	switch m.Code() {
+	case tunnel.Normal:
+		s.sent.Add(float64(len(m.Payload())))
+	case tunnel.DialReject:
+		s.dialFailure.Inc()
+	}
+	return s.Stream.Send(ctx, m)
+}
+
+// interceptCollector collects the number of active intercepts per workload when the metrics are scraped.
+type interceptCollector struct {
+	state *State
+	desc  *prometheus.Desc
+}
+
+func (c *interceptCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *interceptCollector) Collect(ch chan<- prometheus.Metric) {
+	type workload struct {
+		namespace string
+		name      string
+	}
+	counts := make(map[workload]int)
+	for _, ii := range c.state.intercepts.LoadAll() {
+		if ii.Disposition == rpc.InterceptDispositionType_ACTIVE {
+			counts[workload{namespace: ii.Spec.Namespace, name: ii.Spec.Agent}]++
+		}
+	}
+	for wl, n := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), wl.namespace, wl.name)
+	}
+}
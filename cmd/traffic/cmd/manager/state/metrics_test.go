@@ -0,0 +1,91 @@
+package state
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+func TestMetrics_meter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewState(ctx)
+	clientID := s.AddClient(&rpc.ClientInfo{Name: "john@host", Product: "telepresence"}, time.Now())
+
+	id := tunnel.NewConnID(ipproto.TCP, net.IP{192, 168, 0, 2}, net.IP{10, 0, 0, 1}, 4711, 8080)
+	local, peer := tunnel.NewPipe(id, clientID)
+	ms, done := s.metrics.meter(local)
+	assert.Equal(t, float64(1), testutil.ToFloat64(s.metrics.streams.WithLabelValues(clientID)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(s.metrics.activeStreams.WithLabelValues(clientID)))
+
+	require.NoError(t, ms.Send(ctx, tunnel.NewMessage(tunnel.Normal, []byte("hello"))))
+	_, err := peer.Receive(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ms.Send(ctx, tunnel.NewMessage(tunnel.DialReject, nil)))
+	_, err = peer.Receive(ctx)
+	require.NoError(t, err)
+	require.NoError(t, peer.Send(ctx, tunnel.NewMessage(tunnel.Normal, []byte("abc"))))
+	_, err = ms.Receive(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(5), testutil.ToFloat64(s.metrics.bytes.WithLabelValues(clientID, "sent")))
+	assert.Equal(t, float64(3), testutil.ToFloat64(s.metrics.bytes.WithLabelValues(clientID, "received")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(s.metrics.dialFailures.WithLabelValues("tcp4")))
+
+	done()
+	assert.Equal(t, float64(0), testutil.ToFloat64(s.metrics.activeStreams.WithLabelValues(clientID)))
+
+	// The series of a session are removed with the session
+	s.RemoveSession(ctx, clientID)
+	assert.Equal(t, 0, testutil.CollectAndCount(s.metrics.streams))
+	assert.Equal(t, 0, testutil.CollectAndCount(s.metrics.activeStreams))
+	assert.Equal(t, 0, testutil.CollectAndCount(s.metrics.bytes))
+}
+
+func TestMetrics_intercepts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewState(ctx)
+	s.AddAgent(&rpc.AgentInfo{Name: "api", Namespace: "default", Product: "telepresence"}, time.Now())
+
+	addIntercept := func(id, ns, agent string, disposition rpc.InterceptDispositionType) {
+		s.intercepts.Store(id, &rpc.InterceptInfo{
+			Id:          id,
+			Spec:        &rpc.InterceptSpec{Name: id, Namespace: ns, Agent: agent},
+			Disposition: disposition,
+		})
+	}
+	addIntercept("a", "default", "api", rpc.InterceptDispositionType_ACTIVE)
+	addIntercept("b", "default", "api", rpc.InterceptDispositionType_ACTIVE)
+	addIntercept("c", "other", "web", rpc.InterceptDispositionType_ACTIVE)
+	addIntercept("d", "other", "db", rpc.InterceptDispositionType_WAITING)
+
+	var ic *interceptCollector
+	for _, c := range s.Collectors() {
+		if c, ok := c.(*interceptCollector); ok {
+			ic = c
+		}
+	}
+	require.NotNil(t, ic)
+	assert.NoError(t, testutil.CollectAndCompare(ic, strings.NewReader(`
+# HELP active_intercepts Number of active intercepts of a workload
+# TYPE active_intercepts gauge
+active_intercepts{namespace="default",workload="api"} 2
+active_intercepts{namespace="other",workload="web"} 1
+`)))
+	assert.NoError(t, testutil.CollectAndCompare(s.Collectors()[1], strings.NewReader(`
+# HELP agent_count Number of Agents Connected
+# TYPE agent_count gauge
+agent_count 1
+`)))
+}
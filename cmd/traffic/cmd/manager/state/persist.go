@@ -0,0 +1,293 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+)
+
+// Keys in the Secret that the state is persisted in. Each value is a JSON object with the protojson encoded
+// records. Clients and egress rules are keyed by session ID, intercepts by intercept ID, and exposures by
+// service name.
+const (
+	persistedClientsKey    = "clients"
+	persistedInterceptsKey = "intercepts"
+	persistedEgressKey     = "egress"
+	persistedExposuresKey  = "exposures"
+)
+
+// persistRetryInterval is the time to wait before retrying a failed attempt to persist the state.
+const persistRetryInterval = 5 * time.Second
+
+// Restore loads the client sessions, their egress rules and exposed services, and the intercepts that a previous
+// incarnation of the traffic-manager persisted in the Secret with the given name. A restored session is considered
+// marked now, so its client has the whole session TTL to reattach. Restored intercepts wait for their agents to
+// approve them again. Agents always arrive with new sessions, so they aren't restored.
+func (s *State) Restore(ctx context.Context, secretName string) error {
+	env := managerutil.GetEnv(ctx)
+	secret, err := k8sapi.GetK8sInterface(ctx).CoreV1().Secrets(env.ManagerNamespace).Get(ctx, secretName, meta.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to get secret %s.%s: %w", secretName, env.ManagerNamespace, err)
+	}
+	clients, err := unmarshalRecords(secret.Data[persistedClientsKey], func() *rpc.ClientInfo { return &rpc.ClientInfo{} })
+	if err != nil {
+		return fmt.Errorf("unable to restore client sessions: %w", err)
+	}
+	intercepts, err := unmarshalRecords(secret.Data[persistedInterceptsKey], func() *rpc.InterceptInfo { return &rpc.InterceptInfo{} })
+	if err != nil {
+		return fmt.Errorf("unable to restore intercepts: %w", err)
+	}
+	egress, err := unmarshalRecords(secret.Data[persistedEgressKey], func() *rpc.EgressRulesRequest { return &rpc.EgressRulesRequest{} })
+	if err != nil {
+		return fmt.Errorf("unable to restore egress rules: %w", err)
+	}
+	exposures, err := unmarshalRecords(secret.Data[persistedExposuresKey], func() *rpc.ExposeRequest { return &rpc.ExposeRequest{} })
+	if err != nil {
+		return fmt.Errorf("unable to restore exposed services: %w", err)
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	for id, client := range clients {
+		if _, ok := s.sessions[id]; ok {
+			continue
+		}
+		cs := newClientSessionState(s.ctx, now)
+		if rules := egress[id].GetRules(); len(rules) > 0 {
+			if cs.egress, err = newEgressRules(rules); err != nil {
+				dlog.Errorf(ctx, "unable to restore egress rules of session %s: %v", id, err)
+			} else {
+				go cs.egress.resolveHosts(s.ctx)
+			}
+		}
+		s.clients.Store(id, client)
+		s.sessions[id] = cs
+	}
+	for id, cept := range intercepts {
+		if _, ok := clients[cept.GetClientSession().GetSessionId()]; !ok || cept.Spec == nil {
+			continue
+		}
+		if _, ok := s.intercepts.Load(id); ok {
+			continue
+		}
+		cept.Disposition = rpc.InterceptDispositionType_WAITING
+		cept.Message = "Waiting for Agent approval"
+		s.intercepts.Store(id, cept)
+		s.interceptStates[id] = newInterceptState(id)
+	}
+	s.mu.Unlock()
+
+	for _, er := range exposures {
+		if err := s.restoreExposure(ctx, er); err != nil {
+			dlog.Errorf(ctx, "unable to restore exposed service %s: %v", er.Name, err)
+		}
+	}
+	s.exposuresLock.Lock()
+	exposed := len(s.exposures)
+	s.exposuresLock.Unlock()
+	dlog.Infof(ctx, "Restored %d client sessions, %d intercepts, and %d exposed services",
+		s.clients.CountAll(), s.intercepts.CountAll(), exposed)
+	return nil
+}
+
+// notifyPersister tells RunPersister that the egress rules or the exposed services have changed.
+func (s *State) notifyPersister() {
+	select {
+	case s.persistCh <- struct{}{}:
+	default:
+	}
+}
+
+// RunPersister persists the client sessions, their egress rules and exposed services, and the intercepts in the
+// Secret with the given name each time they change, until the given context is cancelled. Nothing is written
+// until the initial snapshots of both the clients and the intercepts have been received, so that a partial state
+// never replaces the persisted one.
+func (s *State) RunPersister(ctx context.Context, secretName string) error {
+	clientsCh := s.clients.Subscribe(ctx)
+	interceptsCh := s.intercepts.Subscribe(ctx)
+	var clients map[string]*rpc.ClientInfo
+	var intercepts map[string]*rpc.InterceptInfo
+	var haveClients, haveIntercepts bool
+	var persisted map[string][]byte
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case snapshot, ok := <-clientsCh:
+			if !ok {
+				return nil
+			}
+			clients = snapshot.State
+			haveClients = true
+		case snapshot, ok := <-interceptsCh:
+			if !ok {
+				return nil
+			}
+			intercepts = snapshot.State
+			haveIntercepts = true
+		case <-s.persistCh:
+		case <-retry:
+		}
+		if !(haveClients && haveIntercepts) {
+			continue
+		}
+		retry = nil
+		data, err := marshalPersistedState(map[string]map[string]proto.Message{
+			persistedClientsKey:    asMessages(clients),
+			persistedInterceptsKey: asMessages(intercepts),
+			persistedEgressKey:     asMessages(s.egressSnapshot()),
+			persistedExposuresKey:  asMessages(s.exposuresSnapshot()),
+		})
+		if err != nil {
+			dlog.Errorf(ctx, "unable to persist state: %v", err)
+			continue
+		}
+		if persistedDataEqual(persisted, data) {
+			continue
+		}
+		if err = storePersistedState(ctx, secretName, data); err != nil {
+			dlog.Errorf(ctx, "unable to persist state: %v", err)
+			retry = time.After(persistRetryInterval)
+			continue
+		}
+		persisted = data
+	}
+}
+
+// egressSnapshot returns the egress rules of all client sessions that have them, keyed by session ID.
+func (s *State) egressSnapshot() map[string]*rpc.EgressRulesRequest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rs := make(map[string]*rpc.EgressRulesRequest)
+	for id, ss := range s.sessions {
+		if cs, ok := ss.(*clientSessionState); ok {
+			cs.Lock()
+			er := cs.egress
+			cs.Unlock()
+			if er != nil {
+				rs[id] = &rpc.EgressRulesRequest{Session: &rpc.SessionInfo{SessionId: id}, Rules: er.source}
+			}
+		}
+	}
+	return rs
+}
+
+// exposuresSnapshot returns the requests of all exposed services, keyed by service name.
+func (s *State) exposuresSnapshot() map[string]*rpc.ExposeRequest {
+	s.exposuresLock.Lock()
+	defer s.exposuresLock.Unlock()
+	rs := make(map[string]*rpc.ExposeRequest, len(s.exposures))
+	for name, ex := range s.exposures {
+		rs[name] = &rpc.ExposeRequest{
+			Session:   &rpc.SessionInfo{SessionId: ex.sessionID},
+			Name:      ex.Name,
+			Port:      ex.Port,
+			LocalPort: ex.LocalPort,
+		}
+	}
+	return rs
+}
+
+func asMessages[V proto.Message](records map[string]V) map[string]proto.Message {
+	ms := make(map[string]proto.Message, len(records))
+	for id, r := range records {
+		ms[id] = r
+	}
+	return ms
+}
+
+func marshalPersistedState(records map[string]map[string]proto.Message) (map[string][]byte, error) {
+	data := make(map[string][]byte, len(records))
+	for key, rs := range records {
+		d, err := marshalRecords(rs)
+		if err != nil {
+			return nil, err
+		}
+		data[key] = d
+	}
+	return data, nil
+}
+
+func persistedDataEqual(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if !bytes.Equal(v, b[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+func storePersistedState(ctx context.Context, secretName string, data map[string][]byte) error {
+	env := managerutil.GetEnv(ctx)
+	api := k8sapi.GetK8sInterface(ctx).CoreV1().Secrets(env.ManagerNamespace)
+	secret, err := api.Get(ctx, secretName, meta.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		_, err = api.Create(ctx, &core.Secret{
+			ObjectMeta: meta.ObjectMeta{
+				Name:      secretName,
+				Namespace: env.ManagerNamespace,
+			},
+			Data: data,
+		}, meta.CreateOptions{})
+		return err
+	}
+	secret.Data = data
+	_, err = api.Update(ctx, secret, meta.UpdateOptions{})
+	return err
+}
+
+// marshalRecords returns a JSON object with the protojson encoding of each record. The keys are sorted by
+// encoding/json, so the result is stable as long as the records are unchanged.
+func marshalRecords[V proto.Message](records map[string]V) ([]byte, error) {
+	rm := make(map[string]json.RawMessage, len(records))
+	for id, r := range records {
+		data, err := protojson.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		rm[id] = data
+	}
+	return json.Marshal(rm)
+}
+
+func unmarshalRecords[V proto.Message](data []byte, create func() V) (map[string]V, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var rm map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rm); err != nil {
+		return nil, err
+	}
+	records := make(map[string]V, len(rm))
+	for id, rd := range rm {
+		r := create()
+		if err := protojson.Unmarshal(rd, r); err != nil {
+			return nil, fmt.Errorf("record %s: %w", id, err)
+		}
+		records[id] = r
+	}
+	return records, nil
+}
@@ -0,0 +1,230 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	core "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/datawire/k8sapi/pkg/k8sapi"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/charts"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
+)
+
+func TestPersistAndRestore(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	ki := fake.NewSimpleClientset()
+	ctx = k8sapi.WithK8sInterface(ctx, ki)
+	ctx = managerutil.WithEnv(ctx, &managerutil.Env{ManagerNamespace: "ambassador", PodIP: net.IP{10, 1, 2, 3}})
+	const secretName = "traffic-manager-state"
+
+	// Nothing to restore
+	require.NoError(t, NewState(ctx).Restore(ctx, secretName))
+
+	s := NewState(ctx)
+	now := time.Now()
+	clientID := s.AddClient(&rpc.ClientInfo{Name: "john@host", InstallId: "abc", Product: "telepresence"}, now)
+	otherID := s.AddClient(&rpc.ClientInfo{Name: "jane@host", Product: "telepresence"}, now)
+	s.AddAgent(&rpc.AgentInfo{Name: "api", Namespace: "default", Product: "telepresence"}, now)
+	spec := &rpc.InterceptSpec{Name: "api", Client: "john@host", Agent: "api", Namespace: "default", Mechanism: "tcp"}
+	cept, err := s.AddIntercept(clientID, "cluster", "", s.GetClient(clientID), spec)
+	require.NoError(t, err)
+	s.UpdateIntercept(cept.Id, func(ii *rpc.InterceptInfo) {
+		ii.Disposition = rpc.InterceptDispositionType_ACTIVE
+		ii.PodIp = "10.1.2.3"
+	})
+	rules := []*rpc.EgressRule{{Workload: "db", Namespace: "default", Destinations: []string{"10.0.0.0/8"}}}
+	require.NoError(t, s.SetEgressRules(ctx, clientID, rules))
+	_, err = s.Expose(ctx, clientID, &rpc.ExposeRequest{Name: "newsvc", Port: 80, LocalPort: 8080})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	pctx, pcancel := context.WithCancel(ctx)
+	go func() { done <- s.RunPersister(pctx, secretName) }()
+
+	api := ki.CoreV1().Secrets("ambassador")
+	persisted := func(id string) bool {
+		secret, err := api.Get(ctx, secretName, meta.GetOptions{})
+		return err == nil && strings.Contains(string(secret.Data[persistedClientsKey]), id)
+	}
+	require.Eventually(t, func() bool { return persisted(otherID) }, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		secret, err := api.Get(ctx, secretName, meta.GetOptions{})
+		return err == nil &&
+			strings.Contains(string(secret.Data[persistedEgressKey]), "10.0.0.0/8") &&
+			strings.Contains(string(secret.Data[persistedExposuresKey]), "newsvc")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Removals are persisted too
+	s.RemoveSession(ctx, otherID)
+	require.Eventually(t, func() bool { return !persisted(otherID) }, 5*time.Second, 10*time.Millisecond)
+	pcancel()
+	require.NoError(t, <-done)
+
+	r := NewState(ctx)
+	require.NoError(t, r.Restore(ctx, secretName))
+	clients := r.GetAllClients()
+	require.Len(t, clients, 1)
+	assert.Equal(t, "john@host", clients[clientID].Name)
+	assert.Equal(t, "abc", clients[clientID].InstallId)
+	assert.True(t, r.MarkSession(&rpc.RemainRequest{Session: &rpc.SessionInfo{SessionId: clientID}}, time.Now()))
+	assert.Empty(t, r.GetAllAgents())
+
+	ii, ok := r.GetIntercept(cept.Id)
+	require.True(t, ok)
+	assert.Equal(t, rpc.InterceptDispositionType_WAITING, ii.Disposition)
+	assert.Equal(t, "10.1.2.3", ii.PodIp)
+	assert.Equal(t, "api", ii.Spec.Agent)
+	require.NoError(t, r.AddInterceptFinalizer(cept.Id, func(context.Context, *rpc.InterceptInfo) error { return nil }))
+
+	// The egress rules and the exposed services of the restored session are restored too, and the exposed
+	// service now points to the listener of the new traffic-manager.
+	egress := r.egressSnapshot()
+	require.Contains(t, egress, clientID)
+	assert.Equal(t, "10.0.0.0/8", egress[clientID].Rules[0].Destinations[0])
+	exposures := r.exposuresSnapshot()
+	require.Contains(t, exposures, "newsvc")
+	assert.Equal(t, int32(8080), exposures["newsvc"].LocalPort)
+	require.NoError(t, r.RemoveStaleExposures(ctx))
+	svc, err := ki.CoreV1().Services("ambassador").Get(ctx, "newsvc", meta.GetOptions{})
+	require.NoError(t, err)
+	r.exposuresLock.Lock()
+	port := r.exposures["newsvc"].listener.Addr().(*net.TCPAddr).Port
+	r.exposuresLock.Unlock()
+	assert.Equal(t, port, svc.Spec.Ports[0].TargetPort.IntValue())
+
+	// The restored intercepts are removed with their session
+	r.RemoveSession(ctx, clientID)
+	_, ok = r.GetIntercept(cept.Id)
+	assert.False(t, ok)
+}
+
+// shippedStateObjects returns the Secret and the Role that the Helm chart creates for the persisted state.
+func shippedStateObjects(t *testing.T, namespace string) (*core.Secret, *rbac.Role) {
+	var buf bytes.Buffer
+	require.NoError(t, charts.WriteChart(charts.DirTypeTelepresence, &buf, "telepresence", "2.0.0"))
+	chrt, err := loader.LoadArchive(&buf)
+	require.NoError(t, err)
+
+	// Only the helpers are needed to render the template of the state.
+	const stateTemplate = "templates/trafficManagerRbac/state.yaml"
+	var templates []*chart.File
+	for _, f := range chrt.Templates {
+		if f.Name == stateTemplate || strings.HasSuffix(f.Name, ".tpl") {
+			templates = append(templates, f)
+		}
+	}
+	chrt.Templates = templates
+	vals, err := chartutil.ToRenderValues(chrt, map[string]any{}, chartutil.ReleaseOptions{
+		Name:      "traffic-manager",
+		Namespace: namespace,
+	}, chartutil.DefaultCapabilities)
+	require.NoError(t, err)
+	files, err := engine.Render(chrt, vals)
+	require.NoError(t, err)
+
+	var secret *core.Secret
+	var role *rbac.Role
+	for _, doc := range strings.Split(files["telepresence/"+stateTemplate], "\n---") {
+		var tm meta.TypeMeta
+		require.NoError(t, yaml.Unmarshal([]byte(doc), &tm))
+		switch tm.Kind {
+		case "Secret":
+			secret = &core.Secret{}
+			require.NoError(t, yaml.Unmarshal([]byte(doc), secret))
+		case "Role":
+			role = &rbac.Role{}
+			require.NoError(t, yaml.Unmarshal([]byte(doc), role))
+		}
+	}
+	require.NotNil(t, secret)
+	require.NotNil(t, role)
+	return secret, role
+}
+
+// allows returns true if one of the rules of the role allows the given verb on the secret with the given
+// name. The name is empty for a create, because the name isn't part of such a request.
+func allows(role *rbac.Role, verb, name string) bool {
+	contains := func(ss []string, s string) bool {
+		for _, e := range ss {
+			if e == s || e == rbac.VerbAll {
+				return true
+			}
+		}
+		return false
+	}
+	for _, r := range role.Rules {
+		if contains(r.APIGroups, "") && contains(r.Resources, "secrets") && contains(r.Verbs, verb) &&
+			(len(r.ResourceNames) == 0 || contains(r.ResourceNames, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRunPersister_shippedRBAC(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	const namespace = "ambassador"
+	secret, role := shippedStateObjects(t, namespace)
+
+	ki := fake.NewSimpleClientset(secret)
+	ki.PrependReactor("*", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		var name string
+		switch a := action.(type) {
+		case k8stesting.GetAction:
+			name = a.GetName()
+		case k8stesting.UpdateAction:
+			name = a.GetObject().(*core.Secret).Name
+		}
+		if !allows(role, action.GetVerb(), name) {
+			return true, nil, k8serrors.NewForbidden(core.Resource("secrets"), name, errors.New("denied by the shipped Role"))
+		}
+		return false, nil, nil
+	})
+	ctx = k8sapi.WithK8sInterface(ctx, ki)
+	ctx = managerutil.WithEnv(ctx, &managerutil.Env{ManagerNamespace: namespace})
+
+	s := NewState(ctx)
+	require.NoError(t, s.Restore(ctx, secret.Name))
+	done := make(chan error, 1)
+	go func() { done <- s.RunPersister(ctx, secret.Name) }()
+
+	api := ki.CoreV1().Secrets(namespace)
+	persisted := func(id string) bool {
+		secret, err := api.Get(ctx, secret.Name, meta.GetOptions{})
+		return err == nil && strings.Contains(string(secret.Data[persistedClientsKey]), id)
+	}
+
+	// The Secret that the chart created is updated.
+	firstID := s.AddClient(&rpc.ClientInfo{Name: "john@host", Product: "telepresence"}, time.Now())
+	require.Eventually(t, func() bool { return persisted(firstID) }, 5*time.Second, 10*time.Millisecond)
+
+	// The Secret is recreated when it has been deleted.
+	require.NoError(t, ki.Tracker().Delete(core.SchemeGroupVersion.WithResource("secrets"), namespace, secret.Name))
+	secondID := s.AddClient(&rpc.ClientInfo{Name: "jane@host", Product: "telepresence"}, time.Now())
+	require.Eventually(t, func() bool { return persisted(secondID) }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+}
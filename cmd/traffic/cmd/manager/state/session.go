@@ -147,6 +147,9 @@ func newSessionState(ctx context.Context, now time.Time) sessionState {
 type clientSessionState struct {
 	sessionState
 	pool *tunnel.Pool
+
+	// egress rules, protected by the sessionState mutex
+	egress *egressRules
 }
 
 func newClientSessionState(ctx context.Context, ts time.Time) *clientSessionState {
@@ -17,6 +17,7 @@ import (
 
 	"github.com/datawire/dlib/dlog"
 	rpc "github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/internal/audit"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/internal/watchable"
 	"github.com/telepresenceio/telepresence/v2/cmd/traffic/cmd/manager/managerutil"
 	"github.com/telepresenceio/telepresence/v2/pkg/log"
@@ -53,6 +54,15 @@ type State struct {
 	timedLogLevel   log.TimedLevel
 	llSubs          *loglevelSubscribers
 	cfgMapLocks     map[string]*sync.Mutex
+
+	exposuresLock sync.Mutex
+	exposures     map[string]*exposure // services exposed by clients, keyed by service name
+
+	// persistCh is signalled when state that isn't kept in a watchable.Map, and therefore must be
+	// persisted by other means, has changed.
+	persistCh chan struct{}
+
+	metrics *metrics
 }
 
 func NewState(ctx context.Context) *State {
@@ -63,8 +73,11 @@ func NewState(ctx context.Context) *State {
 		agentsByName:    make(map[string]map[string]*rpc.AgentInfo),
 		cfgMapLocks:     make(map[string]*sync.Mutex),
 		interceptStates: make(map[string]*interceptState),
+		exposures:       make(map[string]*exposure),
+		persistCh:       make(chan struct{}, 1),
 		timedLogLevel:   log.NewTimedLevel(loglevel, log.SetLevel),
 		llSubs:          newLoglevelSubscribers(),
+		metrics:         newMetrics(),
 	}
 }
 
@@ -163,7 +176,7 @@ func (s *State) RemoveSession(ctx context.Context, sessionID string) {
 	defer s.mu.Unlock()
 	dlog.Debugf(ctx, "Session %s removed. Explicit removal", sessionID)
 
-	s.unlockedRemoveSession(sessionID)
+	s.unlockedRemoveSession(sessionID, audit.SessionDeparted)
 }
 
 func (s *State) gcSessionIntercepts(sessionID string) {
@@ -193,12 +206,15 @@ func (s *State) gcSessionIntercepts(sessionID string) {
 	}
 }
 
-func (s *State) unlockedRemoveSession(sessionID string) {
+// unlockedRemoveSession removes the session with the given ID. The removal of a client session is recorded in
+// the audit log as the given event.
+func (s *State) unlockedRemoveSession(sessionID string, event audit.Event) {
 	if sess, ok := s.sessions[sessionID]; ok {
 		// kill the session
 		defer sess.Cancel()
 
 		s.gcSessionIntercepts(sessionID)
+		s.gcSessionExposures(sessionID)
 
 		agent, isAgent := s.agents.Load(sessionID)
 		if isAgent {
@@ -210,11 +226,12 @@ func (s *State) unlockedRemoveSession(sessionID string) {
 			}
 			// remove the session
 			s.agents.Delete(sessionID)
-		} else {
-			s.clients.Delete(sessionID)
+		} else if client, ok := s.clients.LoadAndDelete(sessionID); ok {
+			audit.Add(s.ctx, audit.ClientRecord(event, sessionID, client))
 		}
 
 		delete(s.sessions, sessionID)
+		s.metrics.removeSession(sessionID)
 	}
 }
 
@@ -228,12 +245,12 @@ func (s *State) ExpireSessions(ctx context.Context, clientMoment, agentMoment ti
 		if _, ok := sess.(*clientSessionState); ok {
 			if sess.LastMarked().Before(clientMoment) {
 				dlog.Debugf(ctx, "Client Session %s removed. It has expired", id)
-				s.unlockedRemoveSession(id)
+				s.unlockedRemoveSession(id, audit.SessionExpired)
 			}
 		} else {
 			if sess.LastMarked().Before(agentMoment) {
 				dlog.Debugf(ctx, "Agent Session %s removed. It has expired", id)
-				s.unlockedRemoveSession(id)
+				s.unlockedRemoveSession(id, audit.SessionExpired)
 			}
 		}
 	}
@@ -494,6 +511,10 @@ func (s *State) unlockedRemoveIntercept(interceptID string) bool {
 		delete(s.interceptStates, interceptID)
 		state.terminate(s.ctx, intercept)
 	}
+	if didDelete {
+		client, _ := s.clients.Load(intercept.GetClientSession().GetSessionId())
+		audit.Add(s.ctx, audit.InterceptRecord(audit.InterceptRemoved, intercept, client))
+	}
 
 	return didDelete
 }
@@ -525,6 +546,8 @@ func (s *State) Tunnel(ctx context.Context, stream tunnel.Stream) error {
 	if !ok {
 		return status.Errorf(codes.NotFound, "Session %q not found", sessionID)
 	}
+	stream, done := s.metrics.meter(stream)
+	defer done()
 
 	bidiPipe, err := ss.OnConnect(ctx, stream)
 	if err != nil {
@@ -540,8 +563,9 @@ func (s *State) Tunnel(ctx context.Context, stream tunnel.Stream) error {
 
 	// The session is either the telepresence client or a traffic-agent.
 	//
-	// A client will want to extend the tunnel to a dialer in an intercepted traffic-agent or, if no
-	// intercept is active, to a dialer here in the traffic-agent.
+	// A client will want to extend the tunnel to a dialer in the traffic-agent of a workload that an egress
+	// rule designates, to a dialer in an intercepted traffic-agent or, if no intercept is active, to a dialer
+	// here in the traffic-agent.
 	//
 	// A traffic-agent must always extend the tunnel to the client that it is currently intercepted
 	// by, and hence, start by sending the sessionID of that client on the tunnel.
@@ -563,7 +587,12 @@ func (s *State) Tunnel(ctx context.Context, stream tunnel.Stream) error {
 		s.mu.RUnlock()
 	} else {
 		span.SetAttributes(attribute.String("session-type", "userd"))
-		peerSession = s.getRandomAgentSession(sessionID)
+		if peerSession, err = s.getEgressAgentSession(ctx, ss, stream.ID()); err != nil {
+			return err
+		}
+		if peerSession == nil {
+			peerSession = s.getRandomAgentSession(sessionID)
+		}
 	}
 
 	var endPoint tunnel.Endpoint
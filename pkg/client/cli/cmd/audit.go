@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	daemonClient "github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+)
+
+// auditRecordInfo is the output representation of a manager.AuditRecord.
+type auditRecordInfo struct {
+	Time             time.Time     `json:"time" yaml:"time"`
+	Event            string        `json:"event" yaml:"event"`
+	SessionID        string        `json:"session_id,omitempty" yaml:"session_id,omitempty"`
+	Client           string        `json:"client,omitempty" yaml:"client,omitempty"`
+	InstallID        string        `json:"install_id,omitempty" yaml:"install_id,omitempty"`
+	ReportedKubeUser string        `json:"reported_kube_user,omitempty" yaml:"reported_kube_user,omitempty"`
+	Namespace        string        `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Workload         string        `json:"workload,omitempty" yaml:"workload,omitempty"`
+	Intercept        string        `json:"intercept,omitempty" yaml:"intercept,omitempty"`
+	Duration         time.Duration `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Message          string        `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func newAuditRecordInfo(r *manager.AuditRecord) *auditRecordInfo {
+	return &auditRecordInfo{
+		Time:             r.Time.AsTime(),
+		Event:            r.Event,
+		SessionID:        r.SessionId,
+		Client:           r.Client,
+		InstallID:        r.InstallId,
+		ReportedKubeUser: r.KubeUser,
+		Namespace:        r.Namespace,
+		Workload:         r.Workload,
+		Intercept:        r.Intercept,
+		Duration:         r.Duration.AsDuration(),
+		Message:          r.Message,
+	}
+}
+
+// print prints the record on one line.
+func (ri *auditRecordInfo) print(out io.Writer) {
+	var details []string
+	if ri.Client != "" {
+		client := ri.Client
+		if ri.ReportedKubeUser != "" {
+			client += " (reported kube user " + ri.ReportedKubeUser + ")"
+		}
+		details = append(details, client)
+	}
+	if ri.Intercept != "" {
+		details = append(details, "intercept "+ri.Intercept)
+	}
+	if ri.Workload != "" {
+		details = append(details, "workload "+ri.Workload+"."+ri.Namespace)
+	}
+	if ri.Duration > 0 {
+		details = append(details, "lasted "+ri.Duration.Round(time.Second).String())
+	}
+	if ri.Message != "" {
+		details = append(details, ri.Message)
+	}
+	fmt.Fprintf(out, "%s %-17s %s\n", ri.Time.Local().Format("2006-01-02 15:04:05"), ri.Event, strings.Join(details, ", "))
+}
+
+func auditCmd() *cobra.Command {
+	var since time.Duration
+	rq := &manager.AuditLogRequest{}
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Args:  cobra.NoArgs,
+		Short: "Show the audit log of the traffic-manager",
+		Long: `Show the audit log of the traffic-manager, oldest record first.
+
+The audit log records the arrival and departure of client sessions, the creation, update, and removal of
+intercepts, and the injection and uninstall of traffic-agents. Each record shows the client, with the user of
+its kubeconfig when known, and the workload. The departure of a session and the removal of an intercept also
+show how long it lasted.
+
+The audit log is enabled using the Helm chart value "audit.sink", which makes the traffic-manager write it as
+JSON lines to its stdout, to a file, or as Kubernetes Events in its namespace. The API server deletes Events
+after its --event-ttl, which is one hour by default, so only the recent records are shown when Events are used.`,
+		Example: `  telepresence audit --since 24h
+  telepresence audit --workload api -n default`,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			if since > 0 {
+				rq.Since = timestamppb.New(time.Now().Add(-since))
+			}
+			ctx := cmd.Context()
+			al, err := daemonClient.GetUserClient(ctx).GetAuditLog(ctx, rq)
+			if err != nil {
+				return err
+			}
+			records := make([]*auditRecordInfo, len(al.Records))
+			for i, r := range al.Records {
+				records[i] = newAuditRecordInfo(r)
+			}
+			if output.WantsFormatted(cmd) {
+				output.Object(ctx, records, false)
+				return nil
+			}
+			out := cmd.OutOrStdout()
+			if len(records) == 0 {
+				fmt.Fprintln(out, "No audit records")
+			}
+			for _, ri := range records {
+				ri.print(out)
+			}
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.DurationVar(&since, "since", 0, "only show records that are more recent than the given duration, e.g. 2h")
+	flags.StringVarP(&rq.Namespace, "namespace", "n", "", "only show records of workloads in the given namespace")
+	flags.StringVar(&rq.Workload, "workload", "", "only show records of the given workload")
+	flags.StringVar(&rq.Client, "client", "", "only show records of the given client, e.g. john@host")
+	return cmd
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+func TestAuditRecordInfo_print(t *testing.T) {
+	tm := time.Date(2023, 4, 1, 12, 30, 15, 0, time.Local)
+	out := &strings.Builder{}
+	newAuditRecordInfo(&manager.AuditRecord{
+		Time:      timestamppb.New(tm),
+		Event:     "intercept-removed",
+		SessionId: "abc",
+		Client:    "john@host",
+		KubeUser:  "john",
+		Namespace: "default",
+		Workload:  "api",
+		Intercept: "api-http",
+		Duration:  durationpb.New(90*time.Minute + 400*time.Millisecond),
+	}).print(out)
+	newAuditRecordInfo(&manager.AuditRecord{
+		Time:      timestamppb.New(tm),
+		Event:     "agent-injected",
+		Namespace: "default",
+		Workload:  "api",
+	}).print(out)
+	assert.Equal(t,
+		"2023-04-01 12:30:15 intercept-removed john@host (reported kube user john), intercept api-http, workload api.default, lasted 1h30m0s\n"+
+			"2023-04-01 12:30:15 agent-injected    workload api.default\n",
+		out.String())
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	daemonClient "github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/pcapng"
+	"github.com/telepresenceio/telepresence/v2/pkg/vif"
+)
+
+type captureCommand struct {
+	output     string
+	filter     string
+	snapLength int
+	maxSize    int
+	maxFiles   int
+}
+
+func capture() *cobra.Command {
+	s := &captureCommand{}
+	cmd := &cobra.Command{
+		Use:  "capture --output <file>",
+		Args: cobra.NoArgs,
+
+		Short: "Capture the packets sent to and received from the cluster",
+		Long: `Capture the packets that pass through the virtual network interface of the root daemon and write them
+to a file in pcap-ng format, until interrupted. The file can be analyzed using tools like Wireshark or tcpdump.
+
+The filter consists of terms separated by "and", where each term is one of "host <ip>", "net <cidr>",
+"port <port>", "tcp", "udp", or "icmp".`,
+		Example: `  telepresence capture --output traffic.pcapng --filter "host 10.96.0.10 and port 53"`,
+		RunE:    s.run,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&s.output, "output", "o", "", "the file to write the captured packets to")
+	flags.StringVar(&s.filter, "filter", "", `only capture packets matching the filter, e.g. "host 10.0.0.1 and port 80"`)
+	flags.IntVar(&s.snapLength, "snap-length", 0, "max number of bytes to capture from each packet. Zero means no limit")
+	flags.IntVar(&s.maxSize, "max-size", 100, "max size in megabytes of the output file before it is rotated. Zero disables rotation")
+	flags.IntVar(&s.maxFiles, "max-files", 5, "max number of rotated files to keep")
+	_ = cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+func (s *captureCommand) run(cmd *cobra.Command, _ []string) error {
+	if _, err := vif.ParsePacketFilter(s.filter); err != nil {
+		return errcat.User.New(err)
+	}
+	if s.snapLength < 0 || s.maxSize < 0 || s.maxFiles < 0 {
+		return errcat.User.New("--snap-length, --max-size, and --max-files cannot be negative")
+	}
+	if err := connect.InitCommand(cmd); err != nil {
+		return err
+	}
+
+	// The capture ends when the user interrupts it, and the file must then be flushed and closed properly.
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cc, err := daemonClient.GetUserClient(ctx).Capture(ctx, &daemon.CaptureRequest{
+		Filter:     s.filter,
+		SnapLength: int32(s.snapLength),
+	})
+	if err != nil {
+		return err
+	}
+	rf, err := pcapng.OpenRotatingFile(s.output, "telepresence", pcapng.LinkTypeRaw, s.snapLength, int64(s.maxSize)*1024*1024, s.maxFiles)
+	if err != nil {
+		return errcat.NoDaemonLogs.New(err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Capturing packets to %s. Press Ctrl-C to stop.\n", s.output)
+	count, err := writeCaptured(ctx, cc, rf)
+	if cerr := rf.Close(); err == nil {
+		err = cerr
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "%d packets captured\n", count)
+	return err
+}
+
+// writeCaptured writes the packets received from the given stream until the stream ends or the context is cancelled,
+// and returns the number of packets written.
+func writeCaptured(ctx context.Context, cc interface {
+	Recv() (*daemon.CapturedPacket, error)
+}, rf *pcapng.RotatingFile,
+) (int, error) {
+	count := 0
+	for {
+		p, err := cc.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return count, nil
+			}
+			return count, err
+		}
+		dir := pcapng.DirectionInbound
+		if p.Outbound {
+			dir = pcapng.DirectionOutbound
+		}
+		if err = rf.WritePacket(p.Timestamp.AsTime(), p.Data, int(p.OriginalLength), dir); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
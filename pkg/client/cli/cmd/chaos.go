@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/durationpb"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	daemonClient "github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/ioutil"
+)
+
+func chaos() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "chaos",
+		Short: "Inject latency and faults into the connections to the cluster",
+	}
+	cmd.AddCommand(chaosAdd(), chaosRemove(), chaosList())
+	return cmd
+}
+
+// chaosRuleInfo is the output representation of a daemon.ChaosRule.
+type chaosRuleInfo struct {
+	ID          string        `json:"id" yaml:"id"`
+	Destination string        `json:"destination" yaml:"destination"`
+	Latency     time.Duration `json:"latency,omitempty" yaml:"latency,omitempty"`
+	Jitter      time.Duration `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+	DropRate    float64       `json:"drop_rate,omitempty" yaml:"drop_rate,omitempty"`
+	ResetAfter  time.Duration `json:"reset_after,omitempty" yaml:"reset_after,omitempty"`
+}
+
+func newChaosRuleInfo(cr *daemon.ChaosRule) *chaosRuleInfo {
+	return &chaosRuleInfo{
+		ID:          cr.Id,
+		Destination: cr.Destination,
+		Latency:     cr.Latency.AsDuration(),
+		Jitter:      cr.Jitter.AsDuration(),
+		DropRate:    cr.DropRate,
+		ResetAfter:  cr.ResetAfter.AsDuration(),
+	}
+}
+
+// String returns a one line description of the rule, e.g. "svc.ns:5432 latency 200ms±50ms, drop 5%".
+func (ri *chaosRuleInfo) String() string {
+	var faults []string
+	if ri.Latency > 0 {
+		l := "latency " + ri.Latency.String()
+		if ri.Jitter > 0 {
+			l += "±" + ri.Jitter.String()
+		}
+		faults = append(faults, l)
+	}
+	if ri.DropRate > 0 {
+		faults = append(faults, "drop "+strconv.FormatFloat(ri.DropRate*100, 'f', -1, 64)+"%")
+	}
+	if ri.ResetAfter > 0 {
+		faults = append(faults, "reset after "+ri.ResetAfter.String())
+	}
+	if len(faults) == 0 {
+		faults = append(faults, "no faults")
+	}
+	return ri.Destination + " " + strings.Join(faults, ", ")
+}
+
+// parseDropRate parses a percentage such as "5%" or "0.5%" into a rate between 0 and 1.
+func parseDropRate(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	p, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || p < 0 || p > 100 {
+		return 0, errcat.User.Newf("invalid drop rate %q, must be a percentage between 0%% and 100%%", s)
+	}
+	return p / 100, nil
+}
+
+func chaosAdd() *cobra.Command {
+	var dest, drop string
+	var latency, jitter, resetAfter time.Duration
+	cmd := &cobra.Command{
+		Use:   "add --dest <host[:port]>",
+		Args:  cobra.NoArgs,
+		Short: "Add a rule that injects latency and faults into the connections to a destination",
+		Long: `Add a rule that injects latency and faults into the connections to a destination.
+
+The destination is a host with an optional port. The host is an IP, a CIDR, or a name. A name matches the
+name that was used when looking up the destination IP, so "svc.ns" matches connections to the IPs returned
+when looking up "svc.ns" or "svc.ns.svc.cluster.local".
+
+The latency is added to the data received from the cluster. The drop rate applies to TCP connection
+attempts and UDP datagrams.`,
+		Example: `  telepresence chaos add --dest svc.ns:5432 --latency 200ms --jitter 50ms --drop 5% --reset-after 10s`,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dropRate, err := parseDropRate(drop)
+			if err != nil {
+				return err
+			}
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			cr, err := daemonClient.GetUserClient(ctx).AddChaosRule(ctx, &daemon.ChaosRule{
+				Destination: dest,
+				Latency:     durationpb.New(latency),
+				Jitter:      durationpb.New(jitter),
+				DropRate:    dropRate,
+				ResetAfter:  durationpb.New(resetAfter),
+			})
+			if err != nil {
+				return err
+			}
+			ri := newChaosRuleInfo(cr)
+			if output.WantsFormatted(cmd) {
+				output.Object(ctx, ri, false)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Chaos rule %s added: %s\n", ri.ID, ri)
+			}
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&dest, "dest", "", "the destination, e.g. svc.ns:5432, 10.96.0.12, or 10.96.0.0/16")
+	flags.DurationVar(&latency, "latency", 0, "latency added to the data received from the destination")
+	flags.DurationVar(&jitter, "jitter", 0, "max random deviation from the latency")
+	flags.StringVar(&drop, "drop", "", "percentage of TCP connection attempts and UDP datagrams to drop, e.g. 5%")
+	flags.DurationVar(&resetAfter, "reset-after", 0, "close each connection after this duration")
+	_ = cmd.MarkFlagRequired("dest")
+	return cmd
+}
+
+func chaosRemove() *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "remove [<id> | --all]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Remove a chaos rule, or all chaos rules",
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return errcat.User.New("either a rule id or --all must be given")
+			}
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			rr := &daemon.RemoveChaosRuleRequest{All: all}
+			if !all {
+				rr.Id = args[0]
+			}
+			ctx := cmd.Context()
+			_, err := daemonClient.GetUserClient(ctx).RemoveChaosRule(ctx, rr)
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "remove all chaos rules")
+	return cmd
+}
+
+func chaosList() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Short: "List the chaos rules",
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			crs, err := daemonClient.GetUserClient(ctx).GetChaosRules(ctx, &empty.Empty{})
+			if err != nil {
+				return err
+			}
+			ris := make([]*chaosRuleInfo, len(crs.Rules))
+			for i, cr := range crs.Rules {
+				ris[i] = newChaosRuleInfo(cr)
+			}
+			if output.WantsFormatted(cmd) {
+				output.Object(ctx, ris, false)
+				return nil
+			}
+			if len(ris) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No chaos rules")
+				return nil
+			}
+			kvf := ioutil.DefaultKeyValueFormatter()
+			for _, ri := range ris {
+				kvf.Add(ri.ID, ri.String())
+			}
+			kvf.Println(cmd.OutOrStdout())
+			return nil
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDropRate(t *testing.T) {
+	for s, expected := range map[string]float64{"": 0, "5%": 0.05, "0.5%": 0.005, "100": 1} {
+		r, err := parseDropRate(s)
+		require.NoError(t, err, s)
+		assert.InDelta(t, expected, r, 1e-9, s)
+	}
+	for _, s := range []string{"-1%", "101%", "five"} {
+		_, err := parseDropRate(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestChaosRuleInfo_String(t *testing.T) {
+	ri := &chaosRuleInfo{
+		ID:          "1",
+		Destination: "svc.ns:5432",
+		Latency:     200 * time.Millisecond,
+		Jitter:      50 * time.Millisecond,
+		DropRate:    0.05,
+		ResetAfter:  10 * time.Second,
+	}
+	assert.Equal(t, "svc.ns:5432 latency 200ms±50ms, drop 5%, reset after 10s", ri.String())
+	assert.Equal(t, "10.0.0.1 no faults", (&chaosRuleInfo{Destination: "10.0.0.1"}).String())
+}
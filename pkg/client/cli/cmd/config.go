@@ -52,7 +52,8 @@ func runConfigView(cmd *cobra.Command, _ []string) error {
 		if err != nil {
 			return err
 		}
-		cfg.Config = client.GetConfig(cmd.Context())
+		localCfg := *client.GetConfig(cmd.Context())
+		cfg.Config = &localCfg
 		cfg.ClientFile = filepath.Join(cfgDir, client.ConfigFile)
 
 		rq := daemon.GetRequest(ctx)
@@ -62,6 +63,8 @@ func runConfigView(cmd *cobra.Command, _ []string) error {
 		}
 		cfg.Routing.AlsoProxy = kc.AlsoProxy
 		cfg.Routing.NeverProxy = kc.NeverProxy
+		cfg.Routing.AlsoProxyHosts = kc.AlsoProxyHosts
+		cfg.Routing.NeverProxyHosts = kc.NeverProxyHosts
 		if dns := kc.DNS; dns != nil {
 			cfg.DNS.ExcludeSuffixes = dns.ExcludeSuffixes
 			cfg.DNS.IncludeSuffixes = dns.IncludeSuffixes
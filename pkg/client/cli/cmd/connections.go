@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	daemonClient "github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/logging"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+type connectionsCommand struct {
+	watch    bool
+	interval time.Duration
+	history  string
+}
+
+func connections() *cobra.Command {
+	s := &connectionsCommand{}
+	cmd := &cobra.Command{
+		Use:  "connections",
+		Args: cobra.NoArgs,
+
+		Short: "List the connections that are currently tunneled to the cluster",
+		RunE:  s.run,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+	}
+	flags := cmd.Flags()
+	flags.BoolVarP(&s.watch, "watch", "w", false, "list the connections repeatedly until interrupted")
+	flags.DurationVar(&s.interval, "interval", 2*time.Second, "the interval between each listing when using --watch")
+	flags.StringVar(&s.history, "history", "", ""+
+		"append the connections that end while watching to this file. The file is rotated daily")
+	return cmd
+}
+
+func (s *connectionsCommand) run(cmd *cobra.Command, _ []string) error {
+	if s.history != "" && !s.watch {
+		return errcat.User.New("--history requires --watch")
+	}
+	if s.interval <= 0 {
+		return errcat.User.New("--interval must be greater than zero")
+	}
+	if err := connect.InitCommand(cmd); err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	userD := daemonClient.GetUserClient(ctx)
+	stdout := cmd.OutOrStdout()
+	formatted := output.WantsFormatted(cmd)
+	cs, err := userD.GetConnections(ctx, &empty.Empty{})
+	if err != nil {
+		return err
+	}
+	if !s.watch {
+		printConnections(ctx, stdout, cs.Connections, formatted, time.Now())
+		return nil
+	}
+
+	var history io.Writer
+	if s.history != "" {
+		rf, err := logging.OpenRotatingFile(ctx, s.history, "20060102T150405", true, 0o600, logging.RotateDaily, 5)
+		if err != nil {
+			return err
+		}
+		defer rf.Close()
+		history = rf
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		if !formatted {
+			fmt.Fprintf(stdout, "%s\n", now.Format(time.RFC3339))
+		}
+		printConnections(ctx, stdout, cs.Connections, formatted, now)
+		if !formatted {
+			fmt.Fprintln(stdout)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		prev := cs.Connections
+		if cs, err = userD.GetConnections(ctx, &empty.Empty{}); err != nil {
+			return err
+		}
+		if history != nil {
+			if ended := endedConnections(prev, cs.Connections); len(ended) > 0 {
+				writeConnections(history, ended, time.Now())
+			}
+		}
+	}
+}
+
+func connectionKey(c *daemon.Connection) string {
+	return c.Protocol + " " + c.Source + " " + c.Destination
+}
+
+// endedConnections returns the connections in prev that are not present in curr.
+func endedConnections(prev, curr []*daemon.Connection) []*daemon.Connection {
+	active := make(map[string]struct{}, len(curr))
+	for _, c := range curr {
+		active[connectionKey(c)] = struct{}{}
+	}
+	var ended []*daemon.Connection
+	for _, c := range prev {
+		if _, ok := active[connectionKey(c)]; !ok {
+			ended = append(ended, c)
+		}
+	}
+	return ended
+}
+
+func printConnections(ctx context.Context, out io.Writer, cs []*daemon.Connection, formatted bool, now time.Time) {
+	if formatted {
+		if cs == nil {
+			cs = []*daemon.Connection{}
+		}
+		output.Object(ctx, cs, false)
+		return
+	}
+	if len(cs) == 0 {
+		fmt.Fprintln(out, "No active connections")
+		return
+	}
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROTO\tSOURCE\tDESTINATION\tNAME\tAGE\tIDLE\tSENT\tRECEIVED")
+	writeConnectionRows(tw, cs, now)
+	_ = tw.Flush()
+}
+
+// writeConnections writes one line for each of the given connections, prefixed with the given time. It's used
+// when writing the history file.
+func writeConnections(out io.Writer, cs []*daemon.Connection, now time.Time) {
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, c := range cs {
+		fmt.Fprintf(tw, "%s\t", now.Format(time.RFC3339))
+		writeConnectionRows(tw, []*daemon.Connection{c}, now)
+	}
+	_ = tw.Flush()
+}
+
+func writeConnectionRows(out io.Writer, cs []*daemon.Connection, now time.Time) {
+	for _, c := range cs {
+		name := c.ServiceName
+		if name == "" {
+			name = "-"
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.Protocol, c.Source, c.Destination, name,
+			formatAge(now, c.Started.AsTime()), formatAge(now, c.LastActivity.AsTime()),
+			formatBytes(c.BytesSent), formatBytes(c.BytesReceived))
+	}
+}
+
+func formatAge(now, t time.Time) string {
+	if t.IsZero() || t.Unix() == 0 {
+		return "-"
+	}
+	d := now.Sub(t)
+	if d < 0 {
+		d = 0
+	}
+	if d < time.Minute {
+		return d.Truncate(time.Second).String()
+	}
+	return d.Truncate(time.Minute).String()
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
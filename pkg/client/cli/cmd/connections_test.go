@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
+)
+
+func TestEndedConnections(t *testing.T) {
+	a := &daemon.Connection{Protocol: "tcp", Source: "10.0.0.1:4711", Destination: "10.96.0.1:80"}
+	b := &daemon.Connection{Protocol: "udp", Source: "10.0.0.1:4712", Destination: "10.96.0.10:53"}
+	c := &daemon.Connection{Protocol: "tcp", Source: "10.0.0.1:4713", Destination: "10.96.0.1:80"}
+	assert.Equal(t, []*daemon.Connection{b}, endedConnections([]*daemon.Connection{a, b}, []*daemon.Connection{a, c}))
+	assert.Empty(t, endedConnections([]*daemon.Connection{a}, []*daemon.Connection{a}))
+	assert.Empty(t, endedConnections(nil, []*daemon.Connection{a}))
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "0B", formatBytes(0))
+	assert.Equal(t, "1023B", formatBytes(1023))
+	assert.Equal(t, "1.0KiB", formatBytes(1024))
+	assert.Equal(t, "1.5MiB", formatBytes(3*512*1024))
+	assert.Equal(t, "2.0GiB", formatBytes(2*1024*1024*1024))
+}
+
+func TestWriteConnections(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	c := &daemon.Connection{
+		Protocol:      "tcp",
+		Source:        "10.0.0.1:4711",
+		Destination:   "10.96.0.1:80",
+		ServiceName:   "echo.default.svc.cluster.local",
+		Started:       timestamppb.New(now.Add(-90 * time.Second)),
+		LastActivity:  timestamppb.New(now.Add(-5 * time.Second)),
+		BytesSent:     100,
+		BytesReceived: 2048,
+	}
+	sb := strings.Builder{}
+	writeConnections(&sb, []*daemon.Connection{c}, now)
+	assert.Equal(t,
+		strings.Join([]string{
+			"2023-04-01T12:00:00Z", "tcp", "10.0.0.1:4711", "10.96.0.1:80",
+			"echo.default.svc.cluster.local", "1m0s", "5s", "100B", "2.0KiB",
+		}, "  ")+"\n",
+		sb.String())
+}
@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	daemonClient "github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/ioutil"
+)
+
+func dnsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dns",
+		Short: "Inspect the DNS server of the root daemon",
+	}
+	cmd.AddCommand(dnsCache(), dnsQuery(), dnsLog())
+	return cmd
+}
+
+// dnsCacheEntryInfo is the output representation of a daemon.DNSCacheEntry.
+type dnsCacheEntryInfo struct {
+	Name    string    `json:"name" yaml:"name"`
+	Type    string    `json:"type" yaml:"type"`
+	RCode   string    `json:"r_code" yaml:"r_code"`
+	Answer  []string  `json:"answer,omitempty" yaml:"answer,omitempty"`
+	Expires time.Time `json:"expires" yaml:"expires"`
+}
+
+// dnsCacheInfo is the output representation of a daemon.DNSCache.
+type dnsCacheInfo struct {
+	MinTTL             time.Duration        `json:"min_ttl" yaml:"min_ttl"`
+	MaxTTL             time.Duration        `json:"max_ttl" yaml:"max_ttl"`
+	NegativeTTL        time.Duration        `json:"negative_ttl" yaml:"negative_ttl"`
+	MaxEntries         int                  `json:"max_entries" yaml:"max_entries"`
+	Hits               int64                `json:"hits" yaml:"hits"`
+	Misses             int64                `json:"misses" yaml:"misses"`
+	Evictions          int64                `json:"evictions" yaml:"evictions"`
+	ClusterLookups     int64                `json:"cluster_lookups" yaml:"cluster_lookups"`
+	ClusterLookupTime  time.Duration        `json:"cluster_lookup_time" yaml:"cluster_lookup_time"`
+	FallbackLookups    int64                `json:"fallback_lookups" yaml:"fallback_lookups"`
+	FallbackLookupTime time.Duration        `json:"fallback_lookup_time" yaml:"fallback_lookup_time"`
+	Entries            []*dnsCacheEntryInfo `json:"entries" yaml:"entries"`
+	Flushed            bool                 `json:"flushed,omitempty" yaml:"flushed,omitempty"`
+}
+
+func newDNSCacheInfo(c *daemon.DNSCache) *dnsCacheInfo {
+	ci := &dnsCacheInfo{
+		MinTTL:             c.MinTtl.AsDuration(),
+		MaxTTL:             c.MaxTtl.AsDuration(),
+		NegativeTTL:        c.NegativeTtl.AsDuration(),
+		MaxEntries:         int(c.MaxEntries),
+		Hits:               c.Hits,
+		Misses:             c.Misses,
+		Evictions:          c.Evictions,
+		ClusterLookups:     c.ClusterLookups,
+		ClusterLookupTime:  c.ClusterLookupTime.AsDuration(),
+		FallbackLookups:    c.FallbackLookups,
+		FallbackLookupTime: c.FallbackLookupTime.AsDuration(),
+		Entries:            make([]*dnsCacheEntryInfo, len(c.Entries)),
+	}
+	for i, e := range c.Entries {
+		ci.Entries[i] = &dnsCacheEntryInfo{
+			Name:    e.Name,
+			Type:    e.Type,
+			RCode:   e.RCode,
+			Answer:  e.Answer,
+			Expires: e.Expires.AsTime(),
+		}
+	}
+	return ci
+}
+
+// lookups returns a description of a number of lookups and their average duration, e.g. "12, avg 3ms".
+func lookups(n int64, total time.Duration) string {
+	if n == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%d, avg %s", n, (total / time.Duration(n)).Round(time.Microsecond))
+}
+
+func (ci *dnsCacheInfo) print(out io.Writer, now time.Time) {
+	kvf := ioutil.DefaultKeyValueFormatter()
+	hits := fmt.Sprintf("%d", ci.Hits)
+	if total := ci.Hits + ci.Misses; total > 0 {
+		hits += fmt.Sprintf(" (%.1f%%)", float64(ci.Hits)*100/float64(total))
+	}
+	kvf.Add("Hits", hits)
+	kvf.Add("Misses", fmt.Sprintf("%d", ci.Misses))
+	kvf.Add("Evictions", fmt.Sprintf("%d", ci.Evictions))
+	kvf.Add("Cluster lookups", lookups(ci.ClusterLookups, ci.ClusterLookupTime))
+	kvf.Add("Fallback lookups", lookups(ci.FallbackLookups, ci.FallbackLookupTime))
+	kvf.Add("TTL", fmt.Sprintf("%s to %s", ci.MinTTL, ci.MaxTTL))
+	negTTL := "disabled"
+	if ci.NegativeTTL > 0 {
+		negTTL = ci.NegativeTTL.String()
+	}
+	kvf.Add("Negative TTL", negTTL)
+	kvf.Add("Entries", fmt.Sprintf("%d of max %d", len(ci.Entries), ci.MaxEntries))
+	kvf.Println(out)
+
+	if len(ci.Entries) > 0 {
+		fmt.Fprintln(out)
+		kvf = ioutil.DefaultKeyValueFormatter()
+		for _, e := range ci.Entries {
+			desc := fmt.Sprintf("%s, expires in %s", e.RCode, e.Expires.Sub(now).Round(time.Second))
+			if len(e.Answer) > 0 {
+				desc += "\n" + strings.Join(e.Answer, "\n")
+			}
+			kvf.Add(fmt.Sprintf("%-5s %s", e.Type, e.Name), desc)
+		}
+		kvf.Println(out)
+	}
+	if ci.Flushed {
+		fmt.Fprintf(out, "\nFlushed %d entries\n", len(ci.Entries))
+	}
+}
+
+func dnsCache() *cobra.Command {
+	var flush bool
+	cmd := &cobra.Command{
+		Use:   "cache [--flush]",
+		Args:  cobra.NoArgs,
+		Short: "Show the statistics and the entries of the DNS cache",
+		Long: `Show the statistics and the entries of the DNS cache.
+
+The hits and misses count the queries that were found and not found in the cache. The cluster lookups are
+the queries that were sent to the cluster because of a miss, and the fallback lookups are the queries that
+weren't found in the cluster and were sent to the DNS server that is used when not connected.
+
+The TTLs of the cached answers, and the max number of entries, are configured in the "dns.cache" section of
+the config.yml file.`,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			c, err := daemonClient.GetUserClient(ctx).GetDNSCache(ctx, &daemon.DNSCacheRequest{Flush: flush})
+			if err != nil {
+				return err
+			}
+			ci := newDNSCacheInfo(c)
+			ci.Flushed = flush
+			if output.WantsFormatted(cmd) {
+				output.Object(ctx, ci, false)
+			} else {
+				ci.print(cmd.OutOrStdout(), time.Now())
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&flush, "flush", false, "flush the cache after showing its entries")
+	return cmd
+}
+
+// dnsQueryInfo is the output representation of a daemon.DNSQueryResponse.
+type dnsQueryInfo struct {
+	Name     string        `json:"name" yaml:"name"`
+	Type     string        `json:"type" yaml:"type"`
+	Path     string        `json:"path" yaml:"path"`
+	RCode    string        `json:"r_code" yaml:"r_code"`
+	Answer   []string      `json:"answer,omitempty" yaml:"answer,omitempty"`
+	Duration time.Duration `json:"duration" yaml:"duration"`
+}
+
+func (qi *dnsQueryInfo) print(out io.Writer) {
+	kvf := ioutil.DefaultKeyValueFormatter()
+	kvf.Add("Query", qi.Type+" "+qi.Name)
+	kvf.Add("Path", qi.Path)
+	kvf.Add("Result", qi.RCode)
+	kvf.Add("Duration", qi.Duration.Round(time.Microsecond).String())
+	if len(qi.Answer) > 0 {
+		kvf.Add("Answer", strings.Join(qi.Answer, "\n"))
+	}
+	kvf.Println(out)
+}
+
+func dnsQuery() *cobra.Command {
+	return &cobra.Command{
+		Use:   "query <name> [type]",
+		Args:  cobra.RangeArgs(1, 2),
+		Short: "Resolve a name using the DNS server of the root daemon",
+		Long: `Resolve a name using the DNS server of the root daemon, and show the path that the resolution took.
+
+The path is "mapping" when the name is mapped in the "dns.mappings" configuration, "cache" when the answer
+was cached, "cluster" when it was resolved in the cluster, and "fallback" when it wasn't found in the cluster
+and was resolved by the DNS server that is used when not connected. The name is resolved as is, without using
+the search path of the workstation. The type defaults to A.`,
+		Example: `  telepresence dns query web.default
+  telepresence dns query _http._tcp.web.default SRV`,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			rq := &daemon.DNSQueryRequest{Name: args[0], Type: "A"}
+			if len(args) == 2 {
+				rq.Type = strings.ToUpper(args[1])
+			}
+			ctx := cmd.Context()
+			r, err := daemonClient.GetUserClient(ctx).QueryDNS(ctx, rq)
+			if err != nil {
+				return err
+			}
+			qi := &dnsQueryInfo{
+				Name:     rq.Name,
+				Type:     rq.Type,
+				Path:     strings.ToLower(r.Path.String()),
+				RCode:    r.RCode,
+				Answer:   r.Answer,
+				Duration: r.Duration.AsDuration(),
+			}
+			if output.WantsFormatted(cmd) {
+				output.Object(ctx, qi, false)
+			} else {
+				qi.print(cmd.OutOrStdout())
+			}
+			return nil
+		},
+	}
+}
+
+// dnsLogEntryInfo is the output representation of a daemon.DNSQueryLogEntry.
+type dnsLogEntryInfo struct {
+	Time     time.Time     `json:"time" yaml:"time"`
+	Client   string        `json:"client,omitempty" yaml:"client,omitempty"`
+	Name     string        `json:"name" yaml:"name"`
+	Type     string        `json:"type" yaml:"type"`
+	Cluster  bool          `json:"cluster" yaml:"cluster"`
+	Lookups  []string      `json:"lookups,omitempty" yaml:"lookups,omitempty"`
+	Path     string        `json:"path" yaml:"path"`
+	RCode    string        `json:"r_code" yaml:"r_code"`
+	Answer   []string      `json:"answer,omitempty" yaml:"answer,omitempty"`
+	Duration time.Duration `json:"duration" yaml:"duration"`
+}
+
+func newDNSLogEntryInfo(e *daemon.DNSQueryLogEntry) *dnsLogEntryInfo {
+	return &dnsLogEntryInfo{
+		Time:     e.Time.AsTime(),
+		Client:   e.Client,
+		Name:     e.Name,
+		Type:     e.Type,
+		Cluster:  e.Cluster,
+		Lookups:  e.Lookups,
+		Path:     strings.ToLower(e.Path.String()),
+		RCode:    e.RCode,
+		Answer:   e.Answer,
+		Duration: e.Duration.AsDuration(),
+	}
+}
+
+// print prints the entry on one line.
+func (li *dnsLogEntryInfo) print(out io.Writer) {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "%s %-5s %s -> %s, %s in %s",
+		li.Time.Local().Format("15:04:05.000"), li.Type, li.Name, li.RCode, li.Path, li.Duration.Round(time.Microsecond))
+	if li.Client != "" {
+		fmt.Fprintf(&sb, ", client %s", li.Client)
+	}
+	if !li.Cluster {
+		sb.WriteString(", not routed to the cluster")
+	}
+	if len(li.Lookups) > 0 {
+		fmt.Fprintf(&sb, ", lookups %s", strings.Join(li.Lookups, " "))
+	}
+	fmt.Fprintln(out, sb.String())
+}
+
+func dnsLog() *cobra.Command {
+	var follow bool
+	cmd := &cobra.Command{
+		Use:   "log [--follow]",
+		Args:  cobra.NoArgs,
+		Short: "Show the queries that the DNS server of the root daemon has received",
+		Long: `Show the queries that the DNS server of the root daemon has received.
+
+Each entry shows the client that sent the query, when it can be determined, whether the name is routed to the
+cluster, the names that were looked up in the cluster when the search path was expanded, the result, and the
+time that it took to resolve the query.
+
+Queries are only recorded while the log is enabled, or followed using --follow. The log is enabled by setting
+"dns.queryLog" to true in the config.yml file, which also writes the entries to a daily rotated
+dns-queries.log file in the log directory. The most recent entries are retained in memory.`,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if follow && output.WantsFormatted(cmd) && !output.WantsStream(cmd) {
+				return errcat.User.New("--follow can only be combined with --output=json-stream")
+			}
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			stream, err := daemonClient.GetUserClient(ctx).GetDNSQueryLog(ctx, &daemon.DNSQueryLogRequest{Follow: follow})
+			if err != nil {
+				return err
+			}
+			formatted := output.WantsFormatted(cmd)
+			streamed := output.WantsStream(cmd)
+			entries := []*dnsLogEntryInfo{}
+			n := 0
+			for {
+				e, err := stream.Recv()
+				if err != nil {
+					if errors.Is(err, io.EOF) || ctx.Err() != nil {
+						break
+					}
+					return err
+				}
+				n++
+				li := newDNSLogEntryInfo(e)
+				switch {
+				case streamed:
+					output.Object(ctx, li, false)
+				case formatted:
+					entries = append(entries, li)
+				default:
+					li.print(cmd.OutOrStdout())
+				}
+			}
+			switch {
+			case streamed:
+			case formatted:
+				output.Object(ctx, entries, false)
+			case n == 0 && !follow:
+				fmt.Fprintln(cmd.OutOrStdout(), `No queries have been logged. Set "dns.queryLog" to true in the config.yml file, or use --follow`)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "continue to show new queries as they are received")
+	return cmd
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+)
+
+func TestDNSCacheInfo_print(t *testing.T) {
+	now := time.Now()
+	ci := newDNSCacheInfo(&rpc.DNSCache{
+		MinTtl:            durationpb.New(time.Minute),
+		MaxTtl:            durationpb.New(10 * time.Minute),
+		NegativeTtl:       durationpb.New(0),
+		MaxEntries:        10000,
+		Hits:              3,
+		Misses:            1,
+		ClusterLookups:    1,
+		ClusterLookupTime: durationpb.New(12 * time.Millisecond),
+		Entries: []*rpc.DNSCacheEntry{{
+			Name:    "web.default.",
+			Type:    "A",
+			RCode:   "NOERROR",
+			Answer:  []string{"web.default.\t4\tIN\tA\t10.96.0.12"},
+			Expires: timestamppb.New(now.Add(45 * time.Second)),
+		}},
+	})
+	ci.Flushed = true
+
+	out := &strings.Builder{}
+	ci.print(out, now)
+	s := out.String()
+	assert.Contains(t, s, "Hits            : 3 (75.0%)\n")
+	assert.Contains(t, s, "Cluster lookups : 1, avg 12ms\n")
+	assert.Contains(t, s, "Fallback lookups: 0\n")
+	assert.Contains(t, s, "Negative TTL    : disabled\n")
+	assert.Contains(t, s, "Entries         : 1 of max 10000\n")
+	assert.Contains(t, s, "A     web.default.: NOERROR, expires in 45s\n")
+	assert.Contains(t, s, "    web.default.\t4\tIN\tA\t10.96.0.12\n")
+	assert.True(t, strings.HasSuffix(s, "Flushed 1 entries\n"))
+}
+
+func TestDNSQueryInfo_print(t *testing.T) {
+	qi := &dnsQueryInfo{
+		Name:     "web.default",
+		Type:     "A",
+		Path:     "cache",
+		RCode:    "NOERROR",
+		Answer:   []string{"web.default.\t4\tIN\tA\t10.96.0.12"},
+		Duration: 1500 * time.Microsecond,
+	}
+	out := &strings.Builder{}
+	qi.print(out)
+	lines := strings.Split(out.String(), "\n")
+	require.Len(t, lines, 6)
+	assert.Equal(t, "Query   : A web.default", lines[0])
+	assert.Equal(t, "Path    : cache", lines[1])
+	assert.Equal(t, "Duration: 1.5ms", lines[3])
+}
+
+func TestDNSLogEntryInfo_print(t *testing.T) {
+	li := &dnsLogEntryInfo{
+		Time:     time.Date(2023, 4, 5, 10, 11, 12, 345e6, time.Local),
+		Client:   "127.0.0.1:54321 curl[1234]",
+		Name:     "web.",
+		Type:     "A",
+		Lookups:  []string{"web.default.", "web.other."},
+		Path:     "cluster",
+		RCode:    "NOERROR",
+		Duration: 2 * time.Millisecond,
+	}
+	out := &strings.Builder{}
+	li.print(out)
+	assert.Equal(t, "10:11:12.345 A     web. -> NOERROR, cluster in 2ms, client 127.0.0.1:54321 curl[1234], "+
+		"not routed to the cluster, lookups web.default. web.other.\n", out.String())
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+	"k8s.io/client-go/kubernetes"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+)
+
+type doctorCheck struct {
+	Name    string `json:"name" yaml:"name"`
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message" yaml:"message"`
+	Hint    string `json:"hint,omitempty" yaml:"hint,omitempty"`
+}
+
+type doctorReport struct {
+	Checks   []*doctorCheck `json:"checks" yaml:"checks"`
+	Failed   int            `json:"failed" yaml:"failed"`
+	Warnings int            `json:"warnings" yaml:"warnings"`
+}
+
+func (r *doctorReport) add(dc *rpc.DiagnosticCheck) {
+	switch dc.Status {
+	case rpc.DiagnosticCheck_FAILED:
+		r.Failed++
+	case rpc.DiagnosticCheck_WARNING:
+		r.Warnings++
+	}
+	r.Checks = append(r.Checks, &doctorCheck{
+		Name:    dc.Name,
+		Status:  strings.ToLower(dc.Status.String()),
+		Message: dc.Message,
+		Hint:    dc.Hint,
+	})
+}
+
+func (r *doctorReport) print(out io.Writer) {
+	w := 0
+	for _, c := range r.Checks {
+		if l := len(c.Name); l > w {
+			w = l
+		}
+	}
+	icons := map[string]string{"ok": good, "warning": "⚠️ ", "failed": bad, "skipped": "➖"}
+	for _, c := range r.Checks {
+		fmt.Fprintf(out, "%s %-*s : %s\n", icons[c.Status], w, c.Name, c.Message)
+		if c.Hint != "" {
+			fmt.Fprintf(out, "   %-*s   %s\n", w, "", c.Hint)
+		}
+	}
+	switch {
+	case r.Failed > 0:
+		fmt.Fprintf(out, "\n%d checks failed and %d produced warnings\n", r.Failed, r.Warnings)
+	case r.Warnings > 0:
+		fmt.Fprintf(out, "\nNo checks failed, but %d produced warnings\n", r.Warnings)
+	default:
+		fmt.Fprintln(out, "\nAll checks passed")
+	}
+}
+
+func doctor() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Args:  cobra.NoArgs,
+		Short: "Diagnose the connection to the cluster",
+		Long: `Diagnose the connection to the cluster.
+
+Each hop between this workstation and the cluster is checked: the kubeconfig and the credentials, the
+port-forward to the traffic-manager and its version, the virtual network interface and its routes, the
+DNS configuration, a DNS lookup, connections to a service IP and a pod IP, and the routes to the
+traffic-agents. A hint is printed for each check that fails. Use --output json to get a JSON report.
+
+A connection is established unless one already exists.`,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: runDoctor,
+	}
+}
+
+func runDoctor(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	if daemon.GetRequest(ctx) == nil {
+		ctx = daemon.WithDefaultRequest(ctx, cmd)
+		cmd.SetContext(ctx)
+	}
+
+	report := &doctorReport{}
+	report.add(checkKubeconfig(ctx, daemon.GetRequest(ctx)))
+	if report.Failed == 0 {
+		if err := connect.InitCommand(cmd); err != nil {
+			report.add(&rpc.DiagnosticCheck{
+				Name:    "connect",
+				Status:  rpc.DiagnosticCheck_FAILED,
+				Message: err.Error(),
+				Hint: "Verify that the traffic-manager is installed using \"telepresence helm install\", and check the " +
+					"connector.log and daemon.log files. \"telepresence gather-logs\" collects them.",
+			})
+		} else {
+			ctx = cmd.Context()
+			d, err := daemon.GetUserClient(ctx).Diagnose(ctx, &empty.Empty{})
+			if err != nil {
+				return err
+			}
+			for _, dc := range d.Checks {
+				report.add(dc)
+			}
+		}
+	}
+	if output.WantsFormatted(cmd) {
+		output.Object(ctx, report, false)
+	} else {
+		report.print(cmd.OutOrStdout())
+	}
+	return nil
+}
+
+// checkKubeconfig checks that the kubeconfig can be loaded, and that its credentials are accepted by the API server.
+func checkKubeconfig(ctx context.Context, rq *daemon.Request) *rpc.DiagnosticCheck {
+	dc := &rpc.DiagnosticCheck{Name: "kubeconfig"}
+	kc, err := client.NewKubeconfig(ctx, rq.KubeFlags, rq.ManagerNamespace)
+	if err != nil {
+		dc.Status = rpc.DiagnosticCheck_FAILED
+		dc.Message = err.Error()
+		dc.Hint = "Verify the KUBECONFIG environment variable and the current context using \"kubectl config get-contexts\"."
+		return dc
+	}
+	cs, err := kubernetes.NewForConfig(kc.RestConfig)
+	if err == nil {
+		var sv fmt.Stringer
+		if sv, err = cs.Discovery().ServerVersion(); err == nil {
+			dc.Message = fmt.Sprintf("context %q, server %s, Kubernetes %s", kc.Context, kc.Server, sv)
+			return dc
+		}
+	}
+	dc.Status = rpc.DiagnosticCheck_FAILED
+	dc.Message = fmt.Sprintf("context %q, server %s: %v", kc.Context, kc.Server, err)
+	dc.Hint = "The API server is unreachable or the credentials were rejected. Verify that \"kubectl get namespaces\" " +
+		"works, and log in again if the credentials have expired."
+	return dc
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+)
+
+func TestDoctorReport(t *testing.T) {
+	r := &doctorReport{}
+	r.add(&rpc.DiagnosticCheck{Name: "kubeconfig", Message: "context \"kind\""})
+	r.add(&rpc.DiagnosticCheck{Name: "version", Status: rpc.DiagnosticCheck_WARNING, Message: "mismatch", Hint: "upgrade"})
+	r.add(&rpc.DiagnosticCheck{Name: "dns-lookup", Status: rpc.DiagnosticCheck_FAILED, Message: "timeout", Hint: "check dns"})
+	assert.Equal(t, 1, r.Failed)
+	assert.Equal(t, 1, r.Warnings)
+	assert.Equal(t, "warning", r.Checks[1].Status)
+
+	out := &strings.Builder{}
+	r.print(out)
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, good+" kubeconfig : context \"kind\"", lines[0])
+	assert.Equal(t, bad+" dns-lookup : timeout", lines[3])
+	assert.Equal(t, "                check dns", lines[4])
+	assert.Contains(t, out.String(), "1 checks failed and 1 produced warnings")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	daemonClient "github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+)
+
+func expose() *cobra.Command {
+	var name, port string
+	cmd := &cobra.Command{
+		Use:   "expose --name <service name> --port <[local port:]service port>",
+		Args:  cobra.NoArgs,
+		Short: "Make a local port reachable from the cluster as a Service",
+		Long: `Make a local port reachable from the cluster as a Service.
+
+The traffic-manager creates a Service with the given name in its own namespace. Connections to that
+Service are accepted by the traffic-manager and extended to the local port on this workstation, without
+intercepting any workload. The Service is removed using "telepresence unexpose <service name>", or when
+the session ends.`,
+		Example: `  telepresence expose --name newsvc --port 8080`,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			localPort, svcPort, err := parseForwardPorts(port)
+			if err != nil {
+				return err
+			}
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			ei, err := daemonClient.GetUserClient(ctx).Expose(ctx, &manager.ExposeRequest{
+				Name:      name,
+				Port:      svcPort,
+				LocalPort: localPort,
+			})
+			if err != nil {
+				return err
+			}
+			if output.WantsFormatted(cmd) {
+				output.Object(ctx, ei, false)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Local port %d exposed as service %s.%s:%d\n", ei.LocalPort, ei.Name, ei.Namespace, ei.Port)
+			}
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&name, "name", "", "the name of the service")
+	flags.StringVar(&port, "port", "", "the local port, optionally followed by a colon and the port of the service")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("port")
+	return cmd
+}
+
+func unexpose() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unexpose <service name>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Remove a Service created using expose",
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			_, err := daemonClient.GetUserClient(ctx).Unexpose(ctx, &manager.UnexposeRequest{Name: args[0]})
+			return err
+		},
+	}
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/connect"
+	daemonClient "github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/ioutil"
+)
+
+func forward() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "forward",
+		Short: "Forward local ports to hosts in the cluster",
+	}
+	cmd.AddCommand(forwardAdd(), forwardRemove(), forwardList())
+	return cmd
+}
+
+// forwardInfo is the output representation of a connector.Forward.
+type forwardInfo struct {
+	Name         string `json:"name" yaml:"name"`
+	Host         string `json:"host" yaml:"host"`
+	RemotePort   int32  `json:"remote_port" yaml:"remote_port"`
+	LocalAddress string `json:"local_address" yaml:"local_address"`
+	LocalPort    int32  `json:"local_port" yaml:"local_port"`
+	Error        string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func newForwardInfo(f *connector.Forward) *forwardInfo {
+	return &forwardInfo{
+		Name:         f.Name,
+		Host:         f.Host,
+		RemotePort:   f.RemotePort,
+		LocalAddress: f.LocalAddress,
+		LocalPort:    f.LocalPort,
+		Error:        f.Error,
+	}
+}
+
+// String returns a one line description of the forward, e.g. "127.0.0.1:15432 -> postgres.db:5432".
+func (fi *forwardInfo) String() string {
+	s := net.JoinHostPort(fi.LocalAddress, strconv.Itoa(int(fi.LocalPort))) + " -> " +
+		net.JoinHostPort(fi.Host, strconv.Itoa(int(fi.RemotePort)))
+	if fi.Error != "" {
+		s += " (error: " + fi.Error + ")"
+	}
+	return s
+}
+
+// parseForwardTarget parses a target on the form "[svc/]<name>[.<namespace>]" into a host name.
+func parseForwardTarget(target string) (string, error) {
+	if kind, name, ok := strings.Cut(target, "/"); ok {
+		switch strings.ToLower(kind) {
+		case "svc", "service", "services":
+			target = name
+		default:
+			return "", errcat.User.Newf("invalid target %q: unsupported kind %q, only services can be forwarded to", target, kind)
+		}
+	}
+	if target == "" {
+		return "", errcat.User.New("the target must have a name")
+	}
+	return target, nil
+}
+
+// parseForwardPorts parses ports on the form "[<local port>:]<remote port>". The local port is zero when omitted.
+func parseForwardPorts(ports string) (local, remote int32, err error) {
+	parsePort := func(s string) (int32, error) {
+		p, err := strconv.ParseUint(s, 10, 16)
+		if err != nil || p == 0 {
+			return 0, errcat.User.Newf("invalid port %q in %q", s, ports)
+		}
+		return int32(p), nil
+	}
+	if ls, rs, ok := strings.Cut(ports, ":"); ok {
+		if local, err = parsePort(ls); err != nil {
+			return 0, 0, err
+		}
+		remote, err = parsePort(rs)
+	} else {
+		remote, err = parsePort(ports)
+	}
+	return local, remote, err
+}
+
+func forwardAdd() *cobra.Command {
+	var address string
+	cmd := &cobra.Command{
+		Use:   "add <name> <target> <[local port:]remote port>",
+		Args:  cobra.ExactArgs(3),
+		Short: "Add a named forward from a local port to a port of a service in the cluster",
+		Long: `Add a named forward from a local port to a port of a service in the cluster.
+
+The target is a service, optionally qualified with its namespace, e.g. "svc/postgres.db". Connections
+are dialed through the traffic-manager, so the forward works without a virtual network interface. The
+forward is remembered and restored every time a connection to the same context is made, until it is
+removed.`,
+		Example: `  telepresence forward add pg svc/postgres.db 15432:5432`,
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			host, err := parseForwardTarget(args[1])
+			if err != nil {
+				return err
+			}
+			localPort, remotePort, err := parseForwardPorts(args[2])
+			if err != nil {
+				return err
+			}
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			f, err := daemonClient.GetUserClient(ctx).AddForward(ctx, &connector.Forward{
+				Name:         args[0],
+				Host:         host,
+				RemotePort:   remotePort,
+				LocalPort:    localPort,
+				LocalAddress: address,
+			})
+			if err != nil {
+				return err
+			}
+			fi := newForwardInfo(f)
+			if output.WantsFormatted(cmd) {
+				output.Object(ctx, fi, false)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Forward %s added: %s\n", fi.Name, fi)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&address, "address", "127.0.0.1", "the local address to listen on")
+	return cmd
+}
+
+func forwardRemove() *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "remove [<name> | --all]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Remove a forward, or all forwards",
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return errcat.User.New("either a forward name or --all must be given")
+			}
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			rr := &connector.RemoveForwardRequest{All: all}
+			if !all {
+				rr.Name = args[0]
+			}
+			ctx := cmd.Context()
+			_, err := daemonClient.GetUserClient(ctx).RemoveForward(ctx, rr)
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "remove all forwards")
+	return cmd
+}
+
+func forwardList() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Short: "List the forwards",
+		Annotations: map[string]string{
+			ann.Session: ann.Required,
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := connect.InitCommand(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			fs, err := daemonClient.GetUserClient(ctx).GetForwards(ctx, &empty.Empty{})
+			if err != nil {
+				return err
+			}
+			fis := make([]*forwardInfo, len(fs.Forwards))
+			for i, f := range fs.Forwards {
+				fis[i] = newForwardInfo(f)
+			}
+			if output.WantsFormatted(cmd) {
+				output.Object(ctx, fis, false)
+				return nil
+			}
+			if len(fis) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No forwards")
+				return nil
+			}
+			kvf := ioutil.DefaultKeyValueFormatter()
+			for _, fi := range fis {
+				kvf.Add(fi.Name, fi.String())
+			}
+			kvf.Println(cmd.OutOrStdout())
+			return nil
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseForwardTarget(t *testing.T) {
+	for s, expected := range map[string]string{"svc/postgres.db": "postgres.db", "service/api": "api", "api.ns": "api.ns"} {
+		host, err := parseForwardTarget(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, host, s)
+	}
+	for _, s := range []string{"deploy/api", "svc/", ""} {
+		_, err := parseForwardTarget(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseForwardPorts(t *testing.T) {
+	local, remote, err := parseForwardPorts("15432:5432")
+	require.NoError(t, err)
+	assert.Equal(t, int32(15432), local)
+	assert.Equal(t, int32(5432), remote)
+
+	local, remote, err = parseForwardPorts("5432")
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), local)
+	assert.Equal(t, int32(5432), remote)
+
+	for _, s := range []string{"", "0", "65536", "a:5432", "15432:", "1:2:3"} {
+		_, _, err = parseForwardPorts(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -38,6 +38,8 @@ type rootDaemonStatus struct {
 	APIVersion           int32            `json:"api_version,omitempty" yaml:"api_version,omitempty"`
 	DNS                  *client.DNSSnake `json:"dns,omitempty" yaml:"dns,omitempty"`
 	*client.RoutingSnake `yaml:",inline"`
+	VirtualSubnet        *iputil.Subnet   `json:"virtual_subnet,omitempty" yaml:"virtual_subnet,omitempty"`
+	ChaosRules           []*chaosRuleInfo `json:"chaos_rules,omitempty" yaml:"chaos_rules,omitempty"`
 }
 
 type userDaemonStatus struct {
@@ -51,8 +53,11 @@ type userDaemonStatus struct {
 	Error             string                   `json:"error,omitempty" yaml:"error,omitempty"`
 	KubernetesServer  string                   `json:"kubernetes_server,omitempty" yaml:"kubernetes_server,omitempty"`
 	KubernetesContext string                   `json:"kubernetes_context,omitempty" yaml:"kubernetes_context,omitempty"`
+	ConnectedContexts []string                 `json:"connected_contexts,omitempty" yaml:"connected_contexts,omitempty"`
 	ManagerNamespace  string                   `json:"manager_namespace,omitempty" yaml:"manager_namespace,omitempty"`
 	MappedNamespaces  []string                 `json:"mapped_namespaces,omitempty" yaml:"mapped_namespaces,omitempty"`
+	ProxyMode         string                   `json:"proxy_mode,omitempty" yaml:"proxy_mode,omitempty"`
+	ProxyAddress      string                   `json:"proxy_address,omitempty" yaml:"proxy_address,omitempty"`
 	Intercepts        []connectStatusIntercept `json:"intercepts,omitempty" yaml:"intercepts,omitempty"`
 }
 
@@ -170,6 +175,7 @@ func BasicGetStatusInfo(ctx context.Context) (ioutil.WriterTos, error) {
 		us.Status = "Connected"
 		us.KubernetesServer = status.ClusterServer
 		us.KubernetesContext = status.ClusterContext
+		us.ConnectedContexts = status.ConnectedContexts
 		for _, icept := range status.GetIntercepts().GetIntercepts() {
 			us.Intercepts = append(us.Intercepts, connectStatusIntercept{
 				Name:   icept.Spec.Name,
@@ -178,6 +184,8 @@ func BasicGetStatusInfo(ctx context.Context) (ioutil.WriterTos, error) {
 		}
 		us.ManagerNamespace = status.ManagerNamespace
 		us.MappedNamespaces = status.MappedNamespaces
+		us.ProxyMode = status.ProxyMode
+		us.ProxyAddress = status.ProxyAddress
 	case connector.ConnectInfo_MUST_RESTART:
 		us.Status = "Connected, but must restart"
 	case connector.ConnectInfo_DISCONNECTED:
@@ -217,6 +225,14 @@ func BasicGetStatusInfo(ctx context.Context) (ioutil.WriterTos, error) {
 			for _, subnet := range obc.NeverProxySubnets {
 				rs.RoutingSnake.NeverProxy = append(rs.RoutingSnake.NeverProxy, (*iputil.Subnet)(iputil.IPNetFromRPC(subnet)))
 			}
+			rs.RoutingSnake.AlsoProxyHosts = obc.AlsoProxyHosts
+			rs.RoutingSnake.NeverProxyHosts = obc.NeverProxyHosts
+			if obc.VirtualSubnet != nil {
+				rs.VirtualSubnet = (*iputil.Subnet)(iputil.IPNetFromRPC(obc.VirtualSubnet))
+			}
+		}
+		for _, cr := range rStatus.ChaosRules {
+			rs.ChaosRules = append(rs.ChaosRules, newChaosRuleInfo(cr))
 		}
 	}
 	return wt, nil
@@ -273,6 +289,17 @@ func (ds *rootDaemonStatus) printNetwork(kvf *ioutil.KeyValueFormatter) {
 	if ds.RoutingSnake != nil {
 		printRouting(kvf, ds.RoutingSnake)
 	}
+	if ds.VirtualSubnet != nil {
+		kvf.Add("Virtual Subnet", ds.VirtualSubnet.String())
+	}
+	if len(ds.ChaosRules) > 0 {
+		out := &strings.Builder{}
+		fmt.Fprintf(out, "(%d rules)", len(ds.ChaosRules))
+		for _, ri := range ds.ChaosRules {
+			ioutil.Printf(out, "\n- %s: %s", ri.ID, ri)
+		}
+		kvf.Add("Chaos Rules", out.String())
+	}
 }
 
 func printDNS(kvf *ioutil.KeyValueFormatter, d *client.DNSSnake) {
@@ -301,6 +328,12 @@ func printRouting(kvf *ioutil.KeyValueFormatter, r *client.RoutingSnake) {
 	}
 	printSubnets("Also Proxy", r.AlsoProxy)
 	printSubnets("Never Proxy", r.NeverProxy)
+	if len(r.AlsoProxyHosts) > 0 {
+		kvf.Add("Also Proxy Hosts", fmt.Sprintf("%v", r.AlsoProxyHosts))
+	}
+	if len(r.NeverProxyHosts) > 0 {
+		kvf.Add("Never Proxy Hosts", fmt.Sprintf("%v", r.NeverProxyHosts))
+	}
 }
 
 func (cs *userDaemonStatus) WriteTo(out io.Writer) (int64, error) {
@@ -328,10 +361,16 @@ func (cs *userDaemonStatus) print(kvf *ioutil.KeyValueFormatter) {
 	}
 	kvf.Add("Kubernetes server", cs.KubernetesServer)
 	kvf.Add("Kubernetes context", cs.KubernetesContext)
+	if len(cs.ConnectedContexts) > 0 {
+		kvf.Add("Connected contexts", strings.Join(cs.ConnectedContexts, ", "))
+	}
 	kvf.Add("Manager namespace", cs.ManagerNamespace)
 	if len(cs.MappedNamespaces) > 0 {
 		kvf.Add("Mapped namespaces", fmt.Sprintf("%v", cs.MappedNamespaces))
 	}
+	if cs.ProxyMode != "" {
+		kvf.Add("Proxy", fmt.Sprintf("%s at %s", cs.ProxyMode, cs.ProxyAddress))
+	}
 	out := &strings.Builder{}
 	fmt.Fprintf(out, "%d total\n", len(cs.Intercepts))
 	if len(cs.Intercepts) > 0 {
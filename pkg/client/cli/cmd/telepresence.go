@@ -133,8 +133,9 @@ func OnlySubcommands(cmd *cobra.Command, args []string) error {
 
 func WithSubCommands(ctx context.Context) context.Context {
 	return MergeSubCommands(ctx,
-		config(), connectCmd(), currentClusterId(), gatherLogs(), gatherTraces(), genYAML(), helm(), interceptCmd(), leave(),
-		list(), loglevel(), quit(), statusCmd(), testVPN(), uninstall(), uploadTraces(), version(),
+		auditCmd(), capture(), chaos(), config(), connectCmd(), connections(), currentClusterId(), dnsCmd(), doctor(), expose(), forward(), gatherLogs(),
+		gatherTraces(), genYAML(), helm(), interceptCmd(), leave(), list(), loglevel(), quit(), statusCmd(), testVPN(),
+		unexpose(), uninstall(), uploadTraces(), version(),
 	)
 }
 
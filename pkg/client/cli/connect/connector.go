@@ -258,6 +258,9 @@ func connectSession(ctx context.Context, userD *daemon.UserClient, request *daem
 	switch ci.Error {
 	case connector.ConnectInfo_UNSPECIFIED:
 		fmt.Fprintf(output.Info(ctx), "Connected to context %s (%s)\n", ci.ClusterContext, ci.ClusterServer)
+		if ci.ProxyMode != "" {
+			fmt.Fprintf(output.Info(ctx), "Using %s proxy at %s\n", ci.ProxyMode, ci.ProxyAddress)
+		}
 		return &daemon.Session{
 			UserClient: *userD,
 			Info:       ci,
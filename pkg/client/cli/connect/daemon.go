@@ -12,6 +12,7 @@ import (
 	"google.golang.org/grpc"
 	empty "google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
 	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
 	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
@@ -69,6 +70,10 @@ func ensureRootDaemonRunning(ctx context.Context) error {
 		// Never start root daemon when connecting using a docker container.
 		return nil
 	}
+	if usesProxyMode(ctx, cr) {
+		// The user daemon provides a local proxy and the root daemon isn't needed.
+		return nil
+	}
 	if addr := client.GetEnv(ctx).UserDaemonAddress; addr != "" {
 		// Always assume that root daemon is running when a user daemon address is provided
 		return nil
@@ -86,6 +91,26 @@ func ensureRootDaemonRunning(ctx context.Context) error {
 	return nil
 }
 
+// usesProxyMode returns true if the given request asks for proxy mode, or if the request is implicit and
+// the user daemon already has a session that uses proxy mode.
+func usesProxyMode(ctx context.Context, cr *daemon.Request) bool {
+	if cr == nil {
+		return false
+	}
+	if cr.ProxyMode != "" {
+		return true
+	}
+	if !cr.Implicit {
+		return false
+	}
+	ud := daemon.GetUserClient(ctx)
+	if ud == nil {
+		return false
+	}
+	ci, err := ud.Status(ctx, &empty.Empty{})
+	return err == nil && ci.Error == connector.ConnectInfo_ALREADY_CONNECTED && ci.ProxyMode != ""
+}
+
 // Disconnect shuts down a session in the root daemon. When it shuts down, it will tell the connector to shut down.
 func Disconnect(ctx context.Context, quitDaemons bool) error {
 	err := UserDaemonDisconnect(ctx, quitDaemons)
@@ -5,8 +5,10 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/ann"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/global"
 	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 )
 
@@ -28,6 +30,12 @@ func CommandInitializer(cmd *cobra.Command) (err error) {
 	ctx := cmd.Context()
 	as := cmd.Annotations
 
+	if cf := cmd.Flag(global.FlagContext); cf != nil && cf.Changed {
+		// When the daemons are connected to several clusters, the calls made by this command
+		// apply to the session of the given context.
+		ctx = client.WithKubeContext(ctx, cf.Value.String())
+	}
+
 	if v, ok := as[ann.Session]; ok {
 		as[ann.UserDaemon] = v
 		as[ann.RootDaemon] = v
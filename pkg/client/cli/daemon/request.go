@@ -45,8 +45,19 @@ func InitRequest(cmd *cobra.Command) *Request {
 	nwFlags.StringSliceVar(&cr.NeverProxy,
 		"never-proxy", nil, ``+
 			`Comma separated list of CIDR to never proxy`)
+	nwFlags.StringVar(&cr.VirtualSubnet,
+		"virtual-subnet", "", ``+
+			`CIDR onto which cluster subnets that conflict with the local network are mapped`)
+	nwFlags.StringArrayVar(&cr.EgressVia,
+		"egress-via", nil, ``+
+			`Make the traffic-agent of a workload dial the connections to the given destinations, or to all destinations. `+
+			`Format is "[<kind>/]<name>[.<namespace>][=<CIDR or host>,...]". Can be repeated`)
 	nwFlags.StringVar(&cr.ManagerNamespace, "manager-namespace", "", `The namespace where the traffic manager is to be found. `+
 		`Overrides any other manager namespace set in config`)
+	nwFlags.StringVar(&cr.ProxyMode, "proxy-mode", "", ``+
+		`Use a local "socks5" or "http" proxy instead of the root daemon and its virtual network interface`)
+	nwFlags.StringVar(&cr.ProxyAddress, "proxy-address", "", ``+
+		`The address that the local proxy listens to when --proxy-mode is used. Defaults to 127.0.0.1:1080`)
 	flags.AddFlagSet(nwFlags)
 
 	dbgFlags := pflag.NewFlagSet("Debug and Profiling flags", 0)
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -9,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 	"unsafe"
@@ -23,6 +25,7 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
 	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
 )
 
 const ConfigFile = "config.yml"
@@ -37,6 +40,9 @@ type Config struct {
 	TelepresenceAPI TelepresenceAPI `json:"telepresenceAPI,omitempty" yaml:"telepresenceAPI,omitempty"`
 	Intercept       Intercept       `json:"intercept,omitempty" yaml:"intercept,omitempty"`
 	Cluster         Cluster         `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	Tunnel          Tunnel          `json:"tunnel,omitempty" yaml:"tunnel,omitempty"`
+	Routing         Routing         `json:"routing,omitempty" yaml:"routing,omitempty"`
+	DNS             DNS             `json:"dns,omitempty" yaml:"dns,omitempty"`
 }
 
 func ParseConfigYAML(data []byte) (*Config, error) {
@@ -57,6 +63,9 @@ func (c *Config) Merge(o *Config) {
 	c.TelepresenceAPI.merge(&o.TelepresenceAPI)
 	c.Intercept.merge(&o.Intercept)
 	c.Cluster.merge(&o.Cluster)
+	c.Tunnel.merge(&o.Tunnel)
+	c.Routing.merge(&o.Routing)
+	c.DNS.merge(&o.DNS)
 }
 
 func (c *Config) String() string {
@@ -831,6 +840,55 @@ func (cc Cluster) MarshalYAML() (any, error) {
 	return cm, nil
 }
 
+type Tunnel struct {
+	// Compression is the compression that the client requests for the payload of its tunnel streams.
+	Compression tunnel.Compression `json:"compression,omitempty" yaml:"compression,omitempty"`
+
+	// compressionSet is true when the compression was given explicitly, so that a "none" can override
+	// a compression that was set in a configuration with lower priority.
+	compressionSet bool
+}
+
+func (tc *Tunnel) merge(o *Tunnel) {
+	if o.compressionSet || o.Compression != tunnel.CompressionNone {
+		tc.Compression = o.Compression
+	}
+}
+
+// IsZero controls whether this element will be included in marshalled output.
+func (tc Tunnel) IsZero() bool {
+	return tc.Compression == tunnel.CompressionNone
+}
+
+// UnmarshalYAML parses the tunnel YAML.
+func (tc *Tunnel) UnmarshalYAML(node *yaml.Node) (err error) {
+	if node.Kind != yaml.MappingNode {
+		return errors.New(withLoc("tunnel must be an object", node))
+	}
+
+	ms := node.Content
+	top := len(ms)
+	for i := 0; i < top; i += 2 {
+		kv, err := stringKey(ms[i])
+		if err != nil {
+			return err
+		}
+		v := ms[i+1]
+		switch kv {
+		case "compression":
+			if tc.Compression, err = tunnel.ParseCompression(v.Value); err != nil {
+				return errors.New(withLoc(err.Error(), v))
+			}
+			tc.compressionSet = true
+		default:
+			if parseContext != nil {
+				dlog.Warn(parseContext, withLoc(fmt.Sprintf("unknown key %q", kv), ms[i]))
+			}
+		}
+	}
+	return nil
+}
+
 var parseContext context.Context //nolint:gochecknoglobals // cannot be propagated in any other way
 
 type parsedFile struct{}
@@ -905,6 +963,9 @@ func GetDefaultConfig() Config {
 		Cluster: Cluster{
 			DefaultManagerNamespace: defaultDefaultManagerNamespace,
 		},
+		DNS: DNS{
+			Cache: defaultDNSCache,
+		},
 	}
 }
 
@@ -977,13 +1038,209 @@ type Routing struct {
 	Subnets    []*iputil.Subnet `json:"subnets,omitempty" yaml:"subnets,omitempty"`
 	AlsoProxy  []*iputil.Subnet `json:"alsoProxy,omitempty" yaml:"alsoProxy,omitempty"`
 	NeverProxy []*iputil.Subnet `json:"neverProxy,omitempty" yaml:"neverProxy,omitempty"`
+
+	// AlsoProxyHosts are host names that are resolved using the cluster's DNS, and whose addresses are routed
+	// to the cluster. A name that starts with "*." matches all subdomains of the name that follows.
+	AlsoProxyHosts []string `json:"alsoProxyHosts,omitempty" yaml:"alsoProxyHosts,omitempty"`
+
+	// NeverProxyHosts are host names, using the same syntax as AlsoProxyHosts, that are excluded from them.
+	NeverProxyHosts []string `json:"neverProxyHosts,omitempty" yaml:"neverProxyHosts,omitempty"`
+
+	// Stack contains tuning options for the network stack of the virtual network interface.
+	Stack Stack `json:"stack,omitempty" yaml:"stack,omitempty"`
+}
+
+// sessionRoutingKeys are keys in the routing section that are provided by the kubeconfig extension or by the
+// traffic-manager. They are ignored when found in the client configuration.
+var sessionRoutingKeys = map[string]struct{}{ //nolint:gochecknoglobals // constant
+	"subnets":           {},
+	"alsoProxy":         {},
+	"neverProxy":        {},
+	"alsoProxySubnets":  {},
+	"neverProxySubnets": {},
+	"alsoProxyHosts":    {},
+	"neverProxyHosts":   {},
+}
+
+// merge merges the settings of the client configuration. All other routing settings belong to the session.
+func (r *Routing) merge(o *Routing) {
+	r.Stack.merge(&o.Stack)
+}
+
+// IsZero controls whether this element will be included in marshalled output.
+func (r Routing) IsZero() bool {
+	return len(r.Subnets) == 0 && len(r.AlsoProxy) == 0 && len(r.NeverProxy) == 0 &&
+		len(r.AlsoProxyHosts) == 0 && len(r.NeverProxyHosts) == 0 && r.Stack.IsZero()
+}
+
+// UnmarshalYAML parses the routing YAML.
+func (r *Routing) UnmarshalYAML(node *yaml.Node) (err error) {
+	if node.Kind != yaml.MappingNode {
+		return errors.New(withLoc("routing must be an object", node))
+	}
+
+	ms := node.Content
+	top := len(ms)
+	for i := 0; i < top; i += 2 {
+		kv, err := stringKey(ms[i])
+		if err != nil {
+			return err
+		}
+		if kv == "stack" {
+			if err = ms[i+1].Decode(&r.Stack); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, ok := sessionRoutingKeys[kv]; !ok && parseContext != nil {
+			dlog.Warn(parseContext, withLoc(fmt.Sprintf("unknown key %q", kv), ms[i]))
+		}
+	}
+	return nil
+}
+
+// Stack contains tuning options for the gVisor network stack that handles the traffic of the virtual network
+// interface. Zero values mean that the stack's defaults are used.
+type Stack struct {
+	// MTU is the MTU of the virtual network interface.
+	MTU int `json:"mtu,omitempty" yaml:"mtu,omitempty"`
+
+	// MaxInFlight is the max number of TCP connection attempts that can be in-flight at the same time.
+	MaxInFlight int `json:"maxInFlight,omitempty" yaml:"maxInFlight,omitempty"`
+
+	// WindowScale is the TCP window scale. The max TCP receive window is 16KiB shifted left by this value. It's a
+	// pointer so that an explicit zero can be told apart from an unset value.
+	WindowScale *int `json:"windowScale,omitempty" yaml:"windowScale,omitempty"`
+
+	// ReceiveBufferSize is the initial size of the TCP receive buffers.
+	ReceiveBufferSize resource.Quantity `json:"receiveBufferSize,omitempty" yaml:"receiveBufferSize,omitempty"`
+
+	// SendBufferSize is the initial size of the TCP send buffers.
+	SendBufferSize resource.Quantity `json:"sendBufferSize,omitempty" yaml:"sendBufferSize,omitempty"`
+
+	// CongestionControl is the TCP congestion control algorithm, "reno" or "cubic".
+	CongestionControl string `json:"congestionControl,omitempty" yaml:"congestionControl,omitempty"`
+
+	// SACK enables or disables TCP selective acknowledgements. They are enabled by default.
+	SACK *bool `json:"sack,omitempty" yaml:"sack,omitempty"`
+}
+
+func (s *Stack) merge(o *Stack) {
+	if o.MTU != 0 {
+		s.MTU = o.MTU
+	}
+	if o.MaxInFlight != 0 {
+		s.MaxInFlight = o.MaxInFlight
+	}
+	if o.WindowScale != nil {
+		s.WindowScale = o.WindowScale
+	}
+	if !o.ReceiveBufferSize.IsZero() {
+		s.ReceiveBufferSize = o.ReceiveBufferSize
+	}
+	if !o.SendBufferSize.IsZero() {
+		s.SendBufferSize = o.SendBufferSize
+	}
+	if o.CongestionControl != "" {
+		s.CongestionControl = o.CongestionControl
+	}
+	if o.SACK != nil {
+		s.SACK = o.SACK
+	}
+}
+
+// IsZero controls whether this element will be included in marshalled output.
+func (s Stack) IsZero() bool {
+	return s.MTU == 0 && s.MaxInFlight == 0 && s.WindowScale == nil && s.ReceiveBufferSize.IsZero() &&
+		s.SendBufferSize.IsZero() && s.CongestionControl == "" && s.SACK == nil
+}
+
+// UnmarshalYAML parses the stack YAML.
+func (s *Stack) UnmarshalYAML(node *yaml.Node) (err error) {
+	if node.Kind != yaml.MappingNode {
+		return errors.New(withLoc("stack must be an object", node))
+	}
+
+	ms := node.Content
+	top := len(ms)
+	for i := 0; i < top; i += 2 {
+		kv, err := stringKey(ms[i])
+		if err != nil {
+			return err
+		}
+		v := ms[i+1]
+		switch kv {
+		case "mtu":
+			err = v.Decode(&s.MTU)
+		case "maxInFlight":
+			err = v.Decode(&s.MaxInFlight)
+		case "windowScale":
+			var ws int
+			if err = v.Decode(&ws); err == nil {
+				s.WindowScale = &ws
+			}
+		case "receiveBufferSize":
+			s.ReceiveBufferSize, err = resource.ParseQuantity(v.Value)
+		case "sendBufferSize":
+			s.SendBufferSize, err = resource.ParseQuantity(v.Value)
+		case "congestionControl":
+			switch v.Value {
+			case "reno", "cubic":
+				s.CongestionControl = v.Value
+			default:
+				err = fmt.Errorf("invalid congestion control %q, must be \"reno\" or \"cubic\"", v.Value)
+			}
+		case "sack":
+			var b bool
+			if err = v.Decode(&b); err == nil {
+				s.SACK = &b
+			}
+		default:
+			if parseContext != nil {
+				dlog.Warn(parseContext, withLoc(fmt.Sprintf("unknown key %q", kv), ms[i]))
+			}
+		}
+		if err != nil {
+			return errors.New(withLoc(err.Error(), ms[i]))
+		}
+	}
+	return nil
+}
+
+// MarshalYAML is not using pointer receiver here, because Stack is not pointer in the Routing struct.
+func (s Stack) MarshalYAML() (any, error) {
+	sm := make(map[string]any)
+	if s.MTU != 0 {
+		sm["mtu"] = s.MTU
+	}
+	if s.MaxInFlight != 0 {
+		sm["maxInFlight"] = s.MaxInFlight
+	}
+	if s.WindowScale != nil {
+		sm["windowScale"] = *s.WindowScale
+	}
+	if !s.ReceiveBufferSize.IsZero() {
+		sm["receiveBufferSize"] = s.ReceiveBufferSize.String()
+	}
+	if !s.SendBufferSize.IsZero() {
+		sm["sendBufferSize"] = s.SendBufferSize.String()
+	}
+	if s.CongestionControl != "" {
+		sm["congestionControl"] = s.CongestionControl
+	}
+	if s.SACK != nil {
+		sm["sack"] = *s.SACK
+	}
+	return sm, nil
 }
 
 // RoutingSnake is the same as Routing but with snake_case json/yaml names.
 type RoutingSnake struct {
-	Subnets    []*iputil.Subnet `json:"subnets,omitempty" yaml:"subnets,omitempty"`
-	AlsoProxy  []*iputil.Subnet `json:"also_proxy_subnets,omitempty" yaml:"also_proxy_subnets,omitempty"`
-	NeverProxy []*iputil.Subnet `json:"never_proxy_subnets,omitempty" yaml:"never_proxy_subnets,omitempty"`
+	Subnets         []*iputil.Subnet `json:"subnets,omitempty" yaml:"subnets,omitempty"`
+	AlsoProxy       []*iputil.Subnet `json:"also_proxy_subnets,omitempty" yaml:"also_proxy_subnets,omitempty"`
+	NeverProxy      []*iputil.Subnet `json:"never_proxy_subnets,omitempty" yaml:"never_proxy_subnets,omitempty"`
+	AlsoProxyHosts  []string         `json:"also_proxy_hosts,omitempty" yaml:"also_proxy_hosts,omitempty"`
+	NeverProxyHosts []string         `json:"never_proxy_hosts,omitempty" yaml:"never_proxy_hosts,omitempty"`
 }
 
 type DNS struct {
@@ -992,6 +1249,254 @@ type DNS struct {
 	IncludeSuffixes []string      `json:"includeSuffixes,omitempty" yaml:"includeSuffixes,omitempty"`
 	ExcludeSuffixes []string      `json:"excludeSuffixes,omitempty" yaml:"excludeSuffixes,omitempty"`
 	LookupTimeout   time.Duration `json:"lookupTimeout,omitempty" yaml:"lookupTimeout,omitempty"`
+
+	// Mappings are names that the DNS server resolves locally, either to an IP or as an alias for another name.
+	Mappings []*DNSMapping `json:"mappings,omitempty" yaml:"mappings,omitempty"`
+
+	// Cache controls how long the DNS server caches the answers from the cluster, and how many it caches.
+	Cache DNSCache `json:"cache,omitempty" yaml:"cache,omitempty"`
+
+	// QueryLog enables the log of the queries that the DNS server receives, which is written to the
+	// dns-queries.log file in the log directory.
+	QueryLog bool `json:"queryLog,omitempty" yaml:"queryLog,omitempty"`
+}
+
+// DNSMapping maps a name to an IP, or makes it an alias for another name. An alias is resolved by the DNS server
+// in the same way as any other name, so a name like "api.default" will resolve to the IP of that service.
+type DNSMapping struct {
+	Name     string `json:"name" yaml:"name"`
+	AliasFor string `json:"aliasFor,omitempty" yaml:"aliasFor,omitempty"`
+	IP       net.IP `json:"ip,omitempty" yaml:"ip,omitempty"`
+}
+
+// Validate checks that the mapping has a name and exactly one of AliasFor and IP.
+func (m *DNSMapping) Validate() error {
+	switch {
+	case m.Name == "":
+		return errors.New("dns mapping must have a name")
+	case m.AliasFor == "" && m.IP == nil:
+		return fmt.Errorf("dns mapping for %q must have an aliasFor or an ip", m.Name)
+	case m.AliasFor != "" && m.IP != nil:
+		return fmt.Errorf("dns mapping for %q cannot have both an aliasFor and an ip", m.Name)
+	}
+	return nil
+}
+
+// sessionDNSKeys are keys in the dns section that are provided by the kubeconfig extension or by the
+// traffic-manager. They are ignored when found in the client configuration.
+var sessionDNSKeys = map[string]struct{}{ //nolint:gochecknoglobals // constant
+	"localIP":         {},
+	"remoteIP":        {},
+	"includeSuffixes": {},
+	"excludeSuffixes": {},
+	"lookupTimeout":   {},
+}
+
+// merge merges the settings of the client configuration. All other DNS settings belong to the session. A
+// mapping in the given DNS replaces the mapping with the same name.
+func (d *DNS) merge(o *DNS) {
+	d.Cache.merge(&o.Cache)
+	if o.QueryLog {
+		d.QueryLog = true
+	}
+	if len(o.Mappings) == 0 {
+		return
+	}
+	replaced := make(map[string]struct{}, len(o.Mappings))
+	for _, m := range o.Mappings {
+		replaced[strings.ToLower(m.Name)] = struct{}{}
+	}
+	ms := make([]*DNSMapping, 0, len(d.Mappings)+len(o.Mappings))
+	for _, m := range d.Mappings {
+		if _, ok := replaced[strings.ToLower(m.Name)]; !ok {
+			ms = append(ms, m)
+		}
+	}
+	d.Mappings = append(ms, o.Mappings...)
+}
+
+// IsZero controls whether this element will be included in marshalled output.
+func (d DNS) IsZero() bool {
+	return d.LocalIP == nil && d.RemoteIP == nil && len(d.IncludeSuffixes) == 0 && len(d.ExcludeSuffixes) == 0 &&
+		d.LookupTimeout == 0 && len(d.Mappings) == 0 && d.Cache.IsZero() && !d.QueryLog
+}
+
+// UnmarshalYAML parses the dns YAML.
+func (d *DNS) UnmarshalYAML(node *yaml.Node) (err error) {
+	if node.Kind != yaml.MappingNode {
+		return errors.New(withLoc("dns must be an object", node))
+	}
+
+	ms := node.Content
+	top := len(ms)
+	for i := 0; i < top; i += 2 {
+		kv, err := stringKey(ms[i])
+		if err != nil {
+			return err
+		}
+		if kv == "mappings" {
+			if err = ms[i+1].Decode(&d.Mappings); err != nil {
+				return err
+			}
+			for _, m := range d.Mappings {
+				if err = m.Validate(); err != nil {
+					return errors.New(withLoc(err.Error(), ms[i+1]))
+				}
+			}
+			continue
+		}
+		if kv == "cache" {
+			if err = ms[i+1].Decode(&d.Cache); err != nil {
+				return err
+			}
+			continue
+		}
+		if kv == "queryLog" {
+			if d.QueryLog, err = strconv.ParseBool(ms[i+1].Value); err != nil {
+				return errors.New(withLoc(fmt.Sprintf("bool expected for key %q", kv), ms[i+1]))
+			}
+			continue
+		}
+		if _, ok := sessionDNSKeys[kv]; !ok && parseContext != nil {
+			dlog.Warn(parseContext, withLoc(fmt.Sprintf("unknown key %q", kv), ms[i]))
+		}
+	}
+	return nil
+}
+
+// DNSCache controls the cache of the DNS server. The TTL of the records in an answer from the cluster decides
+// how long the answer is cached, within the bounds of MinTTL and MaxTTL.
+type DNSCache struct {
+	MinTTL time.Duration `json:"minTTL,omitempty" yaml:"minTTL,omitempty"`
+	MaxTTL time.Duration `json:"maxTTL,omitempty" yaml:"maxTTL,omitempty"`
+
+	// NegativeTTL is how long a NXDOMAIN answer is cached. Such answers aren't cached when it is zero.
+	NegativeTTL time.Duration `json:"negativeTTL,omitempty" yaml:"negativeTTL,omitempty"`
+
+	// MaxEntries is the max number of cached answers. The oldest answers are evicted when it is exceeded.
+	MaxEntries int `json:"maxEntries,omitempty" yaml:"maxEntries,omitempty"`
+
+	// The xxxSet fields are true when the corresponding value was given explicitly, so that a default value
+	// can override a value that was set in a configuration with lower priority.
+	minTTLSet      bool
+	maxTTLSet      bool
+	negativeTTLSet bool
+	maxEntriesSet  bool
+}
+
+const (
+	defaultDNSCacheMinTTL      = 60 * time.Second
+	defaultDNSCacheMaxTTL      = 10 * time.Minute
+	defaultDNSCacheNegativeTTL = 0
+	defaultDNSCacheMaxEntries  = 10000
+)
+
+var defaultDNSCache = DNSCache{ //nolint:gochecknoglobals // constant
+	MinTTL:      defaultDNSCacheMinTTL,
+	MaxTTL:      defaultDNSCacheMaxTTL,
+	NegativeTTL: defaultDNSCacheNegativeTTL,
+	MaxEntries:  defaultDNSCacheMaxEntries,
+}
+
+// merge merges the explicitly set or non-default values of the given DNSCache into this one.
+func (dc *DNSCache) merge(o *DNSCache) {
+	if o.minTTLSet || o.MinTTL != defaultDNSCacheMinTTL {
+		dc.MinTTL = o.MinTTL
+	}
+	if o.maxTTLSet || o.MaxTTL != defaultDNSCacheMaxTTL {
+		dc.MaxTTL = o.MaxTTL
+	}
+	if o.negativeTTLSet || o.NegativeTTL != defaultDNSCacheNegativeTTL {
+		dc.NegativeTTL = o.NegativeTTL
+	}
+	if o.maxEntriesSet || o.MaxEntries != defaultDNSCacheMaxEntries {
+		dc.MaxEntries = o.MaxEntries
+	}
+}
+
+// TTL returns the time that an answer, whose records have the given TTL, is cached. The MaxTTL wins when
+// merged configurations have a MinTTL that is greater than the MaxTTL.
+func (dc *DNSCache) TTL(ttl time.Duration) time.Duration {
+	if ttl < dc.MinTTL {
+		ttl = dc.MinTTL
+	}
+	if ttl > dc.MaxTTL {
+		ttl = dc.MaxTTL
+	}
+	return ttl
+}
+
+// IsZero controls whether this element will be included in marshalled output.
+func (dc DNSCache) IsZero() bool {
+	return dc.MinTTL == defaultDNSCacheMinTTL &&
+		dc.MaxTTL == defaultDNSCacheMaxTTL &&
+		dc.NegativeTTL == defaultDNSCacheNegativeTTL &&
+		dc.MaxEntries == defaultDNSCacheMaxEntries
+}
+
+// MarshalYAML is not using pointer receiver here, because DNSCache is not pointer in the DNS struct.
+func (dc DNSCache) MarshalYAML() (any, error) {
+	cm := make(map[string]any)
+	if dc.MinTTL != defaultDNSCacheMinTTL {
+		cm["minTTL"] = dc.MinTTL.String()
+	}
+	if dc.MaxTTL != defaultDNSCacheMaxTTL {
+		cm["maxTTL"] = dc.MaxTTL.String()
+	}
+	if dc.NegativeTTL != defaultDNSCacheNegativeTTL {
+		cm["negativeTTL"] = dc.NegativeTTL.String()
+	}
+	if dc.MaxEntries != defaultDNSCacheMaxEntries {
+		cm["maxEntries"] = dc.MaxEntries
+	}
+	return cm, nil
+}
+
+// UnmarshalYAML parses the dns cache YAML.
+func (dc *DNSCache) UnmarshalYAML(node *yaml.Node) (err error) {
+	if node.Kind != yaml.MappingNode {
+		return errors.New(withLoc("cache must be an object", node))
+	}
+
+	ms := node.Content
+	top := len(ms)
+	for i := 0; i < top; i += 2 {
+		kv, err := stringKey(ms[i])
+		if err != nil {
+			return err
+		}
+		v := ms[i+1]
+		var dp *time.Duration
+		switch kv {
+		case "minTTL":
+			dp = &dc.MinTTL
+			dc.minTTLSet = true
+		case "maxTTL":
+			dp = &dc.MaxTTL
+			dc.maxTTLSet = true
+		case "negativeTTL":
+			dp = &dc.NegativeTTL
+			dc.negativeTTLSet = true
+		case "maxEntries":
+			if err = v.Decode(&dc.MaxEntries); err != nil || dc.MaxEntries <= 0 {
+				return errors.New(withLoc("maxEntries must be a positive integer", v))
+			}
+			dc.maxEntriesSet = true
+			continue
+		default:
+			if parseContext != nil {
+				dlog.Warn(parseContext, withLoc(fmt.Sprintf("unknown key %q", kv), ms[i]))
+			}
+			continue
+		}
+		if *dp, err = time.ParseDuration(v.Value); err != nil || *dp < 0 {
+			return errors.New(withLoc(fmt.Sprintf("%q is not a valid duration", v.Value), v))
+		}
+	}
+	if dc.minTTLSet && dc.maxTTLSet && dc.MinTTL > dc.MaxTTL {
+		return errors.New(withLoc("minTTL cannot be greater than maxTTL", node))
+	}
+	return nil
 }
 
 // DNSSnake is the same as DNS but with snake_case json/yaml names.
@@ -1006,7 +1511,66 @@ type DNSSnake struct {
 type SessionConfig struct {
 	ClientFile       string `json:"clientFile,omitempty" yaml:"clientFile,omitempty"`
 	*Config          `json:"clientConfig" yaml:",inline"`
-	DNS              DNS     `json:"dns,omitempty" yaml:"dns,omitempty"`
-	Routing          Routing `json:"routing,omitempty" yaml:"routing,omitempty"`
-	ManagerNamespace string  `json:"managerNamespace,omitempty" yaml:"managerNamespace,omitempty"`
+	ManagerNamespace string `json:"managerNamespace,omitempty" yaml:"managerNamespace,omitempty"`
+}
+
+// sessionConfigJSON is the JSON layout of a SessionConfig. The DNS and routing settings are siblings of the
+// clientConfig rather than a part of it, which is the layout used before the client configuration got dns and
+// routing sections.
+type sessionConfigJSON struct {
+	ClientFile       string                     `json:"clientFile,omitempty"`
+	ClientConfig     map[string]json.RawMessage `json:"clientConfig"`
+	DNS              json.RawMessage            `json:"dns,omitempty"`
+	Routing          json.RawMessage            `json:"routing,omitempty"`
+	ManagerNamespace string                     `json:"managerNamespace,omitempty"`
+}
+
+// MarshalJSON is not using pointer receiver here, so that the layout is retained when a SessionConfig is
+// marshalled by value.
+func (sc SessionConfig) MarshalJSON() ([]byte, error) {
+	sj := sessionConfigJSON{ClientFile: sc.ClientFile, ManagerNamespace: sc.ManagerNamespace}
+	if sc.Config != nil {
+		data, err := json.Marshal(sc.Config)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, &sj.ClientConfig); err != nil {
+			return nil, err
+		}
+		sj.DNS = sj.ClientConfig["dns"]
+		sj.Routing = sj.ClientConfig["routing"]
+		delete(sj.ClientConfig, "dns")
+		delete(sj.ClientConfig, "routing")
+	}
+	return json.Marshal(&sj)
+}
+
+// UnmarshalJSON parses the JSON produced by MarshalJSON.
+func (sc *SessionConfig) UnmarshalJSON(data []byte) error {
+	var sj sessionConfigJSON
+	if err := json.Unmarshal(data, &sj); err != nil {
+		return err
+	}
+	sc.ClientFile = sj.ClientFile
+	sc.ManagerNamespace = sj.ManagerNamespace
+	if sj.ClientConfig == nil && sj.DNS == nil && sj.Routing == nil {
+		return nil
+	}
+	if sj.ClientConfig == nil {
+		sj.ClientConfig = make(map[string]json.RawMessage, 2)
+	}
+	if sj.DNS != nil {
+		sj.ClientConfig["dns"] = sj.DNS
+	}
+	if sj.Routing != nil {
+		sj.ClientConfig["routing"] = sj.Routing
+	}
+	cfgData, err := json.Marshal(sj.ClientConfig)
+	if err != nil {
+		return err
+	}
+	if sc.Config == nil {
+		sc.Config = &Config{}
+	}
+	return json.Unmarshal(cfgData, sc.Config)
 }
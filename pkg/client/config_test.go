@@ -1,6 +1,8 @@
 package client
 
 import (
+	"encoding/json"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
@@ -15,6 +17,8 @@ import (
 	"github.com/datawire/dlib/dlog"
 	"github.com/datawire/k8sapi/pkg/k8sapi"
 	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
 )
 
 func TestGetConfig(t *testing.T) {
@@ -27,6 +31,19 @@ logLevels:
   rootDaemon: debug
 cluster:
   defaultManagerNamespace: hello
+routing:
+  stack:
+    mtu: 1400
+    windowScale: 4
+dns:
+  mappings:
+  - name: db.example.com
+    ip: 192.168.1.4
+  - name: api.staging.example.com
+    aliasFor: api.default
+  cache:
+    minTTL: 10s
+    maxTTL: 2m
 `,
 		/* sys2 */ `
 timeouts:
@@ -50,6 +67,24 @@ intercept:
   appProtocolStrategy: portName
   defaultPort: 9080
   useFtp: true
+tunnel:
+  compression: zstd
+routing:
+  alsoProxySubnets:
+  - 10.1.0.0/16
+  stack:
+    windowScale: 0
+    congestionControl: cubic
+    sack: false
+dns:
+  excludeSuffixes:
+  - .io
+  mappings:
+  - name: DB.example.com
+    aliasFor: db.default
+  cache:
+    negativeTTL: 5s
+    maxEntries: 500
 `,
 	}
 
@@ -91,6 +126,25 @@ intercept:
 	assert.Equal(t, 9080, cfg.Intercept.DefaultPort)                                           // from user
 	assert.True(t, cfg.Intercept.UseFtp)                                                       // from user
 	assert.Equal(t, cfg.Cluster.DefaultManagerNamespace, "hello")                              // from sys1
+	assert.Equal(t, tunnel.CompressionZstd, cfg.Tunnel.Compression)                            // from user
+	assert.Equal(t, 1400, cfg.Routing.Stack.MTU)                                               // from sys1
+	require.NotNil(t, cfg.Routing.Stack.WindowScale)                                           // from user
+	assert.Equal(t, 0, *cfg.Routing.Stack.WindowScale)                                         // from user, explicit zero
+	assert.Equal(t, "cubic", cfg.Routing.Stack.CongestionControl)                              // from user
+	require.NotNil(t, cfg.Routing.Stack.SACK)                                                  // from user
+	assert.False(t, *cfg.Routing.Stack.SACK)                                                   // from user
+	assert.Empty(t, cfg.Routing.AlsoProxy)                                                     // session setting, ignored
+	assert.Empty(t, cfg.DNS.ExcludeSuffixes)                                                   // session setting, ignored
+	assert.Equal(t, []*DNSMapping{
+		{Name: "api.staging.example.com", AliasFor: "api.default"}, // from sys1
+		{Name: "DB.example.com", AliasFor: "db.default"},           // from user, replaces the one in sys1
+	}, cfg.DNS.Mappings)
+	assert.Equal(t, DNSCache{
+		MinTTL:      10 * time.Second, // from sys1
+		MaxTTL:      2 * time.Minute,  // from sys1
+		NegativeTTL: 5 * time.Second,  // from user
+		MaxEntries:  500,              // from user
+	}, cfg.DNS.Cache)
 }
 
 func Test_ConfigMarshalYAML(t *testing.T) {
@@ -108,6 +162,17 @@ func Test_ConfigMarshalYAML(t *testing.T) {
 	cfg.Intercept.AppProtocolStrategy = k8sapi.PortName
 	cfg.Intercept.DefaultPort = 9080
 	cfg.Cluster.DefaultManagerNamespace = "hello-there"
+	cfg.Tunnel.Compression = tunnel.CompressionS2
+	cfg.Routing.Stack.MTU = 9000
+	cfg.Routing.Stack.ReceiveBufferSize, _ = resource.ParseQuantity("4Mi")
+	cfg.Routing.Stack.CongestionControl = "cubic"
+	cfg.DNS.Mappings = []*DNSMapping{
+		{Name: "api.staging.example.com", AliasFor: "api.default"},
+		{Name: "db.example.com", IP: net.ParseIP("192.168.1.4")},
+	}
+	cfg.DNS.Cache.NegativeTTL = 30 * time.Second
+	cfg.DNS.Cache.MaxEntries = 2000
+	cfg.DNS.QueryLog = true
 	cfgBytes, err := yaml.Marshal(cfg)
 	require.NoError(t, err)
 
@@ -127,3 +192,173 @@ func Test_ConfigMarshalYAMLDefaults(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "{}\n", string(cfgBytes))
 }
+
+func TestLoadConfig_compressionNone(t *testing.T) {
+	tmp := t.TempDir()
+	sys := filepath.Join(tmp, "sys")
+	user := filepath.Join(tmp, "user")
+	require.NoError(t, os.MkdirAll(sys, 0o700))
+	require.NoError(t, os.MkdirAll(user, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(sys, ConfigFile), []byte("tunnel:\n  compression: zstd\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(user, ConfigFile), []byte("tunnel:\n  compression: none\n"), 0o600))
+
+	c := dlog.NewTestContext(t, false)
+	c = filelocation.WithAppSystemConfigDirs(c, []string{sys})
+	c = filelocation.WithAppUserConfigDir(c, user)
+	cfg, err := LoadConfig(c)
+	require.NoError(t, err)
+	assert.Equal(t, tunnel.CompressionNone, cfg.Tunnel.Compression)
+}
+
+func TestLoadConfig_dnsCacheDefaults(t *testing.T) {
+	tmp := t.TempDir()
+	sys := filepath.Join(tmp, "sys")
+	user := filepath.Join(tmp, "user")
+	require.NoError(t, os.MkdirAll(sys, 0o700))
+	require.NoError(t, os.MkdirAll(user, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(sys, ConfigFile), []byte(`
+dns:
+  cache:
+    minTTL: 5s
+    maxTTL: 1m
+    maxEntries: 100
+`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(user, ConfigFile), []byte(`
+dns:
+  cache:
+    minTTL: 60s
+    maxTTL: 10m
+    maxEntries: 10000
+`), 0o600))
+
+	c := dlog.NewTestContext(t, false)
+	c = filelocation.WithAppSystemConfigDirs(c, []string{sys})
+	c = filelocation.WithAppUserConfigDir(c, user)
+	cfg, err := LoadConfig(c)
+	require.NoError(t, err)
+	assert.Equal(t, defaultDNSCacheMinTTL, cfg.DNS.Cache.MinTTL)
+	assert.Equal(t, defaultDNSCacheMaxTTL, cfg.DNS.Cache.MaxTTL)
+	assert.Equal(t, defaultDNSCacheMaxEntries, cfg.DNS.Cache.MaxEntries)
+}
+
+func TestParseConfigYAML_invalidDNSMapping(t *testing.T) {
+	_, err := ParseConfigYAML([]byte(`
+dns:
+  mappings:
+  - name: db.example.com
+    aliasFor: db.default
+    ip: 192.168.1.4
+`))
+	assert.ErrorContains(t, err, "cannot have both")
+
+	_, err = ParseConfigYAML([]byte(`
+dns:
+  mappings:
+  - aliasFor: db.default
+`))
+	assert.ErrorContains(t, err, "must have a name")
+}
+
+func TestParseConfigYAML_invalidDNSCache(t *testing.T) {
+	_, err := ParseConfigYAML([]byte(`
+dns:
+  cache:
+    minTTL: 2m
+    maxTTL: 1m
+`))
+	assert.ErrorContains(t, err, "minTTL cannot be greater than maxTTL")
+
+	_, err = ParseConfigYAML([]byte(`
+dns:
+  cache:
+    maxEntries: 0
+`))
+	assert.ErrorContains(t, err, "maxEntries must be a positive integer")
+
+	_, err = ParseConfigYAML([]byte(`
+dns:
+  cache:
+    negativeTTL: soon
+`))
+	assert.ErrorContains(t, err, `"soon" is not a valid duration`)
+}
+
+func TestParseConfigYAML_dnsQueryLog(t *testing.T) {
+	cfg, err := ParseConfigYAML([]byte(`
+dns:
+  queryLog: true
+`))
+	require.NoError(t, err)
+	assert.True(t, cfg.DNS.QueryLog)
+
+	_, err = ParseConfigYAML([]byte(`
+dns:
+  queryLog: sometimes
+`))
+	assert.ErrorContains(t, err, `bool expected for key "queryLog"`)
+}
+
+func TestDNSCache_TTL(t *testing.T) {
+	dc := DNSCache{MinTTL: 10 * time.Second, MaxTTL: time.Minute}
+	assert.Equal(t, 10*time.Second, dc.TTL(4*time.Second))
+	assert.Equal(t, 30*time.Second, dc.TTL(30*time.Second))
+	assert.Equal(t, time.Minute, dc.TTL(time.Hour))
+}
+
+func TestSessionConfig_JSONLayout(t *testing.T) {
+	cfg := GetDefaultConfig()
+	cfg.DNS.ExcludeSuffixes = []string{".com"}
+	cfg.DNS.Mappings = []*DNSMapping{{Name: "db.example.com", AliasFor: "db.default"}}
+	cfg.Intercept.DefaultPort = 9080
+	sc := &SessionConfig{ClientFile: "/home/me/.config/telepresence/config.yml", Config: &cfg, ManagerNamespace: "ambassador"}
+
+	data, err := json.Marshal(sc)
+	require.NoError(t, err)
+
+	// The dns is a sibling of the clientConfig, not a part of it.
+	var top, clientConfig map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &top))
+	require.NoError(t, json.Unmarshal(top["clientConfig"], &clientConfig))
+	assert.Contains(t, top, "dns")
+	assert.NotContains(t, clientConfig, "dns")
+	assert.Contains(t, clientConfig, "intercept")
+
+	var sc2 SessionConfig
+	require.NoError(t, json.Unmarshal(data, &sc2))
+	assert.Equal(t, sc.ClientFile, sc2.ClientFile)
+	assert.Equal(t, sc.ManagerNamespace, sc2.ManagerNamespace)
+	assert.Equal(t, cfg.DNS.ExcludeSuffixes, sc2.DNS.ExcludeSuffixes)
+	assert.Equal(t, cfg.DNS.Mappings, sc2.DNS.Mappings)
+	assert.Equal(t, 9080, sc2.Intercept.DefaultPort)
+}
+
+func TestSessionConfig_JSONRoutingRoundTrip(t *testing.T) {
+	cfg := GetDefaultConfig()
+	ws := 0
+	cfg.Routing.AlsoProxy = []*iputil.Subnet{(*iputil.Subnet)(&net.IPNet{IP: net.IP{10, 1, 0, 0}, Mask: net.CIDRMask(16, 32)})}
+	cfg.Routing.NeverProxyHosts = []string{"*.internal.example.com"}
+	cfg.Routing.Stack.WindowScale = &ws
+	cfg.Routing.Stack.CongestionControl = "cubic"
+	cfg.Routing.Stack.ReceiveBufferSize = resource.MustParse("4Mi")
+	cfg.Routing.Stack.SendBufferSize = resource.MustParse("1Mi")
+	sc := &SessionConfig{Config: &cfg, ManagerNamespace: "ambassador"}
+
+	data, err := json.Marshal(sc)
+	require.NoError(t, err)
+
+	// The routing is a sibling of the clientConfig, not a part of it.
+	var top, clientConfig map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &top))
+	require.NoError(t, json.Unmarshal(top["clientConfig"], &clientConfig))
+	assert.Contains(t, top, "routing")
+	assert.NotContains(t, clientConfig, "routing")
+
+	var sc2 SessionConfig
+	require.NoError(t, json.Unmarshal(data, &sc2))
+	assert.Equal(t, cfg.Routing, sc2.Routing)
+
+	// The result is stable.
+	data2, err := json.Marshal(&sc2)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(data2))
+}
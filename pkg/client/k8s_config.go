@@ -46,6 +46,10 @@ type DnsConfig struct {
 
 	// The maximum time to wait for a cluster side host lookup.
 	LookupTimeout v1.Duration `json:"lookup-timeout,omitempty"`
+
+	// Mappings are names that the DNS resolver resolves locally, either to an IP or as an alias for another
+	// name. They take priority over the mappings in the client configuration.
+	Mappings []*DNSMapping `json:"mappings,omitempty"`
 }
 
 // The ManagerConfig is part of the KubeconfigExtension struct. It configures discovery of the traffic manager.
@@ -60,12 +64,24 @@ type KubeconfigExtension struct {
 	AlsoProxy  []*iputil.Subnet `json:"also-proxy,omitempty"`
 	NeverProxy []*iputil.Subnet `json:"never-proxy,omitempty"`
 	Manager    *ManagerConfig   `json:"manager,omitempty"`
+
+	// AlsoProxyHosts are host names that are resolved using the cluster's DNS, and whose addresses are
+	// routed to the cluster. A name that starts with "*." matches all subdomains of the name that follows.
+	AlsoProxyHosts []string `json:"also-proxy-hosts,omitempty"`
+
+	// NeverProxyHosts are host names that are excluded from the AlsoProxyHosts.
+	NeverProxyHosts []string `json:"never-proxy-hosts,omitempty"`
+
+	// VirtualSubnet, when set, makes the root daemon map cluster subnets that conflict with the local
+	// network onto this subnet.
+	VirtualSubnet *iputil.Subnet `json:"virtual-subnet,omitempty"`
 }
 
 type Kubeconfig struct {
 	KubeconfigExtension
 	Namespace   string // default cluster namespace.
 	Context     string
+	User        string // name of the user in the kubeconfig, empty when in-cluster.
 	Server      string
 	FlagMap     map[string]string
 	ConfigFlags *genericclioptions.ConfigFlags
@@ -203,8 +219,14 @@ func newKubeconfig(c context.Context, flagMap map[string]string, managerNamespac
 		namespace = "default"
 	}
 
+	user := flagMap["user"]
+	if user == "" {
+		user = ctx.AuthInfo
+	}
+
 	k := &Kubeconfig{
 		Context:     ctxName,
+		User:        user,
 		Server:      cluster.Server,
 		Namespace:   namespace,
 		FlagMap:     flagMap,
@@ -302,9 +324,13 @@ func (kf *Kubeconfig) GetRestConfig() *rest.Config {
 
 func (kf *Kubeconfig) AddRemoteKubeConfigExtension(ctx context.Context, cfgYaml []byte) error {
 	dlog.Debugf(ctx, "Applying remote dns and routing: %s", cfgYaml)
+	// The remote types have the fields of DNS and Routing, but not their UnmarshalYAML methods, because those
+	// ignore the settings that belong to the session.
+	type remoteDNS DNS
+	type remoteRouting Routing
 	remote := struct {
-		DNS     *DNS     `yaml:"dns,omitempty"`
-		Routing *Routing `yaml:"routing,omitempty"`
+		DNS     *remoteDNS     `yaml:"dns,omitempty"`
+		Routing *remoteRouting `yaml:"routing,omitempty"`
 	}{}
 	if err := yaml.Unmarshal(cfgYaml, &remote); err != nil {
 		return fmt.Errorf("unable to parse remote kubeconfig: %w", err)
@@ -328,6 +354,8 @@ func (kf *Kubeconfig) AddRemoteKubeConfigExtension(ctx context.Context, cfgYaml
 	if routing := remote.Routing; routing != nil {
 		kf.AlsoProxy = append(kf.AlsoProxy, routing.AlsoProxy...)
 		kf.NeverProxy = append(kf.NeverProxy, routing.NeverProxy...)
+		kf.AlsoProxyHosts = append(kf.AlsoProxyHosts, routing.AlsoProxyHosts...)
+		kf.NeverProxyHosts = append(kf.NeverProxyHosts, routing.NeverProxyHosts...)
 	}
 	return nil
 }
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// KubeContextKey is the gRPC metadata key that selects the session that a call to a daemon applies to when the
+// daemon has sessions for several Kubernetes contexts.
+const KubeContextKey = "telepresence-kube-context"
+
+// WithKubeContext returns a context that will pass the given Kubernetes context name in the metadata of outgoing
+// gRPC calls. The given context is returned unchanged if the name is empty.
+func WithKubeContext(ctx context.Context, name string) context.Context {
+	if name == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, KubeContextKey, name)
+}
+
+// GetKubeContext returns the Kubernetes context name found in the metadata of an incoming gRPC call, or an empty
+// string if no such name was passed.
+func GetKubeContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vs := md.Get(KubeContextKey); len(vs) > 0 {
+			return vs[0]
+		}
+	}
+	return ""
+}
+
+// KubeContextDialOptions returns dial options with interceptors that add the given Kubernetes context name to the
+// metadata of all calls made using the resulting connection.
+func KubeContextDialOptions(name string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(
+			ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+		) error {
+			return invoker(WithKubeContext(ctx, name), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(
+			ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			return streamer(WithKubeContext(ctx, name), desc, cc, method, opts...)
+		}),
+	}
+}
+
+// KubeContextDomain returns the DNS domain that is used when disambiguating names that are resolved in the cluster
+// of the given Kubernetes context, so that "<svc>.<ns>.<domain>" resolves "<svc>.<ns>" in that cluster. All
+// characters that aren't valid in a DNS label are replaced by dashes.
+func KubeContextDomain(name string) string {
+	name = strings.ToLower(name)
+	b := make([]byte, len(name))
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9') {
+			c = '-'
+		}
+		b[i] = c
+	}
+	d := strings.Trim(string(b), "-")
+	if len(d) > 63 {
+		d = strings.TrimRight(d[:63], "-")
+	}
+	return d
+}
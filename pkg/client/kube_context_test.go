@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestKubeContextMetadata(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithKubeContext(ctx, ""))
+
+	md, ok := metadata.FromOutgoingContext(WithKubeContext(ctx, "kind-east"))
+	require.True(t, ok)
+	assert.Equal(t, "kind-east", GetKubeContext(metadata.NewIncomingContext(ctx, md)))
+	assert.Equal(t, "", GetKubeContext(ctx))
+}
+
+func TestKubeContextDomain(t *testing.T) {
+	tests := map[string]string{
+		"minikube":                      "minikube",
+		"kind-East":                     "kind-east",
+		"gke_project_europe-west1_prod": "gke-project-europe-west1-prod",
+		"arn:aws:eks:us-east-1:123456789012:cluster/prod": "arn-aws-eks-us-east-1-123456789012-cluster-prod",
+		"admin@home.": "admin-home",
+	}
+	for name, expected := range tests {
+		assert.Equal(t, expected, KubeContextDomain(name), name)
+	}
+}
@@ -0,0 +1,198 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/dnsproxy"
+)
+
+// cacheStats are the counters of the cache, and of the lookups that are made when an answer isn't cached.
+type cacheStats struct {
+	hits               int64
+	misses             int64
+	evictions          int64
+	clusterLookups     int64
+	clusterLookupTime  int64 // nanoseconds
+	fallbackLookups    int64
+	fallbackLookupTime int64 // nanoseconds
+}
+
+func (cs *cacheStats) clusterLookup(d time.Duration) {
+	atomic.AddInt64(&cs.clusterLookups, 1)
+	atomic.AddInt64(&cs.clusterLookupTime, int64(d))
+}
+
+func (cs *cacheStats) fallbackLookup(d time.Duration) {
+	atomic.AddInt64(&cs.fallbackLookups, 1)
+	atomic.AddInt64(&cs.fallbackLookupTime, int64(d))
+}
+
+// minTTL returns the lowest TTL of the given records.
+func minTTL(rrs dnsproxy.RRs) time.Duration {
+	var ttl uint32
+	for i, rr := range rrs {
+		if h := rr.Header(); h != nil && (i == 0 || h.Ttl < ttl) {
+			ttl = h.Ttl
+		}
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// SetCacheConfig sets the TTL bounds and the max number of entries of the cache. Entries that are already
+// cached retain their TTL.
+func (s *Server) SetCacheConfig(dc client.DNSCache) {
+	s.cacheConfig.Store(&dc)
+	if atomic.LoadInt64(&s.cacheSize) > int64(dc.MaxEntries) {
+		s.evict()
+	}
+}
+
+func (s *Server) getCacheConfig() *client.DNSCache {
+	return s.cacheConfig.Load()
+}
+
+// cacheEntryAdded must be called when an entry has been added to the cache. It evicts entries when the cache is full.
+func (s *Server) cacheEntryAdded() {
+	if atomic.AddInt64(&s.cacheSize, 1) > int64(s.getCacheConfig().MaxEntries) {
+		s.evict()
+	}
+}
+
+func (s *Server) deleteCacheEntry(key cacheKey) {
+	if _, ok := s.cache.LoadAndDelete(key); ok {
+		atomic.AddInt64(&s.cacheSize, -1)
+	}
+}
+
+// evict removes the expired entries from the cache, and then the oldest entries until it is 90% full. Entries
+// that are being resolved are never evicted.
+func (s *Server) evict() {
+	if !s.evicting.CompareAndSwap(false, true) {
+		// Another goroutine is evicting
+		return
+	}
+	defer s.evicting.Store(false)
+
+	type agedKey struct {
+		key     cacheKey
+		created time.Time
+	}
+	var done []agedKey
+	size := int64(0)
+	s.cache.Range(func(k, v any) bool {
+		key, dv := k.(cacheKey), v.(*cacheEntry)
+		select {
+		case <-dv.wait:
+			if dv.expired() {
+				s.deleteCacheEntry(key)
+				return true
+			}
+			done = append(done, agedKey{key: key, created: dv.created})
+		default:
+		}
+		size++
+		return true
+	})
+
+	target := int64(s.getCacheConfig().MaxEntries) * 9 / 10
+	if size > target {
+		sort.Slice(done, func(i, j int) bool { return done[i].created.Before(done[j].created) })
+		for _, ak := range done {
+			if size <= target {
+				break
+			}
+			s.deleteCacheEntry(ak.key)
+			atomic.AddInt64(&s.cacheStats.evictions, 1)
+			size--
+		}
+	}
+	// The counter is adjusted in case it has drifted due to concurrent updates.
+	atomic.StoreInt64(&s.cacheSize, size)
+}
+
+// GetCache returns the configuration and statistics of the cache, and the entries that haven't expired. The cache
+// is flushed after the entries have been collected when flush is true.
+func (s *Server) GetCache(flush bool) *rpc.DNSCache {
+	cc := s.getCacheConfig()
+	cs := &s.cacheStats
+	r := &rpc.DNSCache{
+		MinTtl:             durationpb.New(cc.MinTTL),
+		MaxTtl:             durationpb.New(cc.MaxTTL),
+		NegativeTtl:        durationpb.New(cc.NegativeTTL),
+		MaxEntries:         int32(cc.MaxEntries),
+		Hits:               atomic.LoadInt64(&cs.hits),
+		Misses:             atomic.LoadInt64(&cs.misses),
+		Evictions:          atomic.LoadInt64(&cs.evictions),
+		ClusterLookups:     atomic.LoadInt64(&cs.clusterLookups),
+		ClusterLookupTime:  durationpb.New(time.Duration(atomic.LoadInt64(&cs.clusterLookupTime))),
+		FallbackLookups:    atomic.LoadInt64(&cs.fallbackLookups),
+		FallbackLookupTime: durationpb.New(time.Duration(atomic.LoadInt64(&cs.fallbackLookupTime))),
+	}
+	s.cache.Range(func(k, v any) bool {
+		key, dv := k.(cacheKey), v.(*cacheEntry)
+		select {
+		case <-dv.wait:
+		default:
+			return true
+		}
+		if dv.expired() {
+			return true
+		}
+		answer := make([]string, len(dv.answer))
+		for i, rr := range dv.answer {
+			answer[i] = rr.String()
+		}
+		r.Entries = append(r.Entries, &rpc.DNSCacheEntry{
+			Name:    key.name,
+			Type:    dns.TypeToString[key.qType],
+			RCode:   dns.RcodeToString[dv.rCode],
+			Answer:  answer,
+			Expires: timestamppb.New(dv.created.Add(dv.ttl)),
+		})
+		return true
+	})
+	sort.Slice(r.Entries, func(i, j int) bool {
+		ei, ej := r.Entries[i], r.Entries[j]
+		if ei.Name == ej.Name {
+			return ei.Type < ej.Type
+		}
+		return ei.Name < ej.Name
+	})
+	if flush {
+		s.flushDNS()
+	}
+	return r
+}
+
+// Query resolves the given name in the same way as a query that is received by this server, and returns the
+// answer together with the path that the resolution took and the time it took.
+func (s *Server) Query(ctx context.Context, name string, qType uint16) (*rpc.DNSQueryResponse, error) {
+	if s.ctx == nil {
+		return nil, errors.New("the DNS server is not running")
+	}
+	r := new(dns.Msg)
+	r.SetQuestion(dns.Fqdn(name), qType)
+
+	qt := &queryTrace{}
+	start := time.Now()
+	msg, path, _ := s.serveDNS(withQueryTrace(ctx, qt), r, false)
+	qr := &rpc.DNSQueryResponse{
+		Path:     qt.path(path),
+		RCode:    dns.RcodeToString[msg.Rcode],
+		Duration: durationpb.New(time.Since(start)),
+	}
+	for _, rr := range msg.Answer {
+		qr.Answer = append(qr.Answer, rr.String())
+	}
+	return qr, nil
+}
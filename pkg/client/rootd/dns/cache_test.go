@@ -0,0 +1,164 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/dnsproxy"
+)
+
+// newCacheTestServer returns a server that resolves "<ttl>.ttl." names with an A record that has the given
+// TTL, and that answers NXDOMAIN for all other names. The returned counter is the number of lookups.
+func newCacheTestServer(t *testing.T, dc client.DNSCache) (*Server, *int64) {
+	s := NewServer(nil, nil, false)
+	s.ctx = dlog.NewTestContext(t, false)
+	s.SetCacheConfig(dc)
+	var lookups int64
+	s.resolve = func(_ context.Context, q *dns.Question) (dnsproxy.RRs, int, error) {
+		atomic.AddInt64(&lookups, 1)
+		var ttl uint32
+		if _, err := fmt.Sscanf(q.Name, "%d.ttl.", &ttl); err != nil || q.Qtype != dns.TypeA {
+			return nil, dns.RcodeNameError, nil
+		}
+		return dnsproxy.RRs{&dns.A{
+			Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+			A:   net.IP{10, 0, 0, 1},
+		}}, dns.RcodeSuccess, nil
+	}
+	return s, &lookups
+}
+
+func (s *Server) cachedTTL(name string, qType uint16) time.Duration {
+	if v, ok := s.cache.Load(cacheKey{name: name, qType: qType}); ok {
+		return v.(*cacheEntry).ttl
+	}
+	return 0
+}
+
+func TestCache_ttl(t *testing.T) {
+	s, lookups := newCacheTestServer(t, client.DNSCache{MinTTL: 30 * time.Second, MaxTTL: 5 * time.Minute, MaxEntries: 100})
+
+	// The TTL of the records is honoured within the bounds, and the callers get a low TTL
+	answer, rCode, err := s.cacheResolve(s.ctx, &dns.Question{Name: "120.ttl.", Qtype: dns.TypeA})
+	require.NoError(t, err)
+	assert.Equal(t, dns.RcodeSuccess, rCode)
+	require.Len(t, answer, 1)
+	assert.Equal(t, uint32(dnsTTL), answer[0].Header().Ttl)
+	assert.Equal(t, 2*time.Minute, s.cachedTTL("120.ttl.", dns.TypeA))
+
+	_, _, _ = s.cacheResolve(s.ctx, &dns.Question{Name: "5.ttl.", Qtype: dns.TypeA})
+	assert.Equal(t, 30*time.Second, s.cachedTTL("5.ttl.", dns.TypeA))
+	_, _, _ = s.cacheResolve(s.ctx, &dns.Question{Name: "86400.ttl.", Qtype: dns.TypeA})
+	assert.Equal(t, 5*time.Minute, s.cachedTTL("86400.ttl.", dns.TypeA))
+	assert.Equal(t, int64(3), atomic.LoadInt64(lookups))
+
+	_, _, _ = s.cacheResolve(s.ctx, &dns.Question{Name: "120.ttl.", Qtype: dns.TypeA})
+	assert.Equal(t, int64(3), atomic.LoadInt64(lookups))
+
+	// Negative answers aren't cached unless a negative TTL is configured
+	for i := 0; i < 2; i++ {
+		_, rCode, _ = s.cacheResolve(s.ctx, &dns.Question{Name: "unknown.", Qtype: dns.TypeA})
+		assert.Equal(t, dns.RcodeNameError, rCode)
+	}
+	assert.Equal(t, int64(5), atomic.LoadInt64(lookups))
+
+	c := s.GetCache(false)
+	assert.Equal(t, int64(1), c.Hits)
+	assert.Equal(t, int64(5), c.Misses)
+	assert.Equal(t, int64(5), c.ClusterLookups)
+	require.Len(t, c.Entries, 3)
+	assert.Equal(t, "120.ttl.", c.Entries[0].Name)
+	assert.Equal(t, "A", c.Entries[0].Type)
+	assert.Equal(t, "NOERROR", c.Entries[0].RCode)
+	assert.Len(t, c.Entries[0].Answer, 1)
+
+	// A flush empties the cache, but retains the statistics
+	assert.Len(t, s.GetCache(true).Entries, 3)
+	c = s.GetCache(false)
+	assert.Empty(t, c.Entries)
+	assert.Equal(t, int64(1), c.Hits)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&s.cacheSize))
+}
+
+func TestCache_negativeTTL(t *testing.T) {
+	s, lookups := newCacheTestServer(t, client.DNSCache{MinTTL: 30 * time.Second, MaxTTL: 5 * time.Minute, NegativeTTL: 10 * time.Second, MaxEntries: 100})
+	for i := 0; i < 2; i++ {
+		answer, rCode, err := s.cacheResolve(s.ctx, &dns.Question{Name: "unknown.", Qtype: dns.TypeA})
+		require.NoError(t, err)
+		assert.Equal(t, dns.RcodeNameError, rCode)
+		assert.Empty(t, answer)
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(lookups))
+	assert.Equal(t, 10*time.Second, s.cachedTTL("unknown.", dns.TypeA))
+
+	// The answers to the recursion check are never cached
+	_, _, _ = s.cacheResolve(s.ctx, &dns.Question{Name: recursionCheck, Qtype: dns.TypeA})
+	_, _, _ = s.cacheResolve(s.ctx, &dns.Question{Name: recursionCheck, Qtype: dns.TypeA})
+	assert.Equal(t, int64(3), atomic.LoadInt64(lookups))
+}
+
+func TestCache_maxEntries(t *testing.T) {
+	s, _ := newCacheTestServer(t, client.DNSCache{MinTTL: 30 * time.Second, MaxTTL: 5 * time.Minute, MaxEntries: 10})
+	for i := 0; i < 25; i++ {
+		_, _, _ = s.cacheResolve(s.ctx, &dns.Question{Name: fmt.Sprintf("%d.ttl.", 100+i), Qtype: dns.TypeA})
+	}
+	c := s.GetCache(false)
+	assert.LessOrEqual(t, len(c.Entries), 10)
+	assert.Greater(t, c.Evictions, int64(0))
+
+	// The newest entries are retained
+	assert.NotZero(t, s.cachedTTL("124.ttl.", dns.TypeA))
+	assert.Zero(t, s.cachedTTL("100.ttl.", dns.TypeA))
+
+	// Lowering the max evicts entries immediately
+	s.SetCacheConfig(client.DNSCache{MinTTL: 30 * time.Second, MaxTTL: 5 * time.Minute, MaxEntries: 2})
+	assert.LessOrEqual(t, len(s.GetCache(false).Entries), 2)
+}
+
+func TestQuery(t *testing.T) {
+	s, _ := newCacheTestServer(t, client.DNSCache{MinTTL: 30 * time.Second, MaxTTL: 5 * time.Minute, MaxEntries: 100})
+	s.SetMappings(s.ctx, []*rpc.DNSMapping{{Name: "db.example.com", Ip: net.IP{192, 168, 1, 4}}})
+	ctx := s.ctx
+
+	r, err := s.Query(ctx, "60.ttl", dns.TypeA)
+	require.NoError(t, err)
+	assert.Equal(t, rpc.DNSQueryResponse_CLUSTER, r.Path)
+	assert.Equal(t, "NOERROR", r.RCode)
+	require.Len(t, r.Answer, 1)
+	assert.Contains(t, r.Answer[0], "10.0.0.1")
+	assert.NotNil(t, r.Duration)
+
+	r, err = s.Query(ctx, "60.ttl.", dns.TypeA)
+	require.NoError(t, err)
+	assert.Equal(t, rpc.DNSQueryResponse_CACHE, r.Path)
+
+	r, err = s.Query(ctx, "db.example.com", dns.TypeA)
+	require.NoError(t, err)
+	assert.Equal(t, rpc.DNSQueryResponse_MAPPING, r.Path)
+	assert.Len(t, r.Answer, 1)
+
+	// Without a fallback, the cluster's NXDOMAIN is the answer
+	r, err = s.Query(ctx, "unknown", dns.TypeA)
+	require.NoError(t, err)
+	assert.Equal(t, rpc.DNSQueryResponse_CLUSTER, r.Path)
+	assert.Equal(t, "NXDOMAIN", r.RCode)
+
+	r, err = s.Query(ctx, "60.ttl", dns.TypeHINFO)
+	require.NoError(t, err)
+	assert.Equal(t, rpc.DNSQueryResponse_NONE, r.Path)
+	assert.Equal(t, "NOTIMP", r.RCode)
+
+	_, err = NewServer(nil, nil, false).Query(ctx, "60.ttl", dns.TypeA)
+	assert.Error(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"syscall"
 	"time"
 
 	"github.com/miekg/dns"
@@ -55,6 +56,9 @@ func (cp *ConnPool) RemoteAddr() string {
 }
 
 func (cp *ConnPool) Exchange(ctx context.Context, client *dns.Client, msg *dns.Msg) (r *dns.Msg, rtt time.Duration, err error) {
+	if client.Net == "tcp" {
+		return cp.exchangeTCP(ctx, client, msg)
+	}
 	conn, err := cp.getConnection(ctx)
 	if err != nil {
 		return nil, time.Duration(0), err
@@ -63,6 +67,29 @@ func (cp *ConnPool) Exchange(ctx context.Context, client *dns.Client, msg *dns.M
 	return client.ExchangeWithConn(msg, conn)
 }
 
+// exchangeTCP sends the message using a new TCP connection. The connection is marked, so that it bypasses the
+// NAT rule that redirects the TCP traffic for the DNS server to the local DNS server.
+func (cp *ConnPool) exchangeTCP(ctx context.Context, client *dns.Client, msg *dns.Msg) (*dns.Msg, time.Duration, error) {
+	d := net.Dialer{Timeout: client.Timeout, Control: markFallbackConn}
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(cp.remoteAddr, "53"))
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to create DNS TCP conn to %s: %w", cp.remoteAddr, err)
+	}
+	defer conn.Close()
+	return client.ExchangeWithConn(msg, &dns.Conn{Conn: conn})
+}
+
+// markFallbackConn sets the fallbackMark on the socket of a connection.
+func markFallbackConn(_, _ string, rc syscall.RawConn) error {
+	var serr error
+	if err := rc.Control(func(fd uintptr) {
+		serr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_MARK, fallbackMark)
+	}); err != nil {
+		return err
+	}
+	return serr
+}
+
 func (cp *ConnPool) Close() {
 	cp.cancel()
 	for conn := range cp.items {
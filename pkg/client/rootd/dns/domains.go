@@ -0,0 +1,121 @@
+package dns
+
+import (
+	"strings"
+	"sync"
+)
+
+// DomainRegistry keeps track of the domains that the DNS servers of different sessions route to themselves,
+// so that two sessions never route the same, or overlapping, domains. The session that claims a domain first
+// keeps it until it no longer wants it, or until its DNS server stops. Overlapping domains of sessions that
+// connect later are rejected, and those sessions can only resolve the names within them using their
+// context domain, e.g. "<svc>.<ns>.<context>".
+type DomainRegistry struct {
+	sync.Mutex
+	domains map[*Server][]string
+}
+
+// claim returns the desired domains that don't overlap the domains claimed by other servers, and records
+// them as the domains claimed by the given server. The overlapping domains are returned as rejected. A nil
+// registry rejects nothing.
+func (r *DomainRegistry) claim(s *Server, desired []string) (claimed, rejected []string) {
+	if r == nil {
+		return desired, nil
+	}
+	r.Lock()
+	defer r.Unlock()
+	claimed = make([]string, 0, len(desired))
+	for _, d := range desired {
+		if r.overlapsOthers(s, d) {
+			rejected = append(rejected, d)
+		} else {
+			claimed = append(claimed, d)
+		}
+	}
+	released := false
+	for _, pd := range r.domains[s] {
+		if !containsDomain(claimed, pd) {
+			released = true
+			break
+		}
+	}
+	if r.domains == nil {
+		r.domains = make(map[*Server][]string)
+	}
+	r.domains[s] = claimed
+	if released {
+		r.notifyOthers(s)
+	}
+	return claimed, rejected
+}
+
+// release removes all domains claimed by the given server.
+func (r *DomainRegistry) release(s *Server) {
+	if r == nil {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.domains[s]; ok {
+		delete(r.domains, s)
+		r.notifyOthers(s)
+	}
+}
+
+// hasOthers returns true if servers other than the given one have claimed domains.
+func (r *DomainRegistry) hasOthers(s *Server) bool {
+	if r == nil {
+		return false
+	}
+	r.Lock()
+	defer r.Unlock()
+	for os := range r.domains {
+		if os != s {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *DomainRegistry) overlapsOthers(s *Server, d string) bool {
+	for os, ods := range r.domains {
+		if os == s {
+			continue
+		}
+		for _, od := range ods {
+			if domainsOverlap(d, od) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// notifyOthers tells the servers other than the given one that domains might have been released, so that
+// they can claim the domains that were rejected earlier.
+func (r *DomainRegistry) notifyOthers(s *Server) {
+	for os := range r.domains {
+		if os != s {
+			select {
+			case os.domainsCh <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// domainsOverlap returns true if the two domains are equal, or if one of them is a subdomain of the other.
+func domainsOverlap(a, b string) bool {
+	a = strings.TrimSuffix(strings.ToLower(a), ".")
+	b = strings.TrimSuffix(strings.ToLower(b), ".")
+	return a == b || strings.HasSuffix(a, "."+b) || strings.HasSuffix(b, "."+a)
+}
+
+func containsDomain(domains []string, d string) bool {
+	for _, x := range domains {
+		if x == d {
+			return true
+		}
+	}
+	return false
+}
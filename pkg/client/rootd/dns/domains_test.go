@@ -0,0 +1,60 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainRegistry_firstSessionWins(t *testing.T) {
+	r := &DomainRegistry{}
+	a := NewServer(nil, nil, false)
+	b := NewServer(nil, nil, false)
+
+	claimed, rejected := r.claim(a, []string{"cluster.local.", "default", "a-context", "example.com"})
+	assert.Equal(t, []string{"cluster.local.", "default", "a-context", "example.com"}, claimed)
+	assert.Empty(t, rejected)
+	assert.False(t, r.hasOthers(a))
+	assert.True(t, r.hasOthers(b))
+
+	// Equal domains and subdomains of the domains that the first server routes are rejected.
+	claimed, rejected = r.claim(b, []string{"cluster.local.", "default", "other", "b-context", "svc.example.com"})
+	assert.Equal(t, []string{"other", "b-context"}, claimed)
+	assert.Equal(t, []string{"cluster.local.", "default", "svc.example.com"}, rejected)
+
+	// The second server is told when the first one releases domains, and can then claim them.
+	claimed, _ = r.claim(a, []string{"cluster.local.", "a-context", "example.com"})
+	assert.Equal(t, []string{"cluster.local.", "a-context", "example.com"}, claimed)
+	select {
+	case <-b.domainsCh:
+	default:
+		t.Fatal("no notification when domains were released")
+	}
+	claimed, rejected = r.claim(b, []string{"cluster.local.", "default", "other", "b-context"})
+	assert.Equal(t, []string{"default", "other", "b-context"}, claimed)
+	assert.Equal(t, []string{"cluster.local."}, rejected)
+
+	// Claiming the same domains again doesn't notify anyone.
+	r.claim(b, []string{"cluster.local.", "default", "other", "b-context"})
+	select {
+	case <-a.domainsCh:
+		t.Fatal("notification when no domains were released")
+	default:
+	}
+
+	r.release(a)
+	<-b.domainsCh
+	claimed, rejected = r.claim(b, []string{"cluster.local.", "default", "other", "b-context"})
+	assert.Equal(t, []string{"cluster.local.", "default", "other", "b-context"}, claimed)
+	assert.Empty(t, rejected)
+}
+
+func TestDomainRegistry_nil(t *testing.T) {
+	var r *DomainRegistry
+	s := NewServer(nil, nil, false)
+	claimed, rejected := r.claim(s, []string{"cluster.local."})
+	assert.Equal(t, []string{"cluster.local."}, claimed)
+	assert.Empty(t, rejected)
+	assert.False(t, r.hasOthers(s))
+	r.release(s)
+}
@@ -0,0 +1,145 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/dnsproxy"
+)
+
+// mapping is a name that is resolved locally. Exactly one of aliasFor and ip is set.
+type mapping struct {
+	aliasFor string // fully qualified, lower case, name that the name is an alias for
+	ip       net.IP
+}
+
+// maxAliasDepth is the max number of aliases that are followed when an alias refers to another mapping.
+const maxAliasDepth = 8
+
+// toFQDN returns the given name in lower case and with a trailing dot.
+func toFQDN(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+// SetMappings sets the mappings of the client configuration. They are combined with the mappings of the
+// kubeconfig extension, which take priority when both map the same name. The cache is flushed, and the
+// names are routed to this server, so that the change takes effect immediately.
+func (s *Server) SetMappings(ctx context.Context, mappings []*rpc.DNSMapping) {
+	ms := make(map[string]mapping)
+	add := func(rms []*rpc.DNSMapping) {
+		for _, rm := range rms {
+			switch {
+			case rm.Name == "" || (rm.AliasFor == "") == (len(rm.Ip) == 0):
+				dlog.Warnf(ctx, "ignoring DNS mapping %q, it must have a name and exactly one of aliasFor and ip", rm.Name)
+			case rm.AliasFor != "":
+				ms[toFQDN(rm.Name)] = mapping{aliasFor: toFQDN(rm.AliasFor)}
+			default:
+				ms[toFQDN(rm.Name)] = mapping{ip: rm.Ip}
+			}
+		}
+	}
+	add(mappings)
+	add(s.config.Mappings)
+
+	s.mappingsLock.Lock()
+	s.mappings = ms
+	s.mappingsLock.Unlock()
+	s.flushDNS()
+	select {
+	case s.mappingsCh <- struct{}{}:
+	default:
+		// A change is already pending
+	}
+}
+
+// getMappings returns the mappings that are in effect, sorted by name.
+func (s *Server) getMappings() []*rpc.DNSMapping {
+	s.mappingsLock.RLock()
+	defer s.mappingsLock.RUnlock()
+	rms := make([]*rpc.DNSMapping, 0, len(s.mappings))
+	for name, m := range s.mappings {
+		rms = append(rms, &rpc.DNSMapping{
+			Name:     strings.TrimSuffix(name, "."),
+			AliasFor: strings.TrimSuffix(m.aliasFor, "."),
+			Ip:       m.ip,
+		})
+	}
+	sort.Slice(rms, func(i, j int) bool { return rms[i].Name < rms[j].Name })
+	return rms
+}
+
+// mappingDomains returns the mapped names. Queries for these names must be routed to this server.
+func (s *Server) mappingDomains() []string {
+	s.mappingsLock.RLock()
+	defer s.mappingsLock.RUnlock()
+	ds := make([]string, 0, len(s.mappings))
+	for name := range s.mappings {
+		ds = append(ds, strings.TrimSuffix(name, "."))
+	}
+	sort.Strings(ds)
+	return ds
+}
+
+func (s *Server) lookupMapping(name string) (mapping, bool) {
+	s.mappingsLock.RLock()
+	m, ok := s.mappings[strings.ToLower(name)]
+	s.mappingsLock.RUnlock()
+	return m, ok
+}
+
+// resolveMapping resolves the given query using the mappings. The last return value is false when the name
+// isn't mapped. An alias is answered with a CNAME record, followed by the answer for the name that it refers
+// to, which is resolved in the same way as any other query.
+func (s *Server) resolveMapping(ctx context.Context, q *dns.Question) (dnsproxy.RRs, int, bool) {
+	m, ok := s.lookupMapping(q.Name)
+	if !ok {
+		return nil, dns.RcodeSuccess, false
+	}
+	var answer dnsproxy.RRs
+	owner := q.Name
+	for depth := 0; m.aliasFor != ""; depth++ {
+		if depth == maxAliasDepth {
+			return answer, dns.RcodeServerFailure, true
+		}
+		answer = append(answer, &dns.CNAME{
+			Hdr:    dns.RR_Header{Name: owner, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: dnsTTL},
+			Target: m.aliasFor,
+		})
+		owner = m.aliasFor
+		if m, ok = s.lookupMapping(owner); ok {
+			continue
+		}
+		if q.Qtype == dns.TypeCNAME || s.onlyNames && q.Qtype != dns.TypeA || !dnsproxy.SupportedType(q.Qtype) {
+			return answer, dns.RcodeSuccess, true
+		}
+		rrs, rCode, err := s.cacheResolve(ctx, &dns.Question{Name: owner, Qtype: q.Qtype, Qclass: q.Qclass})
+		if err != nil {
+			dlog.Debugf(s.ctx, "unable to resolve %s, the alias of %s: %v", owner, q.Name, err)
+			return answer, dns.RcodeServerFailure, true
+		}
+		return append(answer, rrs...), rCode, true
+	}
+
+	h := dns.RR_Header{Name: owner, Class: dns.ClassINET, Ttl: dnsTTL}
+	ip4 := m.ip.To4()
+	switch {
+	case q.Qtype == dns.TypeA && ip4 != nil:
+		h.Rrtype = dns.TypeA
+		answer = append(answer, &dns.A{Hdr: h, A: ip4})
+	case q.Qtype == dns.TypeAAAA && ip4 == nil:
+		h.Rrtype = dns.TypeAAAA
+		answer = append(answer, &dns.AAAA{Hdr: h, AAAA: m.ip})
+	}
+	// An empty answer tells the caller that the name exists, but has no records of the given type.
+	return answer, dns.RcodeSuccess, true
+}
@@ -0,0 +1,99 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/dnsproxy"
+)
+
+func TestResolveMapping(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := NewServer(&rpc.DNSConfig{Mappings: []*rpc.DNSMapping{
+		{Name: "db.example.com", Ip: net.IP{192, 168, 1, 5}},
+	}}, nil, false)
+	s.ctx = ctx
+	s.cacheResolve = func(_ context.Context, q *dns.Question) (dnsproxy.RRs, int, error) {
+		if q.Name == "api.default." && q.Qtype == dns.TypeA {
+			return dnsproxy.RRs{&dns.A{Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA}, A: net.IP{10, 96, 0, 12}}}, dns.RcodeSuccess, nil
+		}
+		return nil, dns.RcodeNameError, nil
+	}
+	s.SetMappings(ctx, []*rpc.DNSMapping{
+		{Name: "api.staging.example.com", AliasFor: "api.default"},
+		{Name: "API.Local", AliasFor: "api.staging.example.com."},
+		{Name: "db.example.com", Ip: net.IP{192, 168, 1, 4}},
+		{Name: "v6.example.com", Ip: net.ParseIP("fd00::1")},
+		{Name: "broken.example.com"},
+	})
+	assert.Equal(t, []string{"api.local", "api.staging.example.com", "db.example.com", "v6.example.com"}, s.mappingDomains())
+
+	// Unmapped names are not resolved
+	_, _, ok := s.resolveMapping(ctx, &dns.Question{Name: "other.example.com.", Qtype: dns.TypeA})
+	assert.False(t, ok)
+	_, _, ok = s.resolveMapping(ctx, &dns.Question{Name: "broken.example.com.", Qtype: dns.TypeA})
+	assert.False(t, ok)
+
+	// The mapping of the kubeconfig extension takes priority
+	answer, rCode, ok := s.resolveMapping(ctx, &dns.Question{Name: "db.example.com.", Qtype: dns.TypeA})
+	require.True(t, ok)
+	assert.Equal(t, dns.RcodeSuccess, rCode)
+	require.Len(t, answer, 1)
+	assert.Equal(t, net.IP{192, 168, 1, 5}, answer[0].(*dns.A).A.To4())
+
+	// No AAAA records for an IPv4 mapping
+	answer, rCode, ok = s.resolveMapping(ctx, &dns.Question{Name: "db.example.com.", Qtype: dns.TypeAAAA})
+	require.True(t, ok)
+	assert.Equal(t, dns.RcodeSuccess, rCode)
+	assert.Empty(t, answer)
+
+	answer, _, ok = s.resolveMapping(ctx, &dns.Question{Name: "v6.example.com.", Qtype: dns.TypeAAAA})
+	require.True(t, ok)
+	require.Len(t, answer, 1)
+	assert.Equal(t, net.ParseIP("fd00::1"), answer[0].(*dns.AAAA).AAAA)
+
+	// An alias of an alias is answered with a chain of CNAME records followed by the cluster's answer
+	answer, rCode, ok = s.resolveMapping(ctx, &dns.Question{Name: "api.local.", Qtype: dns.TypeA})
+	require.True(t, ok)
+	assert.Equal(t, dns.RcodeSuccess, rCode)
+	require.Len(t, answer, 3)
+	assert.Equal(t, "api.local.", answer[0].Header().Name)
+	assert.Equal(t, "api.staging.example.com.", answer[0].(*dns.CNAME).Target)
+	assert.Equal(t, "api.default.", answer[1].(*dns.CNAME).Target)
+	assert.Equal(t, net.IP{10, 96, 0, 12}, answer[2].(*dns.A).A)
+
+	// The rCode of the cluster's answer is retained
+	answer, rCode, ok = s.resolveMapping(ctx, &dns.Question{Name: "api.staging.example.com.", Qtype: dns.TypeAAAA})
+	require.True(t, ok)
+	assert.Equal(t, dns.RcodeNameError, rCode)
+	assert.Len(t, answer, 1)
+
+	// A reload replaces the mappings and notifies the search path processor
+	<-s.mappingsCh
+	s.SetMappings(ctx, nil)
+	_, _, ok = s.resolveMapping(ctx, &dns.Question{Name: "api.local.", Qtype: dns.TypeA})
+	assert.False(t, ok)
+	assert.Equal(t, []string{"db.example.com"}, s.mappingDomains())
+	assert.Len(t, s.mappingsCh, 1)
+}
+
+func TestResolveMapping_aliasLoop(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	s := NewServer(nil, nil, false)
+	s.ctx = ctx
+	s.SetMappings(ctx, []*rpc.DNSMapping{
+		{Name: "a.example.com", AliasFor: "b.example.com"},
+		{Name: "b.example.com", AliasFor: "a.example.com"},
+	})
+	answer, rCode, ok := s.resolveMapping(ctx, &dns.Question{Name: "a.example.com.", Qtype: dns.TypeA})
+	require.True(t, ok)
+	assert.Equal(t, dns.RcodeServerFailure, rCode)
+	assert.Len(t, answer, maxAliasDepth)
+}
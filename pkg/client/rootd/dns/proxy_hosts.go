@@ -0,0 +1,107 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/dnsproxy"
+)
+
+// ProxyHostsResolved is called when a name that matches the also-proxy hosts has been resolved using the
+// cluster's DNS. The ttl is the time that the addresses must remain routed to the cluster.
+type ProxyHostsResolved func(ctx context.Context, name string, ips []net.IP, ttl time.Duration)
+
+// normalizeHostPatterns returns the given patterns in lower case and without trailing dots. Empty patterns
+// are dropped.
+func normalizeHostPatterns(patterns []string) []string {
+	ns := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(p)), ".")
+		if p != "" && p != "*" {
+			ns = append(ns, p)
+		}
+	}
+	return ns
+}
+
+// matchHost returns true if the given name, without a trailing dot, matches the given pattern. A pattern that
+// starts with "*." matches all subdomains of the domain that follows, but not the domain itself. Other patterns
+// must be equal to the name.
+func matchHost(pattern, name string) bool {
+	if domain := strings.TrimPrefix(pattern, "*"); len(domain) < len(pattern) {
+		return strings.HasSuffix(name, domain)
+	}
+	return pattern == name
+}
+
+func matchHosts(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if matchHost(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostPatternDomain returns the domain that a name matching the given pattern belongs to.
+func hostPatternDomain(pattern string) string {
+	return strings.TrimPrefix(pattern, "*.")
+}
+
+// SetProxyHosts sets the also-proxy and never-proxy host patterns and the function that is called when a name
+// matching the also-proxy hosts, and none of the never-proxy hosts, has been resolved. It must be called before
+// the server is started.
+func (s *Server) SetProxyHosts(alsoProxy, neverProxy []string, onResolved ProxyHostsResolved) {
+	s.alsoProxyHosts = normalizeHostPatterns(alsoProxy)
+	s.neverProxyHosts = normalizeHostPatterns(neverProxy)
+	s.onProxyHostsResolved = onResolved
+}
+
+// proxyHostDomains returns the domains of the also-proxy hosts. Queries for these domains must be routed to
+// this server.
+func (s *Server) proxyHostDomains() []string {
+	ds := make([]string, len(s.alsoProxyHosts))
+	for i, p := range s.alsoProxyHosts {
+		ds[i] = hostPatternDomain(p)
+	}
+	return ds
+}
+
+// isProxyHost returns true if the given name, without a trailing dot, matches the also-proxy hosts and none
+// of the never-proxy hosts.
+func (s *Server) isProxyHost(name string) bool {
+	return matchHosts(s.alsoProxyHosts, name) && !matchHosts(s.neverProxyHosts, name)
+}
+
+// proxyHostsResolved reports the addresses in the given answer to the onProxyHostsResolved function. The
+// addresses are kept routed until both the local cache entry and the cache entry of the caller have expired,
+// so the TTL is never less than the sum of those times.
+func (s *Server) proxyHostsResolved(c context.Context, name string, answer dnsproxy.RRs) {
+	var ips []net.IP
+	var ttl uint32
+	for _, rr := range answer {
+		var ip net.IP
+		switch rr := rr.(type) {
+		case *dns.A:
+			ip = rr.A
+		case *dns.AAAA:
+			ip = rr.AAAA
+		default:
+			continue
+		}
+		ips = append(ips, ip)
+		if h := rr.Header(); ttl == 0 || h.Ttl < ttl {
+			ttl = h.Ttl
+		}
+	}
+	if len(ips) == 0 {
+		return
+	}
+	// The route must outlive the cached answer.
+	d := s.getCacheConfig().TTL(time.Duration(ttl) * time.Second)
+	s.onProxyHostsResolved(c, name, ips, d+dnsTTL*time.Second)
+}
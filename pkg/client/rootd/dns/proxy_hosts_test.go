@@ -0,0 +1,73 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/dnsproxy"
+)
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"mydb.example.com", "mydb.example.com", true},
+		{"mydb.example.com", "other.example.com", false},
+		{"*.rds.amazonaws.com", "mydb.cluster-xyz.rds.amazonaws.com", true},
+		{"*.rds.amazonaws.com", "rds.amazonaws.com", false},
+		{"*.rds.amazonaws.com", "xrds.amazonaws.com", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.match, matchHost(tt.pattern, tt.name), "%s %s", tt.pattern, tt.name)
+	}
+	assert.Equal(t, []string{"*.example.com", "mydb.example.com"}, normalizeHostPatterns([]string{"*.Example.COM.", " ", "*", "mydb.example.com"}))
+}
+
+func TestShouldDoClusterLookup_proxyHosts(t *testing.T) {
+	s := NewServer(nil, nil, false)
+	s.SetProxyHosts([]string{"*.rds.amazonaws.com"}, []string{"public.rds.amazonaws.com"}, nil)
+
+	assert.True(t, s.shouldDoClusterLookup("mydb.cluster-xyz.rds.amazonaws.com."))
+	assert.False(t, s.shouldDoClusterLookup("example.com."))
+
+	// Without a fallback, a never-proxy host that is routed to this server must be resolved in the cluster.
+	assert.True(t, s.shouldDoClusterLookup("public.rds.amazonaws.com."))
+	assert.False(t, s.isProxyHost("public.rds.amazonaws.com"))
+	assert.Equal(t, []string{"rds.amazonaws.com"}, s.proxyHostDomains())
+}
+
+func TestProxyHostsResolved(t *testing.T) {
+	var gotName string
+	var gotIPs []net.IP
+	var gotTTL time.Duration
+	s := NewServer(nil, nil, false)
+	s.SetProxyHosts([]string{"mydb.example.com"}, nil, func(_ context.Context, name string, ips []net.IP, ttl time.Duration) {
+		gotName, gotIPs, gotTTL = name, ips, ttl
+	})
+	s.proxyHostsResolved(context.Background(), "mydb.example.com.", dnsproxy.RRs{
+		&dns.CNAME{Hdr: dns.RR_Header{Rrtype: dns.TypeCNAME, Ttl: 5}, Target: "db.internal."},
+		&dns.A{Hdr: dns.RR_Header{Rrtype: dns.TypeA, Ttl: 300}, A: net.IP{10, 1, 2, 3}},
+		&dns.A{Hdr: dns.RR_Header{Rrtype: dns.TypeA, Ttl: 200}, A: net.IP{10, 1, 2, 4}},
+	})
+	require.Len(t, gotIPs, 2)
+	assert.Equal(t, "mydb.example.com.", gotName)
+	assert.Equal(t, 200*time.Second+dnsTTL*time.Second, gotTTL)
+
+	// The TTL is clamped in the same way as the TTL of the cached answer
+	s.proxyHostsResolved(context.Background(), "mydb.example.com.", dnsproxy.RRs{
+		&dns.A{Hdr: dns.RR_Header{Rrtype: dns.TypeA, Ttl: 1}, A: net.IP{10, 1, 2, 3}},
+	})
+	assert.Equal(t, s.getCacheConfig().MinTTL+dnsTTL*time.Second, gotTTL)
+	s.proxyHostsResolved(context.Background(), "mydb.example.com.", dnsproxy.RRs{
+		&dns.A{Hdr: dns.RR_Header{Rrtype: dns.TypeA, Ttl: 86400}, A: net.IP{10, 1, 2, 3}},
+	})
+	assert.Equal(t, s.getCacheConfig().MaxTTL+dnsTTL*time.Second, gotTTL)
+}
@@ -0,0 +1,224 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/logging"
+	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+)
+
+// queryLogFile is the name of the query log file in the log directory.
+const queryLogFile = "dns-queries.log"
+
+// queryLogSize is the number of recent entries that the query log retains in memory.
+const queryLogSize = 256
+
+// queryTrace collects what happens during the resolution of a query.
+type queryTrace struct {
+	cached  bool     // set when the answer was found in the cache
+	lookups []string // names looked up in the cluster, in order
+}
+
+type queryTraceKey struct{}
+
+func withQueryTrace(ctx context.Context, qt *queryTrace) context.Context {
+	return context.WithValue(ctx, queryTraceKey{}, qt)
+}
+
+func getQueryTrace(ctx context.Context) *queryTrace {
+	qt, _ := ctx.Value(queryTraceKey{}).(*queryTrace)
+	return qt
+}
+
+// addQueryLookup records that the given name is looked up in the cluster, unless the query isn't traced.
+func addQueryLookup(ctx context.Context, name string) {
+	if qt := getQueryTrace(ctx); qt != nil {
+		qt.lookups = append(qt.lookups, name)
+	}
+}
+
+// path returns the given path of a resolution, or the cache path when the answer was found in the cache.
+func (qt *queryTrace) path(path rpc.DNSQueryResponse_Path) rpc.DNSQueryResponse_Path {
+	if qt.cached && path == rpc.DNSQueryResponse_CLUSTER {
+		return rpc.DNSQueryResponse_CACHE
+	}
+	return path
+}
+
+// queryLog records the queries that the server receives. The entries are written to a rotating file when the
+// log is enabled, and they are sent to the followers. Nothing is recorded unless the log is enabled or followed.
+type queryLog struct {
+	sync.Mutex
+	active    atomic.Bool
+	file      io.WriteCloser
+	recent    []*rpc.DNSQueryLogEntry // ring buffer
+	next      int
+	followers map[chan *rpc.DNSQueryLogEntry]struct{}
+}
+
+func (ql *queryLog) updateActive() {
+	ql.active.Store(ql.file != nil || len(ql.followers) > 0)
+}
+
+func (ql *queryLog) add(e *rpc.DNSQueryLogEntry) {
+	ql.Lock()
+	defer ql.Unlock()
+	if len(ql.recent) < queryLogSize {
+		ql.recent = append(ql.recent, e)
+	} else {
+		ql.recent[ql.next] = e
+		ql.next = (ql.next + 1) % queryLogSize
+	}
+	if ql.file != nil {
+		_, _ = io.WriteString(ql.file, formatQueryLogEntry(e)+"\n")
+	}
+	for ch := range ql.followers {
+		select {
+		case ch <- e:
+		default:
+			// The follower can't keep up
+		}
+	}
+}
+
+// formatQueryLogEntry formats the given entry as a line in the query log file.
+func formatQueryLogEntry(e *rpc.DNSQueryLogEntry) string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "%s %s %-5s %s -> %s %s %s",
+		e.Time.AsTime().Local().Format("2006-01-02 15:04:05.0000"), e.Client, e.Type, e.Name, e.RCode,
+		strings.ToLower(e.Path.String()), e.Duration.AsDuration().Round(time.Microsecond))
+	if !e.Cluster {
+		sb.WriteString(" (not routed to the cluster)")
+	}
+	if len(e.Lookups) > 0 {
+		fmt.Fprintf(&sb, " lookups=%s", strings.Join(e.Lookups, ","))
+	}
+	if len(e.Answer) > 0 {
+		fmt.Fprintf(&sb, " answer=%d", len(e.Answer))
+	}
+	return sb.String()
+}
+
+// SetQueryLog enables or disables the query log file, which is a daily rotated file named dns-queries.log in the
+// log directory.
+func (s *Server) SetQueryLog(ctx context.Context, enabled bool) error {
+	ql := &s.queryLog
+	ql.Lock()
+	defer ql.Unlock()
+	defer ql.updateActive()
+	if (ql.file != nil) == enabled {
+		return nil
+	}
+	if !enabled {
+		err := ql.file.Close()
+		ql.file = nil
+		dlog.Info(ctx, "DNS query log disabled")
+		return err
+	}
+	dir, err := filelocation.AppUserLogDir(ctx)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(dir, queryLogFile)
+	rf, err := logging.OpenRotatingFile(ctx, file, "20060102T150405", true, 0o600, logging.RotateDaily, 5)
+	if err != nil {
+		return err
+	}
+	ql.file = rf
+	dlog.Infof(ctx, "DNS query log enabled, writing to %s", file)
+	return nil
+}
+
+// FollowQueryLog calls send with each of the recently logged queries. When follow is true, it then calls send
+// with each new query until the given context is cancelled. Entries are dropped when send can't keep up.
+func (s *Server) FollowQueryLog(ctx context.Context, follow bool, send func(*rpc.DNSQueryLogEntry) error) error {
+	ql := &s.queryLog
+	ql.Lock()
+	recent := make([]*rpc.DNSQueryLogEntry, 0, len(ql.recent))
+	recent = append(recent, ql.recent[ql.next:]...)
+	recent = append(recent, ql.recent[:ql.next]...)
+	var ch chan *rpc.DNSQueryLogEntry
+	if follow {
+		ch = make(chan *rpc.DNSQueryLogEntry, 256)
+		if ql.followers == nil {
+			ql.followers = make(map[chan *rpc.DNSQueryLogEntry]struct{})
+		}
+		ql.followers[ch] = struct{}{}
+		ql.updateActive()
+		defer func() {
+			ql.Lock()
+			delete(ql.followers, ch)
+			ql.updateActive()
+			ql.Unlock()
+		}()
+	}
+	ql.Unlock()
+
+	for _, e := range recent {
+		if err := send(e); err != nil {
+			return err
+		}
+	}
+	if !follow {
+		return nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-ch:
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// queryClient returns a channel that receives a description of the client with the given address. The process
+// that owns the client's socket is looked up concurrently with the resolution of the query, while it's still
+// likely to be open.
+func queryClient(addr net.Addr) <-chan string {
+	ch := make(chan string, 1)
+	go func() {
+		client := addr.String()
+		if p := clientProcess(addr); p != "" {
+			client += " " + p
+		}
+		ch <- client
+	}()
+	return ch
+}
+
+// logQuery adds an entry for the given query to the query log.
+func (s *Server) logQuery(start time.Time, client, name string, qType uint16, qt *queryTrace, path rpc.DNSQueryResponse_Path, msg *dns.Msg) {
+	query := strings.TrimSuffix(strings.ToLower(name), tel2SubDomainDot)
+	e := &rpc.DNSQueryLogEntry{
+		Time:     timestamppb.New(start),
+		Client:   client,
+		Name:     name,
+		Type:     dns.TypeToString[qType],
+		Cluster:  query != "" && s.shouldDoClusterLookup(query),
+		Lookups:  qt.lookups,
+		Path:     qt.path(path),
+		RCode:    dns.RcodeToString[msg.Rcode],
+		Duration: durationpb.New(time.Since(start)),
+	}
+	for _, rr := range msg.Answer {
+		e.Answer = append(e.Answer, rr.String())
+	}
+	s.queryLog.add(e)
+}
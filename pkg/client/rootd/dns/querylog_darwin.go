@@ -0,0 +1,8 @@
+package dns
+
+import "net"
+
+// clientProcess returns an empty string, because the process that owns a socket isn't determined on macOS.
+func clientProcess(net.Addr) string {
+	return ""
+}
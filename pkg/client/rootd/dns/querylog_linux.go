@@ -0,0 +1,107 @@
+package dns
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// clientProcess returns the name and ID of the local process that owns the socket with the given address, e.g.
+// "curl[1234]", or an empty string when it can't be determined. The process is found using the inode of the
+// socket in /proc/net, which only lists the sockets of this network namespace.
+func clientProcess(addr net.Addr) string {
+	var ip net.IP
+	var port int
+	var proto string
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		ip, port, proto = a.IP, a.Port, "udp"
+	case *net.TCPAddr:
+		ip, port, proto = a.IP, a.Port, "tcp"
+	default:
+		return ""
+	}
+	for _, table := range []string{proto, proto + "6"} {
+		if inode := socketInode("/proc/net/"+table, ip, port); inode != "" {
+			return socketProcess(inode)
+		}
+	}
+	return ""
+}
+
+// parseProcNetAddr parses an address in the hex format of /proc/net/{udp,tcp}[6], e.g. "0100007F:0035". The
+// IP is a sequence of 32-bit words in host byte order.
+func parseProcNetAddr(s string) (net.IP, int, error) {
+	ipHex, portHex, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid address %q", s)
+	}
+	ip, err := hex.DecodeString(ipHex)
+	if err != nil || (len(ip) != 4 && len(ip) != 16) {
+		return nil, 0, fmt.Errorf("invalid address %q", s)
+	}
+	for i := 0; i < len(ip); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = ip[i+3], ip[i+2], ip[i+1], ip[i]
+	}
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid address %q", s)
+	}
+	return ip, int(port), nil
+}
+
+// socketInode returns the inode of the socket in the given /proc/net table that is bound to the given address.
+func socketInode(table string, ip net.IP, port int) string {
+	f, err := os.Open(table)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Scan() // skip header
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		lip, lport, err := parseProcNetAddr(fields[1])
+		if err != nil || lport != port {
+			continue
+		}
+		if lip.Equal(ip) || lip.IsUnspecified() {
+			return fields[9]
+		}
+	}
+	return ""
+}
+
+// socketProcess returns the name and ID of the process that has a file descriptor for the socket with the given inode.
+func socketProcess(inode string) string {
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return ""
+	}
+	target := "socket:[" + inode + "]"
+	for _, p := range procs {
+		pid := p.Name()
+		if pid[0] < '0' || pid[0] > '9' {
+			continue
+		}
+		fdDir := "/proc/" + pid + "/fd/"
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if link, err := os.Readlink(fdDir + fd.Name()); err == nil && link == target {
+				comm, _ := os.ReadFile("/proc/" + pid + "/comm")
+				return fmt.Sprintf("%s[%s]", strings.TrimSpace(string(comm)), pid)
+			}
+		}
+	}
+	return ""
+}
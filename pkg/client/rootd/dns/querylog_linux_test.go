@@ -0,0 +1,37 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProcNetAddr(t *testing.T) {
+	ip, port, err := parseProcNetAddr("0100007F:0035")
+	require.NoError(t, err)
+	assert.Equal(t, net.IP{127, 0, 0, 1}, ip)
+	assert.Equal(t, 53, port)
+
+	ip, port, err = parseProcNetAddr("00000000000000000000000001000000:1F90")
+	require.NoError(t, err)
+	assert.True(t, ip.Equal(net.IPv6loopback))
+	assert.Equal(t, 8080, port)
+
+	_, _, err = parseProcNetAddr("0100007F")
+	assert.Error(t, err)
+	_, _, err = parseProcNetAddr("01007F:0035")
+	assert.Error(t, err)
+}
+
+func TestClientProcess(t *testing.T) {
+	pc, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+	assert.True(t, strings.HasSuffix(clientProcess(pc.LocalAddr()), fmt.Sprintf("[%d]", os.Getpid())))
+	assert.Empty(t, clientProcess(&net.IPAddr{IP: net.IP{127, 0, 0, 1}}))
+}
@@ -0,0 +1,164 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/dnsproxy"
+	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+)
+
+// testResponseWriter is a dns.ResponseWriter that retains the message that it writes.
+type testResponseWriter struct {
+	dns.ResponseWriter
+	msg *dns.Msg
+}
+
+func (w *testResponseWriter) LocalAddr() net.Addr {
+	return &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 53}
+}
+
+func (w *testResponseWriter) RemoteAddr() net.Addr {
+	return &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 1}
+}
+
+func (w *testResponseWriter) WriteMsg(msg *dns.Msg) error {
+	w.msg = msg
+	return nil
+}
+
+func (w *testResponseWriter) Close() error {
+	return nil
+}
+
+// newQueryLogTestServer returns a server that resolves all names that are routed to the cluster with an A record.
+func newQueryLogTestServer(t *testing.T) *Server {
+	s := NewServer(nil, func(_ context.Context, q *dns.Question) (dnsproxy.RRs, int, error) {
+		return dnsproxy.RRs{&dns.A{
+			Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.IP{10, 0, 0, 1},
+		}}, dns.RcodeSuccess, nil
+	}, false)
+	s.ctx = filelocation.WithAppUserLogDir(dlog.NewTestContext(t, false), t.TempDir())
+	s.resolve = s.resolveInCluster
+	return s
+}
+
+func (s *Server) serveTestQuery(name string) *dns.Msg {
+	r := new(dns.Msg)
+	r.SetQuestion(name, dns.TypeA)
+	w := &testResponseWriter{}
+	s.ServeDNS(w, r)
+	return w.msg
+}
+
+func recentQueries(t *testing.T, s *Server) []*rpc.DNSQueryLogEntry {
+	var es []*rpc.DNSQueryLogEntry
+	require.NoError(t, s.FollowQueryLog(s.ctx, false, func(e *rpc.DNSQueryLogEntry) error {
+		es = append(es, e)
+		return nil
+	}))
+	return es
+}
+
+func TestQueryLog_follow(t *testing.T) {
+	s := newQueryLogTestServer(t)
+
+	// Nothing is recorded while the log is neither enabled nor followed
+	s.serveTestQuery("web.default.")
+	assert.Empty(t, recentQueries(t, s))
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	entries := make(chan *rpc.DNSQueryLogEntry, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.FollowQueryLog(ctx, true, func(e *rpc.DNSQueryLogEntry) error {
+			entries <- e
+			return nil
+		})
+	}()
+	require.Eventually(t, s.queryLog.active.Load, 5*time.Second, time.Millisecond)
+
+	next := func() *rpc.DNSQueryLogEntry {
+		select {
+		case e := <-entries:
+			return e
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timeout waiting for query log entry")
+			return nil
+		}
+	}
+
+	msg := s.serveTestQuery("db.default.")
+	require.Len(t, msg.Answer, 1)
+	e := next()
+	assert.Equal(t, "db.default.", e.Name)
+	assert.Equal(t, "A", e.Type)
+	assert.True(t, strings.HasPrefix(e.Client, "127.0.0.1:1"))
+	assert.True(t, e.Cluster)
+	assert.Equal(t, []string{"db.default."}, e.Lookups)
+	assert.Equal(t, rpc.DNSQueryResponse_CLUSTER, e.Path)
+	assert.Equal(t, "NOERROR", e.RCode)
+	assert.Len(t, e.Answer, 1)
+
+	s.serveTestQuery("db.default.")
+	e = next()
+	assert.Equal(t, rpc.DNSQueryResponse_CACHE, e.Path)
+	assert.Empty(t, e.Lookups)
+
+	s.serveTestQuery("example.com.")
+	e = next()
+	assert.False(t, e.Cluster)
+	assert.Empty(t, e.Lookups)
+	assert.Equal(t, "NXDOMAIN", e.RCode)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.False(t, s.queryLog.active.Load())
+	assert.Len(t, recentQueries(t, s), 3)
+}
+
+func TestQueryLog_file(t *testing.T) {
+	s := newQueryLogTestServer(t)
+	require.NoError(t, s.SetQueryLog(s.ctx, true))
+	assert.True(t, s.queryLog.active.Load())
+	s.serveTestQuery("db.default.")
+	require.NoError(t, s.SetQueryLog(s.ctx, false))
+	assert.False(t, s.queryLog.active.Load())
+
+	dir, err := filelocation.AppUserLogDir(s.ctx)
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, queryLogFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), " A     db.default. -> NOERROR cluster ")
+	assert.Contains(t, string(data), " lookups=db.default. answer=1\n")
+}
+
+func TestQueryLog_ring(t *testing.T) {
+	s := newQueryLogTestServer(t)
+	for i := 0; i < queryLogSize+10; i++ {
+		s.queryLog.add(&rpc.DNSQueryLogEntry{
+			Time:     timestamppb.Now(),
+			Name:     fmt.Sprintf("%d.", i),
+			Duration: durationpb.New(0),
+		})
+	}
+	es := recentQueries(t, s)
+	require.Len(t, es, queryLogSize)
+	assert.Equal(t, "10.", es[0].Name)
+	assert.Equal(t, fmt.Sprintf("%d.", queryLogSize+9), es[queryLogSize-1].Name)
+}
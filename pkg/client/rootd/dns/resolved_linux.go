@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/datawire/dlib/dcontext"
@@ -24,6 +25,7 @@ func (s *Server) tryResolveD(c context.Context, dev vif.Device, configureDNS fun
 
 	c, cancelResolveD := context.WithCancel(c)
 	defer cancelResolveD()
+	defer s.domainRegistry.release(s)
 
 	listeners, err := s.dnsListeners(c)
 	if err != nil {
@@ -36,6 +38,7 @@ func (s *Server) tryResolveD(c context.Context, dev vif.Device, configureDNS fun
 	}
 	dnsIP := net.IP(s.config.RemoteIp)
 	configureDNS(dnsIP, dnsResolverAddr)
+	atomic.StoreInt32(&s.mode, int32(ModeResolved))
 
 	g := dgroup.NewGroup(c, dgroup.GroupConfig{})
 
@@ -102,19 +105,37 @@ func (s *Server) tryResolveD(c context.Context, dev vif.Device, configureDNS fun
 func (s *Server) updateLinkDomains(c context.Context, paths []string, dev vif.Device) error {
 	namespaces := make(map[string]struct{})
 	search := make([]string, 0)
-	for i, path := range paths {
+	var ownRoutes, routes []string
+	for _, path := range paths {
 		if strings.ContainsRune(path, '.') {
 			search = append(search, path)
 		} else {
 			namespaces[path] = struct{}{}
-			// Turn namespace into a route
-			paths[i] = "~" + path
+			// Turn namespace into a route. The tel2SubDomain must be routed to the link of every session,
+			// see the comment about the SanityCheck in tryResolveD.
+			if path == tel2SubDomain {
+				ownRoutes = append(ownRoutes, path)
+			} else {
+				routes = append(routes, path)
+			}
 		}
 	}
 	for _, sfx := range s.config.IncludeSuffixes {
-		paths = append(paths, "~"+strings.TrimPrefix(sfx, "."))
+		routes = append(routes, strings.TrimPrefix(sfx, "."))
+	}
+	routes = append(routes, s.clusterDomain)
+	if s.contextDomain != "" {
+		routes = append(routes, s.contextDomain)
+	}
+	routes = append(routes, s.proxyHostDomains()...)
+	routes = append(routes, s.mappingDomains()...)
+
+	// The routes are shared by all links, so routes that are already routed to the link of another session
+	// are left out.
+	paths = append([]string{}, search...)
+	for _, route := range append(ownRoutes, s.claimDomains(c, routes)...) {
+		paths = append(paths, "~"+route)
 	}
-	paths = append(paths, "~"+s.clusterDomain)
 
 	s.domainsLock.Lock()
 	s.namespaces = namespaces
@@ -48,6 +48,32 @@ const (
 	recursionTestInProgress
 )
 
+// Mode tells how the server is hooked into the DNS system of the host.
+type Mode int32
+
+const (
+	ModeUnknown Mode = iota
+	ModeResolved
+	ModeOverriding
+	ModeResolverFiles
+	ModeInterface
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeResolved:
+		return "systemd-resolved"
+	case ModeOverriding:
+		return "overriding"
+	case ModeResolverFiles:
+		return "resolver files"
+	case ModeInterface:
+		return "interface DNS"
+	default:
+		return "unknown"
+	}
+}
+
 // Server is a DNS server which implements the github.com/miekg/dns Handler interface.
 type Server struct {
 	ctx          context.Context // necessary to make logging work in ServeDNS function
@@ -55,8 +81,14 @@ type Server struct {
 	resolve      Resolver
 	requestCount int64
 	cache        sync.Map
+	cacheSize    int64 // approximate number of entries in the cache
+	cacheStats   cacheStats
+	cacheConfig  atomic.Pointer[client.DNSCache]
+	evicting     atomic.Bool
+	queryLog     queryLog
 	recursive    int32 // one of the recursionXXX constants declared above (unique type avoided because it just gets messy with the atomic calls)
-	cacheResolve func(*dns.Question) (dnsproxy.RRs, int, error)
+	mode         int32 // one of the ModeXXX constants declared above
+	cacheResolve func(context.Context, *dns.Question) (dnsproxy.RRs, int, error)
 
 	// Namespaces, accessible using <service-name>.<namespace-name>
 	namespaces map[string]struct{}
@@ -69,11 +101,36 @@ type Server struct {
 	// searchPathCh receives requests to change the search path.
 	searchPathCh chan []string
 
+	// mappings are names that are resolved locally, keyed by lower case names with a trailing dot.
+	mappings     map[string]mapping
+	mappingsLock sync.RWMutex
+
+	// mappingsCh receives a notification when the mappings change, so that their names are routed to this server.
+	mappingsCh chan struct{}
+
+	// domainRegistry, when not nil, is shared with the DNS servers of the sessions for other clusters and
+	// ensures that the domains routed to this server don't overlap theirs.
+	domainRegistry *DomainRegistry
+
+	// domainsCh receives a notification when another server has released domains, so that the domains that
+	// this server was denied earlier can be claimed.
+	domainsCh chan struct{}
+
 	config *rpc.DNSConfig
 
 	// clusterDomain reported by the traffic-manager
 	clusterDomain string
 
+	// contextDomain, when set, is a domain that identifies the cluster, so that "<svc>.<ns>.<contextDomain>"
+	// is resolved as "<svc>.<ns>" in that cluster. Used when connected to several clusters.
+	contextDomain string
+
+	// alsoProxyHosts are host name patterns for names that are resolved in the cluster, and whose addresses
+	// are reported to onProxyHostsResolved, unless they also match the neverProxyHosts.
+	alsoProxyHosts       []string
+	neverProxyHosts      []string
+	onProxyHostsResolved ProxyHostsResolved
+
 	// Function that sends a lookup request to the traffic-manager
 	clusterLookup Resolver
 
@@ -86,21 +143,22 @@ type Server struct {
 
 	// Whether the TUN-device is proxying cluster CIDRs
 	proxyCluster bool
+
+	// servesTCP is set when the first listener given to Run is also served over TCP
+	servesTCP atomic.Bool
 }
 
 type cacheEntry struct {
 	created      time.Time
-	currentQType int32 // will be set to the current qType during call to cluster
+	ttl          time.Duration // time to live in the local DNS cache, set when the wait channel is closed
+	currentQType int32         // will be set to the current qType during call to cluster
 	answer       dnsproxy.RRs
 	rCode        int
 	wait         chan struct{}
 }
 
-// cacheTTL is the time to live for an entry in the local DNS cache.
-const cacheTTL = 60 * time.Second
-
 func (dv *cacheEntry) expired() bool {
-	return time.Since(dv.created) > cacheTTL
+	return time.Since(dv.created) > dv.ttl
 }
 
 // NewServer returns a new dns.Server.
@@ -126,11 +184,15 @@ func NewServer(config *rpc.DNSConfig, clusterLookup Resolver, onlyNames bool) *S
 		domains:       make(map[string]struct{}),
 		search:        []string{""},
 		searchPathCh:  make(chan []string, 5),
+		mappingsCh:    make(chan struct{}, 1),
+		domainsCh:     make(chan struct{}, 1),
 		clusterDomain: defaultClusterDomain,
 		clusterLookup: clusterLookup,
 		onlyNames:     onlyNames,
 		ready:         make(chan error, 2),
 	}
+	dc := client.GetDefaultConfig().DNS.Cache
+	s.cacheConfig.Store(&dc)
 	s.cacheResolve = s.resolveWithRecursionCheck
 	return s
 }
@@ -170,6 +232,12 @@ func (s *Server) shouldDoClusterLookup(query string) bool {
 
 	query = query[:len(query)-1] // skip last dot
 
+	if matchHosts(s.alsoProxyHosts, query) {
+		// A never-proxy host that matches an also-proxy host is routed to this server. It is resolved
+		// locally when possible, and in the cluster otherwise, but never routed to the cluster.
+		return !matchHosts(s.neverProxyHosts, query) || s.fallbackPool == nil
+	}
+
 	// Always include configured includeSuffixes
 	for _, sfx := range s.config.IncludeSuffixes {
 		if strings.HasSuffix(query, sfx) {
@@ -190,6 +258,14 @@ func (s *Server) resolveInCluster(c context.Context, q *dns.Question) (result dn
 	origQuery := q.Name
 	query := strings.ToLower(origQuery)
 	query = strings.TrimSuffix(query, tel2SubDomainDot)
+	if s.contextDomain != "" {
+		if query == s.contextDomain+"." {
+			return nil, dns.RcodeNameError, nil
+		}
+		if strings.HasSuffix(query, "."+s.contextDomain+".") {
+			query = strings.TrimSuffix(query, s.contextDomain+".")
+		}
+	}
 	q.Name = query
 
 	if query == "localhost." {
@@ -217,6 +293,7 @@ func (s *Server) resolveInCluster(c context.Context, q *dns.Question) (result dn
 	if !s.shouldDoClusterLookup(query) {
 		return nil, dns.RcodeNameError, nil
 	}
+	addQueryLookup(c, query)
 
 	// Give the cluster lookup a reasonable timeout.
 	c, cancel := context.WithTimeout(c, s.config.LookupTimeout.AsDuration())
@@ -226,15 +303,12 @@ func (s *Server) resolveInCluster(c context.Context, q *dns.Question) (result dn
 	if err != nil {
 		return nil, rCode, client.CheckTimeout(c, err)
 	}
-	// Keep the TTLs of requests resolved in the cluster low. We
-	// cache them locally anyway, but our cache is flushed when things are
-	// intercepted or the namespaces change.
+	if s.onProxyHostsResolved != nil && s.isProxyHost(query[:len(query)-1]) {
+		s.proxyHostsResolved(c, origQuery, result)
+	}
 	for _, rr := range result {
-		if h := rr.Header(); h != nil {
-			if h.Name == query {
-				h.Name = origQuery
-			}
-			h.Ttl = dnsTTL
+		if h := rr.Header(); h != nil && h.Name == query {
+			h.Name = origQuery
 		}
 	}
 	return result, rCode, nil
@@ -248,9 +322,15 @@ func (s *Server) GetConfig() *rpc.DNSConfig {
 		dnsConfig.IncludeSuffixes = s.config.IncludeSuffixes
 		dnsConfig.LookupTimeout = s.config.LookupTimeout
 	}
+	dnsConfig.Mappings = s.getMappings()
 	return dnsConfig
 }
 
+// Mode returns the mode that the server uses to hook into the DNS system of the host.
+func (s *Server) Mode() Mode {
+	return Mode(atomic.LoadInt32(&s.mode))
+}
+
 func (s *Server) Ready() <-chan error {
 	return s.ready
 }
@@ -292,6 +372,33 @@ func (s *Server) SetClusterDNS(dns *manager.DNS, remoteIP net.IP) {
 	s.config.IncludeSuffixes = appendUnique(s.config.IncludeSuffixes, dns.IncludeSuffixes)
 }
 
+// SetContextDomain sets the domain that identifies the cluster, so that "<svc>.<ns>.<domain>" is resolved
+// as "<svc>.<ns>" in that cluster. It must be called before the server is started.
+func (s *Server) SetContextDomain(domain string) {
+	s.contextDomain = domain
+}
+
+// SetDomainRegistry sets the registry that is shared with the DNS servers of the sessions for other
+// clusters. It must be called before the server is started.
+func (s *Server) SetDomainRegistry(r *DomainRegistry) {
+	s.domainRegistry = r
+}
+
+// claimDomains returns the given domains, minus those that overlap the domains routed to the DNS server of
+// another session. A warning is logged for the domains that are left out.
+func (s *Server) claimDomains(c context.Context, domains []string) []string {
+	claimed, rejected := s.domainRegistry.claim(s, domains)
+	if len(rejected) > 0 {
+		if s.contextDomain != "" {
+			dlog.Warnf(c, "DNS domains %v are routed to the cluster of another connection; names in them can be resolved in this cluster using the %q suffix",
+				rejected, s.contextDomain)
+		} else {
+			dlog.Warnf(c, "DNS domains %v are routed to the cluster of another connection", rejected)
+		}
+	}
+	return claimed
+}
+
 // SetSearchPath updates the DNS search path used by the resolver.
 func (s *Server) SetSearchPath(ctx context.Context, paths, namespaces []string) {
 	if len(namespaces) > 0 {
@@ -330,6 +437,13 @@ func (s *Server) processSearchPaths(g *dgroup.Group, processor func(context.Cont
 			return true
 		}
 
+		// rerun runs the processor again with the previous paths. It may modify the paths, so it gets a copy.
+		rerun := func(c context.Context) error {
+			paths := make([]string, len(prevPaths))
+			copy(paths, prevPaths)
+			return processor(c, paths, dev)
+		}
+
 		for {
 			select {
 			case <-c.Done():
@@ -347,6 +461,16 @@ func (s *Server) processSearchPaths(g *dgroup.Group, processor func(context.Cont
 						return err
 					}
 				}
+			case <-s.mappingsCh:
+				// The mapped names are routed to this server, so the processor must run again.
+				if err := rerun(c); err != nil {
+					return err
+				}
+			case <-s.domainsCh:
+				// Another server released domains that this server might want.
+				if err := rerun(c); err != nil {
+					return err
+				}
 			}
 		}
 	})
@@ -354,7 +478,7 @@ func (s *Server) processSearchPaths(g *dgroup.Group, processor func(context.Cont
 
 func (s *Server) flushDNS() {
 	s.cache.Range(func(key, _ any) bool {
-		s.cache.Delete(key)
+		s.deleteCacheEntry(key.(cacheKey))
 		return true
 	})
 }
@@ -395,7 +519,7 @@ type cacheKey struct {
 // resolveThruCache resolves the given query by first performing a cache lookup. If a cached
 // entry is found that hasn't expired, it's returned. If not, this function will call
 // resolveQuery() to resolve and store in the case.
-func (s *Server) resolveThruCache(q *dns.Question) (dnsproxy.RRs, int, error) {
+func (s *Server) resolveThruCache(ctx context.Context, q *dns.Question) (dnsproxy.RRs, int, error) {
 	newDv := &cacheEntry{wait: make(chan struct{}), created: time.Now()}
 	key := cacheKey{name: q.Name, qType: q.Qtype}
 	if v, loaded := s.cache.LoadOrStore(key, newDv); loaded {
@@ -406,17 +530,24 @@ func (s *Server) resolveThruCache(q *dns.Question) (dnsproxy.RRs, int, error) {
 		}
 		<-oldDv.wait
 		if !oldDv.expired() {
+			atomic.AddInt64(&s.cacheStats.hits, 1)
+			if qt := getQueryTrace(ctx); qt != nil {
+				qt.cached = true
+			}
 			return copyRRs(oldDv.answer, q.Qtype), oldDv.rCode, nil
 		}
 		s.cache.Store(key, newDv)
+	} else {
+		s.cacheEntryAdded()
 	}
-	return s.resolveQuery(q, newDv)
+	atomic.AddInt64(&s.cacheStats.misses, 1)
+	return s.resolveQuery(ctx, q, newDv)
 }
 
 // resolveWithRecursionCheck is a special version of resolveThruCache which is only used until the
 // recursionCheck query has completed, and it has been determined whether a query that is propagated
 // to the cluster will recurse back to this resolver or not.
-func (s *Server) resolveWithRecursionCheck(q *dns.Question) (dnsproxy.RRs, int, error) {
+func (s *Server) resolveWithRecursionCheck(ctx context.Context, q *dns.Question) (dnsproxy.RRs, int, error) {
 	newDv := &cacheEntry{wait: make(chan struct{}), created: time.Now()}
 	key := cacheKey{name: q.Name, qType: q.Qtype}
 	if v, loaded := s.cache.LoadOrStore(key, newDv); loaded {
@@ -431,12 +562,19 @@ func (s *Server) resolveWithRecursionCheck(q *dns.Question) (dnsproxy.RRs, int,
 		}
 		<-oldDv.wait
 		if !oldDv.expired() {
+			atomic.AddInt64(&s.cacheStats.hits, 1)
+			if qt := getQueryTrace(ctx); qt != nil {
+				qt.cached = true
+			}
 			return copyRRs(oldDv.answer, q.Qtype), oldDv.rCode, nil
 		}
 		s.cache.Store(key, newDv)
+	} else {
+		s.cacheEntryAdded()
 	}
+	atomic.AddInt64(&s.cacheStats.misses, 1)
 
-	answer, rCode, err := s.resolveQuery(q, newDv)
+	answer, rCode, err := s.resolveQuery(ctx, q, newDv)
 	if q.Name == recursionCheck {
 		if atomic.LoadInt32(&s.recursive) == recursionDetected {
 			dlog.Debug(s.ctx, "DNS resolver is recursive")
@@ -513,32 +651,73 @@ func (s *Server) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 
 	atomic.AddInt64(&s.requestCount, 1)
 
-	var err error
-	var rCode int
-	var answer dnsproxy.RRs
-
-	var pfx dfs = func() string { return "" }
-	var txt dfs = func() string { return "" }
-	var rct dfs = func() string { return dns.RcodeToString[rCode] }
+	// The query is traced when it is logged
+	qc := c
+	var qt *queryTrace
+	var client <-chan string
+	name, qType, start := q.Name, q.Qtype, time.Now()
+	if s.queryLog.active.Load() {
+		qt = &queryTrace{}
+		qc = withQueryTrace(c, qt)
+		client = queryClient(w.RemoteAddr())
+	}
+
+	_, tcp := w.LocalAddr().(*net.TCPAddr)
+	msg, path, txt := s.serveDNS(qc, r, tcp)
+	fitResponse(r, msg, tcp)
+	if qt != nil {
+		s.logQuery(start, <-client, name, qType, qt, path, msg)
+	}
+
+	var pfx dfs = func() string {
+		switch path {
+		case rpc.DNSQueryResponse_MAPPING:
+			return "(mapping) "
+		case rpc.DNSQueryResponse_FALLBACK:
+			return fmt.Sprintf("(%s) ", s.fallbackPool.RemoteAddr())
+		default:
+			return ""
+		}
+	}
+	var rct dfs = func() string { return dns.RcodeToString[msg.Rcode] }
+	tc := ""
+	if msg.Truncated {
+		tc = " (truncated)"
+	}
+	dlog.Debugf(c, "%s%5d %-6s %s -> %s %s%s", pfx, r.Id, qts, q.Name, rct, txt, tc)
+	_ = w.WriteMsg(msg)
+}
 
-	var msg *dns.Msg
+// serveDNS creates the response to the given request. It also returns the path that the resolution took, and a
+// description of the answer that is suitable for logging.
+func (s *Server) serveDNS(c context.Context, r *dns.Msg, tcp bool) (msg *dns.Msg, path rpc.DNSQueryResponse_Path, txt dfs) {
+	q := &r.Question[0]
+	txt = func() string { return "" }
 
-	defer func() {
-		dlog.Debugf(c, "%s%5d %-6s %s -> %s %s", pfx, r.Id, qts, q.Name, rct, txt)
-		_ = w.WriteMsg(msg)
-	}()
+	// Mapped names are never dispatched to the fallback DNS-server.
+	if answer, rCode, ok := s.resolveMapping(c, q); ok {
+		msg = new(dns.Msg)
+		msg.SetRcode(r, rCode)
+		msg.Answer = answer
+		msg.Authoritative = true
+		msg.RecursionAvailable = true
+		return msg, rpc.DNSQueryResponse_MAPPING, func() string { return answer.String() }
+	}
 
+	var err error
+	var rCode int
+	var answer dnsproxy.RRs
 	if s.onlyNames {
 		switch q.Qtype {
 		case dns.TypeA:
-			answer, rCode, err = s.cacheResolve(q)
+			answer, rCode, err = s.cacheResolve(c, q)
 		case dns.TypeAAAA:
 			if atomic.LoadInt32(&s.recursive) == recursionDetected || q.Name == recursionCheck {
 				rCode = dns.RcodeNameError
 				break
 			}
 			q.Qtype = dns.TypeA
-			answer, rCode, err = s.cacheResolve(q)
+			answer, rCode, err = s.cacheResolve(c, q)
 			q.Qtype = dns.TypeAAAA
 			if rCode == dns.RcodeSuccess {
 				// return EMPTY to indicate that dns.TypeA exists
@@ -547,15 +726,15 @@ func (s *Server) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		default:
 			msg = new(dns.Msg)
 			msg.SetRcode(r, dns.RcodeNotImplemented)
-			return
+			return msg, rpc.DNSQueryResponse_NONE, txt
 		}
 	} else {
 		if !dnsproxy.SupportedType(q.Qtype) {
 			msg = new(dns.Msg)
 			msg.SetRcode(r, dns.RcodeNotImplemented)
-			return
+			return msg, rpc.DNSQueryResponse_NONE, txt
 		}
-		answer, rCode, err = s.cacheResolve(q)
+		answer, rCode, err = s.cacheResolve(c, q)
 	}
 
 	if err == nil && rCode == dns.RcodeSuccess {
@@ -568,8 +747,7 @@ func (s *Server) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		// single dns server, this will prevent us
 		// from intercepting all queries
 		msg.RecursionAvailable = true
-		txt = func() string { return answer.String() }
-		return
+		return msg, rpc.DNSQueryResponse_CLUSTER, func() string { return answer.String() }
 	}
 
 	// The recursion check query, or queries that end with the cluster domain name, are not dispatched to the
@@ -587,12 +765,21 @@ func (s *Server) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		}
 		msg = new(dns.Msg)
 		msg.SetRcode(r, rCode)
-		return
+		return msg, rpc.DNSQueryResponse_CLUSTER, txt
 	}
 
-	pfx = func() string { return fmt.Sprintf("(%s) ", s.fallbackPool.RemoteAddr()) }
 	dc := &dns.Client{Net: "udp", Timeout: s.config.LookupTimeout.AsDuration()}
+	if tcp {
+		dc.Net = "tcp"
+	}
+	start := time.Now()
 	msg, _, err = s.fallbackPool.Exchange(c, dc, r)
+	if err == nil && msg.Truncated && !tcp {
+		// The truncated answer might still fit in what the client accepts when it's retrieved over TCP.
+		dc.Net = "tcp"
+		msg, _, err = s.fallbackPool.Exchange(c, dc, r)
+	}
+	s.cacheStats.fallbackLookup(time.Since(start))
 	if err != nil {
 		msg = new(dns.Msg)
 		rCode = dns.RcodeServerFailure
@@ -608,26 +795,51 @@ func (s *Server) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		}
 		msg.SetRcode(r, rCode)
 	} else {
-		rCode = msg.Rcode
 		txt = func() string { return dnsproxy.RRs(msg.Answer).String() }
 	}
+	return msg, rpc.DNSQueryResponse_FALLBACK, txt
 }
 
 // dnsTTL is the number of seconds that a found DNS record should be allowed to live in the callers cache. We
-// keep this low to avoid such caching.
+// keep this low to avoid such caching. The answers are cached locally anyway, and the local cache is flushed
+// when things are intercepted or the namespaces change.
 const dnsTTL = 4
 
-func (s *Server) resolveQuery(q *dns.Question, dv *cacheEntry) (dnsproxy.RRs, int, error) {
+func (s *Server) resolveQuery(ctx context.Context, q *dns.Question, dv *cacheEntry) (dnsproxy.RRs, int, error) {
 	atomic.StoreInt32(&dv.currentQType, int32(q.Qtype))
 	defer func() {
 		atomic.StoreInt32(&dv.currentQType, int32(dns.TypeNone))
 		close(dv.wait)
 	}()
 
+	// The lookup uses the server's context, because its result is shared with other queries that wait for it.
+	rc := s.ctx
+	if qt := getQueryTrace(ctx); qt != nil {
+		rc = withQueryTrace(rc, qt)
+	}
 	var err error
-	dv.answer, dv.rCode, err = s.resolve(s.ctx, q)
-	if err != nil || dv.rCode != dns.RcodeSuccess {
-		s.cache.Delete(cacheKey{name: q.Name, qType: q.Qtype}) // Don't cache unless the lookup succeeded.
+	start := time.Now()
+	dv.answer, dv.rCode, err = s.resolve(rc, q)
+	s.cacheStats.clusterLookup(time.Since(start))
+
+	cc := s.getCacheConfig()
+	switch {
+	case err == nil && dv.rCode == dns.RcodeSuccess:
+		dv.ttl = cc.TTL(minTTL(dv.answer))
+		for _, rr := range dv.answer {
+			if h := rr.Header(); h != nil {
+				h.Ttl = dnsTTL
+			}
+		}
+	case err == nil && dv.rCode == dns.RcodeNameError && cc.NegativeTTL > 0 && !strings.HasPrefix(q.Name, recursionCheck):
+		dv.ttl = cc.NegativeTTL
+		dv.answer = nil
+		return nil, dv.rCode, nil
+	default:
+		// Don't cache unless the lookup succeeded. The result is still shared with concurrent queries that
+		// wait for this entry.
+		dv.ttl = cc.MinTTL
+		s.deleteCacheEntry(cacheKey{name: q.Name, qType: q.Qtype})
 		return nil, dv.rCode, err
 	}
 
@@ -645,9 +857,8 @@ func (s *Server) Run(c context.Context, initDone chan<- struct{}, listeners []ne
 	s.proxyCluster = proxyCluster
 
 	g := dgroup.NewGroup(c, dgroup.GroupConfig{})
-	for _, listener := range listeners {
-		srv := &dns.Server{PacketConn: listener, Handler: s, ReadTimeout: time.Second}
-		g.Go(listener.LocalAddr().String(), func(c context.Context) error {
+	serve := func(name string, srv *dns.Server) {
+		g.Go(name, func(c context.Context) error {
 			go func() {
 				<-c.Done()
 				dlog.Debugf(c, "Shutting down DNS server")
@@ -656,6 +867,42 @@ func (s *Server) Run(c context.Context, initDone chan<- struct{}, listeners []ne
 			return srv.ActivateAndServe()
 		})
 	}
+	for i, listener := range listeners {
+		addr := listener.LocalAddr().String()
+		serve(addr, &dns.Server{PacketConn: listener, Handler: s, ReadTimeout: time.Second, UDPSize: dns.DefaultMsgSize})
+
+		// Clients retry over TCP when a response is truncated, so TCP is served on the same address.
+		tl, err := net.Listen("tcp", addr)
+		if err != nil {
+			dlog.Warnf(c, "Unable to serve DNS over TCP on %s: %v", addr, err)
+			continue
+		}
+		if i == 0 {
+			s.servesTCP.Store(true)
+		}
+		serve("tcp/"+addr, &dns.Server{Listener: tl, Handler: s, ReadTimeout: time.Second})
+	}
 	close(initDone)
 	return g.Wait()
 }
+
+// maxResponseSize returns the max size of a response to the given request. Over UDP, that's the UDP size of
+// the request's EDNS0 option, or the minimum DNS message size when the request has no such option.
+func maxResponseSize(r *dns.Msg, tcp bool) int {
+	if tcp {
+		return dns.MaxMsgSize
+	}
+	if opt := r.IsEdns0(); opt != nil && opt.UDPSize() > dns.MinMsgSize {
+		return int(opt.UDPSize())
+	}
+	return dns.MinMsgSize
+}
+
+// fitResponse adds an EDNS0 option to the response when the request has one, and truncates the response, setting
+// its TC bit, when it doesn't fit in the size that the client accepts.
+func fitResponse(r, msg *dns.Msg, tcp bool) {
+	if opt := r.IsEdns0(); opt != nil && msg.IsEdns0() == nil {
+		msg.SetEdns0(dns.DefaultMsgSize, opt.Do())
+	}
+	msg.Truncate(maxResponseSize(r, tcp))
+}
@@ -8,8 +8,10 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/datawire/dlib/dgroup"
 	"github.com/datawire/dlib/dlog"
@@ -133,6 +135,10 @@ func (r *resolveFile) setSearchPaths(paths ...string) {
 func (s *Server) Worker(c context.Context, dev vif.Device, proxyCluster bool, configureDNS func(net.IP, *net.UDPAddr)) error {
 	resolverDirName := filepath.Join("/etc", "resolver")
 	resolverFileName := filepath.Join(resolverDirName, "telepresence.local")
+	if s.contextDomain != "" {
+		// Each session has its own main resolver file when connected to several clusters.
+		resolverFileName = filepath.Join(resolverDirName, "telepresence-"+s.contextDomain+".local")
+	}
 
 	listener, err := newLocalUDPListener(c)
 	if err != nil {
@@ -143,28 +149,29 @@ func (s *Server) Worker(c context.Context, dev vif.Device, proxyCluster bool, co
 		return err
 	}
 	configureDNS(nil, dnsAddr)
+	atomic.StoreInt32(&s.mode, int32(ModeResolverFiles))
 
 	err = os.MkdirAll(resolverDirName, 0o755)
 	if err != nil {
 		return err
 	}
 
-	// Ensure lingering all telepresence.* files are removed.
-	if err := s.removeResolverFiles(c, resolverDirName); err != nil {
-		return err
+	// Ensure lingering all telepresence.* files are removed, unless they belong to the session of another cluster.
+	if !s.domainRegistry.hasOthers(s) {
+		if err := s.removeResolverFiles(c, resolverDirName); err != nil {
+			return err
+		}
 	}
 
-	kubernetesZone := s.clusterDomain
-	if kubernetesZone == "" {
-		kubernetesZone = "cluster.local."
-	}
-	kubernetesZone = kubernetesZone[:len(kubernetesZone)-1] // strip trailing dot
+	// Released after the resolver files have been removed, so that they don't remove files of other sessions.
+	defer s.domainRegistry.release(s)
+
 	rf := resolveFile{
 		port:        dnsAddr.Port,
-		domain:      kubernetesZone,
+		domain:      s.resolverDomain(s.claimDomains(c, []string{s.kubernetesZone()})),
 		nameservers: []net.IP{dnsAddr.IP},
-		search:      []string{kubernetesZone},
 	}
+	rf.search = []string{rf.domain}
 	if err = rf.write(resolverFileName); err != nil {
 		return err
 	}
@@ -200,7 +207,7 @@ func (s *Server) removeResolverFiles(c context.Context, resolverDirName string)
 		return err
 	}
 	for _, file := range files {
-		if n := file.Name(); strings.HasPrefix(n, "telepresence.") {
+		if n := file.Name(); strings.HasPrefix(n, "telepresence.") || strings.HasPrefix(n, "telepresence-") {
 			fn := filepath.Join(resolverDirName, n)
 			dlog.Debugf(c, "Removing file %q", fn)
 			if err := os.Remove(fn); err != nil {
@@ -235,6 +242,31 @@ func (s *Server) updateResolverFiles(c context.Context, resolverDirName, resolve
 	for _, sfx := range s.config.IncludeSuffixes {
 		domains[strings.TrimPrefix(sfx, ".")] = struct{}{}
 	}
+	if s.contextDomain != "" {
+		domains[s.contextDomain] = struct{}{}
+	}
+	for _, d := range s.proxyHostDomains() {
+		domains[d] = struct{}{}
+	}
+	for _, d := range s.mappingDomains() {
+		domains[d] = struct{}{}
+	}
+
+	// The /etc/resolver directory is shared by all sessions, so domains that are already routed to the DNS
+	// server of another session are left out. The kubernetes zone is routed using the main resolver file.
+	zone := s.kubernetesZone()
+	desired := make([]string, 0, len(domains)+1)
+	for d := range domains {
+		desired = append(desired, d)
+	}
+	sort.Strings(desired)
+	claimed := s.claimDomains(c, append(desired, zone))
+	domains = make(map[string]struct{}, len(claimed))
+	for _, d := range claimed {
+		domains[d] = struct{}{}
+	}
+	delete(domains, zone)
+	rf.domain = s.resolverDomain(claimed)
 
 	s.domainsLock.Lock()
 	defer s.domainsLock.Unlock()
@@ -292,6 +324,30 @@ func (s *Server) updateResolverFiles(c context.Context, resolverDirName, resolve
 	return nil
 }
 
+// kubernetesZone returns the cluster domain without the trailing dot.
+func (s *Server) kubernetesZone() string {
+	kubernetesZone := s.clusterDomain
+	if kubernetesZone == "" {
+		kubernetesZone = "cluster.local."
+	}
+	return kubernetesZone[:len(kubernetesZone)-1] // strip trailing dot
+}
+
+// resolverDomain returns the domain of the main resolver file. It is the kubernetes zone, unless that zone
+// is routed to the DNS server of another session, in which case it's the context domain.
+func (s *Server) resolverDomain(claimed []string) string {
+	zone := s.kubernetesZone()
+	if s.contextDomain == "" {
+		return zone
+	}
+	for _, d := range claimed {
+		if d == zone {
+			return zone
+		}
+	}
+	return s.contextDomain
+}
+
 func domainResolverFile(resolverDirName, domain string) string {
 	return filepath.Join(resolverDirName, "telepresence."+domain+".local")
 }
@@ -9,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/miekg/dns"
@@ -65,11 +66,11 @@ func (s *Server) shouldApplySearch(query string) bool {
 		}
 	}
 
-	// Don't apply search path to namespaces or "svc".
+	// Don't apply search path to namespaces, "svc", or the context domain.
 	query = query[:len(query)-1]
 	if lastDot := strings.LastIndexByte(query, '.'); lastDot >= 0 {
 		tld := query[lastDot+1:]
-		if _, ok := s.namespaces[tld]; ok || tld == "svc" {
+		if _, ok := s.namespaces[tld]; ok || tld == "svc" || tld == s.contextDomain {
 			return false
 		}
 	}
@@ -105,6 +106,7 @@ func (s *Server) resolveInSearch(c context.Context, q *dns.Question) (dnsproxy.R
 }
 
 func (s *Server) runOverridingServer(c context.Context, dev vif.Device, proxyCluster bool) error {
+	atomic.StoreInt32(&s.mode, int32(ModeOverriding))
 	if s.config.LocalIp == nil {
 		dat, err := os.ReadFile("/etc/resolv.conf")
 		if err != nil {
@@ -212,7 +214,7 @@ func (s *Server) runOverridingServer(c context.Context, dev vif.Device, proxyClu
 			// Give DNS server time to start before rerouting NAT
 			dtime.SleepWithContext(c, time.Millisecond)
 
-			err := routeDNS(c, s.config.LocalIp, dnsResolverAddr, pool.LocalAddrs())
+			err := routeDNS(c, s.config.LocalIp, dnsResolverAddr, pool.LocalAddrs(), s.servesTCP.Load())
 			if err != nil {
 				return err
 			}
@@ -323,11 +325,16 @@ func runNatTableCmd(c context.Context, args ...string) error {
 
 const tpDNSChain = "TELEPRESENCE_DNS"
 
+// fallbackMark is the mark of the TCP connections that the local DNS server uses to reach the original DNS
+// server. Unlike the UDP connections of the ConnPool, they don't have a fixed source address.
+const fallbackMark = 0x7e1
+
 // routeDNS creates a new chain in the "nat" table with two rules in it. One rule ensures
 // that all packets sent to the currently configured DNS service are rerouted to our local
 // DNS service. Another rule ensures that when our local DNS service cannot resolve and
-// uses a fallback, that fallback reaches the original DNS service.
-func routeDNS(c context.Context, dnsIP net.IP, toAddr *net.UDPAddr, localDNSs []*net.UDPAddr) (err error) {
+// uses a fallback, that fallback reaches the original DNS service. When tcp is true, the
+// same rules are added for TCP.
+func routeDNS(c context.Context, dnsIP net.IP, toAddr *net.UDPAddr, localDNSs []*net.UDPAddr, tcp bool) (err error) {
 	// create the chain
 	unrouteDNS(c)
 
@@ -358,6 +365,24 @@ func routeDNS(c context.Context, dnsIP net.IP, toAddr *net.UDPAddr, localDNSs []
 	); err != nil {
 		return err
 	}
+	if tcp {
+		if err = runNatTableCmd(c, "-A", tpDNSChain,
+			"-p", "tcp",
+			"-m", "mark", "--mark", strconv.Itoa(fallbackMark),
+			"-j", "RETURN",
+		); err != nil {
+			return err
+		}
+		if err = runNatTableCmd(c, "-A", tpDNSChain,
+			"-p", "tcp",
+			"--dest", dnsIP.String()+"/32",
+			"--dport", "53",
+			"-j", "DNAT",
+			"--to-destination", toAddr.String(),
+		); err != nil {
+			return err
+		}
+	}
 
 	// Alter locally generated packets before routing
 	return runNatTableCmd(c, "-I", "OUTPUT", "1", "-j", tpDNSChain)
@@ -0,0 +1,86 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/dnsproxy"
+)
+
+// manyRecords resolves A queries with 100 records, which is more than a 512 byte message can hold.
+func manyRecords(_ context.Context, q *dns.Question) (dnsproxy.RRs, int, error) {
+	if q.Qtype != dns.TypeA {
+		return nil, dns.RcodeSuccess, nil
+	}
+	rrs := make(dnsproxy.RRs, 100)
+	for i := range rrs {
+		rrs[i] = &dns.A{Hdr: dnsproxy.NewHeader(q.Name, q.Qtype), A: net.IP{10, 0, 1, byte(i)}}
+	}
+	return rrs, dns.RcodeSuccess, nil
+}
+
+func TestServeDNS_largeResponses(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	listener, err := newLocalUDPListener(ctx)
+	require.NoError(t, err)
+	addr := listener.LocalAddr().String()
+	s := NewServer(nil, nil, false)
+	initDone := make(chan struct{})
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		_ = s.Run(ctx, initDone, []net.PacketConn{listener}, nil, manyRecords, true)
+	}()
+	defer func() {
+		cancel()
+		<-runDone
+	}()
+	<-initDone
+	require.True(t, s.servesTCP.Load())
+
+	exchange := func(network string, udpSize uint16) *dns.Msg {
+		t.Helper()
+		m := new(dns.Msg)
+		m.SetQuestion("headless.default.", dns.TypeA)
+		if udpSize > 0 {
+			m.SetEdns0(udpSize, false)
+		}
+		dc := &dns.Client{Net: network, Timeout: 2 * time.Second}
+		var r *dns.Msg
+		require.Eventually(t, func() bool {
+			r, _, err = dc.Exchange(m, addr)
+			return err == nil
+		}, 5*time.Second, 50*time.Millisecond)
+		return r
+	}
+
+	// Without EDNS0, the response is truncated to 512 bytes
+	r := exchange("udp", 0)
+	assert.True(t, r.Truncated)
+	assert.NotEmpty(t, r.Answer)
+	assert.Less(t, len(r.Answer), 100)
+	assert.Nil(t, r.IsEdns0())
+
+	// The EDNS0 UDP size of the request is honoured
+	r = exchange("udp", 4096)
+	assert.False(t, r.Truncated)
+	assert.Len(t, r.Answer, 100)
+	require.NotNil(t, r.IsEdns0())
+
+	r = exchange("udp", 1024)
+	assert.True(t, r.Truncated)
+	assert.Greater(t, len(r.Answer), 50)
+	assert.Less(t, len(r.Answer), 100)
+
+	// TCP is served on the same address
+	r = exchange("tcp", 0)
+	assert.False(t, r.Truncated)
+	assert.Len(t, r.Answer, 100)
+}
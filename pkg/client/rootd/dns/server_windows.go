@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync/atomic"
 
 	"github.com/datawire/dlib/dgroup"
 	"github.com/telepresenceio/telepresence/v2/pkg/vif"
@@ -20,6 +21,7 @@ func (s *Server) Worker(c context.Context, dev vif.Device, proxyCluster bool, co
 		return err
 	}
 	configureDNS(s.config.RemoteIp, dnsAddr)
+	atomic.StoreInt32(&s.mode, int32(ModeInterface))
 
 	// Start local DNS server
 	g := dgroup.NewGroup(c, dgroup.GroupConfig{})
@@ -0,0 +1,250 @@
+package rootd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/rootd/dns"
+	"github.com/telepresenceio/telepresence/v2/pkg/vif/routing"
+)
+
+// dnsLookupTimeout is the max time that the DNS lookup performed by the diagnosis may take.
+const dnsLookupTimeout = 5 * time.Second
+
+func okCheck(name, format string, args ...any) *rpc.DiagnosticCheck {
+	return &rpc.DiagnosticCheck{Name: name, Status: rpc.DiagnosticCheck_OK, Message: fmt.Sprintf(format, args...)}
+}
+
+func skippedCheck(name, format string, args ...any) *rpc.DiagnosticCheck {
+	return &rpc.DiagnosticCheck{Name: name, Status: rpc.DiagnosticCheck_SKIPPED, Message: fmt.Sprintf(format, args...)}
+}
+
+func failedCheck(name, hint, format string, args ...any) *rpc.DiagnosticCheck {
+	return &rpc.DiagnosticCheck{Name: name, Status: rpc.DiagnosticCheck_FAILED, Message: fmt.Sprintf(format, args...), Hint: hint}
+}
+
+func warningCheck(name, hint, format string, args ...any) *rpc.DiagnosticCheck {
+	return &rpc.DiagnosticCheck{Name: name, Status: rpc.DiagnosticCheck_WARNING, Message: fmt.Sprintf(format, args...), Hint: hint}
+}
+
+// diagnose checks the TUN device, its routes, the DNS configuration, and the connectivity to the cluster.
+func (s *Session) diagnose(ctx context.Context, dr *rpc.DiagnoseRequest) *rpc.Diagnosis {
+	var checks []*rpc.DiagnosticCheck
+	if s.dev == nil {
+		checks = append(checks, failedCheck("tun-device",
+			"Check the root daemon's log (daemon.log) for errors that occurred when the device was created.",
+			"no virtual network interface has been created"))
+		return &rpc.Diagnosis{Checks: checks}
+	}
+	devName := s.dev.Name()
+	checks = append(checks, okCheck("tun-device", "%s (index %d), MTU %d", devName, s.dev.Index(), s.dev.MTU()))
+
+	table, err := routing.GetRoutingTable(ctx)
+	if err != nil {
+		checks = append(checks, failedCheck("routes", "", "unable to get the routing table: %v", err))
+	} else {
+		checks = append(checks, checkRoutes(devName, s.curSubnets, table)...)
+	}
+
+	s.clusterInfoLock.Lock()
+	info := s.clusterInfo
+	s.clusterInfoLock.Unlock()
+
+	checks = append(checks, s.checkDNSConfig(devName), s.checkDNSLookup(ctx, info))
+	checks = append(checks, s.checkServiceReachable(ctx, info), s.checkPodReachable(ctx, info))
+	if table != nil {
+		checks = append(checks, checkAgents(devName, dr.AgentIps, table, s.proxyClusterPods)...)
+	}
+	return &rpc.Diagnosis{Checks: checks}
+}
+
+// mostSpecificRoute returns the route in the given table that has the longest prefix that contains the given IP,
+// or nil when no such route exists.
+func mostSpecificRoute(table []*routing.Route, ip net.IP) *routing.Route {
+	var best *routing.Route
+	bestOnes := -1
+	for _, rt := range table {
+		if rt.RoutedNet == nil || !rt.Routes(ip) {
+			continue
+		}
+		if ones, _ := rt.RoutedNet.Mask.Size(); ones > bestOnes {
+			best, bestOnes = rt, ones
+		}
+	}
+	return best
+}
+
+func ifaceName(rt *routing.Route) string {
+	if rt.Interface == nil {
+		return "unknown interface"
+	}
+	return rt.Interface.Name
+}
+
+// checkRoutes verifies that the given subnets are routed to the TUN device with the given name, and that no part
+// of them is routed elsewhere.
+func checkRoutes(devName string, subnets []*net.IPNet, table []*routing.Route) []*rpc.DiagnosticCheck {
+	const name = "routes"
+	const vpnHint = "Another interface, often a VPN, routes this subnet. Run \"telepresence test-vpn\", or add the " +
+		"conflicting subnet to the never-proxy subnets in the kubeconfig extension."
+	if len(subnets) == 0 {
+		return []*rpc.DiagnosticCheck{skippedCheck(name, "no subnets are routed to %s", devName)}
+	}
+	var checks []*rpc.DiagnosticCheck
+	for _, sn := range subnets {
+		rt := mostSpecificRoute(table, sn.IP)
+		switch {
+		case rt == nil:
+			checks = append(checks, failedCheck(name,
+				"The route may have been removed by another program. Reconnect using \"telepresence quit\" and \"telepresence connect\".",
+				"subnet %s is not routed", sn))
+			continue
+		case ifaceName(rt) != devName:
+			checks = append(checks, failedCheck(name, vpnHint, "subnet %s is routed to %s by %s", sn, ifaceName(rt), rt.RoutedNet))
+			continue
+		}
+		snOnes, _ := sn.Mask.Size()
+		for _, ort := range table {
+			if ort.RoutedNet == nil || ifaceName(ort) == devName || !sn.Contains(ort.RoutedNet.IP) {
+				continue
+			}
+			if ones, _ := ort.RoutedNet.Mask.Size(); ones > snOnes {
+				checks = append(checks, warningCheck(name, vpnHint, "%s, a part of subnet %s, is routed to %s", ort.RoutedNet, sn, ifaceName(ort)))
+			}
+		}
+	}
+	if len(checks) == 0 {
+		sns := make([]string, len(subnets))
+		for i, sn := range subnets {
+			sns[i] = sn.String()
+		}
+		checks = append(checks, okCheck(name, "%s routed to %s", strings.Join(sns, ", "), devName))
+	}
+	return checks
+}
+
+// checkAgents verifies that the pod IPs of the given agents are routed, either to the TUN device or, when pods
+// are not proxied, by another interface.
+func checkAgents(devName string, agentIPs map[string][]byte, table []*routing.Route, proxyPods bool) []*rpc.DiagnosticCheck {
+	if len(agentIPs) == 0 {
+		return []*rpc.DiagnosticCheck{skippedCheck("agents", "no traffic-agents found in the connected namespaces")}
+	}
+	names := make([]string, 0, len(agentIPs))
+	for n := range agentIPs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	checks := make([]*rpc.DiagnosticCheck, len(names))
+	for i, n := range names {
+		name := "agent " + n
+		ip := net.IP(agentIPs[n])
+		rt := mostSpecificRoute(table, ip)
+		switch {
+		case rt == nil || rt.Default && proxyPods:
+			checks[i] = failedCheck(name, "Add a subnet that contains the pod IP to the also-proxy subnets in the kubeconfig extension.",
+				"pod IP %s is not routed to the cluster", ip)
+		case ifaceName(rt) == devName:
+			checks[i] = okCheck(name, "pod IP %s is routed to %s", ip, devName)
+		case proxyPods:
+			checks[i] = warningCheck(name, "Another interface, often a VPN, routes the pod IP. Run \"telepresence test-vpn\".",
+				"pod IP %s is routed to %s", ip, ifaceName(rt))
+		default:
+			checks[i] = okCheck(name, "pod IP %s is routed to %s, which already reaches the cluster", ip, ifaceName(rt))
+		}
+	}
+	return checks
+}
+
+func (s *Session) checkDNSConfig(devName string) *rpc.DiagnosticCheck {
+	const name = "dns-config"
+	if s.dnsLocalAddr == nil {
+		return failedCheck(name, "Check the root daemon's log (daemon.log) for errors that occurred when the DNS server was started.",
+			"the local DNS server has not been started")
+	}
+	cfg := s.dnsServer.GetConfig()
+	var suffixes string
+	if len(cfg.IncludeSuffixes) > 0 {
+		suffixes = fmt.Sprintf(", include suffixes %v", cfg.IncludeSuffixes)
+	}
+	if len(cfg.ExcludeSuffixes) > 0 {
+		suffixes += fmt.Sprintf(", exclude suffixes %v", cfg.ExcludeSuffixes)
+	}
+	mode := s.dnsServer.Mode()
+	switch mode {
+	case dns.ModeResolved:
+		return okCheck(name, "%s: link %s uses DNS server %s%s", mode, devName, s.remoteDnsIP, suffixes)
+	case dns.ModeOverriding:
+		return okCheck(name, "%s: local server %s replaces the DNS servers in /etc/resolv.conf%s", mode, s.dnsLocalAddr, suffixes)
+	case dns.ModeResolverFiles:
+		return okCheck(name, "%s: files in /etc/resolver direct the cluster domains to %s%s", mode, s.dnsLocalAddr, suffixes)
+	case dns.ModeInterface:
+		return okCheck(name, "%s: interface %s uses DNS server %s%s", mode, devName, s.dnsLocalAddr, suffixes)
+	default:
+		return failedCheck(name, "Check the root daemon's log (daemon.log) for errors that occurred when the DNS was configured.",
+			"the DNS server has not been hooked into the DNS system of this host")
+	}
+}
+
+// checkDNSLookup looks up the kubernetes service using the resolver of the host, which ensures that the DNS
+// server is reachable from the host, and that it resolves names in the cluster.
+func (s *Session) checkDNSLookup(ctx context.Context, info *manager.ClusterInfo) *rpc.DiagnosticCheck {
+	const name = "dns-lookup"
+	clusterDomain := "cluster.local"
+	if info != nil && info.Dns != nil && info.Dns.ClusterDomain != "" {
+		clusterDomain = strings.TrimSuffix(info.Dns.ClusterDomain, ".")
+	}
+	host := "kubernetes.default.svc." + clusterDomain
+	ctx, cancel := context.WithTimeout(ctx, dnsLookupTimeout)
+	defer cancel()
+	start := time.Now()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return failedCheck(name, "Verify the dns-config check, and run \"telepresence loglevel debug\" to see the lookups in daemon.log. "+
+			"A VPN or a security program may intercept the DNS traffic.",
+			"lookup of %s failed: %v", host, err)
+	}
+	return okCheck(name, "%s resolved to %s in %s", host, strings.Join(addrs, ", "), time.Since(start).Round(time.Millisecond))
+}
+
+func connectivityCheckTimeout(ctx context.Context) time.Duration {
+	return client.GetConfig(ctx).Timeouts.Get(client.TimeoutConnectivityCheck)
+}
+
+func (s *Session) checkServiceReachable(ctx context.Context, info *manager.ClusterInfo) *rpc.DiagnosticCheck {
+	const name = "service-dial"
+	switch {
+	case info == nil || info.InjectorSvcIp == nil:
+		return skippedCheck(name, "the traffic-manager didn't report the IP of the agent-injector service")
+	case connectivityCheckTimeout(ctx) == 0:
+		return skippedCheck(name, "the connectivity check is disabled by timeouts.connectivityCheck")
+	}
+	ip := net.IP(info.InjectorSvcIp)
+	if err := probeService(ctx, info, connectivityCheckTimeout(ctx)); err != nil {
+		return failedCheck(name, "Verify the routes check. A firewall or a VPN may block the traffic to the service subnet.",
+			"no valid response from the agent-injector service at %s: %v", ip, err)
+	}
+	return okCheck(name, "the agent-injector service at %s responded", ip)
+}
+
+func (s *Session) checkPodReachable(ctx context.Context, info *manager.ClusterInfo) *rpc.DiagnosticCheck {
+	const name = "pod-dial"
+	switch {
+	case info == nil || info.ManagerPodIp == nil:
+		return skippedCheck(name, "the traffic-manager didn't report its pod IP")
+	case connectivityCheckTimeout(ctx) == 0:
+		return skippedCheck(name, "the connectivity check is disabled by timeouts.connectivityCheck")
+	}
+	ip := net.IP(info.ManagerPodIp)
+	if err := probePod(ctx, info, connectivityCheckTimeout(ctx)); err != nil {
+		return failedCheck(name, "Verify the routes check. A firewall or a VPN may block the traffic to the pod subnet.",
+			"no valid response from the traffic-manager pod at %s: %v", ip, err)
+	}
+	return okCheck(name, "the traffic-manager pod at %s responded", ip)
+}
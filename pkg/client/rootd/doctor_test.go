@@ -0,0 +1,117 @@
+package rootd
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/vif/routing"
+)
+
+func testRoute(cidr, iface string) *routing.Route {
+	_, rn, _ := net.ParseCIDR(cidr)
+	ones, _ := rn.Mask.Size()
+	return &routing.Route{RoutedNet: rn, Interface: &net.Interface{Name: iface}, Default: ones == 0}
+}
+
+func TestCheckRoutes(t *testing.T) {
+	table := []*routing.Route{
+		testRoute("0.0.0.0/0", "eth0"),
+		testRoute("10.96.0.0/16", "tel0"),
+		testRoute("10.244.0.0/16", "tel0"),
+		testRoute("10.244.8.0/24", "utun3"),
+		testRoute("10.100.0.0/16", "utun3"),
+	}
+
+	checks := checkRoutes("tel0", cidrs("10.96.0.0/16"), table)
+	require.Len(t, checks, 1)
+	assert.Equal(t, rpc.DiagnosticCheck_OK, checks[0].Status)
+
+	checks = checkRoutes("tel0", cidrs("10.244.0.0/16"), table)
+	require.Len(t, checks, 1)
+	assert.Equal(t, rpc.DiagnosticCheck_WARNING, checks[0].Status)
+	assert.Contains(t, checks[0].Message, "10.244.8.0/24")
+
+	checks = checkRoutes("tel0", cidrs("10.100.0.0/16"), table)
+	require.Len(t, checks, 1)
+	assert.Equal(t, rpc.DiagnosticCheck_FAILED, checks[0].Status)
+	assert.NotEmpty(t, checks[0].Hint)
+
+	checks = checkRoutes("tel0", nil, table)
+	require.Len(t, checks, 1)
+	assert.Equal(t, rpc.DiagnosticCheck_SKIPPED, checks[0].Status)
+}
+
+func TestCheckAgents(t *testing.T) {
+	table := []*routing.Route{
+		testRoute("0.0.0.0/0", "eth0"),
+		testRoute("10.244.0.0/16", "tel0"),
+		testRoute("10.100.0.0/16", "utun3"),
+	}
+	agentIPs := map[string][]byte{
+		"echo.default": net.ParseIP("10.244.1.2").To4(),
+		"vpn.default":  net.ParseIP("10.100.1.2").To4(),
+		"lost.default": net.ParseIP("192.168.1.2").To4(),
+	}
+
+	checks := checkAgents("tel0", agentIPs, table, true)
+	require.Len(t, checks, 3)
+	assert.Equal(t, "agent echo.default", checks[0].Name)
+	assert.Equal(t, rpc.DiagnosticCheck_OK, checks[0].Status)
+	assert.Equal(t, "agent lost.default", checks[1].Name)
+	assert.Equal(t, rpc.DiagnosticCheck_FAILED, checks[1].Status)
+	assert.Equal(t, "agent vpn.default", checks[2].Name)
+	assert.Equal(t, rpc.DiagnosticCheck_WARNING, checks[2].Status)
+
+	// When pods aren't proxied, the routes of other interfaces are expected
+	checks = checkAgents("tel0", agentIPs, table, false)
+	assert.Equal(t, rpc.DiagnosticCheck_OK, checks[2].Status)
+
+	checks = checkAgents("tel0", nil, table, true)
+	require.Len(t, checks, 1)
+	assert.Equal(t, rpc.DiagnosticCheck_SKIPPED, checks[0].Status)
+}
+
+func TestCheckServiceReachable(t *testing.T) {
+	healthy := true
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+
+	logBuf := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(logBuf)
+	logger.SetLevel(logrus.DebugLevel)
+	ctx := dlog.WithLogger(context.Background(), dlog.WrapLogrus(logger))
+	cfg := client.GetDefaultConfig()
+	ctx = client.WithConfig(ctx, &cfg)
+
+	s := &Session{}
+	info := &manager.ClusterInfo{InjectorSvcIp: addr.IP, InjectorSvcPort: int32(addr.Port), InjectorSvcHost: "agent-injector"}
+	check := s.checkServiceReachable(ctx, info)
+	assert.Equal(t, rpc.DiagnosticCheck_OK, check.Status, check.Message)
+
+	healthy = false
+	check = s.checkServiceReachable(ctx, info)
+	assert.Equal(t, rpc.DiagnosticCheck_FAILED, check.Status)
+	assert.Contains(t, check.Message, "status code 503")
+
+	// The diagnosis doesn't decide whether subnets are mapped, so it mustn't log that decision.
+	assert.NotContains(t, logBuf.String(), "will not map")
+	assert.NotContains(t, logBuf.String(), "Will proxy")
+}
@@ -2,6 +2,7 @@ package rootd
 
 import (
 	"context"
+	"io"
 
 	"github.com/blang/semver"
 	"google.golang.org/grpc"
@@ -57,6 +58,7 @@ func (rd *InProcSession) Status(ctx context.Context, in *empty.Empty, opts ...gr
 			Name:       client.DisplayName,
 		},
 		OutboundConfig: rd.getNetworkConfig().OutboundInfo,
+		ChaosRules:     rd.getChaosRules(),
 	}, nil
 }
 
@@ -78,6 +80,114 @@ func (rd *InProcSession) GetNetworkConfig(ctx context.Context, in *empty.Empty,
 	return rd.getNetworkConfig(), nil
 }
 
+func (rd *InProcSession) GetConnections(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*rpc.Connections, error) {
+	return rd.getConnections(), nil
+}
+
+// inProcCaptureClient is the in-process rpc.Daemon_CaptureClient. Only the methods that a consumer of the
+// stream needs are implemented.
+type inProcCaptureClient struct {
+	grpc.ClientStream
+	ctx     context.Context
+	packets <-chan *rpc.CapturedPacket
+	errCh   <-chan error
+}
+
+func (c *inProcCaptureClient) Context() context.Context {
+	return c.ctx
+}
+
+func (c *inProcCaptureClient) Recv() (*rpc.CapturedPacket, error) {
+	if p, ok := <-c.packets; ok {
+		return p, nil
+	}
+	if err := <-c.errCh; err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (rd *InProcSession) Capture(ctx context.Context, in *rpc.CaptureRequest, opts ...grpc.CallOption) (rpc.Daemon_CaptureClient, error) {
+	packets := make(chan *rpc.CapturedPacket)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(packets)
+		errCh <- rd.capture(ctx, in, func(p *rpc.CapturedPacket) error {
+			select {
+			case packets <- p:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return &inProcCaptureClient{ctx: ctx, packets: packets, errCh: errCh}, nil
+}
+
+func (rd *InProcSession) AddChaosRule(ctx context.Context, in *rpc.ChaosRule, opts ...grpc.CallOption) (*rpc.ChaosRule, error) {
+	return rd.addChaosRule(in)
+}
+
+func (rd *InProcSession) RemoveChaosRule(ctx context.Context, in *rpc.RemoveChaosRuleRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return &empty.Empty{}, rd.removeChaosRule(in)
+}
+
+func (rd *InProcSession) GetChaosRules(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*rpc.ChaosRules, error) {
+	return &rpc.ChaosRules{Rules: rd.getChaosRules()}, nil
+}
+
+func (rd *InProcSession) Diagnose(ctx context.Context, in *rpc.DiagnoseRequest, opts ...grpc.CallOption) (*rpc.Diagnosis, error) {
+	return rd.diagnose(ctx, in), nil
+}
+
+func (rd *InProcSession) GetDNSCache(ctx context.Context, in *rpc.DNSCacheRequest, opts ...grpc.CallOption) (*rpc.DNSCache, error) {
+	return rd.getDNSCache(in), nil
+}
+
+func (rd *InProcSession) QueryDNS(ctx context.Context, in *rpc.DNSQueryRequest, opts ...grpc.CallOption) (*rpc.DNSQueryResponse, error) {
+	return rd.queryDNS(ctx, in)
+}
+
+// inProcDNSQueryLogClient is the in-process rpc.Daemon_GetDNSQueryLogClient. Only the methods that a consumer of
+// the stream needs are implemented.
+type inProcDNSQueryLogClient struct {
+	grpc.ClientStream
+	ctx     context.Context
+	entries <-chan *rpc.DNSQueryLogEntry
+	errCh   <-chan error
+}
+
+func (c *inProcDNSQueryLogClient) Context() context.Context {
+	return c.ctx
+}
+
+func (c *inProcDNSQueryLogClient) Recv() (*rpc.DNSQueryLogEntry, error) {
+	if e, ok := <-c.entries; ok {
+		return e, nil
+	}
+	if err := <-c.errCh; err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (rd *InProcSession) GetDNSQueryLog(ctx context.Context, in *rpc.DNSQueryLogRequest, opts ...grpc.CallOption) (rpc.Daemon_GetDNSQueryLogClient, error) {
+	entries := make(chan *rpc.DNSQueryLogEntry)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(entries)
+		errCh <- rd.dnsServer.FollowQueryLog(ctx, in.Follow, func(e *rpc.DNSQueryLogEntry) error {
+			select {
+			case entries <- e:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return &inProcDNSQueryLogClient{ctx: ctx, entries: entries, errCh: errCh}, nil
+}
+
 func (rd *InProcSession) SetDnsSearchPath(ctx context.Context, paths *rpc.Paths, opts ...grpc.CallOption) (*empty.Empty, error) {
 	rd.SetSearchPath(ctx, paths.Paths, paths.Namespaces)
 	return &empty.Empty{}, nil
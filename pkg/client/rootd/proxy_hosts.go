@@ -0,0 +1,86 @@
+package rootd
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+// hostRouteCheckInterval is the interval between checks for expired host routes.
+const hostRouteCheckInterval = 10 * time.Second
+
+// hostRoutes keeps track of the single address routes that are added to the TUN-device for the addresses
+// that also-proxy hosts resolve to. A route is removed when the TTL of the last DNS answer that contained
+// its address expires.
+type hostRoutes struct {
+	sync.Mutex
+	expires map[iputil.IPKey]time.Time
+}
+
+func hostSubnet(ip net.IP) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// proxyHostsResolved adds routes for the given addresses, or extends the lifetime of the existing ones.
+func (s *Session) proxyHostsResolved(ctx context.Context, name string, ips []net.IP, ttl time.Duration) {
+	hr := &s.hostRoutes
+	hr.Lock()
+	defer hr.Unlock()
+	if hr.expires == nil {
+		hr.expires = make(map[iputil.IPKey]time.Time)
+	}
+	expires := time.Now().Add(ttl)
+	for _, ip := range ips {
+		k := iputil.IPKey(ip)
+		if _, ok := hr.expires[k]; !ok {
+			if err := s.dev.AddSubnet(ctx, hostSubnet(ip)); err != nil {
+				dlog.Errorf(ctx, "failed to add route for %s (%s): %v", name, ip, err)
+				continue
+			}
+			dlog.Infof(ctx, "Routing %s (%s) to the cluster", name, ip)
+		}
+		if expires.After(hr.expires[k]) {
+			hr.expires[k] = expires
+		}
+	}
+}
+
+// expireHostRoutes removes the routes that have expired.
+func (s *Session) expireHostRoutes(ctx context.Context, now time.Time) {
+	hr := &s.hostRoutes
+	hr.Lock()
+	defer hr.Unlock()
+	for k, expires := range hr.expires {
+		if now.Before(expires) {
+			continue
+		}
+		delete(hr.expires, k)
+		ip := k.IP()
+		if err := s.dev.RemoveSubnet(ctx, hostSubnet(ip)); err != nil {
+			dlog.Errorf(ctx, "failed to remove route for %s: %v", ip, err)
+			continue
+		}
+		dlog.Debugf(ctx, "Route for %s expired", ip)
+	}
+}
+
+// hostRoutesWorker removes expired host routes until the context is cancelled.
+func (s *Session) hostRoutesWorker(ctx context.Context) error {
+	ticker := time.NewTicker(hostRouteCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			s.expireHostRoutes(ctx, now)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package rootd
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+const (
+	// resolvedNamesTTL is how long a name is remembered after the IP was last returned from a lookup.
+	resolvedNamesTTL = time.Hour
+
+	// resolvedNamesMaxEntries is the max number of names that are remembered.
+	resolvedNamesMaxEntries = 4096
+)
+
+type resolvedName struct {
+	name    string
+	expires time.Time
+	seq     uint64
+}
+
+// resolvedNames maps IPs returned from cluster DNS lookups to the names that were looked up. Names are
+// forgotten when they haven't been returned for resolvedNamesTTL, and the oldest names are evicted when
+// there are more than resolvedNamesMaxEntries.
+type resolvedNames struct {
+	sync.Mutex
+	names map[iputil.IPKey]resolvedName
+	seq   uint64
+}
+
+func (r *resolvedNames) store(ip net.IP, name string) {
+	now := time.Now()
+	r.Lock()
+	defer r.Unlock()
+	if r.names == nil {
+		r.names = make(map[iputil.IPKey]resolvedName)
+	}
+	r.seq++
+	r.names[iputil.IPKey(ip)] = resolvedName{name: name, expires: now.Add(resolvedNamesTTL), seq: r.seq}
+	if len(r.names) > resolvedNamesMaxEntries {
+		r.evict(now)
+	}
+}
+
+func (r *resolvedNames) load(ip net.IP) string {
+	r.Lock()
+	defer r.Unlock()
+	if rn, ok := r.names[iputil.IPKey(ip)]; ok && time.Now().Before(rn.expires) {
+		return rn.name
+	}
+	return ""
+}
+
+// evict removes the expired names, and then the oldest names until the map is 90% full. The caller must
+// hold the lock.
+func (r *resolvedNames) evict(now time.Time) {
+	for k, rn := range r.names {
+		if !now.Before(rn.expires) {
+			delete(r.names, k)
+		}
+	}
+	target := resolvedNamesMaxEntries * 9 / 10
+	if len(r.names) <= target {
+		return
+	}
+	keys := make([]iputil.IPKey, 0, len(r.names))
+	for k := range r.names {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return r.names[keys[i]].seq < r.names[keys[j]].seq })
+	for _, k := range keys[:len(keys)-target] {
+		delete(r.names, k)
+	}
+}
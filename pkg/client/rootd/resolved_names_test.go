@@ -0,0 +1,31 @@
+package rootd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+func TestResolvedNames(t *testing.T) {
+	r := resolvedNames{}
+	ip := func(i int) net.IP { return net.IPv4(10, 0, byte(i>>8), byte(i)) }
+
+	r.store(ip(1), "api.default")
+	assert.Equal(t, "api.default", r.load(ip(1)))
+	assert.Equal(t, "", r.load(ip(2)))
+
+	// Expired names are forgotten
+	r.names[iputil.IPKey(ip(1))] = resolvedName{name: "api.default", expires: time.Now().Add(-time.Second)}
+	assert.Equal(t, "", r.load(ip(1)))
+
+	// The map never grows beyond its max size, and the most recently stored names are retained
+	for i := 0; i <= resolvedNamesMaxEntries; i++ {
+		r.store(ip(i), "svc.default")
+	}
+	assert.LessOrEqual(t, len(r.names), resolvedNamesMaxEntries)
+	assert.Equal(t, "svc.default", r.load(ip(resolvedNamesMaxEntries)))
+}
@@ -17,10 +17,21 @@ func (s *Session) isForDNS(ip net.IP, port uint16) bool {
 	return s.remoteDnsIP != nil && port == 53 && s.remoteDnsIP.Equal(ip)
 }
 
-func (s *Session) streamCreator() tunnel.StreamCreator {
+// natStreamCreator returns the streamCreator, wrapped so that it translates virtual IPs when the session uses a NAT.
+func (s *Session) natStreamCreator(ctx context.Context) tunnel.StreamCreator {
+	if s.nat == nil {
+		return s.streamCreator(ctx)
+	}
+	return s.nat.StreamCreator(s.streamCreator(ctx))
+}
+
+func (s *Session) streamCreator(ctx context.Context) tunnel.StreamCreator {
+	mc := tunnel.NewMuxClient(ctx, func(c context.Context) (tunnel.GRPClientCStream, error) {
+		return s.managerClient.Tunnel(c)
+	}, tunnel.DefaultMaxMuxStreams)
 	return func(c context.Context, id tunnel.ConnID) (tunnel.Stream, error) {
 		p := id.Protocol()
-		if p == ipproto.UDP && s.isForDNS(id.Destination(), id.DestinationPort()) {
+		if (p == ipproto.UDP || p == ipproto.TCP) && s.isForDNS(id.Destination(), id.DestinationPort()) {
 			pipeId := tunnel.NewConnID(p, id.Source(), s.dnsLocalAddr.IP, id.SourcePort(), uint16(s.dnsLocalAddr.Port))
 			dlog.Tracef(c, "Intercept DNS %s to %s", id, pipeId.DestinationAddr())
 			from, to := tunnel.NewPipe(pipeId, s.session.SessionId)
@@ -28,11 +39,9 @@ func (s *Session) streamCreator() tunnel.StreamCreator {
 			return from, nil
 		}
 		dlog.Debugf(c, "Opening tunnel for id %s", id)
-		ct, err := s.managerClient.Tunnel(c)
-		if err != nil {
-			return nil, err
-		}
-		tc := client.GetConfig(c).Timeouts
-		return tunnel.NewClientStream(c, ct, id, s.session.SessionId, tc.Get(client.TimeoutRoundtripLatency), tc.Get(client.TimeoutEndpointDial))
+		cfg := client.GetConfig(c)
+		tc := cfg.Timeouts
+		c = tunnel.WithCompression(c, cfg.Tunnel.Compression)
+		return mc.NewStream(c, id, s.session.SessionId, tc.Get(client.TimeoutRoundtripLatency), tc.Get(client.TimeoutEndpointDial))
 	}
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/telepresenceio/telepresence/rpc/v2/manager"
 	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/logging"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/rootd/dns"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/socket"
 	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
@@ -76,18 +77,35 @@ type sessionReply struct {
 	err    error
 }
 
+// sessionEntry is a session together with the context that it runs in and the function that cancels it.
+type sessionEntry struct {
+	session  *Session
+	ctx      context.Context
+	cancel   context.CancelFunc
+	quitting int32 // atomic boolean. True if non-zero.
+}
+
 // Service represents the state of the Telepresence Daemon.
 type Service struct {
 	rpc.UnsafeDaemonServer
-	quit            context.CancelFunc
-	connectCh       chan *rpc.OutboundInfo
-	connectReplyCh  chan sessionReply
-	sessionLock     sync.RWMutex
-	sessionCancel   context.CancelFunc
-	sessionContext  context.Context
-	sessionQuitting int32 // atomic boolean. True if non-zero.
-	session         *Session
-	timedLogLevel   log.TimedLevel
+	quit           context.CancelFunc
+	connectCh      chan *rpc.OutboundInfo
+	connectReplyCh chan sessionReply
+	sessionLock    sync.RWMutex
+
+	// sessions contains the active sessions, keyed by the name of their Kubernetes context.
+	sessions map[string]*sessionEntry
+
+	// currentSession is the name of the Kubernetes context of the session that calls that don't
+	// specify a context apply to. It is the session that was connected last.
+	currentSession string
+	timedLogLevel  log.TimedLevel
+
+	// subnets ensures that the sessions don't route overlapping subnets.
+	subnets subnetRegistry
+
+	// domains ensures that the sessions don't route overlapping DNS domains.
+	domains dns.DomainRegistry
 
 	scout *scout.Reporter
 }
@@ -98,6 +116,7 @@ func NewService(sr *scout.Reporter, cfg *client.Config) *Service {
 		timedLogLevel:  log.NewTimedLevel(cfg.LogLevels.RootDaemon.String(), log.SetLevel),
 		connectCh:      make(chan *rpc.OutboundInfo),
 		connectReplyCh: make(chan sessionReply),
+		sessions:       make(map[string]*sessionEntry),
 	}
 }
 
@@ -132,7 +151,7 @@ func (s *Service) Version(_ context.Context, _ *empty.Empty) (*common.VersionInf
 	}, nil
 }
 
-func (s *Service) Status(_ context.Context, _ *empty.Empty) (*rpc.DaemonStatus, error) {
+func (s *Service) Status(ctx context.Context, _ *empty.Empty) (*rpc.DaemonStatus, error) {
 	s.sessionLock.RLock()
 	defer s.sessionLock.RUnlock()
 	r := &rpc.DaemonStatus{
@@ -142,8 +161,9 @@ func (s *Service) Status(_ context.Context, _ *empty.Empty) (*rpc.DaemonStatus,
 			Name:       client.DisplayName,
 		},
 	}
-	if s.session != nil {
-		r.OutboundConfig = s.session.getNetworkConfig().OutboundInfo
+	if se := s.sessionFor(ctx); se != nil {
+		r.OutboundConfig = se.session.getNetworkConfig().OutboundInfo
+		r.ChaosRules = se.session.getChaosRules()
 	}
 	return r, nil
 }
@@ -152,13 +172,13 @@ func (s *Service) Quit(ctx context.Context, _ *empty.Empty) (*empty.Empty, error
 	dlog.Debug(ctx, "Received gRPC Quit")
 	s.sessionLock.RLock()
 	defer s.sessionLock.RUnlock()
-	s.cancelSessionReadLocked()
+	s.cancelSessionReadLocked("")
 	s.quit()
 	return &empty.Empty{}, nil
 }
 
 func (s *Service) SetDnsSearchPath(ctx context.Context, paths *rpc.Paths) (*empty.Empty, error) {
-	err := s.WithSession(func(ctx context.Context, session *Session) error {
+	err := s.WithSession(ctx, func(ctx context.Context, session *Session) error {
 		session.SetSearchPath(ctx, paths.Paths, paths.Namespaces)
 		return nil
 	})
@@ -182,12 +202,13 @@ func (s *Service) Connect(ctx context.Context, info *rpc.OutboundInfo) (*rpc.Dae
 
 func (s *Service) Disconnect(ctx context.Context, _ *empty.Empty) (*empty.Empty, error) {
 	dlog.Debug(ctx, "Received gRPC Disconnect")
-	s.cancelSession()
+	// Disconnect the session of the given context, or all sessions if no context is given.
+	s.cancelSession(client.GetKubeContext(ctx))
 	return &empty.Empty{}, nil
 }
 
 func (s *Service) WaitForNetwork(ctx context.Context, e *empty.Empty) (*empty.Empty, error) {
-	err := s.WithSession(func(ctx context.Context, session *Session) error {
+	err := s.WithSession(ctx, func(ctx context.Context, session *Session) error {
 		if err, ok := <-session.networkReady(ctx); ok {
 			return status.Error(codes.Unavailable, err.Error())
 		}
@@ -196,47 +217,208 @@ func (s *Service) WaitForNetwork(ctx context.Context, e *empty.Empty) (*empty.Em
 	return &empty.Empty{}, err
 }
 
-func (s *Service) cancelSessionReadLocked() {
-	if s.sessionCancel != nil {
-		s.sessionCancel()
+// sessionFor returns the session that a call with the given context applies to, i.e. the session of the
+// Kubernetes context found in the metadata of the call, or the current session if the call doesn't specify
+// a context. It returns nil if no such session exists. Must be called with the sessionLock held.
+func (s *Service) sessionFor(ctx context.Context) *sessionEntry {
+	name := client.GetKubeContext(ctx)
+	if name == "" {
+		name = s.currentSession
 	}
+	return s.sessions[name]
 }
 
-func (s *Service) cancelSession() {
-	if !atomic.CompareAndSwapInt32(&s.sessionQuitting, 0, 1) {
+// removeSessionLocked removes the given session unless it has already been replaced, and ensures that the
+// current session, if any remain, is one of the remaining sessions. Must be called with the sessionLock held.
+func (s *Service) removeSessionLocked(name string, se *sessionEntry) {
+	if s.sessions[name] != se {
 		return
 	}
+	delete(s.sessions, name)
+	if s.currentSession == name {
+		s.currentSession = ""
+		for n := range s.sessions {
+			s.currentSession = n
+			break
+		}
+	}
+}
+
+// cancelSessionReadLocked cancels the session of the given Kubernetes context, or all sessions when the
+// name is empty. Sessions that are already quitting are skipped, so each session is cancelled only once.
+// Must be called with the sessionLock held.
+func (s *Service) cancelSessionReadLocked(name string) map[string]*sessionEntry {
+	ses := make(map[string]*sessionEntry)
+	for n, se := range s.sessions {
+		if (name == "" || name == n) && atomic.CompareAndSwapInt32(&se.quitting, 0, 1) {
+			ses[n] = se
+			se.cancel()
+		}
+	}
+	return ses
+}
+
+// cancelSession cancels the session of the given Kubernetes context, or all sessions when the name is empty.
+func (s *Service) cancelSession(name string) {
 	s.sessionLock.RLock()
-	s.cancelSessionReadLocked()
+	ses := s.cancelSessionReadLocked(name)
 	s.sessionLock.RUnlock()
+	if len(ses) == 0 {
+		return
+	}
 
 	s.sessionLock.Lock()
-	s.session = nil
-	s.sessionCancel = nil
-	atomic.StoreInt32(&s.sessionQuitting, 0)
+	for n, se := range ses {
+		s.removeSessionLocked(n, se)
+	}
 	s.sessionLock.Unlock()
 }
 
-func (s *Service) WithSession(f func(context.Context, *Session) error) error {
-	if atomic.LoadInt32(&s.sessionQuitting) != 0 {
-		return status.Error(codes.Canceled, "session cancelled")
-	}
+// WithSession calls the given function with the session that a call with the given context applies to.
+func (s *Service) WithSession(ctx context.Context, f func(context.Context, *Session) error) error {
 	s.sessionLock.RLock()
 	defer s.sessionLock.RUnlock()
-	if s.session == nil {
+	se := s.sessionFor(ctx)
+	if se == nil {
+		if name := client.GetKubeContext(ctx); name != "" {
+			return status.Errorf(codes.Unavailable, "no active session for context %q", name)
+		}
 		return status.Error(codes.Unavailable, "no active session")
 	}
-	return f(s.sessionContext, s.session)
+	if atomic.LoadInt32(&se.quitting) != 0 {
+		return status.Error(codes.Canceled, "session cancelled")
+	}
+	return f(se.ctx, se.session)
 }
 
 func (s *Service) GetNetworkConfig(ctx context.Context, e *empty.Empty) (nc *rpc.NetworkConfig, err error) {
-	err = s.WithSession(func(ctx context.Context, session *Session) error {
+	err = s.WithSession(ctx, func(ctx context.Context, session *Session) error {
 		nc = session.getNetworkConfig()
 		return nil
 	})
 	return
 }
 
+func (s *Service) GetConnections(ctx context.Context, _ *empty.Empty) (cs *rpc.Connections, err error) {
+	err = s.WithSession(ctx, func(ctx context.Context, session *Session) error {
+		cs = session.getConnections()
+		return nil
+	})
+	return
+}
+
+func (s *Service) Capture(req *rpc.CaptureRequest, server rpc.Daemon_CaptureServer) error {
+	var session *Session
+	var sessionCtx context.Context
+	err := s.WithSession(server.Context(), func(ctx context.Context, s *Session) error {
+		sessionCtx, session = ctx, s
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The capture is long-lived, so it must not hold on to the session lock. It ends when the session ends.
+	ctx, cancel := context.WithCancel(server.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-sessionCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return session.capture(ctx, req, server.Send)
+}
+
+func (s *Service) Diagnose(ctx context.Context, dr *rpc.DiagnoseRequest) (*rpc.Diagnosis, error) {
+	var session *Session
+	var sessionCtx context.Context
+	err := s.WithSession(ctx, func(ctx context.Context, s *Session) error {
+		sessionCtx, session = ctx, s
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The checks may take a while, so they must not hold on to the session lock. They use the session's context
+	// but end when the call is cancelled.
+	sessionCtx, cancel := context.WithCancel(sessionCtx)
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-sessionCtx.Done():
+		}
+	}()
+	return session.diagnose(sessionCtx, dr), nil
+}
+
+func (s *Service) AddChaosRule(ctx context.Context, cr *rpc.ChaosRule) (r *rpc.ChaosRule, err error) {
+	err = s.WithSession(ctx, func(ctx context.Context, session *Session) error {
+		r, err = session.addChaosRule(cr)
+		return err
+	})
+	return
+}
+
+func (s *Service) RemoveChaosRule(ctx context.Context, rr *rpc.RemoveChaosRuleRequest) (*empty.Empty, error) {
+	err := s.WithSession(ctx, func(ctx context.Context, session *Session) error {
+		return session.removeChaosRule(rr)
+	})
+	return &empty.Empty{}, err
+}
+
+func (s *Service) GetChaosRules(ctx context.Context, _ *empty.Empty) (rs *rpc.ChaosRules, err error) {
+	err = s.WithSession(ctx, func(ctx context.Context, session *Session) error {
+		rs = &rpc.ChaosRules{Rules: session.getChaosRules()}
+		return nil
+	})
+	return
+}
+
+func (s *Service) GetDNSCache(ctx context.Context, rq *rpc.DNSCacheRequest) (c *rpc.DNSCache, err error) {
+	err = s.WithSession(ctx, func(ctx context.Context, session *Session) error {
+		c = session.getDNSCache(rq)
+		return nil
+	})
+	return
+}
+
+func (s *Service) QueryDNS(ctx context.Context, rq *rpc.DNSQueryRequest) (r *rpc.DNSQueryResponse, err error) {
+	err = s.WithSession(ctx, func(_ context.Context, session *Session) error {
+		r, err = session.queryDNS(ctx, rq)
+		return err
+	})
+	return
+}
+
+func (s *Service) GetDNSQueryLog(req *rpc.DNSQueryLogRequest, server rpc.Daemon_GetDNSQueryLogServer) error {
+	var session *Session
+	var sessionCtx context.Context
+	err := s.WithSession(server.Context(), func(ctx context.Context, s *Session) error {
+		sessionCtx, session = ctx, s
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Following the log is long-lived, so it must not hold on to the session lock. It ends when the session ends.
+	ctx, cancel := context.WithCancel(server.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-sessionCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return session.dnsServer.FollowQueryLog(ctx, req.Follow, server.Send)
+}
+
 func (s *Service) SetLogLevel(ctx context.Context, request *manager.LogLevelRequest) (*empty.Empty, error) {
 	duration := time.Duration(0)
 	if request.Duration != nil {
@@ -249,10 +431,15 @@ func (s *Service) configReload(c context.Context) error {
 	return client.Watch(c, func(c context.Context) error {
 		s.sessionLock.RLock()
 		defer s.sessionLock.RUnlock()
-		if s.session == nil {
+		if len(s.sessions) == 0 {
 			return client.RestoreDefaults(c, true)
 		}
-		return s.session.applyConfig(c)
+		for _, se := range s.sessions {
+			if err := se.session.applyConfig(c); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 }
 
@@ -280,7 +467,7 @@ func (s *Service) manageSessions(c context.Context) error {
 			default:
 				// Nobody left to read the response? That's fine really. Just means that
 				// whoever wanted to start the session terminated early.
-				s.cancelSession()
+				s.cancelSession(oi.KubeContext)
 			}
 		}
 	}
@@ -297,8 +484,9 @@ func (s *Service) startSession(ctx context.Context, oi *rpc.OutboundInfo, wg *sy
 			},
 		},
 	}
-	if s.session != nil {
-		reply.status.OutboundConfig = s.session.getNetworkConfig().OutboundInfo
+	name := oi.KubeContext
+	if se, ok := s.sessions[name]; ok {
+		reply.status.OutboundConfig = se.session.getNetworkConfig().OutboundInfo
 		return reply
 	}
 
@@ -310,33 +498,40 @@ func (s *Service) startSession(ctx context.Context, oi *rpc.OutboundInfo, wg *sy
 		return reply
 	}
 
-	s.session = session
-	s.sessionContext = ctx
-	s.sessionCancel = func() {
-		cancel()
-		<-session.Done()
+	session.subnetRegistry = &s.subnets
+	session.dnsServer.SetDomainRegistry(&s.domains)
+	se := &sessionEntry{
+		session: session,
+		ctx:     ctx,
+		cancel: func() {
+			cancel()
+			<-session.Done()
+		},
 	}
-	if err := s.session.applyConfig(ctx); err != nil {
+	s.sessions[name] = se
+	s.currentSession = name
+	if err := session.applyConfig(ctx); err != nil {
 		dlog.Warnf(ctx, "failed to apply config from traffic-manager: %v", err)
 	}
 
-	reply.status.OutboundConfig = s.session.getNetworkConfig().OutboundInfo
+	reply.status.OutboundConfig = session.getNetworkConfig().OutboundInfo
 
 	// Run the session asynchronously. We must be able to respond to connect (with getNetworkConfig) while
-	// the session is running. The d.session.cancel is called from Disconnect
+	// the session is running. The se.cancel is called from Disconnect
 	wg.Add(1)
 	go func() {
 		defer func() {
 			s.sessionLock.Lock()
-			s.session = nil
-			s.sessionCancel = nil
-			if err := client.RestoreDefaults(ctx, true); err != nil {
-				dlog.Warn(ctx, err)
+			s.removeSessionLocked(name, se)
+			if len(s.sessions) == 0 {
+				if err := client.RestoreDefaults(ctx, true); err != nil {
+					dlog.Warn(ctx, err)
+				}
 			}
 			s.sessionLock.Unlock()
 			wg.Done()
 		}()
-		if err := s.session.run(s.sessionContext); err != nil {
+		if err := session.run(ctx); err != nil {
 			dlog.Error(ctx, err)
 		}
 	}()
@@ -0,0 +1,61 @@
+package rootd
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+func TestService_cancelSessionPerContext(t *testing.T) {
+	ctx := context.Background()
+	release := make(chan struct{})
+	var bCancelled int32
+	a := &sessionEntry{session: &Session{}, ctx: ctx, cancel: func() { <-release }}
+	b := &sessionEntry{session: &Session{}, ctx: ctx, cancel: func() { atomic.StoreInt32(&bCancelled, 1) }}
+	s := &Service{sessions: map[string]*sessionEntry{"a": a, "b": b}, currentSession: "b"}
+
+	callCtx := func(name string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(client.KubeContextKey, name))
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.cancelSession("a")
+	}()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&a.quitting) != 0 }, 5*time.Second, time.Millisecond)
+
+	// Calls for a session that is quitting are cancelled, while calls for other sessions proceed.
+	err := s.WithSession(callCtx("a"), func(context.Context, *Session) error { return nil })
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	called := false
+	require.NoError(t, s.WithSession(callCtx("b"), func(context.Context, *Session) error {
+		called = true
+		return nil
+	}))
+	assert.True(t, called)
+
+	// A concurrent cancel of another session isn't dropped.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.cancelSession("b")
+	}()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&bCancelled) != 0 }, 5*time.Second, time.Millisecond)
+
+	close(release)
+	wg.Wait()
+	assert.Empty(t, s.sessions)
+	assert.Equal(t, "", s.currentSession)
+}
@@ -25,7 +25,9 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	empty "google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"gopkg.in/yaml.v3"
 	"gvisor.dev/gvisor/pkg/tcpip/stack"
 
@@ -114,6 +116,27 @@ type Session struct {
 
 	// Subnets configured not to be proxied
 	neverProxyRoutes []*routing.Route
+
+	// Host name patterns configured to be proxied, and excluded from being proxied
+	alsoProxyHosts  []string
+	neverProxyHosts []string
+
+	// Routes for the addresses that the alsoProxyHosts resolve to
+	hostRoutes hostRoutes
+
+	// subnetRegistry, when not nil, is shared with the sessions for other clusters and ensures that the
+	// subnets routed by this session don't overlap theirs.
+	subnetRegistry *subnetRegistry
+
+	// nat, when not nil, maps cluster subnets that conflict with the local network onto a virtual subnet
+	nat *vif.NAT
+
+	// resolvedNames maps IPs returned from cluster DNS lookups to the names that were looked up.
+	resolvedNames resolvedNames
+
+	// chaos contains the rules that inject faults into the connections to the cluster
+	chaos tunnel.Chaos
+
 	// Subnets that the router is currently configured with. Managed, and only used in
 	// the refreshSubnets() method.
 	curSubnets      []*net.IPNet
@@ -128,6 +151,9 @@ type Session struct {
 	// session contains the manager session
 	session *manager.SessionInfo
 
+	// kubeContext is the name of the Kubernetes context that the session is connected to
+	kubeContext string
+
 	// rndSource is the source for the random number generator in the TCP handlers
 	rndSource rand.Source
 
@@ -143,9 +169,16 @@ type Session struct {
 	// vifReady is closed when the virtual network interface has been configured.
 	vifReady chan error
 
+	// clusterInfo is the last cluster info received from the traffic-manager
+	clusterInfo     *manager.ClusterInfo
+	clusterInfoLock sync.Mutex
+
 	// config is the session config given by the traffic manager
 	config client.Config
 
+	// remoteDNSMappings are the DNS mappings of the config given by the traffic manager
+	remoteDNSMappings []*client.DNSMapping
+
 	// done is closed when the session ends
 	done chan struct{}
 }
@@ -166,7 +199,7 @@ func GetNewSessionFunc(ctx context.Context) NewSessionFunc {
 }
 
 // connectToManager connects to the traffic-manager and asserts that its version is compatible.
-func connectToUserDaemon(c context.Context) (*grpc.ClientConn, connector.ManagerProxyClient, semver.Version, error) {
+func connectToUserDaemon(c context.Context, kubeContext string) (*grpc.ClientConn, connector.ManagerProxyClient, semver.Version, error) {
 	// First check. Establish connection
 	clientConfig := client.GetConfig(c)
 	tos := &clientConfig.Timeouts
@@ -174,10 +207,12 @@ func connectToUserDaemon(c context.Context) (*grpc.ClientConn, connector.Manager
 	defer cancel()
 
 	var conn *grpc.ClientConn
-	conn, err := socket.Dial(tc, socket.ConnectorName,
+	// The user daemon proxies the calls to the traffic-manager of the given context.
+	opts := append([]grpc.DialOption{
 		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
 		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
-	)
+	}, client.KubeContextDialOptions(kubeContext)...)
+	conn, err := socket.Dial(tc, socket.ConnectorName, opts...)
 	var mgrVer semver.Version
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -223,7 +258,7 @@ func convertSubnets(ms []*manager.IPNet) []*net.IPNet {
 func NewSession(c context.Context, scout *scout.Reporter, mi *rpc.OutboundInfo) (*Session, error) {
 	dlog.Info(c, "-- Starting new session")
 
-	conn, mc, ver, err := connectToUserDaemon(c)
+	conn, mc, ver, err := connectToUserDaemon(c, mi.KubeContext)
 	if mc == nil || err != nil {
 		return nil, err
 	}
@@ -251,20 +286,28 @@ func newSession(c context.Context, scout *scout.Reporter, mi *rpc.OutboundInfo,
 	as := convertSubnets(mi.AlsoProxySubnets)
 	ns := convertSubnets(mi.NeverProxySubnets)
 	s := &Session{
-		scout:            scout,
-		handlers:         tunnel.NewPool(),
-		fragmentMap:      make(map[uint16][]*buffer.Data),
-		rndSource:        rand.NewSource(time.Now().UnixNano()),
-		session:          mi.Session,
-		managerClient:    mc,
-		managerVersion:   ver,
-		alsoProxySubnets: as,
-		neverProxyRoutes: routing.Routes(c, ns),
-		proxyClusterPods: true,
-		proxyClusterSvcs: true,
-		vifReady:         make(chan error, 2),
-		config:           cfg,
-		done:             make(chan struct{}),
+		scout:             scout,
+		handlers:          tunnel.NewPool(),
+		fragmentMap:       make(map[uint16][]*buffer.Data),
+		rndSource:         rand.NewSource(time.Now().UnixNano()),
+		session:           mi.Session,
+		kubeContext:       mi.KubeContext,
+		managerClient:     mc,
+		managerVersion:    ver,
+		alsoProxySubnets:  as,
+		neverProxyRoutes:  routing.Routes(c, ns),
+		alsoProxyHosts:    mi.AlsoProxyHosts,
+		neverProxyHosts:   mi.NeverProxyHosts,
+		proxyClusterPods:  true,
+		proxyClusterSvcs:  true,
+		vifReady:          make(chan error, 2),
+		config:            cfg,
+		remoteDNSMappings: cfg.DNS.Mappings,
+		done:              make(chan struct{}),
+	}
+
+	if mi.VirtualSubnet != nil {
+		s.nat = vif.NewNAT(iputil.IPNetFromRPC(mi.VirtualSubnet))
 	}
 
 	s.dev, err = vif.OpenTun(c)
@@ -273,12 +316,23 @@ func newSession(c context.Context, scout *scout.Reporter, mi *rpc.OutboundInfo,
 	}
 
 	if dnsproxy.ManagerCanDoDNSQueryTypes(ver) {
-		s.dnsServer = dns.NewServer(mi.Dns, s.clusterLookup, false)
+		s.dnsServer = dns.NewServer(mi.Dns, s.recordNames(s.natLookup(s.clusterLookup)), false)
 	} else {
-		s.dnsServer = dns.NewServer(mi.Dns, s.legacyClusterLookup, true)
+		s.dnsServer = dns.NewServer(mi.Dns, s.recordNames(s.natLookup(s.legacyClusterLookup)), true)
+	}
+	if mi.KubeContext != "" {
+		s.dnsServer.SetContextDomain(client.KubeContextDomain(mi.KubeContext))
 	}
 	dlog.Infof(c, "also-proxy subnets %v", as)
 	dlog.Infof(c, "never-proxy subnets %v", ns)
+	if len(s.alsoProxyHosts) > 0 {
+		s.dnsServer.SetProxyHosts(s.alsoProxyHosts, s.neverProxyHosts, s.proxyHostsResolved)
+		dlog.Infof(c, "also-proxy hosts %v", s.alsoProxyHosts)
+		dlog.Infof(c, "never-proxy hosts %v", s.neverProxyHosts)
+	}
+	if s.nat != nil {
+		dlog.Infof(c, "virtual subnet %s", s.nat.VirtualSubnet())
+	}
 	return s, nil
 }
 
@@ -299,6 +353,176 @@ func (s *Session) clusterLookup(ctx context.Context, q *dns2.Question) (dnsproxy
 	return dnsproxy.FromRPC(r)
 }
 
+// natLookup returns a dns.Resolver that replaces the IPs of A and AAAA records returned by the given
+// lookup with virtual IPs when they belong to a cluster subnet that conflicts with the local network.
+func (s *Session) natLookup(lookup dns.Resolver) dns.Resolver {
+	if s.nat == nil {
+		return lookup
+	}
+	return func(ctx context.Context, q *dns2.Question) (dnsproxy.RRs, int, error) {
+		rrs, rCode, err := lookup(ctx, q)
+		if err != nil {
+			return rrs, rCode, err
+		}
+		for _, rr := range rrs {
+			var err error
+			switch rr := rr.(type) {
+			case *dns2.A:
+				rr.A, err = s.nat.ToVirtual(rr.A)
+			case *dns2.AAAA:
+				rr.AAAA, err = s.nat.ToVirtual(rr.AAAA)
+			}
+			if err != nil {
+				dlog.Error(ctx, err)
+			}
+		}
+		return rrs, rCode, nil
+	}
+}
+
+// recordNames returns a dns.Resolver that remembers the names of the A and AAAA records returned by the given
+// lookup, so that the destination of a connection can be presented using the name that it was resolved from.
+func (s *Session) recordNames(lookup dns.Resolver) dns.Resolver {
+	return func(ctx context.Context, q *dns2.Question) (dnsproxy.RRs, int, error) {
+		rrs, rCode, err := lookup(ctx, q)
+		if err == nil {
+			for _, rr := range rrs {
+				switch rr := rr.(type) {
+				case *dns2.A:
+					s.resolvedNames.store(rr.A, strings.TrimSuffix(rr.Hdr.Name, "."))
+				case *dns2.AAAA:
+					s.resolvedNames.store(rr.AAAA, strings.TrimSuffix(rr.Hdr.Name, "."))
+				}
+			}
+		}
+		return rrs, rCode, err
+	}
+}
+
+// getConnections returns the connections that are currently handled by the TUN device.
+func (s *Session) getConnections() *rpc.Connections {
+	cs := &rpc.Connections{}
+	s.handlers.Range(func(id tunnel.ConnID, h tunnel.Handler) {
+		c := &rpc.Connection{
+			Protocol:    id.ProtocolString(),
+			Source:      id.SourceAddr().String(),
+			Destination: id.DestinationAddr().String(),
+		}
+		c.ServiceName = s.resolvedNames.load(id.Destination())
+		if sp, ok := h.(tunnel.StatsProvider); ok {
+			st := sp.Stats()
+			c.Started = timestamppb.New(st.Started)
+			c.LastActivity = timestamppb.New(st.LastActivity)
+			c.BytesSent = st.BytesSent
+			c.BytesReceived = st.BytesReceived
+		}
+		cs.Connections = append(cs.Connections, c)
+	})
+	return cs
+}
+
+func (s *Session) addChaosRule(cr *rpc.ChaosRule) (*rpc.ChaosRule, error) {
+	r := &tunnel.ChaosRule{
+		Destination: cr.Destination,
+		Latency:     cr.Latency.AsDuration(),
+		Jitter:      cr.Jitter.AsDuration(),
+		DropRate:    cr.DropRate,
+		ResetAfter:  cr.ResetAfter.AsDuration(),
+	}
+	if err := s.chaos.Add(r); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return chaosRuleToRPC(r), nil
+}
+
+func (s *Session) removeChaosRule(rr *rpc.RemoveChaosRuleRequest) error {
+	if rr.All {
+		s.chaos.RemoveAll()
+		return nil
+	}
+	if !s.chaos.Remove(rr.Id) {
+		return status.Errorf(codes.NotFound, "chaos rule %q not found", rr.Id)
+	}
+	return nil
+}
+
+func (s *Session) getChaosRules() []*rpc.ChaosRule {
+	rules := s.chaos.Rules()
+	crs := make([]*rpc.ChaosRule, len(rules))
+	for i, r := range rules {
+		crs[i] = chaosRuleToRPC(r)
+	}
+	return crs
+}
+
+func chaosRuleToRPC(r *tunnel.ChaosRule) *rpc.ChaosRule {
+	cr := &rpc.ChaosRule{
+		Id:          r.ID,
+		Destination: r.Destination,
+		DropRate:    r.DropRate,
+	}
+	if r.Latency > 0 {
+		cr.Latency = durationpb.New(r.Latency)
+	}
+	if r.Jitter > 0 {
+		cr.Jitter = durationpb.New(r.Jitter)
+	}
+	if r.ResetAfter > 0 {
+		cr.ResetAfter = durationpb.New(r.ResetAfter)
+	}
+	return cr
+}
+
+// capture calls send with each packet that passes through the TUN device and matches the filter of the given
+// request, until the given context is cancelled. Packets are dropped when send can't keep up.
+func (s *Session) capture(ctx context.Context, req *rpc.CaptureRequest, send func(*rpc.CapturedPacket) error) error {
+	filter, err := vif.ParsePacketFilter(req.Filter)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	snapLen := int(req.SnapLength)
+	packets := make(chan *rpc.CapturedPacket, 1024)
+	var dropped uint64
+	remove := s.dev.AddTap(func(outbound bool, data []byte) {
+		if !filter.Match(data) {
+			return
+		}
+		origLen := len(data)
+		if snapLen > 0 && origLen > snapLen {
+			data = data[:snapLen]
+		}
+		p := &rpc.CapturedPacket{
+			Timestamp:      timestamppb.Now(),
+			Outbound:       outbound,
+			Data:           append([]byte(nil), data...),
+			OriginalLength: int32(origLen),
+		}
+		select {
+		case packets <- p:
+		default:
+			atomic.AddUint64(&dropped, 1)
+		}
+	})
+	defer func() {
+		remove()
+		if d := atomic.LoadUint64(&dropped); d > 0 {
+			dlog.Warnf(ctx, "packet capture dropped %d packets", d)
+		}
+	}()
+	dlog.Infof(ctx, "packet capture started, filter %q", req.Filter)
+	for {
+		select {
+		case <-ctx.Done():
+			dlog.Info(ctx, "packet capture ended")
+			return nil
+		case p := <-packets:
+			if err := send(p); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 // clusterLookup sends a LookupHost request to the traffic-manager and returns the result.
 func (s *Session) legacyClusterLookup(ctx context.Context, q *dns2.Question) (rrs dnsproxy.RRs, rCode int, err error) {
 	qType := q.Qtype
@@ -355,6 +579,11 @@ func (s *Session) getNetworkConfig() *rpc.NetworkConfig {
 			info.NeverProxySubnets[i] = iputil.IPNetToRPC(np.RoutedNet)
 		}
 	}
+	info.AlsoProxyHosts = s.alsoProxyHosts
+	info.NeverProxyHosts = s.neverProxyHosts
+	if s.nat != nil {
+		info.VirtualSubnet = iputil.IPNetToRPC(s.nat.VirtualSubnet())
+	}
 	nc.Subnets = make([]*manager.IPNet, len(s.curSubnets))
 	for i, sn := range s.curSubnets {
 		nc.Subnets[i] = iputil.IPNetToRPC(sn)
@@ -415,10 +644,20 @@ func (s *Session) refreshSubnets(ctx context.Context) (err error) {
 	// Create a unique slice of all desired subnets.
 	ctx, span := otel.GetTracerProvider().Tracer("").Start(ctx, "refreshSubnets")
 	defer tracing.EndAndRecord(span, err)
-	desired := make([]*net.IPNet, len(s.clusterSubnets)+len(s.alsoProxySubnets))
-	copy(desired, s.clusterSubnets)
-	copy(desired[len(s.clusterSubnets):], s.alsoProxySubnets)
+	clusterSubnets := s.clusterSubnets
+	if s.nat != nil {
+		clusterSubnets = s.translateConflicting(ctx, clusterSubnets)
+	}
+	desired := make([]*net.IPNet, len(clusterSubnets)+len(s.alsoProxySubnets))
+	copy(desired, clusterSubnets)
+	copy(desired[len(clusterSubnets):], s.alsoProxySubnets)
 	desired = subnet.Unique(desired)
+	if s.subnetRegistry != nil {
+		var rejected []*net.IPNet
+		if desired, rejected = s.subnetRegistry.claim(s, desired); len(rejected) > 0 {
+			dlog.Warnf(ctx, "subnets %v will not be routed because they overlap subnets routed for another cluster", rejected)
+		}
+	}
 
 	// Remove all no longer desired subnets from the t.curSubnets
 	var removed []*net.IPNet
@@ -459,6 +698,32 @@ func (s *Session) refreshSubnets(ctx context.Context) (err error) {
 	return s.reconcileStaticRoutes(ctx)
 }
 
+// translateConflicting finds the subnets among the given cluster subnets that conflict with the local network
+// and tells the NAT to translate them. It returns the subnets that should be routed to the TUN device, i.e.
+// the non-conflicting subnets and the virtual subnet.
+func (s *Session) translateConflicting(ctx context.Context, clusterSubnets []*net.IPNet) []*net.IPNet {
+	rt, err := routing.GetRoutingTable(ctx)
+	if err != nil {
+		dlog.Errorf(ctx, "unable to detect subnet conflicts: %v", err)
+		return clusterSubnets
+	}
+	conflicting := vif.Conflicts(clusterSubnets, rt, s.dev.Name())
+	s.nat.SetConflicting(conflicting)
+	if len(conflicting) == 0 {
+		return clusterSubnets
+	}
+	dlog.Infof(ctx, "cluster subnets %v conflict with the local network and are mapped onto %s", conflicting, s.nat.VirtualSubnet())
+	_, routed := subnet.Partition(clusterSubnets, func(_ int, sn *net.IPNet) bool {
+		for _, c := range conflicting {
+			if c == sn {
+				return true
+			}
+		}
+		return false
+	})
+	return append(routed, s.nat.VirtualSubnet())
+}
+
 // networkReady returns a channel that is close when both the VIF and DNS are ready.
 func (s *Session) networkReady(ctx context.Context) <-chan error {
 	rdy := make(chan error, 2)
@@ -541,7 +806,17 @@ func (s *Session) onFirstClusterInfo(ctx context.Context, mgrInfo *manager.Clust
 	if err != nil {
 		return err
 	}
-	if s.stack, err = vif.NewStack(ctx, s.dev, s.streamCreator()); err != nil {
+	sc := client.GetConfig(ctx).Routing.Stack
+	opts := vif.StackOptions{
+		MTU:               sc.MTU,
+		MaxInFlight:       sc.MaxInFlight,
+		WindowScale:       sc.WindowScale,
+		ReceiveBufferSize: int(sc.ReceiveBufferSize.Value()),
+		SendBufferSize:    int(sc.SendBufferSize.Value()),
+		CongestionControl: sc.CongestionControl,
+		DisableSACK:       sc.SACK != nil && !*sc.SACK,
+	}
+	if s.stack, err = vif.NewStack(ctx, s.dev, s.chaos.StreamCreator(s.natStreamCreator(ctx), s.resolvedNames.load), s.handlers, opts); err != nil {
 		return fmt.Errorf("NewStack: %v", err)
 	}
 	s.onClusterInfo(ctx, mgrInfo, span)
@@ -550,6 +825,9 @@ func (s *Session) onFirstClusterInfo(ctx context.Context, mgrInfo *manager.Clust
 
 func (s *Session) onClusterInfo(ctx context.Context, mgrInfo *manager.ClusterInfo, span trace.Span) {
 	dlog.Debugf(ctx, "WatchClusterInfo update")
+	s.clusterInfoLock.Lock()
+	s.clusterInfo = mgrInfo
+	s.clusterInfoLock.Unlock()
 	dns := mgrInfo.Dns
 	if dns == nil {
 		// Older traffic-manager. Use deprecated mgrInfo fields for DNS
@@ -624,11 +902,11 @@ func (s *Session) onClusterInfo(ctx context.Context, mgrInfo *manager.ClusterInf
 	)
 }
 
+// errUnexpectedResponse is returned by the connectivity probes when the probed IP is connectable, but the
+// response isn't the one expected from the traffic-manager or its agent-injector.
+var errUnexpectedResponse = errors.New("unexpected response")
+
 func (s *Session) checkSvcConnectivity(ctx context.Context, info *manager.ClusterInfo) bool {
-	// The traffic-manager service is headless, which means we can't try a GRPC connection to its ClusterIP.
-	// Instead we try an HTTP health check on the agent-injector server, since that one does expose a ClusterIP.
-	// This is less precise than if we could check for our own GRPC, since /healthz is a common enough health check path,
-	// but hopefully the server on the other end isn't configured to respond to the hostname "agent-injector" if it isn't the agent-injector.
 	if info.InjectorSvcIp == nil {
 		return true
 	}
@@ -637,6 +915,28 @@ func (s *Session) checkSvcConnectivity(ctx context.Context, info *manager.Cluste
 		dlog.Info(ctx, "Connectivity check for services disabled")
 		return true
 	}
+	err := probeService(ctx, info, ct)
+	switch {
+	case err == nil:
+		dlog.Info(ctx, "Already connected to cluster, will not map service subnets.")
+		return false
+	case errors.Is(err, errUnexpectedResponse):
+		dlog.Warnf(ctx, "Service IP %s is connectable, but did not respond as expected (%v)."+
+			" Will proxy services, but this may interfere with your VPN routes.", net.IP(info.InjectorSvcIp), err)
+	default:
+		// This means either network errors (timeouts, failed to connect), or that the server doesn't speak HTTP.
+		dlog.Debugf(ctx, "Will proxy services (%v)", err)
+	}
+	return true
+}
+
+// probeService performs an HTTP health check on the agent-injector service using the given timeout. It returns
+// nil when the service responded as expected.
+func probeService(ctx context.Context, info *manager.ClusterInfo, timeout time.Duration) error {
+	// The traffic-manager service is headless, which means we can't try a GRPC connection to its ClusterIP.
+	// Instead we try an HTTP health check on the agent-injector server, since that one does expose a ClusterIP.
+	// This is less precise than if we could check for our own GRPC, since /healthz is a common enough health check path,
+	// but hopefully the server on the other end isn't configured to respond to the hostname "agent-injector" if it isn't the agent-injector.
 	ip := net.IP(info.InjectorSvcIp).String()
 	port := info.InjectorSvcPort
 	if port == 0 {
@@ -647,33 +947,25 @@ func (s *Session) checkSvcConnectivity(ctx context.Context, info *manager.Cluste
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 	}
 	client := &http.Client{Transport: tr}
-	tCtx, tCancel := context.WithTimeout(ctx, ct)
+	tCtx, tCancel := context.WithTimeout(ctx, timeout)
 	defer tCancel()
 	url := net.JoinHostPort(ip, strconv.Itoa(int(port)))
 	url = fmt.Sprintf("https://%s/healthz", url)
 	request, err := http.NewRequestWithContext(tCtx, http.MethodGet, url, nil)
 	if err != nil {
-		// As far as I can tell, this error means a) that the context was cancelled before the request could be allocated, or b) that the request is misconstructed, e.g. bad method.
-		// Neither of those two should really happen here (unless you set the timeout to a few microseconds, maybe), but we can't really continue. May as well route the cluster.
-		dlog.Errorf(ctx, "Unexpected: service conn check could not build request: %v. Will route services anyway.", err)
-		return true
+		return err
 	}
 	request.Header.Set("Host", info.InjectorSvcHost)
-	dlog.Debugf(ctx, "Performing service connectivity check on %s with Host %s and timeout %s", url, info.InjectorSvcHost, ct)
+	dlog.Debugf(ctx, "Performing service connectivity check on %s with Host %s and timeout %s", url, info.InjectorSvcHost, timeout)
 	resp, err := client.Do(request)
 	if err != nil {
-		// This means either network errors (timeouts, failed to connect), or that the server doesn't speak HTTP.
-		dlog.Debugf(ctx, "Will proxy services (%v)", err)
-		return true
+		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		dlog.Warnf(ctx, "Service IP %s is connectable, but did not respond as expected (status code %d)."+
-			" Will proxy services, but this may interfere with your VPN routes.", info.InjectorSvcIp, resp.StatusCode)
-		return true
+		return fmt.Errorf("%w, status code %d", errUnexpectedResponse, resp.StatusCode)
 	}
-	dlog.Info(ctx, "Already connected to cluster, will not map service subnets.")
-	return false
+	return nil
 }
 
 func (s *Session) checkPodConnectivity(ctx context.Context, info *manager.ClusterInfo) bool {
@@ -685,28 +977,41 @@ func (s *Session) checkPodConnectivity(ctx context.Context, info *manager.Cluste
 		dlog.Info(ctx, "Connectivity check for pods disabled")
 		return true
 	}
+	err := probePod(ctx, info, ct)
+	switch {
+	case err == nil:
+		dlog.Info(ctx, "Already connected to cluster, will not map pod subnets.")
+		return false
+	case errors.Is(err, errUnexpectedResponse):
+		dlog.Warnf(ctx, "Manager IP %s is connectable but not a traffic-manager instance (%v)."+
+			" Will proxy pods, but this may interfere with your VPN routes.", net.IP(info.ManagerPodIp), err)
+	default:
+		dlog.Debugf(ctx, "Will proxy pods (%v)", err)
+	}
+	return true
+}
+
+// probePod calls the traffic-manager using its pod IP and the given timeout. It returns nil when the
+// traffic-manager responded.
+func probePod(ctx context.Context, info *manager.ClusterInfo, timeout time.Duration) error {
 	ip := net.IP(info.ManagerPodIp).String()
 	port := info.ManagerPodPort
 	if port == 0 {
 		port = 8081 // Traffic managers before 2.8.0 didn't include the port because it was hardcoded at 8081
 	}
-	tCtx, tCancel := context.WithTimeout(ctx, ct)
+	tCtx, tCancel := context.WithTimeout(ctx, timeout)
 	defer tCancel()
-	dlog.Debugf(ctx, "Performing pod connectivity check on IP %s with timeout %s", ip, ct)
+	dlog.Debugf(ctx, "Performing pod connectivity check on IP %s with timeout %s", ip, timeout)
 	conn, err := grpc.DialContext(tCtx, fmt.Sprintf("%s:%d", ip, port), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
 	if err != nil {
-		dlog.Debugf(ctx, "Will proxy pods (%v)", err)
-		return true
+		return err
 	}
 	defer conn.Close()
 	mClient := manager.NewManagerClient(conn)
 	if _, err := mClient.Version(tCtx, &empty.Empty{}); err != nil {
-		dlog.Warnf(ctx, "Manager IP %s is connectable but not a traffic-manager instance (%v)."+
-			" Will proxy pods, but this may interfere with your VPN routes.", ip, err)
-		return true
+		return fmt.Errorf("%w: %v", errUnexpectedResponse, err)
 	}
-	dlog.Info(ctx, "Already connected to cluster, will not map pod subnets.")
-	return false
+	return nil
 }
 
 func (s *Session) run(c context.Context) error {
@@ -774,6 +1079,9 @@ func (s *Session) Start(c context.Context, g *dgroup.Group) error {
 		s.stack.Wait()
 		return nil
 	})
+	if len(s.alsoProxyHosts) > 0 {
+		g.Go("host-routes", s.hostRoutesWorker)
+	}
 	return nil
 }
 
@@ -809,6 +1117,9 @@ func (s *Session) stop(c context.Context) {
 	if err := s.dev.Close(); err != nil {
 		dlog.Errorf(c, "unable to close %s: %v", s.dev.Name(), err)
 	}
+	if s.subnetRegistry != nil {
+		s.subnetRegistry.release(s)
+	}
 }
 
 func (s *Session) SetSearchPath(ctx context.Context, paths []string, namespaces []string) {
@@ -820,7 +1131,41 @@ func (s *Session) applyConfig(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	return client.MergeAndReplace(ctx, &s.config, cfg, true)
+
+	// The merge retains mappings that are absent in the new config, so the merge starts with the traffic-manager's
+	// mappings to ensure that the mappings removed from the config file are removed.
+	s.config.DNS.Mappings = s.remoteDNSMappings
+	if err = client.MergeAndReplace(ctx, &s.config, cfg, true); err != nil {
+		return err
+	}
+	ms := make([]*rpc.DNSMapping, len(s.config.DNS.Mappings))
+	for i, m := range s.config.DNS.Mappings {
+		ms[i] = &rpc.DNSMapping{Name: m.Name, AliasFor: m.AliasFor, Ip: m.IP}
+	}
+	s.dnsServer.SetMappings(ctx, ms)
+	s.dnsServer.SetCacheConfig(s.config.DNS.Cache)
+	if err = s.dnsServer.SetQueryLog(ctx, s.config.DNS.QueryLog); err != nil {
+		dlog.Errorf(ctx, "unable to open the DNS query log: %v", err)
+	}
+	return nil
+}
+
+func (s *Session) getDNSCache(rq *rpc.DNSCacheRequest) *rpc.DNSCache {
+	return s.dnsServer.GetCache(rq.Flush)
+}
+
+func (s *Session) queryDNS(ctx context.Context, rq *rpc.DNSQueryRequest) (*rpc.DNSQueryResponse, error) {
+	qType := dns2.TypeA
+	if rq.Type != "" {
+		var ok bool
+		if qType, ok = dns2.StringToType[strings.ToUpper(rq.Type)]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown DNS query type %q", rq.Type)
+		}
+	}
+	if rq.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "a name is required")
+	}
+	return s.dnsServer.Query(ctx, rq.Name, qType)
 }
 
 func (s *Session) Done() chan struct{} {
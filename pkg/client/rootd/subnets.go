@@ -0,0 +1,48 @@
+package rootd
+
+import (
+	"net"
+	"sync"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/subnet"
+)
+
+// subnetRegistry keeps track of the subnets that each session routes to its TUN-device, so that sessions
+// that are connected to different clusters never route overlapping subnets.
+type subnetRegistry struct {
+	sync.Mutex
+	subnets map[*Session][]*net.IPNet
+}
+
+// claim returns the subnets among the desired ones that don't overlap the subnets claimed by other sessions,
+// and records them as the subnets claimed by the given session. The overlapping subnets are returned as
+// rejected.
+func (r *subnetRegistry) claim(s *Session, desired []*net.IPNet) (claimed, rejected []*net.IPNet) {
+	r.Lock()
+	defer r.Unlock()
+	claimed, rejected = subnet.Partition(desired, func(_ int, sn *net.IPNet) bool {
+		for os, osns := range r.subnets {
+			if os == s {
+				continue
+			}
+			for _, osn := range osns {
+				if subnet.Overlaps(sn, osn) {
+					return false
+				}
+			}
+		}
+		return true
+	})
+	if r.subnets == nil {
+		r.subnets = make(map[*Session][]*net.IPNet)
+	}
+	r.subnets[s] = claimed
+	return claimed, rejected
+}
+
+// release removes all subnets claimed by the given session.
+func (r *subnetRegistry) release(s *Session) {
+	r.Lock()
+	delete(r.subnets, s)
+	r.Unlock()
+}
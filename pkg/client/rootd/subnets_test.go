@@ -0,0 +1,39 @@
+package rootd
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cidrs(ss ...string) []*net.IPNet {
+	ns := make([]*net.IPNet, len(ss))
+	for i, s := range ss {
+		_, ns[i], _ = net.ParseCIDR(s)
+	}
+	return ns
+}
+
+func TestSubnetRegistry(t *testing.T) {
+	r := subnetRegistry{}
+	a, b := &Session{}, &Session{}
+
+	claimed, rejected := r.claim(a, cidrs("10.96.0.0/16", "10.244.0.0/16"))
+	assert.Equal(t, cidrs("10.96.0.0/16", "10.244.0.0/16"), claimed)
+	assert.Empty(t, rejected)
+
+	claimed, rejected = r.claim(b, cidrs("10.96.128.0/17", "10.100.0.0/16"))
+	assert.Equal(t, cidrs("10.100.0.0/16"), claimed)
+	assert.Equal(t, cidrs("10.96.128.0/17"), rejected)
+
+	// A session never conflicts with itself
+	claimed, rejected = r.claim(a, cidrs("10.96.0.0/16"))
+	assert.Equal(t, cidrs("10.96.0.0/16"), claimed)
+	assert.Empty(t, rejected)
+
+	r.release(a)
+	claimed, rejected = r.claim(b, cidrs("10.96.128.0/17", "10.100.0.0/16"))
+	assert.Equal(t, cidrs("10.96.128.0/17", "10.100.0.0/16"), claimed)
+	assert.Empty(t, rejected)
+}
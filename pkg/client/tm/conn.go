@@ -0,0 +1,53 @@
+package tm
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// Conn is a grpc.ClientConnInterface that delegates to a grpc.ClientConn that can be replaced, e.g. when the
+// port-forward used by the current connection was broken by a laptop that went to sleep or switched network.
+// Clients created using a Conn remain valid when the connection is replaced. Calls and streams that are in
+// progress on the replaced connection will fail.
+type Conn struct {
+	sync.RWMutex
+	conn *grpc.ClientConn
+}
+
+var _ grpc.ClientConnInterface = (*Conn)(nil)
+
+// NewConn returns a Conn that delegates to the given connection.
+func NewConn(conn *grpc.ClientConn) *Conn {
+	return &Conn{conn: conn}
+}
+
+// ClientConn returns the current connection.
+func (c *Conn) ClientConn() *grpc.ClientConn {
+	c.RLock()
+	defer c.RUnlock()
+	return c.conn
+}
+
+// Replace replaces the current connection with the given one and closes the replaced connection.
+func (c *Conn) Replace(conn *grpc.ClientConn) {
+	c.Lock()
+	old := c.conn
+	c.conn = conn
+	c.Unlock()
+	_ = old.Close()
+}
+
+// Close closes the current connection.
+func (c *Conn) Close() error {
+	return c.ClientConn().Close()
+}
+
+func (c *Conn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return c.ClientConn().Invoke(ctx, method, args, reply, opts...)
+}
+
+func (c *Conn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return c.ClientConn().NewStream(ctx, desc, method, opts...)
+}
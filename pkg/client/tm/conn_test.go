@@ -0,0 +1,31 @@
+package tm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestConn_Replace(t *testing.T) {
+	dial := func() *grpc.ClientConn {
+		conn, err := grpc.Dial("passthrough:///traffic-manager", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		return conn
+	}
+	first, second := dial(), dial()
+	c := NewConn(first)
+	assert.Same(t, first, c.ClientConn())
+
+	// Replacing the connection closes the replaced one.
+	c.Replace(second)
+	assert.Same(t, second, c.ClientConn())
+	assert.Equal(t, connectivity.Shutdown, first.GetState())
+	assert.NotEqual(t, connectivity.Shutdown, second.GetState())
+
+	require.NoError(t, c.Close())
+	assert.Equal(t, connectivity.Shutdown, second.GetState())
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"runtime"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -78,27 +79,28 @@ func (s *Service) FuseFTPError() error {
 
 func (s *Service) WithSession(c context.Context, callName string, f func(context.Context, userd.Session) error) (err error) {
 	s.logCall(c, callName, func(_ context.Context) {
-		if atomic.LoadInt32(&s.sessionQuitting) != 0 {
-			err = status.Error(codes.Canceled, "session cancelled")
-			return
-		}
 		s.sessionLock.RLock()
 		defer s.sessionLock.RUnlock()
-		if s.session == nil {
-			err = status.Error(codes.Unavailable, "no active session")
+		se := s.sessionFor(c)
+		if se == nil {
+			if name := client.GetKubeContext(c); name != "" {
+				err = status.Errorf(codes.Unavailable, "no active session for context %q", name)
+			} else {
+				err = status.Error(codes.Unavailable, "no active session")
+			}
 			return
 		}
-		if s.sessionContext.Err() != nil {
+		if atomic.LoadInt32(&se.quitting) != 0 || se.ctx.Err() != nil {
 			// Session context has been cancelled
 			err = status.Error(codes.Canceled, "session cancelled")
 			return
 		}
 		defer func() { err = callRecovery(c, recover(), err) }()
 		num := getReqNumber(c)
-		ctx := dgroup.WithGoroutineName(s.sessionContext, fmt.Sprintf("/%s-%d", callName, num))
+		ctx := dgroup.WithGoroutineName(se.ctx, fmt.Sprintf("/%s-%d", callName, num))
 		ctx, span := otel.Tracer("").Start(ctx, callName)
 		defer span.End()
-		err = f(ctx, s.session)
+		err = f(ctx, se.session)
 	})
 	return
 }
@@ -136,9 +138,11 @@ func (s *Service) Connect(ctx context.Context, cr *rpc.ConnectRequest) (result *
 
 func (s *Service) Disconnect(ctx context.Context, ex *empty.Empty) (*empty.Empty, error) {
 	s.logCall(ctx, "Disconnect", func(ctx context.Context) {
-		s.cancelSession()
+		// Disconnect the session of the given context, or all sessions if no context is given.
+		name := client.GetKubeContext(ctx)
+		s.cancelSession(name)
 		_ = s.withRootDaemon(ctx, func(ctx context.Context, rd daemon.DaemonClient) error {
-			_, err := rd.Disconnect(ctx, ex)
+			_, err := rd.Disconnect(client.WithKubeContext(ctx, name), ex)
 			return err
 		})
 	})
@@ -149,14 +153,22 @@ func (s *Service) Status(ctx context.Context, ex *empty.Empty) (result *rpc.Conn
 	s.logCall(ctx, "Status", func(c context.Context) {
 		s.sessionLock.RLock()
 		defer s.sessionLock.RUnlock()
-		if s.session == nil {
+		se := s.sessionFor(c)
+		if se == nil {
 			result = &rpc.ConnectInfo{Error: rpc.ConnectInfo_DISCONNECTED}
 			_ = s.withRootDaemon(c, func(c context.Context, dc daemon.DaemonClient) error {
 				result.DaemonStatus, err = dc.Status(c, ex)
 				return nil
 			})
 		} else {
-			result = s.session.Status(s.sessionContext)
+			result = se.session.Status(se.ctx)
+		}
+		if len(s.sessions) > 1 {
+			result.ConnectedContexts = make([]string, 0, len(s.sessions))
+			for name := range s.sessions {
+				result.ConnectedContexts = append(result.ConnectedContexts, name)
+			}
+			sort.Strings(result.ConnectedContexts)
 		}
 	})
 	return
@@ -165,8 +177,8 @@ func (s *Service) Status(ctx context.Context, ex *empty.Empty) (result *rpc.Conn
 // isMultiPortIntercept checks if the intercept is one of several active intercepts on the same workload.
 // If it is, then the first returned value will be true and the second will indicate if those intercepts are
 // on different services. Otherwise, this function returns false, false.
-func (s *Service) isMultiPortIntercept(spec *manager.InterceptSpec) (multiPort, multiService bool) {
-	wis := s.session.InterceptsForWorkload(spec.Agent, spec.Namespace)
+func isMultiPortIntercept(session userd.Session, spec *manager.InterceptSpec) (multiPort, multiService bool) {
+	wis := session.InterceptsForWorkload(spec.Agent, spec.Namespace)
 
 	// The InterceptsForWorkload will not include failing or removed intercepts so the
 	// subject must be added unless it's already there.
@@ -205,7 +217,7 @@ func (s *Service) scoutInterceptEntries(ctx context.Context, spec *manager.Inter
 			scout.Entry{Key: "intercept_mechanism", Value: spec.Mechanism},
 			scout.Entry{Key: "intercept_mechanism_numargs", Value: len(spec.Mechanism)},
 		)
-		multiPort, multiService := s.isMultiPortIntercept(spec)
+		multiPort, multiService := isMultiPortIntercept(userd.GetSession(ctx), spec)
 		if multiPort {
 			entries = append(entries, scout.Entry{Key: "multi_port", Value: multiPort})
 			if multiService {
@@ -373,6 +385,119 @@ func (s *Service) GetConfig(ctx context.Context, empty *empty.Empty) (cfg *rpc.C
 	return
 }
 
+func (s *Service) GetConnections(ctx context.Context, _ *empty.Empty) (cs *daemon.Connections, err error) {
+	err = s.WithSession(ctx, "GetConnections", func(c context.Context, session userd.Session) error {
+		cs, err = session.GetConnections(c)
+		return err
+	})
+	return
+}
+
+func (s *Service) Capture(req *daemon.CaptureRequest, server rpc.Connector_CaptureServer) error {
+	return s.WithSession(server.Context(), "Capture", func(c context.Context, session userd.Session) error {
+		return session.Capture(c, req, server)
+	})
+}
+
+func (s *Service) AddChaosRule(ctx context.Context, cr *daemon.ChaosRule) (r *daemon.ChaosRule, err error) {
+	err = s.WithSession(ctx, "AddChaosRule", func(c context.Context, session userd.Session) error {
+		r, err = session.AddChaosRule(c, cr)
+		return err
+	})
+	return
+}
+
+func (s *Service) RemoveChaosRule(ctx context.Context, rr *daemon.RemoveChaosRuleRequest) (*empty.Empty, error) {
+	err := s.WithSession(ctx, "RemoveChaosRule", func(c context.Context, session userd.Session) error {
+		return session.RemoveChaosRule(c, rr)
+	})
+	return &empty.Empty{}, err
+}
+
+func (s *Service) GetChaosRules(ctx context.Context, _ *empty.Empty) (rs *daemon.ChaosRules, err error) {
+	err = s.WithSession(ctx, "GetChaosRules", func(c context.Context, session userd.Session) error {
+		rs, err = session.GetChaosRules(c)
+		return err
+	})
+	return
+}
+
+func (s *Service) GetDNSCache(ctx context.Context, rq *daemon.DNSCacheRequest) (r *daemon.DNSCache, err error) {
+	err = s.WithSession(ctx, "GetDNSCache", func(c context.Context, session userd.Session) error {
+		r, err = session.GetDNSCache(c, rq)
+		return err
+	})
+	return
+}
+
+func (s *Service) QueryDNS(ctx context.Context, rq *daemon.DNSQueryRequest) (r *daemon.DNSQueryResponse, err error) {
+	err = s.WithSession(ctx, "QueryDNS", func(c context.Context, session userd.Session) error {
+		r, err = session.QueryDNS(c, rq)
+		return err
+	})
+	return
+}
+
+func (s *Service) GetDNSQueryLog(req *daemon.DNSQueryLogRequest, server rpc.Connector_GetDNSQueryLogServer) error {
+	return s.WithSession(server.Context(), "GetDNSQueryLog", func(c context.Context, session userd.Session) error {
+		return session.GetDNSQueryLog(c, req, server)
+	})
+}
+
+func (s *Service) Expose(ctx context.Context, er *manager.ExposeRequest) (r *manager.ExposeInfo, err error) {
+	err = s.WithSession(ctx, "Expose", func(c context.Context, session userd.Session) error {
+		r, err = session.Expose(c, er)
+		return err
+	})
+	return
+}
+
+func (s *Service) GetAuditLog(ctx context.Context, req *manager.AuditLogRequest) (r *manager.AuditLog, err error) {
+	err = s.WithSession(ctx, "GetAuditLog", func(c context.Context, session userd.Session) error {
+		r, err = session.GetAuditLog(c, req)
+		return err
+	})
+	return
+}
+
+func (s *Service) Diagnose(ctx context.Context, _ *empty.Empty) (r *daemon.Diagnosis, err error) {
+	err = s.WithSession(ctx, "Diagnose", func(c context.Context, session userd.Session) error {
+		r, err = session.Diagnose(c)
+		return err
+	})
+	return
+}
+
+func (s *Service) Unexpose(ctx context.Context, ur *manager.UnexposeRequest) (result *empty.Empty, err error) {
+	err = s.WithSession(ctx, "Unexpose", func(c context.Context, session userd.Session) error {
+		return session.Unexpose(c, ur.Name)
+	})
+	return &empty.Empty{}, err
+}
+
+func (s *Service) AddForward(ctx context.Context, f *rpc.Forward) (r *rpc.Forward, err error) {
+	err = s.WithSession(ctx, "AddForward", func(c context.Context, session userd.Session) error {
+		r, err = session.AddForward(c, f)
+		return err
+	})
+	return
+}
+
+func (s *Service) RemoveForward(ctx context.Context, rr *rpc.RemoveForwardRequest) (*empty.Empty, error) {
+	err := s.WithSession(ctx, "RemoveForward", func(c context.Context, session userd.Session) error {
+		return session.RemoveForward(c, rr)
+	})
+	return &empty.Empty{}, err
+}
+
+func (s *Service) GetForwards(ctx context.Context, _ *empty.Empty) (rs *rpc.Forwards, err error) {
+	err = s.WithSession(ctx, "GetForwards", func(c context.Context, session userd.Session) error {
+		rs, err = session.GetForwards(c)
+		return err
+	})
+	return
+}
+
 func (s *Service) Login(context.Context, *rpc.LoginRequest) (result *rpc.LoginResult, err error) {
 	return nil, status.Error(codes.Unimplemented, "Login")
 }
@@ -446,7 +571,7 @@ func (s *Service) Quit(ctx context.Context, ex *empty.Empty) (*empty.Empty, erro
 	s.logCall(ctx, "Quit", func(c context.Context) {
 		s.sessionLock.RLock()
 		defer s.sessionLock.RUnlock()
-		s.cancelSessionReadLocked()
+		s.cancelSessionReadLocked("")
 		s.quit()
 		_ = s.withRootDaemon(ctx, func(ctx context.Context, rd daemon.DaemonClient) error {
 			_, err := rd.Quit(ctx, ex)
@@ -465,21 +590,21 @@ func (s *Service) Helm(ctx context.Context, req *rpc.HelmRequest) (*common.Resul
 			defer s.quit()
 		}
 
-		var sessionDone <-chan struct{}
+		var sessionsDone []<-chan struct{}
 		s.sessionLock.Lock()
-		if s.session != nil {
-			sessionDone = s.session.Done()
+		for _, se := range s.sessions {
+			sessionsDone = append(sessionsDone, se.session.Done())
 		}
 		s.sessionLock.Unlock()
 
 		// Traffic manager will vanish, so we can't have an alive session.
-		s.cancelSession()
+		s.cancelSession("")
 		_ = s.withRootDaemon(ctx, func(ctx context.Context, rd daemon.DaemonClient) error {
 			_, _ = rd.Disconnect(ctx, &empty.Empty{})
 			s.quitDisable = false
 			return nil
 		})
-		if sessionDone != nil {
+		for _, sessionDone := range sessionsDone {
 			<-sessionDone
 		}
 		s.quitDisable = false
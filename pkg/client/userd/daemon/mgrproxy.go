@@ -15,37 +15,64 @@ import (
 	"github.com/datawire/dlib/dlog"
 	"github.com/telepresenceio/telepresence/rpc/v2/connector"
 	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
 )
 
+type mgrClient struct {
+	client      manager.ManagerClient
+	callOptions []grpc.CallOption
+}
+
 // mgrProxy implements connector.ManagerProxyServer, but just proxies all requests through a manager.ManagerClient.
+// There's one client for each Kubernetes context that the userd has a session for. The client is selected using
+// the context found in the metadata of the call, or, when no context is given, the client that was last added.
 type mgrProxy struct {
 	sync.RWMutex
-	clientX      manager.ManagerClient
-	callOptionsX []grpc.CallOption
+	clientsX map[string]mgrClient
+	lastX    string
 
 	connector.UnsafeManagerProxyServer
 }
 
 var _ connector.ManagerProxyServer = &mgrProxy{}
 
-func (p *mgrProxy) setClient(client manager.ManagerClient, callOptions ...grpc.CallOption) {
+func (p *mgrProxy) setClient(kubeContext string, client manager.ManagerClient, callOptions ...grpc.CallOption) {
 	p.Lock()
-	p.clientX = client
-	p.callOptionsX = callOptions
-	p.Unlock()
+	defer p.Unlock()
+	if client == nil {
+		delete(p.clientsX, kubeContext)
+		if p.lastX == kubeContext {
+			p.lastX = ""
+			for k := range p.clientsX {
+				p.lastX = k
+				break
+			}
+		}
+		return
+	}
+	if p.clientsX == nil {
+		p.clientsX = make(map[string]mgrClient)
+	}
+	p.clientsX[kubeContext] = mgrClient{client: client, callOptions: callOptions}
+	p.lastX = kubeContext
 }
 
-func (p *mgrProxy) get() (manager.ManagerClient, []grpc.CallOption, error) {
+func (p *mgrProxy) get(ctx context.Context) (manager.ManagerClient, []grpc.CallOption, error) {
+	kubeContext := client.GetKubeContext(ctx)
 	p.RLock()
 	defer p.RUnlock()
-	if p.clientX == nil {
+	if kubeContext == "" {
+		kubeContext = p.lastX
+	}
+	mc, ok := p.clientsX[kubeContext]
+	if !ok {
 		return nil, nil, status.Error(codes.Unavailable, "telepresence: the userd is not connected to the manager")
 	}
-	return p.clientX, p.callOptionsX, nil
+	return mc.client, mc.callOptions, nil
 }
 
 func (p *mgrProxy) Version(ctx context.Context, arg *emptypb.Empty) (*manager.VersionInfo2, error) {
-	client, callOptions, err := p.get()
+	client, callOptions, err := p.get(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +80,7 @@ func (p *mgrProxy) Version(ctx context.Context, arg *emptypb.Empty) (*manager.Ve
 }
 
 func (p *mgrProxy) GetClientConfig(ctx context.Context, arg *emptypb.Empty) (*manager.CLIConfig, error) {
-	client, callOptions, err := p.get()
+	client, callOptions, err := p.get(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +95,9 @@ type tmSender interface {
 	Send(*manager.TunnelMessage) error
 }
 
-func recvLoop(ctx context.Context, who string, in tmReceiver, out chan<- *manager.TunnelMessage, wg *sync.WaitGroup) {
+// recvLoop forwards the messages received from in to out. It returns the error that ended the loop,
+// or nil if the loop ended normally.
+func recvLoop(ctx context.Context, who string, in tmReceiver, out chan<- *manager.TunnelMessage, wg *sync.WaitGroup) error {
 	defer func() {
 		dlog.Debugf(ctx, "%s Recv loop ended", who)
 		close(out)
@@ -80,13 +109,14 @@ func recvLoop(ctx context.Context, who string, in tmReceiver, out chan<- *manage
 		if err != nil {
 			if ctx.Err() == nil && !(errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed)) {
 				dlog.Errorf(ctx, "Tunnel %s.Recv() failed: %v", who, err)
+				return err
 			}
-			return
+			return nil
 		}
 		dlog.Tracef(ctx, "<- %s %d", who, len(payload.Payload))
 		select {
 		case <-ctx.Done():
-			return
+			return nil
 		case out <- payload:
 		}
 	}
@@ -125,11 +155,11 @@ func sendLoop(ctx context.Context, who string, out tmSender, in <-chan *manager.
 }
 
 func (p *mgrProxy) Tunnel(fhClient connector.ManagerProxy_TunnelServer) error {
-	client, callOptions, err := p.get()
+	ctx := fhClient.Context()
+	client, callOptions, err := p.get(ctx)
 	if err != nil {
 		return err
 	}
-	ctx := fhClient.Context()
 	fhManager, err := client.Tunnel(ctx, callOptions...)
 	if err != nil {
 		return err
@@ -139,12 +169,15 @@ func (p *mgrProxy) Tunnel(fhClient connector.ManagerProxy_TunnelServer) error {
 
 	wg := sync.WaitGroup{}
 	wg.Add(4)
-	go recvLoop(ctx, "manager", fhManager, mgrToClient, &wg)
+	// The error from the manager is propagated to the client, so that it can tell a failed tunnel
+	// handshake from a stream that just ended.
+	mgrErr := make(chan error, 1)
+	go func() { mgrErr <- recvLoop(ctx, "manager", fhManager, mgrToClient, &wg) }()
 	go sendLoop(ctx, "manager", fhManager, clientToMgr, &wg)
-	go recvLoop(ctx, "client", fhClient, clientToMgr, &wg)
+	go func() { _ = recvLoop(ctx, "client", fhClient, clientToMgr, &wg) }()
 	go sendLoop(ctx, "client", fhClient, mgrToClient, &wg)
 	wg.Wait()
-	return nil
+	return <-mgrErr
 }
 
 // LookupHost
@@ -152,7 +185,7 @@ func (p *mgrProxy) Tunnel(fhClient connector.ManagerProxy_TunnelServer) error {
 //
 //nolint:staticcheck // retained for backward compatibility
 func (p *mgrProxy) LookupHost(ctx context.Context, arg *manager.LookupHostRequest) (*manager.LookupHostResponse, error) {
-	client, callOptions, err := p.get()
+	client, callOptions, err := p.get(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -160,7 +193,7 @@ func (p *mgrProxy) LookupHost(ctx context.Context, arg *manager.LookupHostReques
 }
 
 func (p *mgrProxy) LookupDNS(ctx context.Context, arg *manager.DNSRequest) (*manager.DNSResponse, error) {
-	client, callOptions, err := p.get()
+	client, callOptions, err := p.get(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -168,7 +201,7 @@ func (p *mgrProxy) LookupDNS(ctx context.Context, arg *manager.DNSRequest) (*man
 }
 
 func (p *mgrProxy) WatchClusterInfo(arg *manager.SessionInfo, srv connector.ManagerProxy_WatchClusterInfoServer) error {
-	client, callOptions, err := p.get()
+	client, callOptions, err := p.get(srv.Context())
 	if err != nil {
 		return err
 	}
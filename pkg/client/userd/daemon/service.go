@@ -51,6 +51,14 @@ to troubleshoot problems.
 `
 }
 
+// sessionEntry is a session together with the context that it runs in and the function that cancels it.
+type sessionEntry struct {
+	session  userd.Session
+	ctx      context.Context
+	cancel   context.CancelFunc
+	quitting int32 // atomic boolean. True if non-zero.
+}
+
 // Service represents the long-running state of the Telepresence User Daemon.
 type Service struct {
 	rpc.UnsafeConnectorServer
@@ -70,11 +78,13 @@ type Service struct {
 	// is in effect (rootSessionInProc == true).
 	quitDisable bool
 
-	session         userd.Session
-	sessionCancel   context.CancelFunc
-	sessionContext  context.Context
-	sessionQuitting int32 // atomic boolean. True if non-zero.
-	sessionLock     sync.RWMutex
+	// sessions contains the active sessions, keyed by the name of their Kubernetes context.
+	sessions map[string]*sessionEntry
+
+	// currentSession is the name of the Kubernetes context of the session that calls that don't
+	// specify a context apply to. It is the session that was connected last.
+	currentSession string
+	sessionLock    sync.RWMutex
 
 	// These are used to communicate between the various goroutines.
 	connectRequest  chan *rpc.ConnectRequest // server-grpc.connect() -> connectWorker
@@ -96,6 +106,7 @@ func NewService(ctx context.Context, _ *dgroup.Group, sr *scout.Reporter, cfg *c
 		connectRequest:  make(chan *rpc.ConnectRequest),
 		connectResponse: make(chan *rpc.ConnectInfo),
 		managerProxy:    &mgrProxy{},
+		sessions:        make(map[string]*sessionEntry),
 		timedLogLevel:   log.NewTimedLevel(cfg.LogLevels.UserDaemon.String(), log.SetLevel),
 		fuseFtpMgr:      remotefs.NewFuseFTPManager(),
 	}
@@ -141,8 +152,25 @@ func (s *Service) Server() *grpc.Server {
 	return s.srv
 }
 
-func (s *Service) SetManagerClient(managerClient manager.ManagerClient, callOptions ...grpc.CallOption) {
-	s.managerProxy.setClient(managerClient, callOptions...)
+func (s *Service) SetManagerClient(kubeContext string, managerClient manager.ManagerClient, callOptions ...grpc.CallOption) {
+	s.managerProxy.setClient(kubeContext, managerClient, callOptions...)
+}
+
+// sessionFor returns the session that a call with the given context applies to, i.e. the session of the
+// Kubernetes context found in the metadata of the call, or the current session if the call doesn't specify
+// a context. It returns nil if no such session exists. Must be called with the sessionLock held.
+func (s *Service) sessionFor(ctx context.Context) *sessionEntry {
+	name := client.GetKubeContext(ctx)
+	if name == "" {
+		name = s.currentSession
+	}
+	return s.sessions[name]
+}
+
+// singleSession returns true if this daemon can handle no more than one session at a time. This is
+// the case when the daemon runs the root session in-process, or when it runs in a container.
+func (s *Service) singleSession() bool {
+	return s.rootSessionInProc || s.daemonAddress != nil
 }
 
 const (
@@ -178,10 +206,15 @@ func (s *Service) configReload(c context.Context) error {
 	return client.Watch(c, func(ctx context.Context) error {
 		s.sessionLock.RLock()
 		defer s.sessionLock.RUnlock()
-		if s.session == nil {
+		if len(s.sessions) == 0 {
 			return client.RestoreDefaults(c, false)
 		}
-		return s.session.ApplyConfig(c)
+		for _, se := range s.sessions {
+			if err := se.session.ApplyConfig(c); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 }
 
@@ -207,7 +240,9 @@ func ManageSessions(c context.Context, si userd.Service) error {
 			default:
 				// Nobody left to read the response? That's fine really. Just means that
 				// whoever wanted to start the session terminated early.
-				s.cancelSession()
+				if rsp.ClusterContext != "" {
+					s.cancelSession(rsp.ClusterContext)
+				}
 			}
 		}
 	}
@@ -219,9 +254,10 @@ func startSession(ctx context.Context, si userd.Service, cr *rpc.ConnectRequest,
 	s.sessionLock.Lock() // Locked during creation
 	defer s.sessionLock.Unlock()
 
-	if s.session != nil {
+	if len(s.sessions) > 0 && s.singleSession() {
 		// UpdateStatus sets rpc.ConnectInfo_ALREADY_CONNECTED if successful
-		return s.session.UpdateStatus(s.sessionContext, cr)
+		se := s.sessions[s.currentSession]
+		return se.session.UpdateStatus(se.ctx, cr)
 	}
 
 	// Obtain the kubeconfig from the request parameters so that we can determine
@@ -239,6 +275,13 @@ func startSession(ctx context.Context, si userd.Service, cr *rpc.ConnectRequest,
 		}
 	}
 
+	name := config.Context
+	if se, ok := s.sessions[name]; ok {
+		// UpdateStatus sets rpc.ConnectInfo_ALREADY_CONNECTED if successful
+		s.currentSession = name
+		return se.session.UpdateStatus(se.ctx, cr)
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	ctx = userd.WithService(ctx, si)
 
@@ -248,6 +291,10 @@ func startSession(ctx context.Context, si userd.Service, cr *rpc.ConnectRequest,
 
 	ctx, session, rsp := userd.GetNewSessionFunc(ctx)(ctx, s.scout, cr, config)
 	if ctx.Err() != nil || rsp.Error != rpc.ConnectInfo_UNSPECIFIED {
+		if session != nil {
+			// Release what the session holds, e.g. its port-forwards and proxy listener.
+			session.Epilog(ctx)
+		}
 		cancel()
 		if s.rootSessionInProc {
 			// Simplified session management. The daemon handles one session, then exits.
@@ -255,33 +302,44 @@ func startSession(ctx context.Context, si userd.Service, cr *rpc.ConnectRequest,
 		}
 		return rsp
 	}
-	s.session = session
-	s.sessionContext = userd.WithSession(ctx, session)
-	s.sessionCancel = func() {
-		cancel()
-		<-session.Done()
+	se := &sessionEntry{
+		session: session,
+		ctx:     userd.WithSession(ctx, session),
+		cancel: func() {
+			cancel()
+			<-session.Done()
+		},
+	}
+	s.sessions[name] = se
+	s.currentSession = name
+	if len(s.sessions) > 1 {
+		dlog.Infof(ctx, "Connected to %d clusters. Calls that don't specify a context apply to %q", len(s.sessions), name)
 	}
 
 	// Run the session asynchronously. We must be able to respond to connect (with UpdateStatus) while
-	// the session is running. The s.sessionCancel is called from Disconnect
+	// the session is running. The se.cancel is called from Disconnect
 	wg.Add(1)
 	go func(cr *rpc.ConnectRequest) {
 		defer func() {
 			s.sessionLock.Lock()
-			s.SetManagerClient(nil)
-			s.session = nil
-			s.sessionCancel = nil
-			if err := client.RestoreDefaults(ctx, false); err != nil {
-				dlog.Warn(ctx, err)
+			if cur, ok := s.sessions[name]; !ok || cur == se {
+				// Don't remove the client of a session that has replaced this one
+				s.SetManagerClient(name, nil)
+			}
+			s.removeSessionLocked(name, se)
+			if len(s.sessions) == 0 {
+				if err := client.RestoreDefaults(ctx, false); err != nil {
+					dlog.Warn(ctx, err)
+				}
 			}
 			s.sessionLock.Unlock()
 			wg.Done()
 		}()
-		if err := userd.RunSession(s.sessionContext, session); err != nil {
+		if err := userd.RunSession(se.ctx, session); err != nil {
 			if errors.Is(err, trafficmgr.ErrSessionExpired) {
 				// Session has expired. We need to cancel the owner session and reconnect
 				dlog.Info(ctx, "refreshing session")
-				s.cancelSession()
+				s.cancelSession(name)
 				select {
 				case <-ctx.Done():
 				case s.connectRequest <- cr:
@@ -299,6 +357,22 @@ func startSession(ctx context.Context, si userd.Service, cr *rpc.ConnectRequest,
 	return rsp
 }
 
+// removeSessionLocked removes the given session unless it has already been replaced, and ensures that the
+// current session, if any remain, is one of the remaining sessions. Must be called with the sessionLock held.
+func (s *Service) removeSessionLocked(name string, se *sessionEntry) {
+	if s.sessions[name] != se {
+		return
+	}
+	delete(s.sessions, name)
+	if s.currentSession == name {
+		s.currentSession = ""
+		for n := range s.sessions {
+			s.currentSession = n
+			break
+		}
+	}
+}
+
 func runAliveAndCancellation(ctx context.Context, cancel context.CancelFunc, name string, port int) {
 	daemonInfoFile := cache.DaemonInfoFile(name, port)
 	g := dgroup.NewGroup(ctx, dgroup.GroupConfig{})
@@ -322,29 +396,45 @@ func runAliveAndCancellation(ctx context.Context, cancel context.CancelFunc, nam
 	}
 }
 
-func (s *Service) cancelSessionReadLocked() {
-	if s.sessionCancel != nil {
-		if err := s.session.ClearIntercepts(s.sessionContext); err != nil {
-			dlog.Errorf(s.sessionContext, "failed to clear intercepts: %v", err)
+// cancelSessionReadLocked cancels the session of the given Kubernetes context, or all sessions when the
+// name is empty. Sessions that are already quitting are skipped, so each session is cancelled only once.
+// Must be called with the sessionLock held.
+func (s *Service) cancelSessionReadLocked(name string) []*sessionEntry {
+	var ses []*sessionEntry
+	for n, se := range s.sessions {
+		if (name == "" || name == n) && atomic.CompareAndSwapInt32(&se.quitting, 0, 1) {
+			ses = append(ses, se)
 		}
-		s.sessionCancel()
 	}
+	for _, se := range ses {
+		if err := se.session.ClearIntercepts(se.ctx); err != nil {
+			dlog.Errorf(se.ctx, "failed to clear intercepts: %v", err)
+		}
+		se.cancel()
+	}
+	return ses
 }
 
-func (s *Service) cancelSession() {
-	if !atomic.CompareAndSwapInt32(&s.sessionQuitting, 0, 1) {
-		return
-	}
+// cancelSession cancels the session of the given Kubernetes context, or all sessions when the name is empty.
+func (s *Service) cancelSession(name string) {
 	s.sessionLock.RLock()
-	s.cancelSessionReadLocked()
+	ses := s.cancelSessionReadLocked(name)
 	s.sessionLock.RUnlock()
+	if len(ses) == 0 {
+		return
+	}
 
 	// We have to cancel the session before we can acquire this write-lock, because we need any long-running RPCs
 	// that may be holding the RLock to die.
 	s.sessionLock.Lock()
-	s.session = nil
-	s.sessionCancel = nil
-	atomic.StoreInt32(&s.sessionQuitting, 0)
+	for _, se := range ses {
+		for n, e := range s.sessions {
+			if e == se {
+				s.removeSessionLocked(n, se)
+				break
+			}
+		}
+	}
 	s.sessionLock.Unlock()
 }
 
@@ -0,0 +1,91 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+type httpProxy struct {
+	w       io.Writer
+	host    string
+	port    uint16
+	connect bool
+	request []byte
+}
+
+// httpHandshake reads the first request from the client. A CONNECT request establishes a tunnel to the
+// requested host and port. Any other request must use an absolute "http" URI. Such a request is forwarded
+// with a "Connection: close" header so that the client will use a new connection for its next request.
+func httpHandshake(r *bufio.Reader, w io.Writer) (handshake, error) {
+	rq, err := http.ReadRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	h := &httpProxy{w: w}
+	var host, portStr string
+	if rq.Method == http.MethodConnect {
+		h.connect = true
+		if host, portStr, err = net.SplitHostPort(rq.Host); err != nil {
+			_ = h.writeStatus(http.StatusBadRequest)
+			return nil, fmt.Errorf("invalid CONNECT address %q", rq.Host)
+		}
+	} else {
+		if !rq.URL.IsAbs() || rq.URL.Scheme != "http" {
+			_ = h.writeStatus(http.StatusBadRequest)
+			return nil, fmt.Errorf("unsupported proxy request URI %q", rq.RequestURI)
+		}
+		if host, portStr = rq.URL.Hostname(), rq.URL.Port(); portStr == "" {
+			portStr = "80"
+		}
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		_ = h.writeStatus(http.StatusBadRequest)
+		return nil, fmt.Errorf("invalid port %q", portStr)
+	}
+	h.host = host
+	h.port = uint16(port)
+
+	if !h.connect {
+		rq.Header.Del("Proxy-Connection")
+		rq.Header.Del("Proxy-Authorization")
+		rq.Header.Set("Connection", "close")
+		rq.Close = true
+		buf := bytes.Buffer{}
+		if err = rq.Write(&buf); err != nil {
+			return nil, err
+		}
+		h.request = buf.Bytes()
+	}
+	return h, nil
+}
+
+func (h *httpProxy) destination() (string, uint16) {
+	return h.host, h.port
+}
+
+func (h *httpProxy) reply(ok bool) error {
+	if !ok {
+		return h.writeStatus(http.StatusBadGateway)
+	}
+	if h.connect {
+		_, err := io.WriteString(h.w, "HTTP/1.1 200 Connection established\r\n\r\n")
+		return err
+	}
+	// The response is produced by the destination.
+	return nil
+}
+
+func (h *httpProxy) initialData() []byte {
+	return h.request
+}
+
+func (h *httpProxy) writeStatus(code int) error {
+	_, err := fmt.Fprintf(h.w, "HTTP/1.1 %d %s\r\nContent-Length: 0\r\nConnection: close\r\n\r\n", code, http.StatusText(code))
+	return err
+}
@@ -0,0 +1,250 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+// Mode is the protocol that the proxy server speaks with its clients.
+type Mode string
+
+const (
+	// ModeSOCKS5 is a SOCKS version 5 proxy (RFC 1928) that accepts the CONNECT command.
+	ModeSOCKS5 = Mode("socks5")
+
+	// ModeHTTP is an HTTP proxy that accepts CONNECT requests and plain HTTP requests using absolute URIs.
+	ModeHTTP = Mode("http")
+
+	// ModeForward forwards all connections to a fixed destination without any negotiation.
+	ModeForward = Mode("forward")
+)
+
+// DefaultAddress is the address that the proxy listens to unless an address has been given explicitly.
+const DefaultAddress = "127.0.0.1:1080"
+
+// ParseMode returns the Mode that corresponds to the given string.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(s)); m {
+	case ModeSOCKS5, ModeHTTP:
+		return m, nil
+	default:
+		return "", errcat.User.Newf("invalid proxy mode %q, must be %q or %q", s, ModeSOCKS5, ModeHTTP)
+	}
+}
+
+// Resolver resolves the given host name into an IP address.
+type Resolver func(ctx context.Context, host string) (net.IP, error)
+
+// Server accepts connections from local clients and dispatches them to tunnel.Stream instances obtained from a
+// tunnel.StreamCreator. Host names are resolved using a Resolver before the stream is created, so that names are
+// resolved in the cluster rather than locally.
+type Server struct {
+	mode          Mode
+	resolve       Resolver
+	streamCreator tunnel.StreamCreator
+
+	// fixed is the destination used in ModeForward
+	fixed *fixedDestination
+}
+
+// NewServer creates a new proxy Server.
+func NewServer(mode Mode, resolve Resolver, streamCreator tunnel.StreamCreator) *Server {
+	return &Server{
+		mode:          mode,
+		resolve:       resolve,
+		streamCreator: streamCreator,
+	}
+}
+
+// NewForwarder creates a Server that forwards all connections to the given host and port.
+func NewForwarder(host string, port uint16, resolve Resolver, streamCreator tunnel.StreamCreator) *Server {
+	return &Server{
+		mode:          ModeForward,
+		resolve:       resolve,
+		streamCreator: streamCreator,
+		fixed:         &fixedDestination{host: host, port: port},
+	}
+}
+
+// Serve accepts connections on the given listener until the context is cancelled. The listener is
+// closed when this method returns.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+	dlog.Infof(ctx, "%s proxy listening at %s", s.mode, l.Addr())
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("%s proxy accept failed: %w", s.mode, err)
+		}
+		go s.handleConn(ctx, conn)
+	}
+}
+
+// handshake performs the protocol specific negotiation and returns the requested destination.
+type handshake interface {
+	// destination returns the requested destination host and port.
+	destination() (string, uint16)
+
+	// reply tells the client whether the connection was established.
+	reply(ok bool) error
+
+	// initialData returns data that has already been consumed from the client and that must be sent
+	// to the destination once it has been dialed.
+	initialData() []byte
+}
+
+func (s *Server) handleConn(ctx context.Context, conn net.Conn) {
+	br := bufio.NewReader(conn)
+	var hs handshake
+	var err error
+	switch s.mode {
+	case ModeForward:
+		hs = s.fixed
+	case ModeSOCKS5:
+		hs, err = socks5Handshake(br, conn)
+	default:
+		hs, err = httpHandshake(br, conn)
+	}
+	if err != nil {
+		dlog.Errorf(ctx, "%s proxy handshake with %s failed: %v", s.mode, conn.RemoteAddr(), err)
+		_ = conn.Close()
+		return
+	}
+	host, port := hs.destination()
+	if err = s.dispatch(ctx, &bufferedConn{Conn: conn, r: br}, hs, host, port); err != nil {
+		dlog.Errorf(ctx, "%s proxy failed to connect %s to %s: %v", s.mode, conn.RemoteAddr(), net.JoinHostPort(host, strconv.Itoa(int(port))), err)
+		_ = conn.Close()
+	}
+}
+
+// dispatch connects the given conn to the destination, and replies to the client exactly once, telling it
+// whether the connection was established.
+func (s *Server) dispatch(ctx context.Context, conn net.Conn, hs handshake, host string, port uint16) error {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := s.connect(ctx, conn, host, port)
+	if err != nil {
+		cancel()
+		_ = hs.reply(false)
+		return err
+	}
+	if err = hs.reply(true); err != nil {
+		cancel()
+		return err
+	}
+	if data := hs.initialData(); len(data) > 0 {
+		if err = stream.Send(ctx, tunnel.NewMessage(tunnel.Normal, data)); err != nil {
+			cancel()
+			return err
+		}
+	}
+	ep := tunnel.NewConnEndpoint(stream, conn, cancel)
+	ep.Start(ctx)
+	<-ep.Done()
+	return nil
+}
+
+// connect creates a stream to the given destination and waits until its peer has dialed it.
+func (s *Server) connect(ctx context.Context, conn net.Conn, host string, port uint16) (tunnel.Stream, error) {
+	ip := iputil.Parse(host)
+	if ip == nil {
+		var err error
+		if ip, err = s.resolve(ctx, host); err != nil {
+			return nil, err
+		}
+	}
+	srcIP, srcPort, err := iputil.SplitToIPPort(conn.RemoteAddr())
+	if err != nil {
+		return nil, err
+	}
+	id := tunnel.NewConnID(ipproto.TCP, srcIP, ip, srcPort, port)
+	dlog.Debugf(ctx, "%s proxy opening tunnel for %s (%s)", s.mode, id, host)
+
+	stream, err := s.streamCreator(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = awaitDial(ctx, stream); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// awaitDial waits for the peer of the given stream to report the outcome of its dial.
+func awaitDial(ctx context.Context, stream tunnel.Stream) error {
+	timeout := stream.DialTimeout() + 2*stream.RoundtripLatency()
+	tc, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		m   tunnel.Message
+		err error
+	}
+	rc := make(chan result, 1)
+	go func() {
+		m, err := stream.Receive(tc)
+		rc <- result{m: m, err: err}
+	}()
+
+	select {
+	case <-tc.Done():
+		return fmt.Errorf("dial timed out after %s", timeout)
+	case r := <-rc:
+		switch {
+		case r.err != nil:
+			return r.err
+		case r.m == nil:
+			return tc.Err()
+		case r.m.Code() == tunnel.DialOK:
+			return nil
+		case r.m.Code() == tunnel.DialReject:
+			return errors.New("connection refused by peer")
+		default:
+			return fmt.Errorf("unexpected message %s while waiting for dial", r.m.Code())
+		}
+	}
+}
+
+// fixedDestination is a handshake that doesn't negotiate anything with the client.
+type fixedDestination struct {
+	host string
+	port uint16
+}
+
+func (f *fixedDestination) destination() (string, uint16) {
+	return f.host, f.port
+}
+
+func (f *fixedDestination) reply(bool) error {
+	return nil
+}
+
+func (f *fixedDestination) initialData() []byte {
+	return nil
+}
+
+// bufferedConn is a net.Conn that reads what's left in a bufio.Reader before it reads from the connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
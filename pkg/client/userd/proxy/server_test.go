@@ -0,0 +1,212 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+const echoHost = "echo.example"
+
+// startEcho starts a TCP server that echoes everything it receives and returns its port.
+func startEcho(ctx context.Context, t *testing.T) uint16 {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return uint16(l.Addr().(*net.TCPAddr).Port)
+}
+
+func testResolve(_ context.Context, host string) (net.IP, error) {
+	if host == echoHost {
+		return net.IPv4(127, 0, 0, 1), nil
+	}
+	return nil, fmt.Errorf("unable to resolve %q", host)
+}
+
+// testStreamCreator creates streams that are dialed using a tunnel.Dialer.
+func testStreamCreator(ctx context.Context, id tunnel.ConnID) (tunnel.Stream, error) {
+	from, to := tunnel.NewPipe(id, "session-id")
+	ctx, cancel := context.WithCancel(ctx)
+	tunnel.NewDialer(to, cancel).Start(ctx)
+	return from, nil
+}
+
+// startServer starts the given Server and returns its address.
+func startServer(ctx context.Context, t *testing.T, s *Server) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = s.Serve(ctx, l)
+	}()
+	return l.Addr().String()
+}
+
+// startProxy starts a proxy Server that dials its destinations using a tunnel.Dialer and returns its address.
+func startProxy(ctx context.Context, t *testing.T, mode Mode) string {
+	return startServer(ctx, t, NewServer(mode, testResolve, testStreamCreator))
+}
+
+func assertEcho(t *testing.T, conn net.Conn, r io.Reader) {
+	msg := []byte("hello from the proxy client")
+	_, err := conn.Write(msg)
+	require.NoError(t, err)
+	buf := make([]byte, len(msg))
+	_, err = io.ReadFull(r, buf)
+	require.NoError(t, err)
+	assert.Equal(t, msg, buf)
+}
+
+func TestServer_SOCKS5(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	port := startEcho(ctx, t)
+	conn, err := net.DialTimeout("tcp", startProxy(ctx, t, ModeSOCKS5), 5*time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(10*time.Second)))
+
+	_, err = conn.Write([]byte{socksVersion5, 1, socksNoAuth})
+	require.NoError(t, err)
+	rsp := make([]byte, 2)
+	_, err = io.ReadFull(conn, rsp)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{socksVersion5, socksNoAuth}, rsp)
+
+	req := []byte{socksVersion5, socksCmdConnect, 0, socksAtypDomain, byte(len(echoHost))}
+	req = append(req, echoHost...)
+	req = binary.BigEndian.AppendUint16(req, port)
+	_, err = conn.Write(req)
+	require.NoError(t, err)
+	rsp = make([]byte, socksReplyLength)
+	_, err = io.ReadFull(conn, rsp)
+	require.NoError(t, err)
+	assert.Equal(t, byte(socksRepSucceeded), rsp[1])
+
+	assertEcho(t, conn, conn)
+}
+
+func TestServer_SOCKS5_unresolved(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	conn, err := net.DialTimeout("tcp", startProxy(ctx, t, ModeSOCKS5), 5*time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(10*time.Second)))
+
+	_, err = conn.Write([]byte{socksVersion5, 1, socksNoAuth})
+	require.NoError(t, err)
+	rsp := make([]byte, 2)
+	_, err = io.ReadFull(conn, rsp)
+	require.NoError(t, err)
+
+	const host = "unknown.example"
+	req := []byte{socksVersion5, socksCmdConnect, 0, socksAtypDomain, byte(len(host))}
+	req = append(req, host...)
+	req = binary.BigEndian.AppendUint16(req, 80)
+	_, err = conn.Write(req)
+	require.NoError(t, err)
+	rsp = make([]byte, socksReplyLength)
+	_, err = io.ReadFull(conn, rsp)
+	require.NoError(t, err)
+	assert.Equal(t, byte(socksRepHostUnreachable), rsp[1])
+}
+
+func TestServer_HTTPConnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	port := startEcho(ctx, t)
+	conn, err := net.DialTimeout("tcp", startProxy(ctx, t, ModeHTTP), 5*time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(10*time.Second)))
+
+	_, err = fmt.Fprintf(conn, "CONNECT %s:%d HTTP/1.1\r\nHost: %s:%d\r\n\r\n", echoHost, port, echoHost, port)
+	require.NoError(t, err)
+	br := bufio.NewReader(conn)
+	rsp, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	assertEcho(t, conn, br)
+}
+
+// failingSendStream is a stream that is dialed, but that fails to send anything.
+type failingSendStream struct {
+	tunnel.Stream
+}
+
+func (failingSendStream) Send(context.Context, tunnel.Message) error {
+	return errors.New("send failed")
+}
+
+func TestServer_HTTP_singleReply(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	port := startEcho(ctx, t)
+	addr := startServer(ctx, t, NewServer(ModeHTTP, testResolve, func(ctx context.Context, id tunnel.ConnID) (tunnel.Stream, error) {
+		s, err := testStreamCreator(ctx, id)
+		return failingSendStream{Stream: s}, err
+	}))
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(10*time.Second)))
+
+	// The reply to a plain request is produced by the destination, so the proxy has replied once it has
+	// dialed. It must not also reply with an error when the request can't be forwarded.
+	_, err = fmt.Fprintf(conn, "GET http://%s:%d/ HTTP/1.1\r\nHost: %s:%d\r\n\r\n", echoHost, port, echoHost, port)
+	require.NoError(t, err)
+	data, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Empty(t, string(data))
+}
+
+func TestForwarder(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	port := startEcho(ctx, t)
+	conn, err := net.DialTimeout("tcp", startServer(ctx, t, NewForwarder(echoHost, port, testResolve, testStreamCreator)), 5*time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(10*time.Second)))
+	assertEcho(t, conn, conn)
+}
+
+func TestParseMode(t *testing.T) {
+	m, err := ParseMode("SOCKS5")
+	require.NoError(t, err)
+	assert.Equal(t, ModeSOCKS5, m)
+	m, err = ParseMode("http")
+	require.NoError(t, err)
+	assert.Equal(t, ModeHTTP, m)
+	_, err = ParseMode("socks4")
+	assert.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+// SOCKS5 protocol constants as defined in RFC 1928.
+const (
+	socksVersion5 = 0x05
+
+	socksNoAuth       = 0x00
+	socksNoAcceptable = 0xff
+
+	socksCmdConnect = 0x01
+
+	socksAtypIPv4   = 0x01
+	socksAtypDomain = 0x03
+	socksAtypIPv6   = 0x04
+
+	socksRepSucceeded        = 0x00
+	socksRepHostUnreachable  = 0x04
+	socksRepCmdNotSupported  = 0x07
+	socksRepAtypNotSupported = 0x08
+
+	// socksReplyLength is the length of a reply that uses an IPv4 bound address.
+	socksReplyLength = 10
+)
+
+type socks5 struct {
+	w    io.Writer
+	host string
+	port uint16
+}
+
+// socks5Handshake performs the method negotiation and reads the client's request. Only the CONNECT
+// command and the "no authentication required" method are supported.
+func socks5Handshake(r *bufio.Reader, w io.Writer) (handshake, error) {
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, err
+	}
+	if hdr[0] != socksVersion5 {
+		return nil, fmt.Errorf("unsupported SOCKS version %d", hdr[0])
+	}
+	methods := make([]byte, hdr[1])
+	if _, err := io.ReadFull(r, methods); err != nil {
+		return nil, err
+	}
+	method := byte(socksNoAcceptable)
+	for _, m := range methods {
+		if m == socksNoAuth {
+			method = socksNoAuth
+			break
+		}
+	}
+	if _, err := w.Write([]byte{socksVersion5, method}); err != nil {
+		return nil, err
+	}
+	if method == socksNoAcceptable {
+		return nil, errors.New("client does not support unauthenticated SOCKS5")
+	}
+
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(r, req); err != nil {
+		return nil, err
+	}
+	s := &socks5{w: w}
+	if req[0] != socksVersion5 {
+		return nil, fmt.Errorf("unsupported SOCKS version %d", req[0])
+	}
+	if req[1] != socksCmdConnect {
+		_ = s.writeReply(socksRepCmdNotSupported)
+		return nil, fmt.Errorf("unsupported SOCKS command %d", req[1])
+	}
+
+	switch req[3] {
+	case socksAtypIPv4, socksAtypIPv6:
+		ip := make(net.IP, net.IPv4len)
+		if req[3] == socksAtypIPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		if _, err := io.ReadFull(r, ip); err != nil {
+			return nil, err
+		}
+		s.host = ip.String()
+	case socksAtypDomain:
+		l, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		name := make([]byte, l)
+		if _, err = io.ReadFull(r, name); err != nil {
+			return nil, err
+		}
+		s.host = string(name)
+	default:
+		_ = s.writeReply(socksRepAtypNotSupported)
+		return nil, fmt.Errorf("unsupported SOCKS address type %d", req[3])
+	}
+
+	pb := make([]byte, 2)
+	if _, err := io.ReadFull(r, pb); err != nil {
+		return nil, err
+	}
+	s.port = binary.BigEndian.Uint16(pb)
+	return s, nil
+}
+
+func (s *socks5) destination() (string, uint16) {
+	return s.host, s.port
+}
+
+func (s *socks5) reply(ok bool) error {
+	if ok {
+		return s.writeReply(socksRepSucceeded)
+	}
+	return s.writeReply(socksRepHostUnreachable)
+}
+
+func (s *socks5) initialData() []byte {
+	return nil
+}
+
+// writeReply writes a reply with the given code. The bound address is always reported as 0.0.0.0:0 since
+// the actual connection is made in the cluster.
+func (s *socks5) writeReply(rep byte) error {
+	b := make([]byte, socksReplyLength)
+	b[0] = socksVersion5
+	b[1] = rep
+	b[3] = socksAtypIPv4
+	_, err := s.w.Write(b)
+	return err
+}
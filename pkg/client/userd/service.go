@@ -26,8 +26,9 @@ type Service interface {
 	Server() *grpc.Server
 
 	// SetManagerClient will assign the manager client that this Service will use when acting as
-	// a ManagerServer proxy
-	SetManagerClient(manager.ManagerClient, ...grpc.CallOption)
+	// a ManagerServer proxy for the session of the given Kubernetes context. A nil client removes
+	// the assignment.
+	SetManagerClient(string, manager.ManagerClient, ...grpc.CallOption)
 
 	// GetAPIKey returns the current API key
 	GetAPIKey(context.Context) (string, error)
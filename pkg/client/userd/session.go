@@ -14,6 +14,7 @@ import (
 	"github.com/telepresenceio/telepresence/rpc/v2/common"
 	"github.com/telepresenceio/telepresence/rpc/v2/connector"
 	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
 	"github.com/telepresenceio/telepresence/rpc/v2/manager"
 	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
 	"github.com/telepresenceio/telepresence/v2/pkg/client"
@@ -25,6 +26,14 @@ type WatchWorkloadsStream interface {
 	Send(*rpc.WorkloadInfoSnapshot) error
 }
 
+type CaptureStream interface {
+	Send(*daemon.CapturedPacket) error
+}
+
+type DNSQueryLogStream interface {
+	Send(*daemon.DNSQueryLogEntry) error
+}
+
 type InterceptInfo interface {
 	APIKey() string
 	InterceptResult() *rpc.InterceptResult
@@ -88,6 +97,21 @@ type Session interface {
 
 	ApplyConfig(context.Context) error
 	GetConfig(context.Context) (*client.SessionConfig, error)
+	GetConnections(context.Context) (*daemon.Connections, error)
+	Capture(context.Context, *daemon.CaptureRequest, CaptureStream) error
+	AddChaosRule(context.Context, *daemon.ChaosRule) (*daemon.ChaosRule, error)
+	RemoveChaosRule(context.Context, *daemon.RemoveChaosRuleRequest) error
+	GetChaosRules(context.Context) (*daemon.ChaosRules, error)
+	GetDNSCache(context.Context, *daemon.DNSCacheRequest) (*daemon.DNSCache, error)
+	QueryDNS(context.Context, *daemon.DNSQueryRequest) (*daemon.DNSQueryResponse, error)
+	GetDNSQueryLog(context.Context, *daemon.DNSQueryLogRequest, DNSQueryLogStream) error
+	AddForward(context.Context, *rpc.Forward) (*rpc.Forward, error)
+	RemoveForward(context.Context, *rpc.RemoveForwardRequest) error
+	GetForwards(context.Context) (*rpc.Forwards, error)
+	Expose(context.Context, *manager.ExposeRequest) (*manager.ExposeInfo, error)
+	Unexpose(context.Context, string) error
+	GetAuditLog(context.Context, *manager.AuditLogRequest) (*manager.AuditLog, error)
+	Diagnose(context.Context) (*daemon.Diagnosis, error)
 	StartServices(g *dgroup.Group)
 	Epilog(ctx context.Context)
 	Done() <-chan struct{}
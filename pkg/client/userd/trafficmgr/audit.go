@@ -0,0 +1,32 @@
+package trafficmgr
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// GetAuditLog returns the records of the traffic-manager's audit log that match the given request.
+func (s *session) GetAuditLog(ctx context.Context, req *manager.AuditLogRequest) (*manager.AuditLog, error) {
+	req = proto.Clone(req).(*manager.AuditLogRequest)
+	req.Session = s.SessionInfo()
+	ctx, cancel := client.GetConfig(ctx).Timeouts.TimeoutContext(ctx, client.TimeoutTrafficManagerAPI)
+	defer cancel()
+	al, err := s.managerClient.GetAuditLog(ctx, req)
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unimplemented:
+			return nil, errcat.User.Newf("traffic-manager %s does not support audit", s.managerVersion)
+		case codes.FailedPrecondition:
+			return nil, errcat.User.New(status.Convert(err).Message())
+		}
+		return nil, err
+	}
+	return al, nil
+}
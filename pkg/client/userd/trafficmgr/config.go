@@ -2,25 +2,162 @@ package trafficmgr
 
 import (
 	"context"
+	"errors"
+	"io"
 	"path/filepath"
 
 	empty "google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
 	"github.com/telepresenceio/telepresence/rpc/v2/manager"
 	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
 	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
 )
 
-func (s *session) GetConfig(ctx context.Context) (*client.SessionConfig, error) {
-	nc, err := s.rootDaemon.GetNetworkConfig(ctx, &empty.Empty{})
+// GetConnections returns the connections that the root daemon currently tunnels to the cluster.
+func (s *session) GetConnections(ctx context.Context) (*daemon.Connections, error) {
+	if s.rootDaemon == nil {
+		return nil, errcat.User.New("connections are tracked by the root daemon, which isn't used in proxy mode")
+	}
+	return s.rootDaemon.GetConnections(ctx, &empty.Empty{})
+}
+
+// Capture relays the packets captured by the root daemon to the given stream.
+func (s *session) Capture(ctx context.Context, req *daemon.CaptureRequest, stream userd.CaptureStream) error {
+	if s.rootDaemon == nil {
+		return errcat.User.New("packets are captured by the root daemon, which isn't used in proxy mode")
+	}
+	cc, err := s.rootDaemon.Capture(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		p, err := cc.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err = stream.Send(p); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *session) chaosRootDaemon() (daemon.DaemonClient, error) {
+	if s.rootDaemon == nil {
+		return nil, errcat.User.New("chaos rules are applied by the root daemon, which isn't used in proxy mode")
+	}
+	return s.rootDaemon, nil
+}
+
+// AddChaosRule adds a chaos rule to the root daemon.
+func (s *session) AddChaosRule(ctx context.Context, cr *daemon.ChaosRule) (*daemon.ChaosRule, error) {
+	rd, err := s.chaosRootDaemon()
+	if err != nil {
+		return nil, err
+	}
+	return rd.AddChaosRule(ctx, cr)
+}
+
+// RemoveChaosRule removes one or all of the root daemon's chaos rules.
+func (s *session) RemoveChaosRule(ctx context.Context, rr *daemon.RemoveChaosRuleRequest) error {
+	rd, err := s.chaosRootDaemon()
+	if err != nil {
+		return err
+	}
+	_, err = rd.RemoveChaosRule(ctx, rr)
+	return err
+}
+
+// GetChaosRules returns the root daemon's chaos rules.
+func (s *session) GetChaosRules(ctx context.Context) (*daemon.ChaosRules, error) {
+	rd, err := s.chaosRootDaemon()
+	if err != nil {
+		return nil, err
+	}
+	return rd.GetChaosRules(ctx, &empty.Empty{})
+}
+
+func (s *session) dnsRootDaemon() (daemon.DaemonClient, error) {
+	if s.rootDaemon == nil {
+		return nil, errcat.User.New("names are resolved by the root daemon's DNS server, which isn't used in proxy mode")
+	}
+	return s.rootDaemon, nil
+}
+
+// GetDNSCache returns the statistics and the entries of the root daemon's DNS cache.
+func (s *session) GetDNSCache(ctx context.Context, rq *daemon.DNSCacheRequest) (*daemon.DNSCache, error) {
+	rd, err := s.dnsRootDaemon()
+	if err != nil {
+		return nil, err
+	}
+	return rd.GetDNSCache(ctx, rq)
+}
+
+// QueryDNS resolves a name using the root daemon's DNS server.
+func (s *session) QueryDNS(ctx context.Context, rq *daemon.DNSQueryRequest) (*daemon.DNSQueryResponse, error) {
+	rd, err := s.dnsRootDaemon()
 	if err != nil {
 		return nil, err
 	}
+	return rd.QueryDNS(ctx, rq)
+}
+
+// GetDNSQueryLog relays the entries of the root daemon's DNS query log to the given stream.
+func (s *session) GetDNSQueryLog(ctx context.Context, req *daemon.DNSQueryLogRequest, stream userd.DNSQueryLogStream) error {
+	rd, err := s.dnsRootDaemon()
+	if err != nil {
+		return err
+	}
+	qc, err := rd.GetDNSQueryLog(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		e, err := qc.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err = stream.Send(e); err != nil {
+			return err
+		}
+	}
+}
+
+// GetConfig returns the session's config. The network config of the root daemon is included unless the session
+// is in proxy mode, where no root daemon is used.
+func (s *session) GetConfig(ctx context.Context) (*client.SessionConfig, error) {
 	cfgDir, err := filelocation.AppUserConfigDir(ctx)
 	if err != nil {
 		return nil, err
 	}
+	cfg := *s.GetSessionConfig()
+	if s.rootDaemon != nil {
+		if err = s.addNetworkConfig(ctx, &cfg); err != nil {
+			return nil, err
+		}
+	}
+	return &client.SessionConfig{
+		ClientFile:       filepath.Join(cfgDir, client.ConfigFile),
+		Config:           &cfg,
+		ManagerNamespace: s.GetManagerNamespace(),
+	}, nil
+}
+
+// addNetworkConfig adds the routing and DNS config that the root daemon uses to the given config.
+func (s *session) addNetworkConfig(ctx context.Context, cfg *client.Config) error {
+	nc, err := s.rootDaemon.GetNetworkConfig(ctx, &empty.Empty{})
+	if err != nil {
+		return err
+	}
 	oi := nc.OutboundInfo
 	dns := oi.Dns
 	subnets := func(rs []*manager.IPNet) []*iputil.Subnet {
@@ -30,21 +167,19 @@ func (s *session) GetConfig(ctx context.Context) (*client.SessionConfig, error)
 		}
 		return ss
 	}
-	return &client.SessionConfig{
-		ClientFile: filepath.Join(cfgDir, client.ConfigFile),
-		Config:     s.GetSessionConfig(),
-		DNS: client.DNS{
-			LocalIP:         dns.LocalIp,
-			RemoteIP:        dns.RemoteIp,
-			IncludeSuffixes: dns.IncludeSuffixes,
-			ExcludeSuffixes: dns.ExcludeSuffixes,
-			LookupTimeout:   dns.LookupTimeout.AsDuration(),
-		},
-		Routing: client.Routing{
-			Subnets:    subnets(nc.Subnets),
-			AlsoProxy:  subnets(oi.AlsoProxySubnets),
-			NeverProxy: subnets(oi.NeverProxySubnets),
-		},
-		ManagerNamespace: s.GetManagerNamespace(),
-	}, nil
+	cfg.Routing.Subnets = subnets(nc.Subnets)
+	cfg.Routing.AlsoProxy = subnets(oi.AlsoProxySubnets)
+	cfg.Routing.NeverProxy = subnets(oi.NeverProxySubnets)
+	cfg.Routing.AlsoProxyHosts = oi.AlsoProxyHosts
+	cfg.Routing.NeverProxyHosts = oi.NeverProxyHosts
+	cfg.DNS.LocalIP = dns.LocalIp
+	cfg.DNS.RemoteIP = dns.RemoteIp
+	cfg.DNS.IncludeSuffixes = dns.IncludeSuffixes
+	cfg.DNS.ExcludeSuffixes = dns.ExcludeSuffixes
+	cfg.DNS.LookupTimeout = dns.LookupTimeout.AsDuration()
+	cfg.DNS.Mappings = make([]*client.DNSMapping, len(dns.Mappings))
+	for i, m := range dns.Mappings {
+		cfg.DNS.Mappings[i] = &client.DNSMapping{Name: m.Name, AliasFor: m.AliasFor, IP: m.Ip}
+	}
+	return nil
 }
@@ -0,0 +1,80 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/blang/semver"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/version"
+)
+
+// Diagnose checks the connection to the traffic-manager and its version, and adds the checks performed by the root
+// daemon, which verifies the network configuration and the reachability of the traffic-agents.
+func (s *session) Diagnose(ctx context.Context) (*daemon.Diagnosis, error) {
+	checks := []*daemon.DiagnosticCheck{s.checkManager(ctx), checkVersions(version.Structured, s.managerVersion)}
+	if s.rootDaemon == nil {
+		checks = append(checks, &daemon.DiagnosticCheck{
+			Name:    "root-daemon",
+			Status:  daemon.DiagnosticCheck_SKIPPED,
+			Message: "no root daemon is used in proxy mode, so there's no network to check",
+		})
+		return &daemon.Diagnosis{Checks: checks}, nil
+	}
+
+	dr := &daemon.DiagnoseRequest{AgentIps: make(map[string][]byte)}
+	for _, ns := range s.GetCurrentNamespaces(true) {
+		for name, ai := range s.getCurrentAgentsInNamespace(ns) {
+			if ip := net.ParseIP(ai.PodIp); ip != nil {
+				dr.AgentIps[name+"."+ns] = ip
+			}
+		}
+	}
+	rd, err := s.rootDaemon.Diagnose(ctx, dr)
+	if err != nil {
+		checks = append(checks, &daemon.DiagnosticCheck{
+			Name:    "root-daemon",
+			Status:  daemon.DiagnosticCheck_FAILED,
+			Message: fmt.Sprintf("unable to diagnose the network: %v", err),
+			Hint:    "The root daemon may have crashed. Check its log (daemon.log), and reconnect using \"telepresence quit -s\" and \"telepresence connect\".",
+		})
+		return &daemon.Diagnosis{Checks: checks}, nil
+	}
+	return &daemon.Diagnosis{Checks: append(checks, rd.Checks...)}, nil
+}
+
+// checkManager checks that the traffic-manager responds through the port-forward of this session.
+func (s *session) checkManager(ctx context.Context) *daemon.DiagnosticCheck {
+	dc := &daemon.DiagnosticCheck{Name: "traffic-manager"}
+	ctx, cancel := client.GetConfig(ctx).Timeouts.TimeoutContext(ctx, client.TimeoutTrafficManagerAPI)
+	defer cancel()
+	start := time.Now()
+	if _, err := s.managerClient.Version(ctx, &empty.Empty{}); err != nil {
+		dc.Status = daemon.DiagnosticCheck_FAILED
+		dc.Message = fmt.Sprintf("no response from the traffic-manager in namespace %s: %v", s.GetManagerNamespace(), err)
+		dc.Hint = fmt.Sprintf("The port-forward to the traffic-manager is broken. Check that its pod is running using "+
+			"\"kubectl -n %s get pods\", and reconnect using \"telepresence quit\" and \"telepresence connect\".", s.GetManagerNamespace())
+		return dc
+	}
+	dc.Message = fmt.Sprintf("namespace %s responded in %s", s.GetManagerNamespace(), time.Since(start).Round(time.Millisecond))
+	return dc
+}
+
+// checkVersions checks that the client and the traffic-manager have the same major and minor version.
+func checkVersions(clientVersion, managerVersion semver.Version) *daemon.DiagnosticCheck {
+	dc := &daemon.DiagnosticCheck{
+		Name:    "version",
+		Message: fmt.Sprintf("client v%s, traffic-manager v%s", clientVersion, managerVersion),
+	}
+	if clientVersion.Major != managerVersion.Major || clientVersion.Minor != managerVersion.Minor {
+		dc.Status = daemon.DiagnosticCheck_WARNING
+		dc.Hint = "Features may be missing or behave differently. Use \"telepresence helm upgrade\" to install a " +
+			"traffic-manager with the same version as the client."
+	}
+	return dc
+}
@@ -0,0 +1,74 @@
+package trafficmgr
+
+import (
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// egressKinds are the workload kinds accepted in an egress rule.
+var egressKinds = map[string]struct{}{ //nolint:gochecknoglobals // constant
+	"deploy":       {},
+	"deployment":   {},
+	"deployments":  {},
+	"rs":           {},
+	"replicaset":   {},
+	"replicasets":  {},
+	"sts":          {},
+	"statefulset":  {},
+	"statefulsets": {},
+}
+
+// parseEgressRules parses egress rules on the form "[<kind>/]<name>[.<namespace>][=<destination>,...]", where
+// each destination is a CIDR, an IP address, or a host name. Workloads without a namespace are assumed to be in
+// the given default namespace.
+func parseEgressRules(specs []string, defaultNamespace string) ([]*manager.EgressRule, error) {
+	rules := make([]*manager.EgressRule, 0, len(specs))
+	for _, spec := range specs {
+		workload, dests, hasDests := strings.Cut(spec, "=")
+		if kind, name, ok := strings.Cut(workload, "/"); ok {
+			if _, ok := egressKinds[strings.ToLower(kind)]; !ok {
+				return nil, errcat.User.Newf("invalid egress rule %q: unsupported workload kind %q", spec, kind)
+			}
+			workload = name
+		}
+		namespace := defaultNamespace
+		if dot := strings.LastIndexByte(workload, '.'); dot > 0 {
+			namespace = workload[dot+1:]
+			workload = workload[:dot]
+		}
+		if errs := validation.IsDNS1123Subdomain(workload); len(errs) > 0 {
+			return nil, errcat.User.Newf("invalid egress rule %q: %s", spec, strings.Join(errs, ", "))
+		}
+		rule := &manager.EgressRule{Workload: workload, Namespace: namespace}
+		if hasDests {
+			for _, d := range strings.Split(dests, ",") {
+				if d = strings.TrimSpace(d); d == "" {
+					continue
+				}
+				if _, _, err := net.ParseCIDR(d); err != nil && net.ParseIP(d) == nil {
+					if errs := validation.IsDNS1123Subdomain(strings.ToLower(d)); len(errs) > 0 {
+						return nil, errcat.User.Newf("invalid egress rule %q: destination %q is neither a CIDR, an IP, nor a host name", spec, d)
+					}
+				}
+				rule.Destinations = append(rule.Destinations, d)
+			}
+			if len(rule.Destinations) == 0 {
+				return nil, errcat.User.Newf("invalid egress rule %q: no destinations after '='", spec)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func destinationsOrAll(dests []string) any {
+	if len(dests) == 0 {
+		return "all destinations"
+	}
+	return dests
+}
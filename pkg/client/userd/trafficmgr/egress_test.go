@@ -0,0 +1,32 @@
+package trafficmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+func TestParseEgressRules(t *testing.T) {
+	rules, err := parseEgressRules([]string{
+		"deploy/api",
+		"sts/db-client.staging=10.100.0.0/16, mydb.cluster-xyz.rds.amazonaws.com,192.168.1.5",
+		"worker",
+	}, "default")
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	assert.Equal(t, &manager.EgressRule{Workload: "api", Namespace: "default"}, rules[0])
+	assert.Equal(t, &manager.EgressRule{
+		Workload:     "db-client",
+		Namespace:    "staging",
+		Destinations: []string{"10.100.0.0/16", "mydb.cluster-xyz.rds.amazonaws.com", "192.168.1.5"},
+	}, rules[1])
+	assert.Equal(t, &manager.EgressRule{Workload: "worker", Namespace: "default"}, rules[2])
+
+	for _, bad := range []string{"svc/api", "Api", "api=", "api=not a host"} {
+		_, err = parseEgressRules([]string{bad}, "default")
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,53 @@
+package trafficmgr
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+)
+
+// Expose asks the traffic-manager to create a Service that extends its connections to a local port.
+func (s *session) Expose(ctx context.Context, er *manager.ExposeRequest) (*manager.ExposeInfo, error) {
+	er = proto.Clone(er).(*manager.ExposeRequest)
+	er.Session = s.SessionInfo()
+	ctx, cancel := client.GetConfig(ctx).Timeouts.TimeoutContext(ctx, client.TimeoutTrafficManagerAPI)
+	defer cancel()
+	ei, err := s.managerClient.Expose(ctx, er)
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unimplemented:
+			return nil, errcat.User.Newf("traffic-manager %s does not support expose", s.managerVersion)
+		case codes.InvalidArgument, codes.AlreadyExists:
+			return nil, errcat.User.New(status.Convert(err).Message())
+		}
+		return nil, err
+	}
+	return ei, nil
+}
+
+// Unexpose asks the traffic-manager to remove a Service created by Expose. The traffic-manager keeps track of the
+// Services that each session has exposed, and it retains them when the session reconnects, so the session doesn't
+// keep its own record that could go stale.
+func (s *session) Unexpose(ctx context.Context, name string) error {
+	dlog.Debugf(ctx, "Removing exposed service %s", name)
+	ctx, cancel := client.GetConfig(ctx).Timeouts.TimeoutContext(ctx, client.TimeoutTrafficManagerAPI)
+	defer cancel()
+	_, err := s.managerClient.Unexpose(ctx, &manager.UnexposeRequest{
+		Session: s.SessionInfo(),
+		Name:    name,
+	})
+	switch status.Code(err) {
+	case codes.Unimplemented:
+		return errcat.User.Newf("traffic-manager %s does not support expose", s.managerVersion)
+	case codes.NotFound:
+		return errcat.User.Newf("service %q is not exposed", name)
+	}
+	return err
+}
@@ -0,0 +1,198 @@
+package trafficmgr
+
+import (
+	"context"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cache"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/proxy"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+const forwardsFile = "forwards.json"
+
+// forwardsFileLock serializes the updates of the forwards file made by sessions for different contexts.
+var forwardsFileLock sync.Mutex //nolint:gochecknoglobals // protects a file
+
+// savedForwards are the persisted forwards, keyed by the name of the kubernetes context they belong to.
+type savedForwards map[string][]*rpc.Forward
+
+func loadForwards(ctx context.Context) (savedForwards, error) {
+	var sf savedForwards
+	if err := cache.LoadFromUserCache(ctx, &sf, forwardsFile); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		sf = make(savedForwards)
+	}
+	return sf, nil
+}
+
+type forward struct {
+	*rpc.Forward
+	cancel context.CancelFunc
+}
+
+// startForward starts a listener for the given forward. The forward's Error is set if the listener can't be started.
+func (s *session) startForward(ctx context.Context, f *rpc.Forward) *forward {
+	f.Error = ""
+	addr := net.JoinHostPort(f.LocalAddress, strconv.Itoa(int(f.LocalPort)))
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		f.Error = err.Error()
+		dlog.Errorf(ctx, "Unable to start forward %s: %v", f.Name, err)
+		return &forward{Forward: f, cancel: func() {}}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		fwd := proxy.NewForwarder(f.Host, uint16(f.RemotePort), s.proxyResolve, s.forwardStreams)
+		if err := fwd.Serve(ctx, l); err != nil {
+			dlog.Errorf(ctx, "Forward %s ended: %v", f.Name, err)
+		}
+	}()
+	dlog.Infof(ctx, "Forwarding %s to %s:%d", addr, f.Host, f.RemotePort)
+	return &forward{Forward: f, cancel: cancel}
+}
+
+// saveForwards persists the forwards of this session. The caller must hold the forwardsLock.
+func (s *session) saveForwards(ctx context.Context) error {
+	forwardsFileLock.Lock()
+	defer forwardsFileLock.Unlock()
+	sf, err := loadForwards(ctx)
+	if err != nil {
+		return err
+	}
+	if len(s.forwards) == 0 {
+		delete(sf, s.Kubeconfig.Context)
+	} else {
+		fs := make([]*rpc.Forward, 0, len(s.forwards))
+		for _, f := range s.forwards {
+			fs = append(fs, f.Forward)
+		}
+		sort.Slice(fs, func(i, j int) bool { return fs[i].Name < fs[j].Name })
+		sf[s.Kubeconfig.Context] = fs
+	}
+	if len(sf) == 0 {
+		return cache.DeleteFromUserCache(ctx, forwardsFile)
+	}
+	return cache.SaveToUserCache(ctx, sf, forwardsFile)
+}
+
+// forwardsWorker starts the persisted forwards of this session's context and then waits until the context is
+// cancelled, at which point all forwards are stopped.
+func (s *session) forwardsWorker(ctx context.Context) error {
+	s.forwardStreams = s.proxyStreamCreator(ctx)
+	forwardsFileLock.Lock()
+	sf, err := loadForwards(ctx)
+	forwardsFileLock.Unlock()
+	if err != nil {
+		dlog.Errorf(ctx, "Unable to load forwards: %v", err)
+	}
+
+	s.forwardsLock.Lock()
+	s.forwards = make(map[string]*forward)
+	for _, f := range sf[s.Kubeconfig.Context] {
+		s.forwards[f.Name] = s.startForward(ctx, f)
+	}
+	s.forwardsCtx = ctx
+	s.forwardsLock.Unlock()
+
+	<-ctx.Done()
+
+	s.forwardsLock.Lock()
+	for _, f := range s.forwards {
+		f.cancel()
+	}
+	s.forwards = nil
+	s.forwardsCtx = nil
+	s.forwardsLock.Unlock()
+	return nil
+}
+
+// AddForward adds and starts a named port-forward.
+func (s *session) AddForward(ctx context.Context, f *rpc.Forward) (*rpc.Forward, error) {
+	f = proto.Clone(f).(*rpc.Forward)
+	if f.Name == "" {
+		return nil, errcat.User.New("a forward must have a name")
+	}
+	if f.RemotePort <= 0 || f.RemotePort > 0xffff {
+		return nil, errcat.User.Newf("invalid remote port %d", f.RemotePort)
+	}
+	if f.LocalPort == 0 {
+		f.LocalPort = f.RemotePort
+	}
+	if f.LocalPort < 0 || f.LocalPort > 0xffff {
+		return nil, errcat.User.Newf("invalid local port %d", f.LocalPort)
+	}
+	if f.LocalAddress == "" {
+		f.LocalAddress = "127.0.0.1"
+	}
+	if f.Host == "" {
+		return nil, errcat.User.New("a forward must have a host")
+	}
+	if !strings.ContainsRune(f.Host, '.') && iputil.Parse(f.Host) == nil {
+		f.Host += "." + s.Namespace
+	}
+
+	s.forwardsLock.Lock()
+	defer s.forwardsLock.Unlock()
+	if s.forwardsCtx == nil {
+		return nil, status.Error(codes.Unavailable, "the session is not ready to accept forwards")
+	}
+	if _, ok := s.forwards[f.Name]; ok {
+		return nil, errcat.User.Newf("a forward named %q already exists", f.Name)
+	}
+	fw := s.startForward(s.forwardsCtx, f)
+	if fw.Error != "" {
+		return nil, errcat.User.Newf("unable to start forward %s: %s", f.Name, fw.Error)
+	}
+	s.forwards[f.Name] = fw
+	if err := s.saveForwards(ctx); err != nil {
+		dlog.Errorf(ctx, "Unable to save forwards: %v", err)
+	}
+	return proto.Clone(f).(*rpc.Forward), nil
+}
+
+// RemoveForward stops and removes one or all port-forwards.
+func (s *session) RemoveForward(ctx context.Context, rr *rpc.RemoveForwardRequest) error {
+	s.forwardsLock.Lock()
+	defer s.forwardsLock.Unlock()
+	if rr.All {
+		for name, f := range s.forwards {
+			f.cancel()
+			delete(s.forwards, name)
+		}
+	} else {
+		f, ok := s.forwards[rr.Name]
+		if !ok {
+			return errcat.User.Newf("no forward named %q", rr.Name)
+		}
+		f.cancel()
+		delete(s.forwards, rr.Name)
+	}
+	return s.saveForwards(ctx)
+}
+
+// GetForwards returns the port-forwards, sorted by name.
+func (s *session) GetForwards(context.Context) (*rpc.Forwards, error) {
+	s.forwardsLock.Lock()
+	fs := make([]*rpc.Forward, 0, len(s.forwards))
+	for _, f := range s.forwards {
+		fs = append(fs, proto.Clone(f.Forward).(*rpc.Forward))
+	}
+	s.forwardsLock.Unlock()
+	sort.Slice(fs, func(i, j int) bool { return fs[i].Name < fs[j].Name })
+	return &rpc.Forwards{Forwards: fs}, nil
+}
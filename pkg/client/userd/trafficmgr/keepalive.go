@@ -0,0 +1,102 @@
+package trafficmgr
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/datawire/dlib/dtime"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/tm"
+)
+
+const (
+	remainInterval          = 5 * time.Second
+	reconnectInitialBackoff = time.Second
+	reconnectMaxBackoff     = 30 * time.Second
+)
+
+// keepAlive keeps a session with the traffic-manager alive. It calls remain at every interval, and the
+// outcome of that call decides what happens next:
+//
+//   - success: the session is connected. If the connection was lost earlier, the length of the outage is logged.
+//   - NotFound: the traffic-manager has dropped the session, typically because the outage outlasted the client
+//     connection TTL. keepAlive gives up and returns ErrSessionExpired so that the caller can create a new session.
+//   - Unavailable or DeadlineExceeded: the connection is broken, typically because the laptop was suspended or
+//     switched network. A new connection is dialed, with exponential backoff between the attempts, until one
+//     succeeds or the context is cancelled. The new connection replaces the one in conn, and the session ID is
+//     retained, so the traffic-manager keeps the session and its intercepts.
+//   - other errors: logged and retried at the next interval.
+type keepAlive struct {
+	conn       *tm.Conn
+	remain     func(context.Context) error
+	dial       func(context.Context) (*grpc.ClientConn, error)
+	interval   time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// run runs the keep-alive loop until the context is cancelled or the session expires.
+func (k *keepAlive) run(c context.Context) error {
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+
+	var lostAt time.Time
+	for {
+		select {
+		case <-c.Done():
+			return nil
+		case <-ticker.C:
+			err := k.remain(c)
+			switch {
+			case err == nil:
+				if !lostAt.IsZero() {
+					dlog.Infof(c, "Connection to traffic-manager restored after %s", time.Since(lostAt).Round(time.Second))
+					lostAt = time.Time{}
+				}
+			case c.Err() != nil:
+				return nil
+			default:
+				dlog.Error(c, err)
+				switch status.Code(err) {
+				case codes.NotFound:
+					return ErrSessionExpired
+				case codes.Unavailable, codes.DeadlineExceeded:
+					if lostAt.IsZero() {
+						lostAt = time.Now()
+					}
+					k.reconnect(c)
+				}
+			}
+		}
+	}
+}
+
+// reconnect replaces the connection to the traffic-manager with a new one. It retries with backoff until
+// it succeeds or the context is cancelled.
+func (k *keepAlive) reconnect(c context.Context) {
+	dlog.Warn(c, "Connection to traffic-manager lost, reconnecting")
+	backoff := k.minBackoff
+	for c.Err() == nil {
+		conn, err := k.dial(c)
+		if err == nil {
+			k.conn.Replace(conn)
+			return
+		}
+		dlog.Errorf(c, "Unable to reconnect to traffic-manager: %v", err)
+		dtime.SleepWithContext(c, backoff)
+		backoff = nextBackoff(backoff, k.maxBackoff)
+	}
+}
+
+// nextBackoff returns the double of the given backoff, limited by max.
+func nextBackoff(backoff, max time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > max {
+		backoff = max
+	}
+	return backoff
+}
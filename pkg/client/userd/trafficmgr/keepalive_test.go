@@ -0,0 +1,301 @@
+package trafficmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/tm"
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+// fakeManager is a manager.ManagerClient that drops its connection and comes back after a given number of
+// failed dial attempts. It forgets all sessions if it was down for too long.
+type fakeManager struct {
+	manager.ManagerClient
+	sync.Mutex
+	sessions     map[string]struct{}
+	up           bool
+	failedDials  int
+	dialFailures int
+	forgetAfter  int
+	remains      []string
+}
+
+func (m *fakeManager) Remain(_ context.Context, rq *manager.RemainRequest, _ ...grpc.CallOption) (*empty.Empty, error) {
+	m.Lock()
+	defer m.Unlock()
+	if !m.up {
+		return nil, status.Error(codes.Unavailable, "connection refused")
+	}
+	if _, ok := m.sessions[rq.Session.SessionId]; !ok {
+		return nil, status.Errorf(codes.NotFound, "session %q not found", rq.Session.SessionId)
+	}
+	m.remains = append(m.remains, rq.Session.SessionId)
+	return &empty.Empty{}, nil
+}
+
+func (m *fakeManager) drop() {
+	m.Lock()
+	m.up = false
+	m.failedDials = 0
+	m.Unlock()
+}
+
+func (m *fakeManager) dial(ctx context.Context) (*grpc.ClientConn, error) {
+	m.Lock()
+	defer m.Unlock()
+	if m.failedDials < m.dialFailures {
+		m.failedDials++
+		if m.forgetAfter > 0 && m.failedDials >= m.forgetAfter {
+			m.sessions = map[string]struct{}{}
+		}
+		return nil, status.Error(codes.Unavailable, "connection refused")
+	}
+	m.up = true
+	return grpc.DialContext(ctx, "passthrough:///traffic-manager", grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+func (m *fakeManager) remainCount() int {
+	m.Lock()
+	defer m.Unlock()
+	return len(m.remains)
+}
+
+func newKeepAliveTest(t *testing.T, m *fakeManager) (*keepAlive, *grpc.ClientConn) {
+	conn, err := grpc.Dial("passthrough:///traffic-manager", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	si := &manager.SessionInfo{SessionId: "session-1"}
+	m.sessions = map[string]struct{}{si.SessionId: {}}
+	m.up = true
+	ka := &keepAlive{
+		conn: tm.NewConn(conn),
+		remain: func(ctx context.Context) error {
+			_, err := m.Remain(ctx, &manager.RemainRequest{Session: si})
+			return err
+		},
+		dial:       m.dial,
+		interval:   time.Millisecond,
+		minBackoff: time.Millisecond,
+		maxBackoff: 4 * time.Millisecond,
+	}
+	t.Cleanup(func() { _ = ka.conn.Close() })
+	return ka, conn
+}
+
+func TestNextBackoff(t *testing.T) {
+	var bs []time.Duration
+	b := time.Second
+	for i := 0; i < 7; i++ {
+		bs = append(bs, b)
+		b = nextBackoff(b, 30*time.Second)
+	}
+	assert.Equal(t, []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second,
+	}, bs)
+}
+
+func TestKeepAlive_restoresSession(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	m := &fakeManager{dialFailures: 3}
+	ka, firstConn := newKeepAliveTest(t, m)
+
+	done := make(chan error, 1)
+	go func() { done <- ka.run(ctx) }()
+
+	require.Eventually(t, func() bool { return m.remainCount() > 0 }, 5*time.Second, time.Millisecond)
+	m.drop()
+	before := m.remainCount()
+
+	// The connection is replaced after the failed dials, and the session is kept alive using the same ID.
+	require.Eventually(t, func() bool { return m.remainCount() > before }, 5*time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	m.Lock()
+	defer m.Unlock()
+	assert.Equal(t, 3, m.failedDials)
+	assert.NotSame(t, firstConn, ka.conn.ClientConn())
+	for _, id := range m.remains {
+		assert.Equal(t, "session-1", id)
+	}
+}
+
+func TestKeepAlive_givesUpWhenSessionExpired(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	m := &fakeManager{dialFailures: 5, forgetAfter: 3}
+	ka, _ := newKeepAliveTest(t, m)
+
+	done := make(chan error, 1)
+	go func() { done <- ka.run(ctx) }()
+	require.Eventually(t, func() bool { return m.remainCount() > 0 }, 5*time.Second, time.Millisecond)
+	m.drop()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrSessionExpired)
+	case <-time.After(5 * time.Second):
+		t.Fatal("keepAlive didn't give up on an expired session")
+	}
+}
+
+func TestKeepAlive_cancelWhileReconnecting(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	m := &fakeManager{dialFailures: 1 << 30}
+	ka, _ := newKeepAliveTest(t, m)
+
+	done := make(chan error, 1)
+	go func() { done <- ka.run(ctx) }()
+	require.Eventually(t, func() bool { return m.remainCount() > 0 }, 5*time.Second, time.Millisecond)
+	m.drop()
+	require.Eventually(t, func() bool {
+		m.Lock()
+		defer m.Unlock()
+		return m.failedDials > 2
+	}, 5*time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("keepAlive didn't return when its context was cancelled")
+	}
+}
+
+// tunnelManager is a traffic-manager gRPC server that accepts sessions and echoes everything that is sent
+// on its multiplexed tunnels.
+type tunnelManager struct {
+	manager.UnimplementedManagerServer
+	lis     *bufconn.Listener
+	srv     *grpc.Server
+	tunnels int32
+}
+
+func startTunnelManager(t *testing.T) *tunnelManager {
+	m := &tunnelManager{lis: bufconn.Listen(1 << 20), srv: grpc.NewServer()}
+	manager.RegisterManagerServer(m.srv, m)
+	go func() { _ = m.srv.Serve(m.lis) }()
+	t.Cleanup(m.srv.Stop)
+	return m
+}
+
+func (m *tunnelManager) Remain(context.Context, *manager.RemainRequest) (*empty.Empty, error) {
+	return &empty.Empty{}, nil
+}
+
+func (m *tunnelManager) Tunnel(s manager.Manager_TunnelServer) error {
+	atomic.AddInt32(&m.tunnels, 1)
+	return tunnel.ServeStreams(s.Context(), s, func(ctx context.Context, s tunnel.Stream) error {
+		for {
+			msg, err := s.Receive(ctx)
+			if err != nil {
+				if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+					return nil
+				}
+				return err
+			}
+			if err = s.Send(ctx, msg); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+func (m *tunnelManager) dial(ctx context.Context) (*grpc.ClientConn, error) {
+	return grpc.DialContext(ctx, "passthrough:///traffic-manager",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return m.lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+func (m *tunnelManager) tunnelCount() int {
+	return int(atomic.LoadInt32(&m.tunnels))
+}
+
+// echoOnce opens a stream using the given MuxClient and verifies that a message is echoed back.
+func echoOnce(ctx context.Context, mc *tunnel.MuxClient, port uint16) error {
+	id := tunnel.NewConnID(ipproto.TCP, iputil.Parse("127.0.0.1"), iputil.Parse("192.168.0.1"), port, 8080)
+	s, err := mc.NewStream(ctx, id, "session-1", 0, 0)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.CloseSend(ctx) }()
+	payload := fmt.Sprintf("hello from %d", port)
+	if err = s.Send(ctx, tunnel.NewMessage(tunnel.Normal, []byte(payload))); err != nil {
+		return err
+	}
+	msg, err := s.Receive(ctx)
+	if err != nil {
+		return err
+	}
+	if string(msg.Payload()) != payload {
+		return fmt.Errorf("expected %q, got %q", payload, msg.Payload())
+	}
+	return nil
+}
+
+// TestKeepAlive_reestablishesTunnels verifies that the tunnel streams that the root daemon opens using the
+// manager client are re-established on the new connection once the keepAlive has replaced the connection
+// to a traffic-manager that went away.
+func TestKeepAlive_reestablishesTunnels(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+	first := startTunnelManager(t)
+	second := startTunnelManager(t)
+
+	conn, err := first.dial(ctx)
+	require.NoError(t, err)
+	mConn := tm.NewConn(conn)
+	t.Cleanup(func() { _ = mConn.Close() })
+	mClient := manager.NewManagerClient(mConn)
+
+	ka := &keepAlive{
+		conn: mConn,
+		remain: func(ctx context.Context) error {
+			_, err := mClient.Remain(ctx, &manager.RemainRequest{Session: &manager.SessionInfo{SessionId: "session-1"}})
+			return err
+		},
+		dial:       second.dial,
+		interval:   time.Millisecond,
+		minBackoff: time.Millisecond,
+		maxBackoff: 4 * time.Millisecond,
+	}
+	done := make(chan error, 1)
+	go func() { done <- ka.run(ctx) }()
+
+	mc := tunnel.NewMuxClient(ctx, func(ctx context.Context) (tunnel.GRPClientCStream, error) {
+		return mClient.Tunnel(ctx)
+	}, 1)
+	require.NoError(t, echoOnce(ctx, mc, 1001))
+	assert.Equal(t, 1, first.tunnelCount())
+
+	// Make the first traffic-manager go away, and wait for the keepAlive to replace the connection.
+	first.srv.Stop()
+	require.Eventually(t, func() bool { return mConn.ClientConn() != conn }, 5*time.Second, time.Millisecond)
+
+	require.Eventually(t, func() bool { return echoOnce(ctx, mc, 1002) == nil }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, first.tunnelCount())
+	assert.Equal(t, 1, second.tunnelCount())
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,117 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	dns2 "github.com/miekg/dns"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/proxy"
+	"github.com/telepresenceio/telepresence/v2/pkg/dnsproxy"
+	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+// proxyServe runs the local SOCKS5 or HTTP proxy that is used instead of the root daemon when the
+// session is in proxy mode.
+func (s *session) proxyServe(ctx context.Context) error {
+	return proxy.NewServer(s.proxyMode, s.proxyResolve, s.proxyStreamCreator(ctx)).Serve(ctx, s.proxyListener)
+}
+
+// proxyResolve resolves the given host name using the traffic-manager's DNS lookup.
+func (s *session) proxyResolve(ctx context.Context, host string) (net.IP, error) {
+	name := dns2.Fqdn(host)
+	if !dnsproxy.ManagerCanDoDNSQueryTypes(s.managerVersion) {
+		//nolint:staticcheck // retained for backward compatibility
+		r, err := s.managerClient.LookupHost(ctx, &manager.LookupHostRequest{Session: s.sessionInfo, Name: host})
+		if err != nil {
+			return nil, err
+		}
+		if ips := iputil.IPsFromBytesSlice(r.Ips); len(ips) > 0 {
+			return ips[0], nil
+		}
+		return nil, fmt.Errorf("unable to resolve %q in the cluster", host)
+	}
+
+	for _, qType := range []uint16{dns2.TypeA, dns2.TypeAAAA} {
+		r, err := s.managerClient.LookupDNS(ctx, &manager.DNSRequest{
+			Session: s.sessionInfo,
+			Name:    name,
+			Type:    uint32(qType),
+		})
+		if err != nil {
+			return nil, err
+		}
+		rrs, _, err := dnsproxy.FromRPC(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, rr := range rrs {
+			switch rr := rr.(type) {
+			case *dns2.A:
+				return rr.A, nil
+			case *dns2.AAAA:
+				return rr.AAAA, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unable to resolve %q in the cluster", host)
+}
+
+// proxyStreamCreator returns a tunnel.StreamCreator that creates streams to the traffic-manager.
+// The streams are multiplexed over a few gRPC streams that end when the given context is cancelled.
+func (s *session) proxyStreamCreator(ctx context.Context) tunnel.StreamCreator {
+	mc := tunnel.NewMuxClient(ctx, func(ctx context.Context) (tunnel.GRPClientCStream, error) {
+		return s.managerClient.Tunnel(ctx)
+	}, tunnel.DefaultMaxMuxStreams)
+	return func(ctx context.Context, id tunnel.ConnID) (tunnel.Stream, error) {
+		dlog.Debugf(ctx, "Opening tunnel for id %s", id)
+		cfg := client.GetConfig(ctx)
+		tc := cfg.Timeouts
+		ctx = tunnel.WithCompression(ctx, cfg.Tunnel.Compression)
+		return mc.NewStream(ctx, id, s.sessionInfo.SessionId, tc.Get(client.TimeoutRoundtripLatency), tc.Get(client.TimeoutEndpointDial))
+	}
+}
+
+// listenProxy creates the listener for the local proxy requested by the given ConnectRequest.
+func (s *session) listenProxy(cr *rpc.ConnectRequest) error {
+	mode, err := proxy.ParseMode(cr.ProxyMode)
+	if err != nil {
+		return err
+	}
+	addr := cr.ProxyAddress
+	if addr == "" {
+		addr = proxy.DefaultAddress
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errcat.User.Newf("unable to listen to %s: %v", addr, err)
+	}
+	s.proxyMode = mode
+	s.proxyListener = l
+	return nil
+}
+
+// setProxyInfo adds the proxy mode and address to the given ConnectInfo when the session is in proxy mode.
+func (s *session) setProxyInfo(ci *rpc.ConnectInfo) {
+	if s.proxyListener != nil {
+		ci.ProxyMode = string(s.proxyMode)
+		ci.ProxyAddress = s.proxyListener.Addr().String()
+	}
+}
+
+// proxyModeChanged returns true if the given ConnectRequest asks for a proxy mode that differs from the
+// one used by this session.
+func (s *session) proxyModeChanged(cr *rpc.ConnectRequest) bool {
+	if cr.ProxyMode == "" {
+		return false
+	}
+	mode, err := proxy.ParseMode(cr.ProxyMode)
+	return err != nil || mode != s.proxyMode
+}
@@ -46,6 +46,7 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/client/tm"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/userd"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/proxy"
 	"github.com/telepresenceio/telepresence/v2/pkg/dnet"
 	"github.com/telepresenceio/telepresence/v2/pkg/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/install/helm"
@@ -53,6 +54,7 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/maps"
 	"github.com/telepresenceio/telepresence/v2/pkg/matcher"
 	"github.com/telepresenceio/telepresence/v2/pkg/restapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
 )
 
 type apiServer struct {
@@ -76,11 +78,15 @@ type session struct {
 	// Kubernetes Port Forward Dialer
 	pfDialer dnet.PortForwardDialer
 
+	// Dialer used for the connection to the traffic-manager. It keeps track of the port-forward that
+	// serves that connection.
+	mgrDialer *managerDialer
+
 	// manager client
 	managerClient manager.ManagerClient
 
-	// manager client connection
-	managerConn *grpc.ClientConn
+	// manager client connection. It is replaced when the connection to the traffic-manager is lost.
+	managerConn *tm.Conn
 
 	// name reported by the manager
 	managerName string
@@ -138,6 +144,24 @@ type session struct {
 
 	sessionConfig client.Config
 
+	// proxyMode is set when the session uses a local proxy instead of the root daemon.
+	proxyMode proxy.Mode
+
+	// proxyListener is the listener used by the local proxy.
+	proxyListener net.Listener
+
+	// forwardsLock protects the forwards and the forwardsCtx
+	forwardsLock sync.Mutex
+
+	// forwards are the named port-forwards, keyed by name
+	forwards map[string]*forward
+
+	// forwardsCtx is the context used by the forwards. It's nil until the forwards worker has started.
+	forwardsCtx context.Context
+
+	// forwardStreams creates the tunnel streams used by the forwards
+	forwardStreams tunnel.StreamCreator
+
 	// done is closed when the session ends
 	done chan struct{}
 }
@@ -199,23 +223,31 @@ func NewSession(
 		}
 	}
 
-	rdRunning := userd.GetService(ctx).RootSessionInProcess()
-	if !rdRunning {
-		// Connect to the root daemon if it is running. It's the CLI that starts it initially
-		rdRunning, err = socket.IsRunning(ctx, socket.DaemonName)
-		if err != nil {
-			return ctx, nil, connectError(rpc.ConnectInfo_DAEMON_FAILED, err)
-		}
-	}
-
-	if rdRunning {
-		tmgr.rootDaemon, err = tmgr.connectRootDaemon(ctx, tmgr.getOutboundInfo(ctx))
-		if err != nil {
+	if cr.ProxyMode != "" {
+		if err = tmgr.listenProxy(cr); err != nil {
 			tmgr.managerConn.Close()
 			return ctx, nil, connectError(rpc.ConnectInfo_DAEMON_FAILED, err)
 		}
+		dlog.Infof(ctx, "Using %s proxy at %s instead of the root daemon", tmgr.proxyMode, tmgr.proxyListener.Addr())
 	} else {
-		dlog.Info(ctx, "Root daemon is not running")
+		rdRunning := userd.GetService(ctx).RootSessionInProcess()
+		if !rdRunning {
+			// Connect to the root daemon if it is running. It's the CLI that starts it initially
+			rdRunning, err = socket.IsRunning(ctx, socket.DaemonName)
+			if err != nil {
+				return ctx, nil, connectError(rpc.ConnectInfo_DAEMON_FAILED, err)
+			}
+		}
+
+		if rdRunning {
+			tmgr.rootDaemon, err = tmgr.connectRootDaemon(ctx, tmgr.getOutboundInfo(ctx))
+			if err != nil {
+				tmgr.managerConn.Close()
+				return ctx, nil, connectError(rpc.ConnectInfo_DAEMON_FAILED, err)
+			}
+		} else {
+			dlog.Info(ctx, "Root daemon is not running")
+		}
 	}
 
 	// Collect data on how long connection time took
@@ -234,6 +266,7 @@ func NewSession(
 		Intercepts:       &manager.InterceptInfoSnapshot{Intercepts: tmgr.getCurrentInterceptInfos()},
 		ManagerNamespace: cluster.Kubeconfig.GetManagerNamespace(),
 	}
+	tmgr.setProxyInfo(ret)
 	return ctx, tmgr, ret
 }
 
@@ -253,7 +286,7 @@ func (s *session) ManagerClient() manager.ManagerClient {
 }
 
 func (s *session) ManagerConn() *grpc.ClientConn {
-	return s.managerConn
+	return s.managerConn.ClientConn()
 }
 
 func (s *session) ManagerName() string {
@@ -341,6 +374,11 @@ func connectMgr(
 		return nil, fmt.Errorf("unable to obtain hostname: %w", err)
 	}
 
+	egressRules, err := parseEgressRules(cr.GetEgressVia(), cluster.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
 	err = CheckTrafficManagerService(ctx, cluster.GetManagerNamespace())
 	if err != nil {
 		return nil, err
@@ -351,10 +389,13 @@ func connectMgr(
 	if err != nil {
 		return nil, err
 	}
-	conn, mClient, vi, err := tm.ConnectToManager(ctx, cluster.GetManagerNamespace(), pfDialer.Dial)
+	mgrDialer := &managerDialer{PortForwardDialer: pfDialer}
+	conn, _, vi, err := tm.ConnectToManager(ctx, cluster.GetManagerNamespace(), mgrDialer.Dial)
 	if err != nil {
 		return nil, err
 	}
+	mConn := tm.NewConn(conn)
+	mClient := manager.NewManagerClient(mConn)
 	managerVersion, err := semver.Parse(strings.TrimPrefix(vi.Version, "v"))
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse manager.Version: %w", err)
@@ -369,6 +410,7 @@ func connectMgr(
 	}
 
 	svc := userd.GetService(ctx)
+	reusedSession := false
 	if si != nil {
 		// Check if the session is still valid in the traffic-manager by calling Remain
 		apiKey, err := svc.GetAPIKey(ctx)
@@ -385,6 +427,7 @@ func connectMgr(
 				return nil, ctx.Err()
 			}
 			dlog.Debugf(ctx, "traffic-manager port-forward established, client was already known to the traffic-manager as %q", userAndHost)
+			reusedSession = true
 		} else {
 			si = nil
 		}
@@ -397,6 +440,7 @@ func connectMgr(
 			InstallId: installID,
 			Product:   "telepresence",
 			Version:   client.Version(),
+			KubeUser:  cluster.Kubeconfig.User,
 		})
 		if err != nil {
 			return nil, client.CheckTimeout(ctx, fmt.Errorf("manager.ArriveAsClient: %w", err))
@@ -406,6 +450,27 @@ func connectMgr(
 		}
 	}
 
+	// A reused session must have its egress rules replaced, even when there are none. The traffic-manager
+	// ensures that the workloads of the rules have a traffic-agent before it registers the rules.
+	if len(egressRules) > 0 || reusedSession {
+		if len(egressRules) > 0 {
+			dlog.Info(ctx, "Ensuring that the egress workloads have a traffic-agent")
+		}
+		_, err = mClient.SetEgressRules(ctx, &manager.EgressRulesRequest{Session: si, Rules: egressRules})
+		switch status.Code(err) {
+		case codes.OK:
+			for _, r := range egressRules {
+				dlog.Infof(ctx, "Egress to %v is dialed by the traffic-agent of %s.%s", destinationsOrAll(r.Destinations), r.Workload, r.Namespace)
+			}
+		case codes.Unimplemented:
+			if len(egressRules) > 0 {
+				return nil, errcat.User.Newf("traffic-manager %s does not support --egress-via", vi.Version)
+			}
+		default:
+			return nil, client.CheckTimeout(ctx, fmt.Errorf("manager.SetEgressRules: %w", err))
+		}
+	}
+
 	var opts []grpc.CallOption
 	cfg := client.GetConfig(ctx)
 	if !cfg.Grpc.MaxReceiveSize.IsZero() {
@@ -413,7 +478,7 @@ func connectMgr(
 			opts = append(opts, grpc.MaxCallRecvMsgSize(int(mz)))
 		}
 	}
-	svc.SetManagerClient(mClient, opts...)
+	svc.SetManagerClient(cluster.Kubeconfig.Context, mClient, opts...)
 
 	managerName := vi.Name
 	if managerName == "" {
@@ -433,14 +498,22 @@ func connectMgr(
 
 	cluster.AlsoProxy = append(cluster.AlsoProxy, extraAlsoProxy...)
 	cluster.NeverProxy = append(cluster.NeverProxy, extraNeverProxy...)
+	if vs := cr.GetVirtualSubnet(); vs != "" {
+		_, ipNet, err := net.ParseCIDR(vs)
+		if err != nil {
+			return nil, errcat.User.Newf("failed to parse virtual subnet: %v", err)
+		}
+		cluster.VirtualSubnet = (*iputil.Subnet)(ipNet)
+	}
 
 	return &session{
 		Cluster:          cluster,
 		installID:        installID,
 		userAndHost:      userAndHost,
 		managerClient:    mClient,
-		managerConn:      conn,
+		managerConn:      mConn,
 		pfDialer:         pfDialer,
+		mgrDialer:        mgrDialer,
 		managerName:      managerName,
 		managerVersion:   managerVersion,
 		sessionInfo:      si,
@@ -538,6 +611,9 @@ func (s *session) Epilog(ctx context.Context) {
 	if s.rootDaemon != nil {
 		_, _ = s.rootDaemon.Disconnect(ctx, &empty.Empty{})
 	}
+	if s.proxyListener != nil {
+		_ = s.proxyListener.Close()
+	}
 	_ = s.pfDialer.Close()
 	dlog.Info(ctx, "-- Session ended")
 	close(s.done)
@@ -548,6 +624,10 @@ func (s *session) StartServices(g *dgroup.Group) {
 	g.Go("intercept-port-forward", s.watchInterceptsHandler)
 	g.Go("agent-watcher", s.agentInfoWatcher)
 	g.Go("dial-request-watcher", s.dialRequestWatcher)
+	if s.proxyListener != nil {
+		g.Go("proxy", s.proxyServe)
+	}
+	g.Go("forwards", s.forwardsWorker)
 }
 
 func runWithRetry(ctx context.Context, f func(context.Context) error) error {
@@ -815,9 +895,7 @@ nextIs:
 var ErrSessionExpired = errors.New("session expired")
 
 func (s *session) remain(c context.Context) error {
-	ticker := time.NewTicker(5 * time.Second)
 	defer func() {
-		ticker.Stop()
 		c = dcontext.WithoutCancel(c)
 		c, cancel := context.WithTimeout(c, 3*time.Second)
 		defer cancel()
@@ -832,27 +910,68 @@ func (s *session) remain(c context.Context) error {
 		s.managerConn.Close()
 	}()
 
-	for {
-		select {
-		case <-c.Done():
-			return nil
-		case <-ticker.C:
-			apiKey, err := userd.GetService(c).GetAPIKey(c)
-			if err != nil {
-				dlog.Errorf(c, "failed to retrieve API key: %v", err)
-			}
-			_, err = s.managerClient.Remain(c, &manager.RemainRequest{
-				Session: s.SessionInfo(),
-				ApiKey:  apiKey,
-			})
-			if err != nil && c.Err() == nil {
-				dlog.Error(c, err)
-				if gErr, ok := status.FromError(err); ok && gErr.Code() == codes.NotFound {
-					// Session has expired. We need to cancel the owner session and reconnect
-					return ErrSessionExpired
-				}
-			}
-		}
+	ka := keepAlive{
+		conn:       s.managerConn,
+		remain:     s.remainOnce,
+		dial:       s.dialManager,
+		interval:   remainInterval,
+		minBackoff: reconnectInitialBackoff,
+		maxBackoff: reconnectMaxBackoff,
+	}
+	return ka.run(c)
+}
+
+func (s *session) remainOnce(c context.Context) error {
+	apiKey, err := userd.GetService(c).GetAPIKey(c)
+	if err != nil {
+		dlog.Errorf(c, "failed to retrieve API key: %v", err)
+	}
+	tc, cancel := client.GetConfig(c).Timeouts.TimeoutContext(c, client.TimeoutTrafficManagerAPI)
+	defer cancel()
+	_, err = s.managerClient.Remain(tc, &manager.RemainRequest{
+		Session: s.SessionInfo(),
+		ApiKey:  apiKey,
+	})
+	return err
+}
+
+// dialManager dials a new connection to the traffic-manager. The port-forward that served the connection
+// that is being replaced is evicted first, because it's likely to be dead. Other port-forwards, such as those
+// used by the tracing and agent connections, are left intact.
+func (s *session) dialManager(c context.Context) (*grpc.ClientConn, error) {
+	s.mgrDialer.evict()
+	tc, cancel := client.GetConfig(c).Timeouts.TimeoutContext(c, client.TimeoutTrafficManagerConnect)
+	defer cancel()
+	conn, _, _, err := tm.ConnectToManager(tc, s.GetManagerNamespace(), s.mgrDialer.Dial)
+	return conn, err
+}
+
+// managerDialer is a dnet.PortForwardDialer that remembers the last connection that it dialed, so that
+// the port-forward that serves the traffic-manager connection can be evicted without closing the others.
+type managerDialer struct {
+	dnet.PortForwardDialer
+	sync.Mutex
+	conn net.Conn
+}
+
+func (d *managerDialer) Dial(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := d.PortForwardDialer.Dial(ctx, addr)
+	if err == nil {
+		d.Lock()
+		d.conn = conn
+		d.Unlock()
+	}
+	return conn, err
+}
+
+// evict evicts the port-forward of the last dialed connection, if any.
+func (d *managerDialer) evict() {
+	d.Lock()
+	conn := d.conn
+	d.conn = nil
+	d.Unlock()
+	if conn != nil {
+		d.Evict(conn)
 	}
 }
 
@@ -862,7 +981,7 @@ func (s *session) UpdateStatus(c context.Context, cr *rpc.ConnectRequest) *rpc.C
 		return connectError(rpc.ConnectInfo_CLUSTER_FAILED, err)
 	}
 
-	if !cr.IsPodDaemon && !s.Kubeconfig.ContextServiceAndFlagsEqual(config) {
+	if !cr.IsPodDaemon && !s.Kubeconfig.ContextServiceAndFlagsEqual(config) || s.proxyModeChanged(cr) {
 		return &rpc.ConnectInfo{
 			Error:          rpc.ConnectInfo_MUST_RESTART,
 			ClusterContext: s.Kubeconfig.Context,
@@ -910,6 +1029,7 @@ func (s *session) Status(c context.Context) *rpc.ConnectInfo {
 	if len(s.MappedNamespaces) > 0 || len(s.sessionConfig.Cluster.MappedNamespaces) > 0 {
 		ret.MappedNamespaces = s.GetCurrentNamespaces(true)
 	}
+	s.setProxyInfo(ret)
 	if s.rootDaemon != nil {
 		var err error
 		ret.DaemonStatus, err = s.rootDaemon.Status(c, &empty.Empty{})
@@ -1145,6 +1265,9 @@ func (s *session) getOutboundInfo(ctx context.Context) *rootdRpc.OutboundInfo {
 		HomeDir:           homedir.HomeDir(),
 		ManagerNamespace:  s.GetManagerNamespace(),
 		KubeFlags:         kubeFlags,
+		KubeContext:       s.Kubeconfig.Context,
+		AlsoProxyHosts:    s.AlsoProxyHosts,
+		NeverProxyHosts:   s.NeverProxyHosts,
 	}
 
 	if s.DNS != nil {
@@ -1159,6 +1282,12 @@ func (s *session) getOutboundInfo(ctx context.Context) *rootdRpc.OutboundInfo {
 		if len(s.DNS.RemoteIP) > 0 {
 			info.Dns.RemoteIp = s.DNS.RemoteIP.IP()
 		}
+		if len(s.DNS.Mappings) > 0 {
+			info.Dns.Mappings = make([]*rootdRpc.DNSMapping, len(s.DNS.Mappings))
+			for i, m := range s.DNS.Mappings {
+				info.Dns.Mappings[i] = &rootdRpc.DNSMapping{Name: m.Name, AliasFor: m.AliasFor, Ip: m.IP}
+			}
+		}
 	}
 
 	if len(s.AlsoProxy) > 0 {
@@ -1167,6 +1296,9 @@ func (s *session) getOutboundInfo(ctx context.Context) *rootdRpc.OutboundInfo {
 			info.AlsoProxySubnets[i] = iputil.IPNetToRPC((*net.IPNet)(ap))
 		}
 	}
+	if s.VirtualSubnet != nil {
+		info.VirtualSubnet = iputil.IPNetToRPC((*net.IPNet)(s.VirtualSubnet))
+	}
 	return info
 }
 
@@ -1186,10 +1318,13 @@ func (s *session) connectRootDaemon(ctx context.Context, oi *rootdRpc.OutboundIn
 		rd = rootSession
 	} else {
 		var conn *grpc.ClientConn
-		conn, err = socket.Dial(ctx, socket.DaemonName,
+		// All calls made on this connection are tagged with the Kubernetes context so that they apply to this
+		// session when the root daemon has sessions for several contexts.
+		opts := append([]grpc.DialOption{
 			grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
 			grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
-		)
+		}, client.KubeContextDialOptions(oi.KubeContext)...)
+		conn, err = socket.Dial(ctx, socket.DaemonName, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("unable open root daemon socket: %w", err)
 		}
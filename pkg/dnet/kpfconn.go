@@ -46,6 +46,10 @@ type DialerFunc func(context.Context, string) (net.Conn, error)
 type PortForwardDialer interface {
 	io.Closer
 	Dial(ctx context.Context, addr string) (net.Conn, error)
+
+	// Evict closes the port-forward to the pod that the given connection, obtained from Dial, is connected to.
+	// All connections to that pod are closed, but the port-forwards to other pods are left intact.
+	Evict(conn net.Conn)
 }
 
 // NewK8sPortForwardDialer returns a dialer function (matching the signature required by
@@ -100,6 +104,23 @@ func (pf *k8sPortForwardDialer) Close() error {
 	return nil
 }
 
+func (pf *k8sPortForwardDialer) Evict(conn net.Conn) {
+	kc, ok := conn.(*kpfConn)
+	if !ok {
+		return
+	}
+	pf.spdyStreamsMu.Lock()
+	s, ok := pf.spdyStreams[kc.cacheKey]
+	if ok && s == kc.spdyStream {
+		delete(pf.spdyStreams, kc.cacheKey)
+	}
+	pf.spdyStreamsMu.Unlock()
+	dlog.Debugf(pf.logCtx, "evicting spdyStream: %s", kc.cacheKey)
+	if err := kc.spdyStream.Close(); err != nil {
+		dlog.Errorf(pf.logCtx, "failed to close spdyStream: %v", err)
+	}
+}
+
 func (pf *k8sPortForwardDialer) resolve(ctx context.Context, addr string) (pod *core.Pod, podPortNumber uint16, err error) {
 	var hostName, portName string
 	hostName, portName, err = net.SplitHostPort(addr)
@@ -277,6 +298,8 @@ func (pf *k8sPortForwardDialer) dial(pod *core.Pod, port uint16) (conn *kpfConn,
 		Stream:      dataStream,
 		remoteAddr:  net.JoinHostPort(pod.Name+"."+pod.Namespace, strconv.FormatInt(int64(port), 10)),
 		errorStream: errorStream,
+		cacheKey:    pod.Name + "." + pod.Namespace,
+		spdyStream:  spdyStream,
 	}
 	conn.init()
 	return conn, nil
@@ -291,6 +314,10 @@ type kpfConn struct {
 	// See the above comment about httpstream.Stream close semantics.
 	errorStream httpstream.Stream
 
+	// The SPDY connection that this connection is multiplexed over, and its key in the dialer's cache.
+	cacheKey   string
+	spdyStream httpstream.Connection
+
 	// Internal data
 
 	oobErrCh chan struct{}
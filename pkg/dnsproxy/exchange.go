@@ -0,0 +1,106 @@
+package dnsproxy
+
+import (
+	"context"
+	"net"
+
+	"github.com/miekg/dns"
+
+	"github.com/datawire/dlib/dlog"
+)
+
+// clientConfig returns the configuration of the nameservers that are used by exchange.
+var clientConfig = func() (*dns.ClientConfig, error) { //nolint:gochecknoglobals // can be overridden by tests
+	return dns.ClientConfigFromFile("/etc/resolv.conf")
+}
+
+// exchange sends the query directly to the nameservers that are configured in /etc/resolv.conf, so that the
+// TTLs of the records in the answer are retained. The net.Resolver used by Lookup doesn't expose them.
+//
+// The records of the given type are returned with the name of the query, and with the lowest TTL that was
+// found in the answer, so that a CNAME chain that expires earlier than the records it leads to is respected.
+// False is returned when no such records were found, in which case the caller must fall back to a lookup
+// that also takes /etc/hosts and other sources of the system's resolver into account.
+func exchange(ctx context.Context, qType uint16, qName string) (RRs, bool) {
+	cfg, err := clientConfig()
+	if err != nil || len(cfg.Servers) == 0 {
+		return nil, false
+	}
+	var names []string
+	if qType == dns.TypePTR {
+		ip, err := PtrAddress(qName)
+		if err != nil {
+			return nil, false
+		}
+		rev, err := dns.ReverseAddr(ip.String())
+		if err != nil {
+			return nil, false
+		}
+		names = []string{rev}
+	} else {
+		name, _ := useLookupName(qName)
+		names = cfg.NameList(name)
+	}
+
+	for _, name := range names {
+		msg := new(dns.Msg)
+		msg.SetQuestion(name, qType)
+		r := exchangeWithServers(ctx, cfg, msg)
+		if r == nil || r.Rcode != dns.RcodeSuccess {
+			if ctx.Err() != nil {
+				return nil, false
+			}
+			continue
+		}
+		if rrs := answerFor(r.Answer, qType, qName); len(rrs) > 0 {
+			return rrs, true
+		}
+	}
+	return nil, false
+}
+
+// exchangeWithServers sends the message to each of the configured servers in turn, and returns the first
+// response that isn't a server failure. A truncated UDP response is retried using TCP.
+func exchangeWithServers(ctx context.Context, cfg *dns.ClientConfig, msg *dns.Msg) *dns.Msg {
+	for _, server := range cfg.Servers {
+		addr := net.JoinHostPort(server, cfg.Port)
+		r, _, err := (&dns.Client{Net: "udp"}).ExchangeContext(ctx, msg, addr)
+		if err == nil && r.Truncated {
+			r, _, err = (&dns.Client{Net: "tcp"}).ExchangeContext(ctx, msg, addr)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			dlog.Debugf(ctx, "DNS exchange of %s with %s failed: %v", msg.Question[0].Name, addr, err)
+			continue
+		}
+		if r.Rcode != dns.RcodeServerFailure {
+			return r
+		}
+	}
+	return nil
+}
+
+// answerFor returns copies of the records of the given type in the answer, owned by qName, and with the
+// lowest TTL of the answer.
+func answerFor(answer []dns.RR, qType uint16, qName string) RRs {
+	var ttl uint32
+	for i, rr := range answer {
+		if h := rr.Header(); i == 0 || h.Ttl < ttl {
+			ttl = h.Ttl
+		}
+	}
+	var rrs RRs
+	for _, rr := range answer {
+		if rr.Header().Rrtype != qType {
+			continue
+		}
+		rr = dns.Copy(rr)
+		h := rr.Header()
+		h.Name = qName
+		h.Ttl = ttl
+		rrs = append(rrs, rr)
+	}
+	return rrs
+}
@@ -0,0 +1,97 @@
+package dnsproxy
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+)
+
+// startNameserver starts a nameserver that answers with the given records, and NXDOMAIN for names that it
+// has no records for. The clientConfig is replaced with one that uses it until the test ends.
+func startNameserver(t *testing.T, records map[string][]dns.RR) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &dns.Server{PacketConn: pc, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		q := r.Question[0]
+		rrs, ok := records[q.Name]
+		if !ok {
+			m.Rcode = dns.RcodeNameError
+		}
+		for _, rr := range rrs {
+			if rt := rr.Header().Rrtype; rt == q.Qtype || rt == dns.TypeCNAME {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+		_ = w.WriteMsg(m)
+	})}
+	started := make(chan struct{})
+	srv.NotifyStartedFunc = func() { close(started) }
+	go func() { _ = srv.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = srv.Shutdown() })
+
+	port := strconv.Itoa(pc.LocalAddr().(*net.UDPAddr).Port)
+	saved := clientConfig
+	clientConfig = func() (*dns.ClientConfig, error) {
+		return &dns.ClientConfig{
+			Servers:  []string{"127.0.0.1"},
+			Port:     port,
+			Search:   []string{"default.svc.cluster.local", "svc.cluster.local"},
+			Ndots:    5,
+			Timeout:  1,
+			Attempts: 1,
+		}, nil
+	}
+	t.Cleanup(func() { clientConfig = saved })
+}
+
+func mustRR(t *testing.T, s string) dns.RR {
+	rr, err := dns.NewRR(s)
+	require.NoError(t, err)
+	return rr
+}
+
+func TestLookup_upstreamTTL(t *testing.T) {
+	startNameserver(t, map[string][]dns.RR{
+		"web.default.svc.cluster.local.": {
+			mustRR(t, "web.default.svc.cluster.local. 300 IN A 10.0.0.1"),
+			mustRR(t, "web.default.svc.cluster.local. 300 IN A 10.0.0.2"),
+		},
+		"alias.example.com.": {
+			mustRR(t, "alias.example.com. 30 IN CNAME web.default.svc.cluster.local."),
+			mustRR(t, "web.default.svc.cluster.local. 300 IN A 10.0.0.1"),
+		},
+	})
+	ctx := dlog.NewTestContext(t, false)
+
+	// The search path is applied, and the TTL of the answer is retained.
+	rrs, rCode, err := Lookup(ctx, dns.TypeA, "web.default.")
+	require.NoError(t, err)
+	assert.Equal(t, dns.RcodeSuccess, rCode)
+	require.Len(t, rrs, 2)
+	for _, rr := range rrs {
+		assert.Equal(t, "web.default.", rr.Header().Name)
+		assert.Equal(t, uint32(300), rr.Header().Ttl)
+	}
+
+	// The CNAME that leads to the records expires first.
+	rrs, rCode, err = Lookup(ctx, dns.TypeA, "alias.example.com.")
+	require.NoError(t, err)
+	assert.Equal(t, dns.RcodeSuccess, rCode)
+	require.Len(t, rrs, 1)
+	assert.Equal(t, net.IP{10, 0, 0, 1}, rrs[0].(*dns.A).A.To4())
+	assert.Equal(t, "alias.example.com.", rrs[0].Header().Name)
+	assert.Equal(t, uint32(30), rrs[0].Header().Ttl)
+
+	// Names that the nameserver doesn't know are left to the system's resolver.
+	_, ok := exchange(ctx, dns.TypeA, "unknown.default.")
+	assert.False(t, ok)
+}
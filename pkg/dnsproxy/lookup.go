@@ -16,6 +16,8 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
 )
 
+// dnsTTL is the TTL of the records in an answer that is obtained from the system's resolver, which doesn't
+// expose the TTLs of the records that it finds.
 const dnsTTL = 4
 
 const (
@@ -188,7 +190,15 @@ func makeError(err error) (RRs, int, error) {
 	return nil, dns.RcodeServerFailure, status.Error(codes.Internal, err.Error())
 }
 
+// Lookup resolves the given name. The records retain the TTLs of the nameserver's answer when the name can be
+// resolved by querying the configured nameservers directly. Otherwise, the system's resolver is used, and
+// the records get a TTL of dnsTTL.
 func Lookup(ctx context.Context, qType uint16, qName string) (RRs, int, error) {
+	if SupportedType(qType) {
+		if answer, ok := exchange(ctx, qType, qName); ok {
+			return answer, dns.RcodeSuccess, nil
+		}
+	}
 	var answer RRs
 	r := &net.Resolver{StrictErrors: true}
 	switch qType {
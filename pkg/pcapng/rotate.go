@@ -0,0 +1,119 @@
+package pcapng
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RotatingFile writes packets to a pcap-ng file that is rotated when it would otherwise grow beyond a max size. The
+// rotated files are renamed so that the most recent one gets the suffix ".1" inserted before the file extension, the
+// one before that ".2", and so on. Each file is a complete pcap-ng file that can be read on its own.
+type RotatingFile struct {
+	path     string
+	ifName   string
+	linkType uint16
+	snapLen  int
+	maxSize  int64
+	maxFiles int
+
+	file *os.File
+	buf  *bufio.Writer
+	w    *Writer
+}
+
+// OpenRotatingFile creates the file at the given path and writes the pcap-ng header for an interface with the given
+// name, link type, and snap length. The file is rotated when it would grow beyond maxSize bytes, and at most maxFiles
+// rotated files are retained. A maxSize of zero disables rotation.
+func OpenRotatingFile(path, ifName string, linkType uint16, snapLen int, maxSize int64, maxFiles int) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		path:     path,
+		ifName:   ifName,
+		linkType: linkType,
+		snapLen:  snapLen,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// WritePacket writes the given packet, rotating the file first if it would grow beyond its max size.
+func (rf *RotatingFile) WritePacket(ts time.Time, data []byte, origLen int, dir Direction) error {
+	if rf.maxSize > 0 {
+		// A file always gets at least one packet, even if that packet alone exceeds the max size.
+		if size := rf.w.Written(); size+rf.w.PacketBlockSize(data, dir) > rf.maxSize && size > rf.headerSize() {
+			if err := rf.rotate(); err != nil {
+				return err
+			}
+		}
+	}
+	return rf.w.WritePacket(ts, data, origLen, dir)
+}
+
+// Close flushes and closes the current file.
+func (rf *RotatingFile) Close() error {
+	err := rf.buf.Flush()
+	if cerr := rf.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(f)
+	w, err := NewWriter(buf, rf.ifName, rf.linkType, rf.snapLen)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	rf.file = f
+	rf.buf = buf
+	rf.w = w
+	return nil
+}
+
+func (rf *RotatingFile) headerSize() int64 {
+	// Section header (28) + interface description with if_tsresol and end of options (32) + if_name
+	size := int64(28 + 32)
+	if rf.ifName != "" {
+		size += int64(4 + pad4(len(rf.ifName)))
+	}
+	return size
+}
+
+func (rf *RotatingFile) rotate() error {
+	if err := rf.Close(); err != nil {
+		return err
+	}
+	if rf.maxFiles > 0 {
+		if err := os.Remove(rf.rotatedName(rf.maxFiles)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		for i := rf.maxFiles - 1; i > 0; i-- {
+			if err := os.Rename(rf.rotatedName(i), rf.rotatedName(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+		if err := os.Rename(rf.path, rf.rotatedName(1)); err != nil {
+			return err
+		}
+	}
+	return rf.open()
+}
+
+func (rf *RotatingFile) rotatedName(i int) string {
+	ext := filepath.Ext(rf.path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(rf.path, ext), i, ext)
+}
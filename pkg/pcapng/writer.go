@@ -0,0 +1,169 @@
+// Package pcapng contains a writer for the pcap-ng capture file format, as described in
+// https://www.ietf.org/archive/id/draft-tuexen-opsawg-pcapng-05.html
+package pcapng
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// LinkTypeRaw is the link type for raw IPv4 or IPv6 packets without any link layer header.
+const LinkTypeRaw = 101
+
+// DefaultSnapLength is the snap length used when none is given.
+const DefaultSnapLength = 0x40000
+
+const (
+	blockTypeSectionHeader   = 0x0A0D0D0A
+	blockTypeInterface       = 0x00000001
+	blockTypeEnhancedPacket  = 0x00000006
+	byteOrderMagic           = 0x1A2B3C4D
+	optEndOfOpt              = 0
+	optIfName                = 2
+	optIfTsResol             = 9
+	optEpbFlags              = 2
+	epbFlagInbound           = 0x1
+	epbFlagOutbound          = 0x2
+	tsResolutionMicroseconds = 6
+)
+
+// Direction is the direction of a captured packet, as seen from the captured interface.
+type Direction int
+
+const (
+	// DirectionUnknown means that the direction isn't recorded.
+	DirectionUnknown Direction = iota
+
+	// DirectionInbound is used for packets that are received by the interface.
+	DirectionInbound
+
+	// DirectionOutbound is used for packets that are sent from the interface.
+	DirectionOutbound
+)
+
+// Writer writes a pcap-ng section with one interface to an io.Writer.
+type Writer struct {
+	out     io.Writer
+	snapLen int
+	written int64
+}
+
+// NewWriter writes a section header and an interface description for an interface with the given name, link type,
+// and snap length to the given io.Writer, and returns a Writer that writes packets captured on that interface.
+func NewWriter(out io.Writer, ifName string, linkType uint16, snapLen int) (*Writer, error) {
+	if snapLen <= 0 {
+		snapLen = DefaultSnapLength
+	}
+	w := &Writer{out: out, snapLen: snapLen}
+
+	// Section header, with an unspecified section length
+	shb := make([]byte, 16)
+	binary.LittleEndian.PutUint32(shb, byteOrderMagic)
+	binary.LittleEndian.PutUint16(shb[4:], 1)
+	binary.LittleEndian.PutUint16(shb[6:], 0)
+	binary.LittleEndian.PutUint64(shb[8:], ^uint64(0))
+	if err := w.writeBlock(blockTypeSectionHeader, shb); err != nil {
+		return nil, err
+	}
+
+	idb := make([]byte, 8, 32)
+	binary.LittleEndian.PutUint16(idb, linkType)
+	binary.LittleEndian.PutUint32(idb[4:], uint32(snapLen))
+	if ifName != "" {
+		idb = appendOption(idb, optIfName, []byte(ifName))
+	}
+	idb = appendOption(idb, optIfTsResol, []byte{tsResolutionMicroseconds})
+	idb = appendOption(idb, optEndOfOpt, nil)
+	if err := w.writeBlock(blockTypeInterface, idb); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Written returns the number of bytes written so far.
+func (w *Writer) Written() int64 {
+	return w.written
+}
+
+// WritePacket writes a packet captured at the given time. The data is truncated to the snap length of the interface.
+// The origLen is the length of the packet before it was truncated, or zero if the data isn't truncated.
+func (w *Writer) WritePacket(ts time.Time, data []byte, origLen int, dir Direction) error {
+	return w.writeBlock(blockTypeEnhancedPacket, w.packetBody(ts, data, origLen, dir))
+}
+
+// PacketBlockSize returns the number of bytes that WritePacket will write for a packet with the given data.
+func (w *Writer) PacketBlockSize(data []byte, dir Direction) int64 {
+	n := len(data)
+	if n > w.snapLen {
+		n = w.snapLen
+	}
+	size := 12 + 20 + pad4(n) + 4 // block header and trailer, fixed fields, data, end of options
+	if dir != DirectionUnknown {
+		size += 8
+	}
+	return int64(size)
+}
+
+func (w *Writer) packetBody(ts time.Time, data []byte, origLen int, dir Direction) []byte {
+	if origLen < len(data) {
+		origLen = len(data)
+	}
+	if len(data) > w.snapLen {
+		data = data[:w.snapLen]
+	}
+	body := make([]byte, 20, 20+pad4(len(data))+12)
+	us := uint64(ts.UnixMicro())
+	binary.LittleEndian.PutUint32(body, 0) // interface ID
+	binary.LittleEndian.PutUint32(body[4:], uint32(us>>32))
+	binary.LittleEndian.PutUint32(body[8:], uint32(us))
+	binary.LittleEndian.PutUint32(body[12:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(body[16:], uint32(origLen))
+	body = appendPadded(body, data)
+
+	var flags uint32
+	switch dir {
+	case DirectionInbound:
+		flags = epbFlagInbound
+	case DirectionOutbound:
+		flags = epbFlagOutbound
+	}
+	if flags != 0 {
+		fb := make([]byte, 4)
+		binary.LittleEndian.PutUint32(fb, flags)
+		body = appendOption(body, optEpbFlags, fb)
+	}
+	return appendOption(body, optEndOfOpt, nil)
+}
+
+// writeBlock writes a block with the given type and body. The body must be padded to a 32-bit boundary.
+func (w *Writer) writeBlock(blockType uint32, body []byte) error {
+	totalLen := uint32(12 + len(body))
+	block := make([]byte, totalLen)
+	binary.LittleEndian.PutUint32(block, blockType)
+	binary.LittleEndian.PutUint32(block[4:], totalLen)
+	copy(block[8:], body)
+	binary.LittleEndian.PutUint32(block[totalLen-4:], totalLen)
+	n, err := w.out.Write(block)
+	w.written += int64(n)
+	return err
+}
+
+func appendOption(b []byte, code uint16, value []byte) []byte {
+	var hdr [4]byte
+	binary.LittleEndian.PutUint16(hdr[:], code)
+	binary.LittleEndian.PutUint16(hdr[2:], uint16(len(value)))
+	return appendPadded(append(b, hdr[:]...), value)
+}
+
+func appendPadded(b, data []byte) []byte {
+	b = append(b, data...)
+	for i := len(data); i < pad4(len(data)); i++ {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func pad4(n int) int {
+	return (n + 3) &^ 3
+}
@@ -0,0 +1,106 @@
+package pcapng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type block struct {
+	blockType uint32
+	body      []byte
+}
+
+func readBlocks(t *testing.T, data []byte) []block {
+	var blocks []block
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 12)
+		bt := binary.LittleEndian.Uint32(data)
+		bl := binary.LittleEndian.Uint32(data[4:])
+		require.Zero(t, bl%4)
+		require.LessOrEqual(t, int(bl), len(data))
+		require.Equal(t, bl, binary.LittleEndian.Uint32(data[bl-4:]))
+		blocks = append(blocks, block{blockType: bt, body: data[8 : bl-4]})
+		data = data[bl:]
+	}
+	return blocks
+}
+
+func TestWriter(t *testing.T) {
+	buf := bytes.Buffer{}
+	w, err := NewWriter(&buf, "tel0", LinkTypeRaw, 4)
+	require.NoError(t, err)
+	ts := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, w.WritePacket(ts, []byte{1, 2, 3, 4, 5, 6}, 0, DirectionOutbound))
+	require.NoError(t, w.WritePacket(ts, []byte{1, 2, 3}, 0, DirectionUnknown))
+	assert.Equal(t, int64(buf.Len()), w.Written())
+
+	blocks := readBlocks(t, buf.Bytes())
+	require.Len(t, blocks, 4)
+
+	assert.Equal(t, uint32(blockTypeSectionHeader), blocks[0].blockType)
+	assert.Equal(t, uint32(byteOrderMagic), binary.LittleEndian.Uint32(blocks[0].body))
+
+	assert.Equal(t, uint32(blockTypeInterface), blocks[1].blockType)
+	assert.Equal(t, uint16(LinkTypeRaw), binary.LittleEndian.Uint16(blocks[1].body))
+	assert.Equal(t, uint32(4), binary.LittleEndian.Uint32(blocks[1].body[4:]))
+
+	epb := blocks[2]
+	assert.Equal(t, uint32(blockTypeEnhancedPacket), epb.blockType)
+	us := uint64(binary.LittleEndian.Uint32(epb.body[4:]))<<32 | uint64(binary.LittleEndian.Uint32(epb.body[8:]))
+	assert.Equal(t, ts.UnixMicro(), int64(us))
+	assert.Equal(t, uint32(4), binary.LittleEndian.Uint32(epb.body[12:]), "captured length")
+	assert.Equal(t, uint32(6), binary.LittleEndian.Uint32(epb.body[16:]), "original length")
+	assert.Equal(t, []byte{1, 2, 3, 4}, epb.body[20:24])
+	assert.Equal(t, uint16(optEpbFlags), binary.LittleEndian.Uint16(epb.body[24:]))
+	assert.Equal(t, uint32(epbFlagOutbound), binary.LittleEndian.Uint32(epb.body[28:]))
+
+	epb = blocks[3]
+	assert.Equal(t, uint32(3), binary.LittleEndian.Uint32(epb.body[12:]))
+	assert.Equal(t, []byte{1, 2, 3, 0}, epb.body[20:24])
+	assert.Equal(t, uint16(optEndOfOpt), binary.LittleEndian.Uint16(epb.body[24:]))
+}
+
+func TestPacketBlockSize(t *testing.T) {
+	buf := bytes.Buffer{}
+	w, err := NewWriter(&buf, "", LinkTypeRaw, 0)
+	require.NoError(t, err)
+	for _, data := range [][]byte{{}, {1}, {1, 2, 3, 4}, {1, 2, 3, 4, 5}} {
+		for _, dir := range []Direction{DirectionUnknown, DirectionInbound} {
+			before := w.Written()
+			require.NoError(t, w.WritePacket(time.Now(), data, 0, dir))
+			assert.Equal(t, w.Written()-before, w.PacketBlockSize(data, dir))
+		}
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "traffic.pcap")
+	rf, err := OpenRotatingFile(path, "tel0", LinkTypeRaw, 0, 200, 2)
+	require.NoError(t, err)
+	assert.Equal(t, rf.w.Written(), rf.headerSize())
+
+	data := make([]byte, 64)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, rf.WritePacket(time.Now(), data, 0, DirectionInbound))
+	}
+	require.NoError(t, rf.Close())
+
+	for _, name := range []string{"traffic.pcap", "traffic.1.pcap", "traffic.2.pcap"} {
+		fd, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(fd), 200)
+		blocks := readBlocks(t, fd)
+		require.Greater(t, len(blocks), 2)
+		assert.Equal(t, uint32(blockTypeSectionHeader), blocks[0].blockType)
+	}
+	_, err = os.Stat(filepath.Join(dir, "traffic.3.pcap"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -148,6 +148,11 @@ func Equal(a, b *net.IPNet) bool {
 	return false
 }
 
+// Overlaps answers the question if network range a and network range b have at least one IP in common.
+func Overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
 // Covers answers the question if network range a contains all of network range b.
 func Covers(a, b *net.IPNet) bool {
 	if !a.Contains(b.IP) {
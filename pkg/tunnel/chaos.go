@@ -0,0 +1,294 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+// ErrChaosDropped is returned by a StreamCreator created by Chaos.StreamCreator when a connection is dropped.
+var ErrChaosDropped = errors.New("connection dropped by chaos rule")
+
+// ChaosRule describes faults that are injected into the connections to a destination.
+type ChaosRule struct {
+	// ID is assigned when the rule is added to a Chaos.
+	ID string
+
+	// Destination is a host with an optional port. The host is an IP, a CIDR, or a name. A name matches the
+	// name used in the DNS lookup that returned the destination IP, or any name that starts with the given
+	// name followed by a dot, so "svc.ns" matches "svc.ns.svc.cluster.local".
+	Destination string
+
+	// Latency is added to each message received from the cluster.
+	Latency time.Duration
+
+	// Jitter is the max random deviation from the Latency.
+	Jitter time.Duration
+
+	// DropRate is the probability, between 0 and 1, that a TCP connection attempt, a UDP datagram, or an ICMP echo message is dropped.
+	DropRate float64
+
+	// ResetAfter is the time after which a connection is closed. Zero means never.
+	ResetAfter time.Duration
+
+	ip   net.IP
+	net  *net.IPNet
+	name string
+	port uint16
+}
+
+func (r *ChaosRule) parseDestination() error {
+	host := r.Destination
+	if h, p, err := net.SplitHostPort(r.Destination); err == nil {
+		port, err := strconv.ParseUint(p, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid port in destination %q", r.Destination)
+		}
+		host = h
+		r.port = uint16(port)
+	}
+	switch {
+	case host == "":
+		return fmt.Errorf("invalid destination %q: missing host", r.Destination)
+	case strings.Contains(host, "/"):
+		_, sn, err := net.ParseCIDR(host)
+		if err != nil {
+			return fmt.Errorf("invalid destination %q: %w", r.Destination, err)
+		}
+		r.net = sn
+	default:
+		if r.ip = iputil.Parse(host); r.ip == nil {
+			r.name = strings.ToLower(strings.TrimSuffix(host, "."))
+		}
+	}
+	return nil
+}
+
+func (r *ChaosRule) validate() error {
+	switch {
+	case r.Latency < 0 || r.Jitter < 0 || r.ResetAfter < 0:
+		return errors.New("latency, jitter, and reset-after cannot be negative")
+	case r.Jitter > 0 && r.Latency == 0:
+		return errors.New("jitter requires a latency")
+	case r.DropRate < 0 || r.DropRate > 1:
+		return errors.New("drop rate must be between 0% and 100%")
+	}
+	return r.parseDestination()
+}
+
+// Matches returns true if the rule applies to the given ConnID. The name is the name that the destination IP was
+// resolved from, or an empty string if no such name is known.
+func (r *ChaosRule) Matches(id ConnID, name string) bool {
+	if r.port != 0 && r.port != id.DestinationPort() {
+		return false
+	}
+	switch {
+	case r.ip != nil:
+		return r.ip.Equal(id.Destination())
+	case r.net != nil:
+		return r.net.Contains(id.Destination())
+	default:
+		name = strings.ToLower(name)
+		return name == r.name || strings.HasPrefix(name, r.name+".")
+	}
+}
+
+func (r *ChaosRule) dropped() bool {
+	return r.DropRate > 0 && rand.Float64() < r.DropRate
+}
+
+func (r *ChaosRule) delay() time.Duration {
+	d := r.Latency
+	if r.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * float64(r.Jitter))
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// Chaos is a set of ChaosRules that can be modified at runtime.
+type Chaos struct {
+	sync.RWMutex
+	rules  []*ChaosRule
+	nextID int
+}
+
+// Add validates the given rule, assigns an ID to it, and adds it.
+func (c *Chaos) Add(r *ChaosRule) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	c.Lock()
+	c.nextID++
+	r.ID = strconv.Itoa(c.nextID)
+	c.rules = append(c.rules, r)
+	c.Unlock()
+	return nil
+}
+
+// Remove removes the rule with the given ID, and returns false if no such rule exists.
+func (c *Chaos) Remove(id string) bool {
+	c.Lock()
+	defer c.Unlock()
+	for i, r := range c.rules {
+		if r.ID == id {
+			c.rules = append(c.rules[:i:i], c.rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveAll removes all rules.
+func (c *Chaos) RemoveAll() {
+	c.Lock()
+	c.rules = nil
+	c.Unlock()
+}
+
+// Rules returns the current rules.
+func (c *Chaos) Rules() []*ChaosRule {
+	c.RLock()
+	defer c.RUnlock()
+	return append([]*ChaosRule(nil), c.rules...)
+}
+
+// Match returns the first rule that matches the given ConnID and name, or nil if no rule matches.
+func (c *Chaos) Match(id ConnID, name string) *ChaosRule {
+	c.RLock()
+	defer c.RUnlock()
+	for _, r := range c.rules {
+		if r.Matches(id, name) {
+			return r
+		}
+	}
+	return nil
+}
+
+// StreamCreator returns a StreamCreator that applies the first matching rule to the streams created by the given
+// streamCreator. The nameOf function returns the name that an IP was resolved from.
+func (c *Chaos) StreamCreator(streamCreator StreamCreator, nameOf func(net.IP) string) StreamCreator {
+	return func(ctx context.Context, id ConnID) (Stream, error) {
+		r := c.Match(id, nameOf(id.Destination()))
+		if r == nil {
+			return streamCreator(ctx, id)
+		}
+		if id.Protocol() == ipproto.TCP && r.dropped() {
+			return nil, fmt.Errorf("%w %s", ErrChaosDropped, r.ID)
+		}
+		s, err := streamCreator(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		dlog.Debugf(ctx, "Applying chaos rule %s to %s", r.ID, id)
+		return newChaosStream(s, r), nil
+	}
+}
+
+type delayedMessage struct {
+	msg Message
+	err error
+	due time.Time
+}
+
+// chaosStream is a Stream that delays and drops the messages of another Stream according to a ChaosRule.
+type chaosStream struct {
+	Stream
+	rule      *ChaosRule
+	datagram  bool
+	startPump sync.Once
+	incoming  chan delayedMessage
+	reset     <-chan time.Time
+}
+
+func newChaosStream(s Stream, r *ChaosRule) Stream {
+	cs := &chaosStream{
+		Stream:   s,
+		rule:     r,
+		datagram: isDatagram(s.ID().Protocol()),
+		incoming: make(chan delayedMessage, 50),
+	}
+	if r.ResetAfter > 0 {
+		cs.reset = time.After(r.ResetAfter)
+	}
+	return cs
+}
+
+// isDatagram returns true if messages of the given protocol may be dropped without breaking the connection.
+func isDatagram(proto int) bool {
+	switch proto {
+	case ipproto.UDP, ipproto.ICMP, ipproto.ICMPV6:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *chaosStream) Send(ctx context.Context, m Message) error {
+	if s.datagram && m.Code() == Normal && s.rule.dropped() {
+		return nil
+	}
+	return s.Stream.Send(ctx, m)
+}
+
+// Receive returns the next message from the underlying Stream once its delay has passed. Messages are received
+// by a separate goroutine so that the delay of one message doesn't add to the delay of the next.
+func (s *chaosStream) Receive(ctx context.Context) (Message, error) {
+	s.startPump.Do(func() { go s.pump(ctx) })
+	var dm delayedMessage
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.reset:
+		return NewMessage(Disconnect, nil), nil
+	case dm = <-s.incoming:
+	}
+	if d := time.Until(dm.due); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.reset:
+			return NewMessage(Disconnect, nil), nil
+		case <-timer.C:
+		}
+	}
+	return dm.msg, dm.err
+}
+
+func (s *chaosStream) pump(ctx context.Context) {
+	var last time.Time
+	for {
+		m, err := s.Stream.Receive(ctx)
+		if err == nil && s.datagram && m.Code() == Normal && s.rule.dropped() {
+			continue
+		}
+		due := time.Now().Add(s.rule.delay())
+		if due.Before(last) {
+			// Retain the order of the messages
+			due = last
+		}
+		last = due
+		select {
+		case <-ctx.Done():
+			return
+		case s.incoming <- delayedMessage{msg: m, err: err, due: due}:
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+func chaosConnID(proto int, dst string, port uint16) ConnID {
+	return NewConnID(proto, iputil.Parse("192.168.1.10"), iputil.Parse(dst), 4711, port)
+}
+
+func TestChaosRule_Matches(t *testing.T) {
+	id := chaosConnID(ipproto.TCP, "10.96.0.12", 5432)
+	tests := []struct {
+		dest  string
+		name  string
+		match bool
+	}{
+		{"10.96.0.12", "", true},
+		{"10.96.0.12:5432", "", true},
+		{"10.96.0.12:5433", "", false},
+		{"10.96.0.13", "", false},
+		{"10.96.0.0/16", "", true},
+		{"10.97.0.0/16:5432", "", false},
+		{"postgres.db:5432", "postgres.db.svc.cluster.local", true},
+		{"postgres.db", "postgres.db", true},
+		{"Postgres.DB.", "postgres.db", true},
+		{"postgres.db", "postgres.dbx.svc.cluster.local", false},
+		{"postgres.db", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dest, func(t *testing.T) {
+			r := &ChaosRule{Destination: tt.dest}
+			require.NoError(t, r.validate())
+			assert.Equal(t, tt.match, r.Matches(id, tt.name))
+		})
+	}
+}
+
+func TestChaos_AddRemove(t *testing.T) {
+	c := Chaos{}
+	assert.Error(t, c.Add(&ChaosRule{Destination: ""}))
+	assert.Error(t, c.Add(&ChaosRule{Destination: "x", DropRate: 1.5}))
+	assert.Error(t, c.Add(&ChaosRule{Destination: "x", Jitter: time.Second}))
+	assert.Error(t, c.Add(&ChaosRule{Destination: "x:http"}))
+
+	r1 := &ChaosRule{Destination: "a.ns"}
+	r2 := &ChaosRule{Destination: "b.ns"}
+	require.NoError(t, c.Add(r1))
+	require.NoError(t, c.Add(r2))
+	assert.Equal(t, "1", r1.ID)
+	assert.Equal(t, "2", r2.ID)
+	id := chaosConnID(ipproto.TCP, "10.96.0.12", 80)
+	assert.Equal(t, r2, c.Match(id, "b.ns.svc.cluster.local"))
+	assert.True(t, c.Remove("2"))
+	assert.False(t, c.Remove("2"))
+	assert.Nil(t, c.Match(id, "b.ns.svc.cluster.local"))
+	assert.Equal(t, []*ChaosRule{r1}, c.Rules())
+	c.RemoveAll()
+	assert.Empty(t, c.Rules())
+}
+
+func TestChaos_StreamCreator(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	c := Chaos{}
+	require.NoError(t, c.Add(&ChaosRule{Destination: "10.96.0.12", DropRate: 1}))
+	require.NoError(t, c.Add(&ChaosRule{Destination: "10.96.0.0/16", Latency: 100 * time.Millisecond}))
+	nameOf := func(net.IP) string { return "" }
+
+	var peer Stream
+	sc := c.StreamCreator(func(ctx context.Context, id ConnID) (Stream, error) {
+		var s Stream
+		s, peer = NewPipe(id, "session")
+		return s, nil
+	}, nameOf)
+
+	// TCP connections are dropped by the first rule
+	_, err := sc(ctx, chaosConnID(ipproto.TCP, "10.96.0.12", 80))
+	assert.True(t, errors.Is(err, ErrChaosDropped))
+
+	// UDP datagrams are dropped
+	s, err := sc(ctx, chaosConnID(ipproto.UDP, "10.96.0.12", 53))
+	require.NoError(t, err)
+	require.NoError(t, s.Send(ctx, NewMessage(Normal, []byte("hello"))))
+	sendCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	m, _ := peer.Receive(sendCtx)
+	cancel()
+	assert.Nil(t, m)
+
+	// Messages received on a matching stream are delayed
+	s, err = sc(ctx, chaosConnID(ipproto.TCP, "10.96.0.13", 80))
+	require.NoError(t, err)
+	start := time.Now()
+	require.NoError(t, peer.Send(ctx, NewMessage(Normal, []byte("hello"))))
+	m, err = s.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(m.Payload()))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestChaos_ResetAfter(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	c := Chaos{}
+	require.NoError(t, c.Add(&ChaosRule{Destination: "10.96.0.12", ResetAfter: 50 * time.Millisecond}))
+	sc := c.StreamCreator(func(ctx context.Context, id ConnID) (Stream, error) {
+		s, _ := NewPipe(id, "session")
+		return s, nil
+	}, func(net.IP) string { return "" })
+	s, err := sc(ctx, chaosConnID(ipproto.TCP, "10.96.0.12", 80))
+	require.NoError(t, err)
+	m, err := s.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Disconnect, m.Code())
+}
@@ -12,6 +12,8 @@ type GRPClientCStream interface {
 	CloseSend() error
 }
 
+// NewClientStream sends a StreamInfo message on the given grpcStream and waits for the StreamOK reply. The
+// compression requested using WithCompression is used if the peer accepts it.
 func NewClientStream(ctx context.Context, grpcStream GRPClientCStream, id ConnID, sessionID string, callDelay, dialTimeout time.Duration) (Stream, error) {
 	s := &clientStream{stream: newStream("CLI", grpcStream)}
 	s.id = id
@@ -19,7 +21,7 @@ func NewClientStream(ctx context.Context, grpcStream GRPClientCStream, id ConnID
 	s.dialTimeout = dialTimeout
 	s.sessionID = sessionID
 
-	if err := s.Send(ctx, StreamInfoMessage(id, sessionID, callDelay, dialTimeout)); err != nil {
+	if err := s.Send(ctx, StreamInfoMessage(id, sessionID, callDelay, dialTimeout, getCompression(ctx))); err != nil {
 		_ = s.CloseSend(ctx)
 		return nil, err
 	}
@@ -33,6 +35,7 @@ func NewClientStream(ctx context.Context, grpcStream GRPClientCStream, id ConnID
 		return nil, errors.New("initial message was not StreamOK")
 	}
 	s.peerVersion = getVersion(m)
+	s.compression = getAcceptedCompression(m)
 	return s, nil
 }
 
@@ -0,0 +1,161 @@
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression is the algorithm used when compressing the payload of Normal messages sent over a Stream.
+type Compression byte
+
+const (
+	CompressionNone = Compression(iota)
+	CompressionS2
+	CompressionZstd
+)
+
+// compressionVersion is the first Version that negotiates compression.
+const compressionVersion = 3
+
+// minCompressSize is the smallest payload that is considered for compression.
+const minCompressSize = 256
+
+// maxDecompressedSize is the largest payload that a compressed message can decompress into.
+const maxDecompressedSize = 16 * 1024 * 1024
+
+// ParseCompression returns the Compression that corresponds to the given string. The empty string
+// is parsed as CompressionNone.
+func ParseCompression(s string) (Compression, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return CompressionNone, nil
+	case "s2":
+		return CompressionS2, nil
+	case "zstd":
+		return CompressionZstd, nil
+	default:
+		return CompressionNone, fmt.Errorf("invalid compression %q, must be one of \"none\", \"s2\", or \"zstd\"", s)
+	}
+}
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionS2:
+		return "s2"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("** unknown compression: %d **", c)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Compression) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Compression) UnmarshalText(text []byte) (err error) {
+	*c, err = ParseCompression(string(text))
+	return err
+}
+
+type compressionKey struct{}
+
+// WithCompression returns a context that makes NewClientStream request the given compression from its peer.
+func WithCompression(ctx context.Context, c Compression) context.Context {
+	return context.WithValue(ctx, compressionKey{}, c)
+}
+
+func getCompression(ctx context.Context) Compression {
+	if c, ok := ctx.Value(compressionKey{}).(Compression); ok {
+		return c
+	}
+	return CompressionNone
+}
+
+type compressionOverrideKey struct{}
+
+// WithCompressionOverride returns a context that makes NewServerStream use the given compression instead of the
+// one requested by the client. A client that doesn't request compression will never get it.
+func WithCompressionOverride(ctx context.Context, c Compression) context.Context {
+	return context.WithValue(ctx, compressionOverrideKey{}, c)
+}
+
+// negotiateCompression returns the compression that a server stream will use, given the requested compression and
+// the version of the client.
+func negotiateCompression(ctx context.Context, peerVersion uint16, requested Compression) Compression {
+	if peerVersion < compressionVersion || requested == CompressionNone {
+		return CompressionNone
+	}
+	if c, ok := ctx.Value(compressionOverrideKey{}).(Compression); ok {
+		return c
+	}
+	switch requested {
+	case CompressionS2, CompressionZstd:
+		return requested
+	default:
+		return CompressionNone
+	}
+}
+
+//nolint:gochecknoglobals // zstd encoders and decoders are expensive to create and safe for concurrent use
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func initZstd() {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize), zstd.WithDecoderConcurrency(0))
+	})
+}
+
+// compress appends the compressed form of src to dst and returns the result.
+func (c Compression) compress(dst, src []byte) []byte {
+	switch c {
+	case CompressionS2:
+		n := len(dst)
+		dst = append(dst, make([]byte, s2.MaxEncodedLen(len(src)))...)
+		return dst[:n+len(s2.Encode(dst[n:], src))]
+	case CompressionZstd:
+		initZstd()
+		return zstdEncoder.EncodeAll(src, dst)
+	default:
+		return append(dst, src...)
+	}
+}
+
+// decompress appends the decompressed form of src to dst and returns the result.
+func (c Compression) decompress(dst, src []byte) ([]byte, error) {
+	switch c {
+	case CompressionS2:
+		l, err := s2.DecodedLen(src)
+		if err != nil {
+			return nil, err
+		}
+		if l > maxDecompressedSize {
+			return nil, fmt.Errorf("decompressed size %d exceeds max size %d", l, maxDecompressedSize)
+		}
+		n := len(dst)
+		dst = append(dst, make([]byte, l)...)
+		if _, err = s2.Decode(dst[n:], src); err != nil {
+			return nil, err
+		}
+		return dst, nil
+	case CompressionZstd:
+		initZstd()
+		return zstdDecoder.DecodeAll(src, dst)
+	default:
+		return append(dst, src...), nil
+	}
+}
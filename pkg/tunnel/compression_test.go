@@ -0,0 +1,257 @@
+package tunnel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+func TestParseCompression(t *testing.T) {
+	for _, c := range []Compression{CompressionNone, CompressionS2, CompressionZstd} {
+		pc, err := ParseCompression(c.String())
+		require.NoError(t, err)
+		assert.Equal(t, c, pc)
+	}
+	pc, err := ParseCompression("")
+	require.NoError(t, err)
+	assert.Equal(t, CompressionNone, pc)
+	_, err = ParseCompression("gzip")
+	assert.Error(t, err)
+}
+
+func TestCompression_roundtrip(t *testing.T) {
+	data := compressiblePayload(0x10000)
+	for _, c := range []Compression{CompressionNone, CompressionS2, CompressionZstd} {
+		t.Run(c.String(), func(t *testing.T) {
+			cd := c.compress([]byte{0xff}, data)
+			assert.Equal(t, byte(0xff), cd[0])
+			if c != CompressionNone {
+				assert.Less(t, len(cd), len(data))
+			}
+			dd, err := c.decompress([]byte{0xfe}, cd[1:])
+			require.NoError(t, err)
+			assert.Equal(t, byte(0xfe), dd[0])
+			assert.True(t, bytes.Equal(data, dd[1:]))
+		})
+	}
+}
+
+func TestStream_CompressionNegotiation(t *testing.T) {
+	id := NewConnID(ipproto.TCP, iputil.Parse("127.0.0.1"), iputil.Parse("192.168.0.1"), 1001, 8080)
+	si := uuid.New().String()
+	override := CompressionS2
+	tests := []struct {
+		name      string
+		requested Compression
+		override  *Compression
+		expected  Compression
+	}{
+		{"none", CompressionNone, nil, CompressionNone},
+		{"s2", CompressionS2, nil, CompressionS2},
+		{"zstd", CompressionZstd, nil, CompressionZstd},
+		{"override", CompressionZstd, &override, CompressionS2},
+		{"override not requested", CompressionNone, &override, CompressionNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := testContext(t, time.Second)
+			defer cancel()
+			tunnel := newBidi(10, ctx.Done())
+
+			var server Stream
+			wg := sync.WaitGroup{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sCtx := ctx
+				if tt.override != nil {
+					sCtx = WithCompressionOverride(sCtx, *tt.override)
+				}
+				var err error
+				server, err = NewServerStream(sCtx, tunnel.serverSide())
+				assert.NoError(t, err)
+			}()
+			client, err := NewClientStream(WithCompression(ctx, tt.requested), tunnel.clientSide(), id, si, 0, 0)
+			require.NoError(t, err)
+			wg.Wait()
+			require.NotNil(t, server)
+			assert.Equal(t, tt.expected, client.(*clientStream).compression)
+			assert.Equal(t, tt.expected, server.(*stream).compression)
+		})
+	}
+}
+
+func TestStream_CompressionLegacyPeer(t *testing.T) {
+	ctx, cancel := testContext(t, time.Second)
+	defer cancel()
+	tunnel := newBidi(10, ctx.Done())
+	id := NewConnID(ipproto.TCP, iputil.Parse("127.0.0.1"), iputil.Parse("192.168.0.1"), 1001, 8080)
+
+	// Simulate a version 2 server that knows nothing about compression.
+	go func() {
+		srv := tunnel.serverSide()
+		if _, err := srv.Recv(); err != nil {
+			return
+		}
+		m := makeMessage(streamOK, 1)
+		m[1] = 2
+		_ = srv.Send(m.TunnelMessage())
+	}()
+	client, err := NewClientStream(WithCompression(ctx, CompressionZstd), tunnel.clientSide(), id, "", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(2), client.PeerVersion())
+	assert.Equal(t, CompressionNone, client.(*clientStream).compression)
+}
+
+func TestStream_XferCompressed(t *testing.T) {
+	ctx, cancel := testContext(t, 30*time.Second)
+	defer cancel()
+
+	id := NewConnID(ipproto.TCP, iputil.Parse("127.0.0.1"), iputil.Parse("192.168.0.1"), 1001, 8080)
+	si := uuid.New().String()
+	b := compressiblePayload(0x1000)
+	large := NewMessage(Normal, b)
+	for _, c := range []Compression{CompressionS2, CompressionZstd} {
+		t.Run(c.String(), func(t *testing.T) {
+			errs := make(chan error, 10)
+			tunnel := newBidi(10, ctx.Done())
+			wg := sync.WaitGroup{}
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				if client, err := NewClientStream(WithCompression(ctx, c), tunnel.clientSide(), id, si, 0, 0); err != nil {
+					errs <- err
+				} else {
+					produce(ctx, client, large, errs)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				if server, err := NewServerStream(ctx, tunnel.serverSide()); err != nil {
+					errs <- err
+				} else {
+					consume(ctx, server, b, errs)
+				}
+			}()
+			wg.Wait()
+			requireNoErrs(t, errs)
+		})
+	}
+}
+
+// compressiblePayload returns a payload that resembles text, i.e. something that compresses reasonably well.
+func compressiblePayload(size int) []byte {
+	words := []string{"telepresence ", "traffic-manager ", "intercept ", "namespace ", "deployment ", "service ", "\n"}
+	rnd := rand.New(rand.NewSource(1))
+	b := bytes.Buffer{}
+	for b.Len() < size {
+		b.WriteString(words[rnd.Intn(len(words))])
+	}
+	return b.Bytes()[:size]
+}
+
+// pipeGRPC adapts one end of a NewPipe to the GRPClientCStream interface and simulates a link with limited bandwidth.
+type pipeGRPC struct {
+	ctx       context.Context
+	s         Stream
+	bandwidth int // bytes per second, zero means unlimited
+	wireBytes *int64
+}
+
+func (p *pipeGRPC) Recv() (*manager.TunnelMessage, error) {
+	m, err := p.s.Receive(p.ctx)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, p.ctx.Err()
+	}
+	return m.TunnelMessage(), nil
+}
+
+func (p *pipeGRPC) Send(tm *manager.TunnelMessage) error {
+	atomic.AddInt64(p.wireBytes, int64(len(tm.Payload)))
+	if p.bandwidth > 0 {
+		time.Sleep(time.Duration(len(tm.Payload)) * time.Second / time.Duration(p.bandwidth))
+	}
+	return p.s.Send(p.ctx, msg(tm.Payload))
+}
+
+func (p *pipeGRPC) CloseSend() error {
+	return p.s.CloseSend(p.ctx)
+}
+
+func benchmarkThroughput(b *testing.B, c Compression, bandwidth int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	id := NewConnID(ipproto.TCP, iputil.Parse("127.0.0.1"), iputil.Parse("192.168.0.1"), 1001, 8080)
+	a, z := NewPipe(id, "")
+	var wireBytes int64
+	ca := &pipeGRPC{ctx: ctx, s: a, bandwidth: bandwidth, wireBytes: &wireBytes}
+	cz := &pipeGRPC{ctx: ctx, s: z, bandwidth: bandwidth, wireBytes: &wireBytes}
+
+	serverCh := make(chan Stream, 1)
+	go func() {
+		s, err := NewServerStream(ctx, cz)
+		if err != nil {
+			b.Error(err)
+		}
+		serverCh <- s
+	}()
+	client, err := NewClientStream(WithCompression(ctx, c), ca, id, "", 0, 0)
+	require.NoError(b, err)
+	server := <-serverCh
+	require.NotNil(b, server)
+
+	payload := compressiblePayload(0x8000)
+	m := NewMessage(Normal, payload)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < b.N; i++ {
+			if _, err := server.Receive(ctx); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	}()
+
+	atomic.StoreInt64(&wireBytes, 0)
+	b.SetBytes(int64(len(payload)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err = client.Send(ctx, m); err != nil {
+			b.Fatal(err)
+		}
+	}
+	<-done
+	b.StopTimer()
+	b.ReportMetric(float64(atomic.LoadInt64(&wireBytes))/float64(b.N), "wire-B/op")
+}
+
+func BenchmarkStream_Throughput(b *testing.B) {
+	for _, bw := range []int{0, 10 * 1024 * 1024} {
+		for _, c := range []Compression{CompressionNone, CompressionS2, CompressionZstd} {
+			name := fmt.Sprintf("%s/unlimited", c)
+			if bw > 0 {
+				name = fmt.Sprintf("%s/%dMiBps", c, bw/(1024*1024))
+			}
+			b.Run(name, func(b *testing.B) {
+				benchmarkThroughput(b, c, bw)
+			})
+		}
+	}
+}
@@ -132,6 +132,10 @@ func (id ConnID) ProtocolString() (proto string) {
 		} else {
 			proto = "udp6"
 		}
+	case ipproto.ICMP:
+		proto = "icmp"
+	case ipproto.ICMPV6:
+		proto = "icmpv6"
 	default:
 		proto = fmt.Sprintf("unknown-%d", p)
 	}
@@ -22,8 +22,9 @@ import (
 // The idleDuration controls how long a dialer for a specific proto+from-to address combination remains alive without
 // reading or writing any messages. The dialer is normally closed by one of the peers.
 const (
-	tcpConnTTL = 2 * time.Hour // Default tcp_keepalive_time on Linux
-	udpConnTTL = 1 * time.Minute
+	tcpConnTTL  = 2 * time.Hour // Default tcp_keepalive_time on Linux
+	udpConnTTL  = 1 * time.Minute
+	icmpConnTTL = 10 * time.Second
 )
 
 const (
@@ -46,6 +47,7 @@ type streamReader interface {
 // The dialer takes care of dispatching messages between gRPC and UDP connections.
 type dialer struct {
 	TimedHandler
+	connStats
 	stream    Stream
 	cancel    context.CancelFunc
 	conn      net.Conn
@@ -68,10 +70,15 @@ func NewDialerTTL(stream Stream, cancel context.CancelFunc, ttl time.Duration) E
 	return NewConnEndpointTTL(stream, nil, cancel, ttl)
 }
 
+// NewConnEndpoint creates a new Endpoint that dispatches messages in both directions between the given gRPC stream
+// and the given connection. The returned Endpoint is also a Handler and a StatsProvider.
 func NewConnEndpoint(stream Stream, conn net.Conn, cancel context.CancelFunc) Endpoint {
 	ttl := tcpConnTTL
-	if stream.ID().Protocol() == ipproto.UDP {
+	switch stream.ID().Protocol() {
+	case ipproto.UDP:
 		ttl = udpConnTTL
+	case ipproto.ICMP, ipproto.ICMPV6:
+		ttl = icmpConnTTL
 	}
 	return NewConnEndpointTTL(stream, conn, cancel, ttl)
 }
@@ -83,6 +90,7 @@ func NewConnEndpointTTL(stream Stream, conn net.Conn, cancel context.CancelFunc,
 	}
 	return &dialer{
 		TimedHandler: NewTimedHandler(stream.ID(), ttl, nil),
+		connStats:    newConnStats(),
 		stream:       stream,
 		cancel:       cancel,
 		conn:         conn,
@@ -106,8 +114,7 @@ func (h *dialer) Start(ctx context.Context) {
 			h.connected = connecting
 
 			dlog.Tracef(ctx, "   CONN %s, dialing", id)
-			d := net.Dialer{Timeout: h.stream.DialTimeout()}
-			conn, err := d.DialContext(ctx, id.ProtocolString(), id.DestinationAddr().String())
+			conn, err := dialConn(ctx, id, h.stream.DialTimeout())
 			if err != nil {
 				dlog.Errorf(ctx, "!! CONN %s, failed to establish connection: %v", id, err)
 				span.SetStatus(codes.Error, err.Error())
@@ -148,6 +155,17 @@ func (h *dialer) Start(ctx context.Context) {
 	}()
 }
 
+// dialConn dials the destination of the given ConnID. ICMP echo requests are sent using an unprivileged ICMP socket.
+func dialConn(ctx context.Context, id ConnID, timeout time.Duration) (net.Conn, error) {
+	switch id.Protocol() {
+	case ipproto.ICMP, ipproto.ICMPV6:
+		return dialEcho(ctx, id)
+	default:
+		d := net.Dialer{Timeout: timeout}
+		return d.DialContext(ctx, id.ProtocolString(), id.DestinationAddr().String())
+	}
+}
+
 func (h *dialer) Done() <-chan struct{} {
 	return h.done
 }
@@ -197,6 +215,7 @@ func (h *dialer) connToStreamLoop(ctx context.Context, wg *sync.WaitGroup) {
 		n, err := h.conn.Read(buf)
 		if n > 0 {
 			dlog.Tracef(ctx, "<- CONN %s, len %d", id, n)
+			h.addSent(n)
 			select {
 			case <-ctx.Done():
 				endReason = ctx.Err().Error()
@@ -231,7 +250,11 @@ func (h *dialer) getStream() Stream {
 }
 
 func (h *dialer) reply(data []byte) (int, error) {
-	return h.conn.Write(data)
+	n, err := h.conn.Write(data)
+	if n > 0 {
+		h.addReceived(n)
+	}
+	return n, err
 }
 
 func (h *dialer) streamToConnLoop(ctx context.Context, wg *sync.WaitGroup) {
@@ -0,0 +1,96 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+
+	"golang.org/x/net/icmp"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+)
+
+const (
+	icmpEchoRequest   = 8
+	icmpEchoReply     = 0
+	icmpv6EchoRequest = 128
+	icmpv6EchoReply   = 129
+)
+
+// errEchoNotPermitted is returned by dialEcho when the kernel refuses to create an unprivileged ICMP socket.
+var errEchoNotPermitted = errors.New("unprivileged ICMP sockets are not permitted by the net.ipv4.ping_group_range sysctl")
+
+// echoNotPermittedOnce ensures that the advice on how to permit unprivileged ICMP sockets is logged only once.
+var echoNotPermittedOnce sync.Once //nolint:gochecknoglobals // logging state
+
+// echoConn is a net.Conn that sends ICMP echo requests to one destination using an unprivileged ICMP socket,
+// and reads the echo replies from that destination. Each Write and each Read is one complete ICMP message.
+//
+// The kernel replaces the identifier of the requests with the identifier of the socket, so the replies will
+// have that identifier. The peer that created the requests is responsible for restoring the original one.
+type echoConn struct {
+	*icmp.PacketConn
+	dst         *net.UDPAddr
+	requestType byte
+	replyType   byte
+}
+
+// dialEcho creates an echoConn for the destination of the given ConnID. The source port of an ICMP ConnID is
+// the identifier of the echo requests. It is ignored here.
+//
+// Unprivileged ICMP sockets must be permitted by the net.ipv4.ping_group_range sysctl of the network namespace.
+// An error wrapping errEchoNotPermitted is returned when they aren't, and a warning that explains how to
+// permit them is logged the first time that happens.
+func dialEcho(ctx context.Context, id ConnID) (net.Conn, error) {
+	c := &echoConn{dst: &net.UDPAddr{IP: id.Destination()}}
+	var network, address string
+	if id.Protocol() == ipproto.ICMP {
+		network, address = "udp4", "0.0.0.0"
+		c.requestType, c.replyType = icmpEchoRequest, icmpEchoReply
+	} else {
+		network, address = "udp6", "::"
+		c.requestType, c.replyType = icmpv6EchoRequest, icmpv6EchoReply
+	}
+	pc, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		if errors.Is(err, syscall.EACCES) || errors.Is(err, syscall.EPERM) {
+			echoNotPermittedOnce.Do(func() {
+				dlog.Warnf(ctx, "ICMP echo requests cannot be forwarded: %v. Add the sysctl "+
+					"net.ipv4.ping_group_range=\"0 2147483647\" to the securityContext of the pod to permit them", err)
+			})
+			return nil, fmt.Errorf("%w: %v", errEchoNotPermitted, err)
+		}
+		return nil, fmt.Errorf("unable to create unprivileged ICMP socket: %w", err)
+	}
+	c.PacketConn = pc
+	return c, nil
+}
+
+// Read reads the next echo reply from the destination. Other messages are discarded.
+func (c *echoConn) Read(b []byte) (int, error) {
+	for {
+		n, addr, err := c.ReadFrom(b)
+		if err != nil {
+			return 0, err
+		}
+		if ua, ok := addr.(*net.UDPAddr); ok && ua.IP.Equal(c.dst.IP) && n >= 8 && b[0] == c.replyType {
+			return n, nil
+		}
+	}
+}
+
+// Write sends the given echo request to the destination.
+func (c *echoConn) Write(b []byte) (int, error) {
+	if len(b) < 8 || b[0] != c.requestType {
+		return 0, fmt.Errorf("not an ICMP echo request")
+	}
+	return c.WriteTo(b, c.dst)
+}
+
+func (c *echoConn) RemoteAddr() net.Addr {
+	return c.dst
+}
@@ -0,0 +1,85 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+// TestDialer_echo tests the peer side of an ICMP echo, i.e. the dialer that the traffic-manager or the
+// traffic-agent creates for a stream with an ICMP ConnID.
+func TestDialer_echo(t *testing.T) {
+	ctx, cancel := testContext(t, 10*time.Second)
+	defer cancel()
+
+	id := NewConnID(ipproto.ICMP, iputil.Parse("127.0.0.1"), iputil.Parse("127.0.0.1"), 1234, 0)
+	conn, err := dialEcho(ctx, id)
+	if errors.Is(err, errEchoNotPermitted) {
+		t.Skip(err.Error())
+	}
+	require.NoError(t, err)
+	_ = conn.Close()
+
+	tunnel := newBidi(10, ctx.Done())
+	var server Stream
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		server, err = NewServerStream(ctx, tunnel.serverSide())
+	}()
+	client, cErr := NewClientStream(ctx, tunnel.clientSide(), id, uuid.New().String(), 0, 0)
+	require.NoError(t, cErr)
+	wg.Wait()
+	require.NoError(t, err)
+
+	dCtx, dCancel := context.WithCancel(ctx)
+	defer dCancel()
+	NewDialer(server, dCancel).Start(dCtx)
+
+	rdCh, errCh := ReadLoop(ctx, client)
+	next := func() Message {
+		select {
+		case m := <-rdCh:
+			return m
+		case err := <-errCh:
+			require.NoError(t, err)
+		case <-ctx.Done():
+			require.NoError(t, ctx.Err())
+		}
+		return nil
+	}
+	m := next()
+	require.NotNil(t, m)
+	require.Equal(t, DialOK, m.Code())
+
+	for seq := 1; seq <= 3; seq++ {
+		data := []byte(fmt.Sprintf("ping %d", seq))
+		rq, err := (&icmp.Message{Type: ipv4.ICMPTypeEcho, Body: &icmp.Echo{ID: 1234, Seq: seq, Data: data}}).Marshal(nil)
+		require.NoError(t, err)
+		require.NoError(t, client.Send(ctx, NewMessage(Normal, rq)))
+
+		m = next()
+		require.NotNil(t, m)
+		require.Equal(t, Normal, m.Code())
+		rp, err := icmp.ParseMessage(1, m.Payload())
+		require.NoError(t, err)
+		require.Equal(t, ipv4.ICMPTypeEchoReply, rp.Type)
+		echo, ok := rp.Body.(*icmp.Echo)
+		require.True(t, ok)
+		assert.Equal(t, seq, echo.Seq)
+		assert.Equal(t, data, echo.Data)
+	}
+}
@@ -57,6 +57,13 @@ const (
 
 	KeepAlive
 	Session
+
+	// compressed is a Normal message with a compressed payload. It's only sent when compression has been
+	// negotiated, and is never returned from Stream.Receive.
+	compressed
+
+	// muxInfo is the initial message of a gRPC stream that multiplexes many connections.
+	muxInfo
 )
 
 func (c MessageCode) String() string {
@@ -77,6 +84,10 @@ func (c MessageCode) String() string {
 		return "KEEP_ALIVE"
 	case Session:
 		return "SESSION"
+	case compressed:
+		return "COMPRESSED"
+	case muxInfo:
+		return "MUX_INFO"
 	default:
 		return fmt.Sprintf("** unknown control code: %d **", c)
 	}
@@ -119,7 +130,7 @@ func NewMessage(code MessageCode, payload []byte) Message {
 	return msg{byte(code)}
 }
 
-func StreamInfoMessage(id ConnID, sessionID string, callDelay, dialTimeout time.Duration) Message {
+func StreamInfoMessage(id ConnID, sessionID string, callDelay, dialTimeout time.Duration, compression Compression) Message {
 	b := bytes.Buffer{}
 	b.WriteByte(byte(streamInfo))
 
@@ -142,12 +153,23 @@ func StreamInfoMessage(id ConnID, sessionID string, callDelay, dialTimeout time.
 	n = binary.PutUvarint(buf, uint64(len(sb)))
 	b.Write(buf[:n])
 	b.Write(sb)
+
+	// Peers prior to compressionVersion will ignore this
+	n = binary.PutUvarint(buf, uint64(compression))
+	b.Write(buf[:n])
 	return msg(b.Bytes())
 }
 
-func StreamOKMessage() Message {
+func StreamOKMessage(compression Compression) Message {
 	m := makeMessage(streamOK, 4)
 	n := binary.PutUvarint(m.Payload(), uint64(Version))
+	n += binary.PutUvarint(m.Payload()[n:], uint64(compression))
+	return m[:n+1]
+}
+
+func muxInfoMessage() Message {
+	m := makeMessage(muxInfo, 4)
+	n := binary.PutUvarint(m.Payload(), uint64(Version))
 	return m[:n+1]
 }
 
@@ -171,6 +193,20 @@ func getVersion(m Message) uint16 {
 	return uint16(v)
 }
 
+// getAcceptedCompression returns the compression that the peer accepted in this StreamOK Message.
+func getAcceptedCompression(m Message) Compression {
+	pl := m.Payload()
+	v, n := binary.Uvarint(pl)
+	if n <= 0 || v < compressionVersion {
+		return CompressionNone
+	}
+	c, n := binary.Uvarint(pl[n:])
+	if n <= 0 {
+		return CompressionNone
+	}
+	return Compression(c)
+}
+
 var errMalformedConnect = errors.New("malformed Connect message")
 
 // connectInfo returns the connectInfo that this Message represents.
@@ -212,5 +248,13 @@ func setConnectInfo(m Message, s *stream) error {
 	}
 	pl = pl[n:]
 	s.sessionID = string(pl[:v])
+	pl = pl[v:]
+
+	if s.peerVersion >= compressionVersion {
+		if v, n = binary.Uvarint(pl); n <= 0 {
+			return errMalformedConnect
+		}
+		s.compression = Compression(v)
+	}
 	return nil
 }
@@ -0,0 +1,428 @@
+package tunnel
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+// muxVersion is the first Version that can multiplex many connections over one gRPC stream.
+const muxVersion = 4
+
+// muxWindowSize is the number of messages that may be sent on a multiplexed connection before the
+// sender must wait for the receiver to grant more credit.
+const muxWindowSize = 64
+
+// muxFrameCode is the first byte of each frame sent on a multiplexed gRPC stream. The code is followed
+// by the ConnID that the frame belongs to and the frame data.
+type muxFrameCode byte
+
+const (
+	// muxData frames carry a message of the multiplexed connection.
+	muxData = muxFrameCode(iota)
+
+	// muxWindow frames grant the peer credit to send more muxData frames. The data is a uvarint with the
+	// number of frames.
+	muxWindow
+
+	// muxClose frames are sent by the client when it will send no more data frames on the connection.
+	muxClose
+
+	// muxEnd frames terminates the connection in both directions. The data, if present, is an error message.
+	muxEnd
+)
+
+func (c muxFrameCode) String() string {
+	switch c {
+	case muxData:
+		return "DATA"
+	case muxWindow:
+		return "WINDOW"
+	case muxClose:
+		return "CLOSE"
+	case muxEnd:
+		return "END"
+	default:
+		return fmt.Sprintf("** unknown frame code: %d **", c)
+	}
+}
+
+func makeMuxFrame(code muxFrameCode, id ConnID, data []byte) []byte {
+	b := make([]byte, 0, 1+binary.MaxVarintLen64+len(id)+len(data))
+	b = append(b, byte(code))
+	b = binary.AppendUvarint(b, uint64(len(id)))
+	b = append(b, id...)
+	return append(b, data...)
+}
+
+var errMalformedMuxFrame = errors.New("malformed multiplexed frame")
+
+func parseMuxFrame(b []byte) (muxFrameCode, ConnID, []byte, error) {
+	if len(b) < 2 {
+		return 0, "", nil, errMalformedMuxFrame
+	}
+	code := muxFrameCode(b[0])
+	b = b[1:]
+	l, n := binary.Uvarint(b)
+	if n <= 0 || l > uint64(len(b)-n) {
+		return 0, "", nil, errMalformedMuxFrame
+	}
+	b = b[n:]
+	return code, ConnID(b[:l]), b[l:], nil
+}
+
+// muxer sends and receives the frames of a multiplexed gRPC stream and dispatches them to muxConn instances.
+type muxer struct {
+	tag        string
+	grpcStream GRPCStream
+	sendLock   sync.Mutex
+
+	connsLock sync.Mutex
+	conns     map[ConnID]*muxConn
+
+	// done is closed when the readLoop ends
+	done chan struct{}
+}
+
+func newMuxer(tag string, grpcStream GRPCStream) *muxer {
+	return &muxer{
+		tag:        tag,
+		grpcStream: grpcStream,
+		conns:      make(map[ConnID]*muxConn),
+		done:       make(chan struct{}),
+	}
+}
+
+func (m *muxer) sendFrame(code muxFrameCode, id ConnID, data []byte) error {
+	select {
+	case <-m.done:
+		return io.EOF
+	default:
+	}
+	m.sendLock.Lock()
+	defer m.sendLock.Unlock()
+	return m.grpcStream.Send(&manager.TunnelMessage{Payload: makeMuxFrame(code, id, data)})
+}
+
+// connCount returns the number of active connections.
+func (m *muxer) connCount() int {
+	m.connsLock.Lock()
+	defer m.connsLock.Unlock()
+	return len(m.conns)
+}
+
+// newConn creates and registers a new connection with the given id. The connection ends when the given
+// context is cancelled.
+func (m *muxer) newConn(ctx context.Context, id ConnID) (*muxConn, error) {
+	m.connsLock.Lock()
+	defer m.connsLock.Unlock()
+	if _, ok := m.conns[id]; ok {
+		return nil, fmt.Errorf("connection %s is already multiplexed", id)
+	}
+	select {
+	case <-m.done:
+		return nil, io.EOF
+	default:
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	c := newMuxConn(ctx, cancel, m, id)
+	m.conns[id] = c
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.end(ctx, ctx.Err())
+		case <-c.ended:
+		}
+	}()
+	return c, nil
+}
+
+func (m *muxer) removeConn(id ConnID) {
+	m.connsLock.Lock()
+	delete(m.conns, id)
+	m.connsLock.Unlock()
+}
+
+// readLoop reads frames from the gRPC stream until it fails or the context is cancelled. The onNew function,
+// when not nil, is called when a data frame arrives for an unknown connection and that data is a StreamInfo
+// message, i.e. when the client creates a new Stream.
+func (m *muxer) readLoop(ctx context.Context, onNew func(*muxConn)) error {
+	var err error
+	defer func() {
+		close(m.done)
+		m.connsLock.Lock()
+		conns := m.conns
+		m.conns = make(map[ConnID]*muxConn)
+		m.connsLock.Unlock()
+		for _, c := range conns {
+			c.remoteEnd(ctx, err)
+		}
+		dlog.Tracef(ctx, "   %s, multiplexer ended: %v", m.tag, err)
+	}()
+	for {
+		var tm *manager.TunnelMessage
+		if tm, err = m.grpcStream.Recv(); err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		code, id, data, perr := parseMuxFrame(tm.Payload)
+		if perr != nil {
+			dlog.Errorf(ctx, "!! %s, %v", m.tag, perr)
+			continue
+		}
+		m.connsLock.Lock()
+		c := m.conns[id]
+		if c == nil && code == muxData && onNew != nil && len(data) > 0 && MessageCode(data[0]) == streamInfo {
+			cc, cancel := context.WithCancel(ctx)
+			c = newMuxConn(cc, cancel, m, id)
+			m.conns[id] = c
+			m.connsLock.Unlock()
+			onNew(c)
+		} else {
+			m.connsLock.Unlock()
+		}
+		if c == nil {
+			dlog.Tracef(ctx, "   %s %s, dropping %s frame for unknown connection", m.tag, id, code)
+			continue
+		}
+		switch code {
+		case muxData:
+			c.push(ctx, data)
+		case muxWindow:
+			if n, nn := binary.Uvarint(data); nn > 0 {
+				c.addCredit(int(n))
+			}
+		case muxClose:
+			c.remoteClose()
+		case muxEnd:
+			m.removeConn(id)
+			var endErr error
+			if len(data) > 0 {
+				endErr = errors.New(string(data))
+			}
+			c.remoteEnd(ctx, endErr)
+		default:
+			dlog.Errorf(ctx, "!! %s %s, unknown frame code %s", m.tag, id, code)
+		}
+	}
+}
+
+// muxConn is one connection of a muxer. It implements the GRPClientCStream interface so that a Stream can
+// use it in place of a gRPC stream of its own.
+type muxConn struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	m      *muxer
+	id     ConnID
+
+	// inbox receives the data frames. It's only written to and closed by the muxer's readLoop.
+	inbox       chan []byte
+	inboxClosed bool
+	endErr      error
+
+	// consumed is the number of frames read from the inbox since the last window update.
+	consumed int
+
+	creditLock sync.Mutex
+	credit     int
+	creditCh   chan struct{}
+
+	endOnce sync.Once
+	ended   chan struct{}
+}
+
+func newMuxConn(ctx context.Context, cancel context.CancelFunc, m *muxer, id ConnID) *muxConn {
+	return &muxConn{
+		ctx:      ctx,
+		cancel:   cancel,
+		m:        m,
+		id:       id,
+		inbox:    make(chan []byte, muxWindowSize),
+		credit:   muxWindowSize,
+		creditCh: make(chan struct{}, 1),
+		ended:    make(chan struct{}),
+	}
+}
+
+// push is called by the readLoop when a data frame arrives.
+func (c *muxConn) push(ctx context.Context, data []byte) {
+	if c.inboxClosed {
+		return
+	}
+	select {
+	case c.inbox <- data:
+	default:
+		// The peer ignored the flow control.
+		dlog.Errorf(ctx, "!! %s %s, window size exceeded", c.m.tag, c.id)
+		c.m.removeConn(c.id)
+		c.end(ctx, errors.New("window size exceeded"))
+		c.remoteEnd(ctx, nil)
+	}
+}
+
+// remoteClose is called by the readLoop when the peer will send no more data.
+func (c *muxConn) remoteClose() {
+	if !c.inboxClosed {
+		c.inboxClosed = true
+		close(c.inbox)
+	}
+}
+
+// remoteEnd is called by the readLoop when the peer terminated the connection.
+func (c *muxConn) remoteEnd(ctx context.Context, err error) {
+	if !c.inboxClosed {
+		c.endErr = err
+		c.inboxClosed = true
+		close(c.inbox)
+	}
+	c.endOnce.Do(func() {
+		dlog.Tracef(ctx, "   %s %s, multiplexed connection ended by peer", c.m.tag, c.id)
+		close(c.ended)
+		c.cancel()
+	})
+}
+
+// end terminates the connection and tells the peer about it.
+func (c *muxConn) end(ctx context.Context, err error) {
+	c.endOnce.Do(func() {
+		c.m.removeConn(c.id)
+		close(c.ended)
+		var data []byte
+		if err != nil && !errors.Is(err, context.Canceled) {
+			data = []byte(err.Error())
+		}
+		if serr := c.m.sendFrame(muxEnd, c.id, data); serr != nil && !errors.Is(serr, io.EOF) {
+			dlog.Errorf(ctx, "!! %s %s, failed to send end of multiplexed connection: %v", c.m.tag, c.id, serr)
+		}
+		c.cancel()
+	})
+}
+
+func (c *muxConn) addCredit(n int) {
+	c.creditLock.Lock()
+	c.credit += n
+	c.creditLock.Unlock()
+	select {
+	case c.creditCh <- struct{}{}:
+	default:
+	}
+}
+
+// Recv returns the next message from the inbox, or io.EOF when the inbox is closed and drained.
+func (c *muxConn) Recv() (*manager.TunnelMessage, error) {
+	select {
+	case data, ok := <-c.inbox:
+		return c.received(data, ok)
+	case <-c.ctx.Done():
+		// The inbox is closed before the context is cancelled when the peer ends the connection, so
+		// what's in it takes precedence.
+		select {
+		case data, ok := <-c.inbox:
+			return c.received(data, ok)
+		default:
+			return nil, c.ctx.Err()
+		}
+	}
+}
+
+func (c *muxConn) received(data []byte, ok bool) (*manager.TunnelMessage, error) {
+	if !ok {
+		if c.endErr != nil {
+			return nil, c.endErr
+		}
+		return nil, io.EOF
+	}
+	if c.consumed++; c.consumed >= muxWindowSize/2 {
+		if err := c.m.sendFrame(muxWindow, c.id, binary.AppendUvarint(nil, uint64(c.consumed))); err != nil {
+			return nil, err
+		}
+		c.consumed = 0
+	}
+	return &manager.TunnelMessage{Payload: data}, nil
+}
+
+// Send sends the given message as a data frame once the peer has granted credit for it.
+func (c *muxConn) Send(tm *manager.TunnelMessage) error {
+	for {
+		c.creditLock.Lock()
+		if c.credit > 0 {
+			c.credit--
+			c.creditLock.Unlock()
+			break
+		}
+		c.creditLock.Unlock()
+		select {
+		case <-c.creditCh:
+		case <-c.ended:
+			return io.EOF
+		}
+	}
+	select {
+	case <-c.ended:
+		return io.EOF
+	default:
+	}
+	return c.m.sendFrame(muxData, c.id, tm.Payload)
+}
+
+// CloseSend tells the peer that no more data frames will be sent.
+func (c *muxConn) CloseSend() error {
+	select {
+	case <-c.ended:
+		return nil
+	default:
+	}
+	return c.m.sendFrame(muxClose, c.id, nil)
+}
+
+// ServeStreams reads the initial message from the given gRPC stream. A StreamInfo message results in one Stream
+// that is passed to the given handler, just like when using NewServerStream. A request to multiplex results in one
+// Stream for each multiplexed connection, each one passed to the handler in a goroutine of its own.
+func ServeStreams(ctx context.Context, grpcStream GRPCStream, handler func(context.Context, Stream) error) error {
+	s := newStream("SRV", grpcStream)
+	m, err := s.Receive(ctx)
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "failed to read initial message: %v", err)
+	}
+	if m.Code() != muxInfo {
+		if err = s.accept(ctx, m); err != nil {
+			return status.Errorf(codes.FailedPrecondition, "failed to connect stream: %v", err)
+		}
+		return handler(ctx, &s)
+	}
+
+	if getVersion(m) < muxVersion {
+		return status.Error(codes.FailedPrecondition, "peer version does not support multiplexing")
+	}
+	if err = s.Send(ctx, StreamOKMessage(CompressionNone)); err != nil {
+		return err
+	}
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+	mx := newMuxer("SRV", grpcStream)
+	return mx.readLoop(ctx, func(c *muxConn) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := NewServerStream(c.ctx, c)
+			if err == nil {
+				err = handler(c.ctx, s)
+			}
+			if err != nil && c.ctx.Err() == nil {
+				dlog.Errorf(c.ctx, "!! %s %s, %v", mx.tag, c.id, err)
+			}
+			c.end(c.ctx, err)
+		}()
+	})
+}
@@ -0,0 +1,163 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/datawire/dlib/dlog"
+)
+
+// DefaultMaxMuxStreams is the number of gRPC streams that a client normally uses for multiplexing.
+const DefaultMaxMuxStreams = 2
+
+// muxHandshakeTimeout is the max time to wait for the peer to respond to a request to multiplex.
+const muxHandshakeTimeout = 10 * time.Second
+
+// MuxClient creates Streams that share a small number of long-lived, multiplexed, gRPC streams. A MuxClient
+// falls back to using one gRPC stream per Stream when the peer doesn't support multiplexing.
+type MuxClient struct {
+	ctx        context.Context
+	openTunnel func(context.Context) (GRPClientCStream, error)
+	maxStreams int
+
+	lock        sync.Mutex
+	muxers      []*muxer
+	unsupported bool
+
+	// failingSince is the time when opening a muxer first failed, or zero when the last attempt succeeded.
+	failingSince time.Time
+}
+
+// NewMuxClient creates a MuxClient that uses at most maxStreams gRPC streams, each one opened using the
+// given openTunnel function. The gRPC streams are closed when the given context is cancelled.
+func NewMuxClient(ctx context.Context, openTunnel func(context.Context) (GRPClientCStream, error), maxStreams int) *MuxClient {
+	if maxStreams < 1 {
+		maxStreams = 1
+	}
+	return &MuxClient{ctx: ctx, openTunnel: openTunnel, maxStreams: maxStreams}
+}
+
+// NewStream creates a new Stream. The arguments have the same meaning as for NewClientStream.
+func (mc *MuxClient) NewStream(ctx context.Context, id ConnID, sessionID string, callDelay, dialTimeout time.Duration) (Stream, error) {
+	mx := mc.getMuxer(ctx)
+	if mx != nil {
+		c, err := mx.newConn(ctx, id)
+		if err == nil {
+			s, err := NewClientStream(c.ctx, c, id, sessionID, callDelay, dialTimeout)
+			if err != nil {
+				c.end(ctx, err)
+				return nil, err
+			}
+			return s, nil
+		}
+		dlog.Debugf(ctx, "Using a dedicated tunnel for %s: %v", id, err)
+	}
+	gs, err := mc.openTunnel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientStream(ctx, gs, id, sessionID, callDelay, dialTimeout)
+}
+
+// getMuxer returns the least busy muxer, opening a new one when fewer than maxStreams are active. It returns
+// nil when the peer doesn't support multiplexing, or when a new muxer couldn't be opened.
+func (mc *MuxClient) getMuxer(ctx context.Context) *muxer {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	if mc.unsupported {
+		return nil
+	}
+
+	// Remove muxers that have ended.
+	active := mc.muxers[:0]
+	for _, mx := range mc.muxers {
+		select {
+		case <-mx.done:
+		default:
+			active = append(active, mx)
+		}
+	}
+	mc.muxers = active
+
+	if len(mc.muxers) < mc.maxStreams {
+		mx, err := mc.openMuxer()
+		if err == nil {
+			if !mc.failingSince.IsZero() {
+				dlog.Infof(ctx, "Multiplexed tunnel restored after %s", time.Since(mc.failingSince).Round(time.Millisecond))
+				mc.failingSince = time.Time{}
+			}
+			mc.muxers = append(mc.muxers, mx)
+			return mx
+		}
+		if status.Code(err) == codes.FailedPrecondition {
+			dlog.Infof(ctx, "Peer doesn't support multiplexed tunnels, using one tunnel per connection")
+			mc.unsupported = true
+			return nil
+		}
+		if mc.failingSince.IsZero() {
+			mc.failingSince = time.Now()
+		}
+		dlog.Errorf(ctx, "Unable to open multiplexed tunnel: %v", err)
+	}
+
+	var best *muxer
+	bestCount := 0
+	for _, mx := range mc.muxers {
+		if cnt := mx.connCount(); best == nil || cnt < bestCount {
+			best = mx
+			bestCount = cnt
+		}
+	}
+	return best
+}
+
+// openMuxer opens a new gRPC stream and performs the multiplexing handshake.
+func (mc *MuxClient) openMuxer() (*muxer, error) {
+	ctx, cancel := context.WithCancel(mc.ctx)
+	gs, err := mc.openTunnel(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	s := newStream("CLI", gs)
+	tm := time.AfterFunc(muxHandshakeTimeout, cancel)
+	m, err := func() (Message, error) {
+		defer tm.Stop()
+		if err := s.Send(ctx, muxInfoMessage()); err != nil {
+			return nil, err
+		}
+		return s.Receive(ctx)
+	}()
+	if err == nil {
+		switch {
+		case m.Code() != streamOK:
+			err = status.Error(codes.FailedPrecondition, "initial message was not StreamOK")
+		case getVersion(m) < muxVersion:
+			err = status.Errorf(codes.FailedPrecondition, "peer version %d does not support multiplexing", getVersion(m))
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil && mc.ctx.Err() == nil {
+			err = fmt.Errorf("multiplexing handshake timed out after %s", muxHandshakeTimeout)
+		}
+		_ = gs.CloseSend()
+		cancel()
+		return nil, err
+	}
+
+	mx := newMuxer("CLI", gs)
+	go func() {
+		defer cancel()
+		if err := mx.readLoop(ctx, nil); err != nil && !errors.Is(err, context.Canceled) {
+			dlog.Errorf(ctx, "multiplexed tunnel ended: %v", err)
+		}
+	}()
+	return mx, nil
+}
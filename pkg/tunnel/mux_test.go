@@ -0,0 +1,277 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+// testTunnel emulates a gRPC Tunnel call, including the error returned from the server handler.
+type testTunnel struct {
+	ctx        context.Context
+	cToS       chan *manager.TunnelMessage
+	sToC       chan *manager.TunnelMessage
+	serverDone chan struct{}
+	serverErr  error
+	closeOnce  sync.Once
+}
+
+func newTestTunnel(ctx context.Context) *testTunnel {
+	return &testTunnel{
+		ctx:        ctx,
+		cToS:       make(chan *manager.TunnelMessage, 10),
+		sToC:       make(chan *manager.TunnelMessage, 10),
+		serverDone: make(chan struct{}),
+	}
+}
+
+// serve runs the given handler as the server side of the tunnel.
+func (t *testTunnel) serve(handler func(context.Context, GRPCStream) error) {
+	go func() {
+		t.serverErr = handler(t.ctx, &testTunnelServer{t})
+		close(t.serverDone)
+		close(t.sToC)
+	}()
+}
+
+func (t *testTunnel) Recv() (*manager.TunnelMessage, error) {
+	select {
+	case <-t.ctx.Done():
+		return nil, t.ctx.Err()
+	case m, ok := <-t.sToC:
+		if !ok {
+			if t.serverErr != nil {
+				return nil, t.serverErr
+			}
+			return nil, io.EOF
+		}
+		return m, nil
+	}
+}
+
+func (t *testTunnel) Send(m *manager.TunnelMessage) error {
+	select {
+	case <-t.serverDone:
+		return io.EOF
+	case t.cToS <- m:
+		return nil
+	}
+}
+
+func (t *testTunnel) CloseSend() error {
+	t.closeOnce.Do(func() { close(t.cToS) })
+	return nil
+}
+
+type testTunnelServer struct {
+	*testTunnel
+}
+
+func (t *testTunnelServer) Recv() (*manager.TunnelMessage, error) {
+	select {
+	case <-t.ctx.Done():
+		return nil, t.ctx.Err()
+	case m, ok := <-t.cToS:
+		if !ok {
+			return nil, io.EOF
+		}
+		return m, nil
+	}
+}
+
+func (t *testTunnelServer) Send(m *manager.TunnelMessage) error {
+	select {
+	case <-t.ctx.Done():
+		return t.ctx.Err()
+	case t.sToC <- m:
+		return nil
+	}
+}
+
+// echo sends all messages received on the stream back to the sender.
+func echo(ctx context.Context, s Stream) error {
+	for {
+		m, err := s.Receive(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		if err = s.Send(ctx, m); err != nil {
+			return err
+		}
+	}
+}
+
+// legacyServer emulates a traffic-manager that doesn't know about multiplexing.
+func legacyServer(ctx context.Context, gs GRPCStream) error {
+	s, err := NewServerStream(ctx, gs)
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "failed to connect stream: %v", err)
+	}
+	return echo(ctx, s)
+}
+
+func muxServer(ctx context.Context, gs GRPCStream) error {
+	return ServeStreams(ctx, gs, echo)
+}
+
+func newTestMuxClient(ctx context.Context, maxStreams int, server func(context.Context, GRPCStream) error) (*MuxClient, *int32) {
+	var opened int32
+	return NewMuxClient(ctx, func(ctx context.Context) (GRPClientCStream, error) {
+		atomic.AddInt32(&opened, 1)
+		tt := newTestTunnel(ctx)
+		tt.serve(server)
+		return tt, nil
+	}, maxStreams), &opened
+}
+
+func testConnID(i int) ConnID {
+	return NewConnID(ipproto.TCP, iputil.Parse("127.0.0.1"), iputil.Parse("192.168.0.1"), uint16(1000+i), 8080)
+}
+
+// exchange sends count messages on the stream, verifies the echoed replies, and then closes the stream.
+func exchange(ctx context.Context, s Stream, count int) error {
+	errCh := make(chan error, 1)
+	go func() {
+		for i := 0; i < count; i++ {
+			if err := s.Send(ctx, NewMessage(Normal, []byte(fmt.Sprintf("%s %d", s.ID(), i)))); err != nil {
+				errCh <- err
+				return
+			}
+		}
+		errCh <- nil
+	}()
+	for i := 0; i < count; i++ {
+		m, err := s.Receive(ctx)
+		if err != nil {
+			return err
+		}
+		if exp := fmt.Sprintf("%s %d", s.ID(), i); exp != string(m.Payload()) {
+			return fmt.Errorf("expected %q, got %q", exp, m.Payload())
+		}
+	}
+	if err := <-errCh; err != nil {
+		return err
+	}
+	if err := s.CloseSend(ctx); err != nil {
+		return err
+	}
+	if m, err := s.Receive(ctx); err == nil {
+		return fmt.Errorf("unexpected message %s after close", m.Code())
+	} else if !(errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed)) {
+		return err
+	}
+	return nil
+}
+
+func TestMuxClient_Xfer(t *testing.T) {
+	ctx, cancel := testContext(t, 30*time.Second)
+	defer cancel()
+
+	mc, opened := newTestMuxClient(ctx, 2, muxServer)
+	si := uuid.New().String()
+	wg := sync.WaitGroup{}
+	const streams = 20
+	wg.Add(streams)
+	for i := 0; i < streams; i++ {
+		go func(i int) {
+			defer wg.Done()
+			s, err := mc.NewStream(ctx, testConnID(i), si, 0, 0)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, Version, s.PeerVersion())
+			assert.NoError(t, exchange(ctx, s, 3*muxWindowSize))
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(opened))
+
+	// All connections have ended, so the muxers should be empty.
+	require.Eventually(t, func() bool {
+		mc.lock.Lock()
+		defer mc.lock.Unlock()
+		for _, mx := range mc.muxers {
+			if mx.connCount() > 0 {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestMuxClient_FlowControl(t *testing.T) {
+	ctx, cancel := testContext(t, 30*time.Second)
+	defer cancel()
+
+	mc, _ := newTestMuxClient(ctx, 1, muxServer)
+	si := uuid.New().String()
+
+	// Stream a is blocked because nothing reads its replies. The server's echo will block once it has
+	// sent muxWindowSize messages, but it will consume what's sent to it.
+	a, err := mc.NewStream(ctx, testConnID(1), si, 0, 0)
+	require.NoError(t, err)
+	const count = muxWindowSize + muxWindowSize/2
+	for i := 0; i < count; i++ {
+		require.NoError(t, a.Send(ctx, NewMessage(Normal, []byte("blocked"))))
+	}
+
+	// Stream b shares the same gRPC stream and must not be affected.
+	b, err := mc.NewStream(ctx, testConnID(2), si, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, exchange(ctx, b, 3*muxWindowSize))
+
+	// Drain a
+	for i := 0; i < count; i++ {
+		m, err := a.Receive(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "blocked", string(m.Payload()))
+	}
+	require.NoError(t, a.CloseSend(ctx))
+}
+
+func TestMuxClient_Fallback(t *testing.T) {
+	ctx, cancel := testContext(t, 30*time.Second)
+	defer cancel()
+
+	mc, opened := newTestMuxClient(ctx, 2, legacyServer)
+	si := uuid.New().String()
+	const streams = 5
+	for i := 0; i < streams; i++ {
+		s, err := mc.NewStream(ctx, testConnID(i), si, 0, 0)
+		require.NoError(t, err)
+		require.NoError(t, exchange(ctx, s, 10))
+	}
+	// One failed attempt to multiplex, and then one tunnel per stream.
+	assert.Equal(t, int32(streams+1), atomic.LoadInt32(opened))
+	assert.True(t, mc.unsupported)
+}
+
+func TestServeStreams_Legacy(t *testing.T) {
+	ctx, cancel := testContext(t, 10*time.Second)
+	defer cancel()
+
+	tt := newTestTunnel(ctx)
+	tt.serve(muxServer)
+	s, err := NewClientStream(ctx, tt, testConnID(1), uuid.New().String(), 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, exchange(ctx, s, 10))
+}
@@ -71,6 +71,8 @@ func (p *Pool) GetOrCreate(ctx context.Context, id ConnID, createHandler Handler
 	var err error
 	handler, err = createHandler(handlerCtx, release)
 	if err != nil {
+		span.End()
+		cancel()
 		return nil, false, err
 	}
 	if handler == nil {
@@ -93,6 +95,23 @@ func (p *Pool) GetOrCreate(ctx context.Context, id ConnID, createHandler Handler
 	return handler, false, nil
 }
 
+// Range calls the given function for each handler in the pool. The function is called without holding
+// the pool's lock, so it's safe for it to use the pool.
+func (p *Pool) Range(f func(ConnID, Handler)) {
+	p.lock.RLock()
+	ids := make([]ConnID, 0, len(p.handlers))
+	handlers := make([]Handler, 0, len(p.handlers))
+	for id, handler := range p.handlers {
+		ids = append(ids, id)
+		handlers = append(handlers, handler)
+	}
+	p.lock.RUnlock()
+
+	for i, id := range ids {
+		f(id, handlers[i])
+	}
+}
+
 func (p *Pool) CloseAll(ctx context.Context) {
 	p.lock.RLock()
 	handlers := make([]Handler, len(p.handlers))
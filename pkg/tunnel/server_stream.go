@@ -6,20 +6,28 @@ import (
 	"fmt"
 )
 
+// NewServerStream reads the initial StreamInfo message from the given grpcStream and replies with a StreamOK
+// message. The compression requested by the client is accepted unless overridden using WithCompressionOverride.
 func NewServerStream(ctx context.Context, grpcStream GRPCStream) (Stream, error) {
-	s := &stream{tag: "SRV", grpcStream: grpcStream, syncRatio: 8, ackWindow: 1}
+	s := newStream("SRV", grpcStream)
 	m, err := s.Receive(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read initial StreamInfo message: %w", err)
 	}
-	if m.Code() != streamInfo {
-		return nil, errors.New("initial message was not StreamInfo")
+	if err = s.accept(ctx, m); err != nil {
+		return nil, err
 	}
-	if err = setConnectInfo(m, s); err != nil {
-		return nil, fmt.Errorf("failed to parse StreamInfo message: %w", err)
+	return &s, nil
+}
+
+// accept parses the given StreamInfo message and replies with a StreamOK message.
+func (s *stream) accept(ctx context.Context, m Message) error {
+	if m.Code() != streamInfo {
+		return errors.New("initial message was not StreamInfo")
 	}
-	if err = s.Send(ctx, StreamOKMessage()); err != nil {
-		return nil, err
+	if err := setConnectInfo(m, s); err != nil {
+		return fmt.Errorf("failed to parse StreamInfo message: %w", err)
 	}
-	return s, nil
+	s.compression = negotiateCompression(ctx, s.peerVersion, s.compression)
+	return s.Send(ctx, StreamOKMessage(s.compression))
 }
@@ -0,0 +1,59 @@
+package tunnel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ConnStats is a snapshot of the traffic of a connection that is handled by an Endpoint.
+type ConnStats struct {
+	// Started is the time when the connection was established.
+	Started time.Time
+
+	// LastActivity is the time when data was last sent or received.
+	LastActivity time.Time
+
+	// BytesSent is the number of bytes read from the connection and sent to the peer.
+	BytesSent uint64
+
+	// BytesReceived is the number of bytes received from the peer and written to the connection.
+	BytesReceived uint64
+}
+
+// StatsProvider is implemented by handlers that keep track of the traffic of their connection.
+type StatsProvider interface {
+	Stats() ConnStats
+}
+
+// connStats collects ConnStats. It's safe for concurrent use.
+type connStats struct {
+	started       time.Time
+	lastActivity  int64 // unix nanos
+	bytesSent     uint64
+	bytesReceived uint64
+}
+
+func newConnStats() connStats {
+	now := time.Now()
+	return connStats{started: now, lastActivity: now.UnixNano()}
+}
+
+func (s *connStats) addSent(n int) {
+	atomic.AddUint64(&s.bytesSent, uint64(n))
+	atomic.StoreInt64(&s.lastActivity, time.Now().UnixNano())
+}
+
+func (s *connStats) addReceived(n int) {
+	atomic.AddUint64(&s.bytesReceived, uint64(n))
+	atomic.StoreInt64(&s.lastActivity, time.Now().UnixNano())
+}
+
+// Stats returns a snapshot of the collected statistics.
+func (s *connStats) Stats() ConnStats {
+	return ConnStats{
+		Started:       s.started,
+		LastActivity:  time.Unix(0, atomic.LoadInt64(&s.lastActivity)),
+		BytesSent:     atomic.LoadUint64(&s.bytesSent),
+		BytesReceived: atomic.LoadUint64(&s.bytesReceived),
+	}
+}
@@ -21,7 +21,10 @@ import (
 //
 //	0 which didn't report versions and didn't do synchronization
 //	1 used MuxTunnel instead of one tunnel per connection.
-const Version = uint16(2)
+//	2 used one tunnel per connection without compression.
+//	3 negotiates compression of message payloads.
+//	4 multiplexes many connections over one gRPC stream.
+const Version = uint16(4)
 
 // Endpoint is an endpoint for a Stream such as a Dialer or a bidirectional pipe.
 type Endpoint interface {
@@ -173,6 +176,7 @@ type stream struct {
 	syncRatio        uint32 // send and check sync after each syncRatio message
 	ackWindow        uint32 // maximum permitted difference between sent and received ack
 	peerVersion      uint16
+	compression      Compression // compression of Normal message payloads, negotiated during connect
 }
 
 func newStream(tag string, grpcStream GRPCStream) stream {
@@ -210,6 +214,13 @@ func (s *stream) Receive(ctx context.Context) (Message, error) {
 	}
 	m := msg(cm.Payload)
 	switch m.Code() {
+	case compressed:
+		pl, err := s.compression.decompress(msg{byte(Normal)}, m.Payload())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress %s message: %w", s.compression, err)
+		}
+		m = pl
+		dlog.Tracef(ctx, "<- %s %s, %s (%s, len %d)", s.tag, s.id, m, s.compression, len(cm.Payload))
 	case closeSend:
 		dlog.Tracef(ctx, "<- %s %s, close send", s.tag, s.id)
 		return nil, net.ErrClosed
@@ -222,6 +233,9 @@ func (s *stream) Receive(ctx context.Context) (Message, error) {
 }
 
 func (s *stream) Send(ctx context.Context, m Message) error {
+	if s.compression != CompressionNone && m.Code() == Normal {
+		m = s.compress(m)
+	}
 	if err := s.grpcStream.Send(m.TunnelMessage()); err != nil {
 		if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
 			dlog.Errorf(ctx, "!! %s %s, Send failed: %v", s.tag, s.id, err)
@@ -232,6 +246,19 @@ func (s *stream) Send(ctx context.Context, m Message) error {
 	return nil
 }
 
+// compress returns a compressed message with the same content as the given Normal message, or the given
+// message if it's too small, or if compression doesn't make it smaller.
+func (s *stream) compress(m Message) Message {
+	pl := m.Payload()
+	if len(pl) < minCompressSize {
+		return m
+	}
+	if cm := msg(s.compression.compress(msg{byte(compressed)}, pl)); len(cm) <= len(pl) {
+		return cm
+	}
+	return m
+}
+
 func (s *stream) CloseSend(ctx context.Context) error {
 	if err := s.Send(ctx, NewMessage(closeSend, nil)); err != nil {
 		if ctx.Err() == nil && !(errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed)) {
@@ -0,0 +1,193 @@
+package vif
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+// PacketTap is called with every packet that passes through a Device. The outbound flag is true for packets
+// that are read from the device (i.e. on their way to the cluster), and false for packets that are written to
+// it. The tap is called synchronously, so it must not block, and it must not retain the data after it returns.
+type PacketTap func(outbound bool, data []byte)
+
+// taps is a set of PacketTaps that can be modified while packets are dispatched to it.
+type taps struct {
+	sync.RWMutex
+	nextID int
+	taps   map[int]PacketTap
+}
+
+func (t *taps) add(tap PacketTap) (remove func()) {
+	t.Lock()
+	if t.taps == nil {
+		t.taps = make(map[int]PacketTap)
+	}
+	id := t.nextID
+	t.nextID++
+	t.taps[id] = tap
+	t.Unlock()
+	return func() {
+		t.Lock()
+		delete(t.taps, id)
+		t.Unlock()
+	}
+}
+
+func (t *taps) dispatch(outbound bool, data []byte) {
+	t.RLock()
+	for _, tap := range t.taps {
+		tap(outbound, data)
+	}
+	t.RUnlock()
+}
+
+// PacketFilter decides what packets to capture. It is a conjunction of terms, each of which must match.
+type PacketFilter struct {
+	terms []func(*packetInfo) bool
+}
+
+type packetInfo struct {
+	src, dst         net.IP
+	proto            tcpip.TransportProtocolNumber
+	srcPort, dstPort uint16
+	hasPorts         bool
+}
+
+// ParsePacketFilter parses a filter expression. The expression consists of terms separated by "and", where each
+// term is one of:
+//
+//	host <ip>    matches packets with the given source or destination IP
+//	net <cidr>   matches packets with a source or destination IP in the given subnet
+//	port <port>  matches TCP and UDP packets with the given source or destination port
+//	tcp, udp, icmp
+//
+// An empty expression matches all packets.
+func ParsePacketFilter(expr string) (*PacketFilter, error) {
+	f := &PacketFilter{}
+	words := strings.Fields(expr)
+	for i := 0; i < len(words); i++ {
+		if len(f.terms) > 0 {
+			if words[i] != "and" {
+				return nil, fmt.Errorf("invalid filter %q: expected \"and\" but found %q", expr, words[i])
+			}
+			if i++; i == len(words) {
+				return nil, fmt.Errorf("invalid filter %q: missing term after \"and\"", expr)
+			}
+		}
+		kw := words[i]
+		var term func(*packetInfo) bool
+		switch kw {
+		case "tcp":
+			term = func(pi *packetInfo) bool { return pi.proto == header.TCPProtocolNumber }
+		case "udp":
+			term = func(pi *packetInfo) bool { return pi.proto == header.UDPProtocolNumber }
+		case "icmp":
+			term = func(pi *packetInfo) bool {
+				return pi.proto == header.ICMPv4ProtocolNumber || pi.proto == header.ICMPv6ProtocolNumber
+			}
+		case "host", "net", "port":
+			if i++; i == len(words) {
+				return nil, fmt.Errorf("invalid filter %q: missing value after %q", expr, kw)
+			}
+			var err error
+			if term, err = valueTerm(kw, words[i]); err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", expr, err)
+			}
+		default:
+			return nil, fmt.Errorf("invalid filter %q: unknown term %q", expr, kw)
+		}
+		f.terms = append(f.terms, term)
+	}
+	return f, nil
+}
+
+func valueTerm(kw, value string) (func(*packetInfo) bool, error) {
+	switch kw {
+	case "host":
+		ip := iputil.Parse(value)
+		if ip == nil {
+			return nil, fmt.Errorf("%q is not a valid IP address", value)
+		}
+		return func(pi *packetInfo) bool { return ip.Equal(pi.src) || ip.Equal(pi.dst) }, nil
+	case "net":
+		_, sn, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(pi *packetInfo) bool { return sn.Contains(pi.src) || sn.Contains(pi.dst) }, nil
+	default:
+		port, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid port", value)
+		}
+		p := uint16(port)
+		return func(pi *packetInfo) bool { return pi.hasPorts && (pi.srcPort == p || pi.dstPort == p) }, nil
+	}
+}
+
+// Match returns true if the given IP packet matches the filter. Packets that cannot be parsed never match
+// a filter that has terms.
+func (f *PacketFilter) Match(data []byte) bool {
+	if len(f.terms) == 0 {
+		return true
+	}
+	pi, ok := parsePacketInfo(data)
+	if !ok {
+		return false
+	}
+	for _, term := range f.terms {
+		if !term(&pi) {
+			return false
+		}
+	}
+	return true
+}
+
+func parsePacketInfo(data []byte) (pi packetInfo, ok bool) {
+	var payload []byte
+	switch header.IPVersion(data) {
+	case header.IPv4Version:
+		ip := header.IPv4(data)
+		if !ip.IsValid(len(data)) {
+			return pi, false
+		}
+		pi.src = net.IP(ip.SourceAddress())
+		pi.dst = net.IP(ip.DestinationAddress())
+		pi.proto = ip.TransportProtocol()
+		if ip.FragmentOffset() == 0 {
+			payload = ip.Payload()
+		}
+	case header.IPv6Version:
+		ip := header.IPv6(data)
+		if !ip.IsValid(len(data)) {
+			return pi, false
+		}
+		pi.src = net.IP(ip.SourceAddress())
+		pi.dst = net.IP(ip.DestinationAddress())
+		pi.proto = ip.TransportProtocol()
+		payload = ip.Payload()
+	default:
+		return pi, false
+	}
+	switch pi.proto {
+	case header.TCPProtocolNumber:
+		if len(payload) >= header.TCPMinimumSize {
+			tcp := header.TCP(payload)
+			pi.srcPort, pi.dstPort, pi.hasPorts = tcp.SourcePort(), tcp.DestinationPort(), true
+		}
+	case header.UDPProtocolNumber:
+		if len(payload) >= header.UDPMinimumSize {
+			udp := header.UDP(payload)
+			pi.srcPort, pi.dstPort, pi.hasPorts = udp.SourcePort(), udp.DestinationPort(), true
+		}
+	}
+	return pi, true
+}
@@ -0,0 +1,86 @@
+package vif
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+)
+
+func udpPacket(src, dst net.IP, srcPort, dstPort uint16) []byte {
+	data := make([]byte, header.IPv4MinimumSize+header.UDPMinimumSize)
+	ip := header.IPv4(data)
+	ip.Encode(&header.IPv4Fields{
+		TotalLength: uint16(len(data)),
+		TTL:         64,
+		Protocol:    uint8(header.UDPProtocolNumber),
+		SrcAddr:     tcpip.Address(src.To4()),
+		DstAddr:     tcpip.Address(dst.To4()),
+	})
+	udp := header.UDP(ip.Payload())
+	udp.Encode(&header.UDPFields{SrcPort: srcPort, DstPort: dstPort, Length: header.UDPMinimumSize})
+	return data
+}
+
+func TestPacketFilter(t *testing.T) {
+	pkt := udpPacket(net.IP{192, 168, 1, 10}, net.IP{10, 96, 0, 10}, 4711, 53)
+	tests := []struct {
+		expr  string
+		match bool
+	}{
+		{"", true},
+		{"udp", true},
+		{"tcp", false},
+		{"host 10.96.0.10", true},
+		{"host 192.168.1.10", true},
+		{"host 10.96.0.11", false},
+		{"net 10.96.0.0/16", true},
+		{"net 10.97.0.0/16", false},
+		{"port 53", true},
+		{"port 4711", true},
+		{"port 80", false},
+		{"host 10.96.0.10 and port 53", true},
+		{"host 10.96.0.10 and port 80", false},
+		{"udp and net 10.96.0.0/12 and port 53", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := ParsePacketFilter(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.match, f.Match(pkt))
+		})
+	}
+
+	f, err := ParsePacketFilter("port 53")
+	require.NoError(t, err)
+	assert.False(t, f.Match([]byte{1, 2, 3}))
+}
+
+func TestParsePacketFilter_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"host",
+		"host 10.0.0",
+		"net 10.0.0.0",
+		"port 70000",
+		"port 53 udp",
+		"port 53 and",
+		"src 10.0.0.1",
+	} {
+		_, err := ParsePacketFilter(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestTaps(t *testing.T) {
+	ts := taps{}
+	var got []bool
+	remove := ts.add(func(outbound bool, _ []byte) { got = append(got, outbound) })
+	ts.dispatch(true, nil)
+	ts.dispatch(false, nil)
+	remove()
+	ts.dispatch(true, nil)
+	assert.Equal(t, []bool{true, false}, got)
+}
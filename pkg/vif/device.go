@@ -24,9 +24,11 @@ import (
 type device struct {
 	sync.Mutex
 	*channel.Endpoint
-	ctx context.Context
-	wg  sync.WaitGroup
-	dev *nativeDevice
+	ctx  context.Context
+	wg   sync.WaitGroup
+	dev  *nativeDevice
+	mtu  uint32
+	taps taps
 }
 
 type Device interface {
@@ -37,6 +39,10 @@ type Device interface {
 	AddSubnet(context.Context, *net.IPNet) error
 	RemoveSubnet(context.Context, *net.IPNet) error
 	SetDNS(context.Context, net.IP, []string) (err error)
+
+	// AddTap adds a PacketTap that is called with every packet that passes through this device. The returned
+	// function removes the tap.
+	AddTap(PacketTap) (remove func())
 }
 
 const defaultDevMtu = 1500
@@ -58,6 +64,7 @@ func OpenTun(ctx context.Context) (Device, error) {
 		Endpoint: channel.New(defaultDevOutQueueLen, defaultDevMtu, ""),
 		ctx:      ctx,
 		dev:      dev,
+		mtu:      defaultDevMtu,
 	}, nil
 }
 
@@ -84,6 +91,10 @@ func (d *device) AddSubnet(ctx context.Context, subnet *net.IPNet) (err error) {
 	return d.dev.addSubnet(ctx, subnet)
 }
 
+func (d *device) AddTap(tap PacketTap) (remove func()) {
+	return d.taps.add(tap)
+}
+
 func (d *device) Close() error {
 	return d.dev.Close()
 }
@@ -103,8 +114,18 @@ func (d *device) SetDNS(ctx context.Context, server net.IP, domains []string) (e
 	return d.dev.setDNS(ctx, server, domains)
 }
 
+// SetMTU sets the MTU of the TUN device and of this endpoint. It must be called before the endpoint is attached.
 func (d *device) SetMTU(mtu int) error {
-	return d.dev.setMTU(mtu)
+	if err := d.dev.setMTU(mtu); err != nil {
+		return err
+	}
+	d.mtu = uint32(mtu)
+	return nil
+}
+
+// MTU returns the MTU of this endpoint.
+func (d *device) MTU() uint32 {
+	return d.mtu
 }
 
 // RemoveSubnet removes a subnet from this TUN device and also removes the route for that subnet which
@@ -152,6 +173,7 @@ func (d *device) tunToDispatch(cancel context.CancelFunc) {
 			continue
 		}
 
+		d.taps.dispatch(true, data[:n])
 		pb := stack.NewPacketBuffer(stack.PacketBufferOptions{
 			Payload: bufferv2.MakeWithData(data[:n]),
 		})
@@ -180,6 +202,7 @@ func (d *device) dispatchToTun(ctx context.Context) {
 			b = b[len(s):]
 		}
 		pb.DecRef()
+		d.taps.dispatch(false, buf.Buf())
 		if _, err := d.dev.writePacket(buf, 0); err != nil {
 			dlog.Errorf(ctx, "WritePacket failed: %v", err)
 		}
@@ -2,7 +2,6 @@ package vif
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net"
 	"net/netip"
@@ -172,8 +171,19 @@ $job | Receive-Job
 	return nil
 }
 
-func (t *nativeDevice) setMTU(int) error {
-	return errors.New("not implemented")
+func (t *nativeDevice) setMTU(mtu int) error {
+	luid := t.getLUID()
+	for _, family := range []winipcfg.AddressFamily{windows.AF_INET, windows.AF_INET6} {
+		ipif, err := luid.IPInterface(family)
+		if err != nil {
+			return fmt.Errorf("set MTU on %s failed: %w", t.name, err)
+		}
+		ipif.NLMTU = uint32(mtu)
+		if err = ipif.Set(); err != nil {
+			return fmt.Errorf("set MTU on %s failed: %w", t.name, err)
+		}
+	}
+	return nil
 }
 
 func (t *nativeDevice) readPacket(into *buffer.Data) (int, error) {
@@ -0,0 +1,284 @@
+package vif
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/bufferv2"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+// echoQueueSize is the max number of echo requests that are queued for one stream. Requests that arrive when
+// the queue is full are dropped.
+const echoQueueSize = 16
+
+// echoReplyTTL is the TTL, or hop limit, of the echo replies that are written to the device.
+const echoReplyTTL = 64
+
+// echoEndpoint is a link endpoint that forwards the ICMP echo requests that arrive on another link endpoint to
+// streams obtained from the streamCreator, and writes the replies that are received from those streams back to
+// the endpoint. The requests are never seen by the stack, so the stack will not reply to them itself.
+//
+// One stream is created for each source, destination, and echo identifier. The peer of the stream performs the
+// echo and returns the reply, so the round-trip time reflects the real path to the destination.
+type echoEndpoint struct {
+	stack.LinkEndpoint
+	ctx           context.Context
+	streamCreator tunnel.StreamCreator
+	pool          *tunnel.Pool
+
+	sync.Mutex
+	conns map[tunnel.ConnID]*echoConn
+}
+
+func newEchoEndpoint(ctx context.Context, ep stack.LinkEndpoint, streamCreator tunnel.StreamCreator, pool *tunnel.Pool) *echoEndpoint {
+	return &echoEndpoint{
+		LinkEndpoint:  ep,
+		ctx:           ctx,
+		streamCreator: streamCreator,
+		pool:          pool,
+		conns:         make(map[tunnel.ConnID]*echoConn),
+	}
+}
+
+func (e *echoEndpoint) Attach(dp stack.NetworkDispatcher) {
+	if dp != nil {
+		dp = &echoDispatcher{NetworkDispatcher: dp, ep: e}
+	}
+	e.LinkEndpoint.Attach(dp)
+}
+
+// echoDispatcher intercepts the ICMP echo requests that are delivered to the stack.
+type echoDispatcher struct {
+	stack.NetworkDispatcher
+	ep *echoEndpoint
+}
+
+func (d *echoDispatcher) DeliverNetworkPacket(protocol tcpip.NetworkProtocolNumber, pkt stack.PacketBufferPtr) {
+	if !d.ep.forwardEcho(protocol, pkt) {
+		d.NetworkDispatcher.DeliverNetworkPacket(protocol, pkt)
+	}
+}
+
+// forwardEcho forwards the given packet and returns true if it is an ICMP echo request.
+func (e *echoEndpoint) forwardEcho(protocol tcpip.NetworkProtocolNumber, pkt stack.PacketBufferPtr) bool {
+	data := pkt.Data()
+	var proto int
+	var src, dst net.IP
+	var msg []byte
+	switch protocol {
+	case header.IPv4ProtocolNumber:
+		hdr, ok := data.PullUp(header.IPv4MinimumSize)
+		if !ok {
+			return false
+		}
+		ip := header.IPv4(hdr)
+		if ip.TransportProtocol() != header.ICMPv4ProtocolNumber || ip.More() || ip.FragmentOffset() != 0 {
+			return false
+		}
+		all, ok := data.PullUp(data.Size())
+		if !ok {
+			return false
+		}
+		ip = all
+		if !ip.IsValid(len(all)) {
+			return false
+		}
+		msg = ip.Payload()
+		if len(msg) < header.ICMPv4MinimumSize || header.ICMPv4(msg).Type() != header.ICMPv4Echo || header.ICMPv4(msg).Code() != 0 {
+			return false
+		}
+		proto, src, dst = ipproto.ICMP, net.IP(ip.SourceAddress()), net.IP(ip.DestinationAddress())
+	case header.IPv6ProtocolNumber:
+		hdr, ok := data.PullUp(header.IPv6MinimumSize)
+		if !ok {
+			return false
+		}
+		ip := header.IPv6(hdr)
+		if ip.TransportProtocol() != header.ICMPv6ProtocolNumber {
+			return false
+		}
+		all, ok := data.PullUp(data.Size())
+		if !ok {
+			return false
+		}
+		ip = all
+		if !ip.IsValid(len(all)) {
+			return false
+		}
+		msg = ip.Payload()
+		if len(msg) < header.ICMPv6EchoMinimumSize || header.ICMPv6(msg).Type() != header.ICMPv6EchoRequest || header.ICMPv6(msg).Code() != 0 {
+			return false
+		}
+		proto, src, dst = ipproto.ICMPV6, net.IP(ip.SourceAddress()), net.IP(ip.DestinationAddress())
+	default:
+		return false
+	}
+
+	// The identifier is at the same position in ICMPv4 and ICMPv6 echo messages.
+	ident := header.ICMPv4(msg).Ident()
+	id := tunnel.NewConnID(proto, src, dst, ident, 0)
+	request := make([]byte, len(msg))
+	copy(request, msg)
+
+	e.Lock()
+	c, ok := e.conns[id]
+	if !ok {
+		c = &echoConn{
+			ep:       e,
+			id:       id,
+			ident:    ident,
+			requests: make(chan []byte, echoQueueSize),
+			closed:   make(chan struct{}),
+		}
+		e.conns[id] = c
+	}
+	e.Unlock()
+	if !ok {
+		go dispatchToStream(e.ctx, id, c, e.streamCreator, e.pool)
+	}
+	select {
+	case c.requests <- request:
+	default:
+		dlog.Debugf(e.ctx, "echo request %s dropped", id)
+	}
+	return true
+}
+
+func (e *echoEndpoint) remove(c *echoConn) {
+	e.Lock()
+	if e.conns[c.id] == c {
+		delete(e.conns, c.id)
+	}
+	e.Unlock()
+}
+
+// writeReply writes an IP packet that contains the given ICMP echo reply to the link endpoint.
+func (e *echoEndpoint) writeReply(c *echoConn, reply []byte) error {
+	var protocol tcpip.NetworkProtocolNumber
+	var pkt []byte
+	src := tcpip.Address(c.id.Destination())
+	dst := tcpip.Address(c.id.Source())
+	if c.id.Protocol() == ipproto.ICMP {
+		protocol = header.IPv4ProtocolNumber
+		pkt = make([]byte, header.IPv4MinimumSize+len(reply))
+		msg := header.ICMPv4(pkt[header.IPv4MinimumSize:])
+		copy(msg, reply)
+		msg.SetType(header.ICMPv4EchoReply)
+		msg.SetCode(0)
+		msg.SetIdent(c.ident)
+		msg.SetChecksum(0)
+		msg.SetChecksum(header.ICMPv4Checksum(msg, 0))
+		ip := header.IPv4(pkt)
+		ip.Encode(&header.IPv4Fields{
+			TotalLength: uint16(len(pkt)),
+			TTL:         echoReplyTTL,
+			Protocol:    uint8(header.ICMPv4ProtocolNumber),
+			SrcAddr:     src,
+			DstAddr:     dst,
+		})
+		ip.SetChecksum(^ip.CalculateChecksum())
+	} else {
+		protocol = header.IPv6ProtocolNumber
+		pkt = make([]byte, header.IPv6MinimumSize+len(reply))
+		msg := header.ICMPv6(pkt[header.IPv6MinimumSize:])
+		copy(msg, reply)
+		msg.SetType(header.ICMPv6EchoReply)
+		msg.SetCode(0)
+		msg.SetIdent(c.ident)
+		msg.SetChecksum(0)
+		msg.SetChecksum(header.ICMPv6Checksum(header.ICMPv6ChecksumParams{Header: msg, Src: src, Dst: dst}))
+		header.IPv6(pkt).Encode(&header.IPv6Fields{
+			PayloadLength:     uint16(len(reply)),
+			TransportProtocol: header.ICMPv6ProtocolNumber,
+			HopLimit:          echoReplyTTL,
+			SrcAddr:           src,
+			DstAddr:           dst,
+		})
+	}
+
+	pb := stack.NewPacketBuffer(stack.PacketBufferOptions{
+		Payload: bufferv2.MakeWithData(pkt),
+	})
+	defer pb.DecRef()
+	pb.NetworkProtocolNumber = protocol
+	var pl stack.PacketBufferList
+	pl.PushBack(pb)
+	if _, err := e.WritePackets(pl); err != nil {
+		return errors.New(err.String())
+	}
+	return nil
+}
+
+// echoConn is the net.Conn that is dispatched to a stream for the echo requests of one source, destination, and
+// identifier. Each Read returns one ICMP echo request, and each Write takes one ICMP echo reply.
+type echoConn struct {
+	ep        *echoEndpoint
+	id        tunnel.ConnID
+	ident     uint16
+	requests  chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *echoConn) Read(b []byte) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, io.EOF
+	case r := <-c.requests:
+		return copy(b, r), nil
+	}
+}
+
+func (c *echoConn) Write(b []byte) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	if len(b) < header.ICMPv4MinimumSize {
+		return 0, errors.New("short ICMP echo reply")
+	}
+	if err := c.ep.writeReply(c, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *echoConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.ep.remove(c)
+	})
+	return nil
+}
+
+func (c *echoConn) LocalAddr() net.Addr {
+	return &net.IPAddr{IP: c.id.Source()}
+}
+
+func (c *echoConn) RemoteAddr() net.Addr {
+	return &net.IPAddr{IP: c.id.Destination()}
+}
+
+func (c *echoConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *echoConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *echoConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
@@ -0,0 +1,171 @@
+package vif
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gvisor.dev/gvisor/pkg/bufferv2"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/link/channel"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+// remoteIdent is the identifier that the peer of the stream uses for the echo requests, like the kernel does
+// when it sends them using an unprivileged ICMP socket.
+const remoteIdent = 0x4242
+
+// echoStreamCreator returns a tunnel.StreamCreator that creates streams that reply to the echo requests that
+// they receive, and counts the streams that it creates.
+func echoStreamCreator(created *atomic.Int32) tunnel.StreamCreator {
+	return func(ctx context.Context, id tunnel.ConnID) (tunnel.Stream, error) {
+		created.Add(1)
+		local, peer := tunnel.NewPipe(id, "session")
+		ctx, cancel := context.WithCancel(ctx)
+		in, out := net.Pipe()
+		tunnel.NewConnEndpoint(peer, in, cancel).Start(ctx)
+		go func() {
+			buf := make([]byte, 0x10000)
+			for {
+				n, err := out.Read(buf)
+				if err != nil {
+					return
+				}
+				msg := header.ICMPv4(buf[:n])
+				if msg.Type() == header.ICMPv4Echo {
+					msg.SetType(header.ICMPv4EchoReply)
+				} else {
+					header.ICMPv6(msg).SetType(header.ICMPv6EchoReply)
+				}
+				msg.SetIdent(remoteIdent)
+				if _, err = out.Write(msg); err != nil {
+					return
+				}
+			}
+		}()
+		return local, nil
+	}
+}
+
+// echoRequest returns an IP packet with an ICMP echo request.
+func echoRequest(src, dst net.IP, ident, seq uint16, data []byte) (tcpip.NetworkProtocolNumber, []byte) {
+	if src.To4() != nil {
+		pkt := make([]byte, header.IPv4MinimumSize+header.ICMPv4MinimumSize+len(data))
+		msg := header.ICMPv4(pkt[header.IPv4MinimumSize:])
+		msg.SetType(header.ICMPv4Echo)
+		msg.SetIdent(ident)
+		msg.SetSequence(seq)
+		copy(msg.Payload(), data)
+		msg.SetChecksum(header.ICMPv4Checksum(msg, 0))
+		ip := header.IPv4(pkt)
+		ip.Encode(&header.IPv4Fields{
+			TotalLength: uint16(len(pkt)),
+			TTL:         64,
+			Protocol:    uint8(header.ICMPv4ProtocolNumber),
+			SrcAddr:     tcpip.Address(src.To4()),
+			DstAddr:     tcpip.Address(dst.To4()),
+		})
+		ip.SetChecksum(^ip.CalculateChecksum())
+		return header.IPv4ProtocolNumber, pkt
+	}
+	pkt := make([]byte, header.IPv6MinimumSize+header.ICMPv6EchoMinimumSize+len(data))
+	msg := header.ICMPv6(pkt[header.IPv6MinimumSize:])
+	msg.SetType(header.ICMPv6EchoRequest)
+	msg.SetIdent(ident)
+	msg.SetSequence(seq)
+	copy(msg.Payload(), data)
+	msg.SetChecksum(header.ICMPv6Checksum(header.ICMPv6ChecksumParams{Header: msg, Src: tcpip.Address(src), Dst: tcpip.Address(dst)}))
+	header.IPv6(pkt).Encode(&header.IPv6Fields{
+		PayloadLength:     uint16(len(msg)),
+		TransportProtocol: header.ICMPv6ProtocolNumber,
+		HopLimit:          64,
+		SrcAddr:           tcpip.Address(src),
+		DstAddr:           tcpip.Address(dst),
+	})
+	return header.IPv6ProtocolNumber, pkt
+}
+
+func TestEchoEndpoint(t *testing.T) {
+	tests := []struct {
+		name string
+		src  net.IP
+		dst  net.IP
+	}{
+		{"ipv4", net.IP{192, 168, 0, 2}, net.IP{10, 0, 0, 1}},
+		{"ipv6", net.ParseIP("fd00::2"), net.ParseIP("fd01::1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(quietContext())
+			defer cancel()
+
+			var created atomic.Int32
+			vifEp := channel.New(16, defaultDevMtu, "")
+			pool := tunnel.NewPool()
+			vs, err := NewStack(ctx, vifEp, echoStreamCreator(&created), pool, StackOptions{})
+			require.NoError(t, err)
+			defer func() {
+				pool.CloseAll(ctx)
+				vs.Close()
+			}()
+
+			data := []byte("telepresence")
+			for seq := uint16(1); seq <= 3; seq++ {
+				protocol, req := echoRequest(tt.src, tt.dst, 0x1234, seq, data)
+				pb := stack.NewPacketBuffer(stack.PacketBufferOptions{Payload: bufferv2.MakeWithData(req)})
+				vifEp.InjectInbound(protocol, pb)
+				pb.DecRef()
+
+				rctx, rcancel := context.WithTimeout(ctx, 5*time.Second)
+				pkt := vifEp.ReadContext(rctx)
+				rcancel()
+				require.False(t, pkt.IsNil(), "timeout waiting for echo reply")
+				reply := pkt.ToView().AsSlice()
+				pkt.DecRef()
+
+				if protocol == header.IPv4ProtocolNumber {
+					ip := header.IPv4(reply)
+					require.True(t, ip.IsValid(len(reply)))
+					assert.True(t, ip.IsChecksumValid())
+					assert.Equal(t, tcpip.Address(tt.dst.To4()), ip.SourceAddress())
+					assert.Equal(t, tcpip.Address(tt.src.To4()), ip.DestinationAddress())
+					msg := header.ICMPv4(ip.Payload())
+					assert.Equal(t, header.ICMPv4EchoReply, msg.Type())
+					assert.Equal(t, uint16(0x1234), msg.Ident())
+					assert.Equal(t, seq, msg.Sequence())
+					assert.Equal(t, data, msg.Payload())
+					assert.Equal(t, msg.Checksum(), header.ICMPv4Checksum(msg, 0))
+				} else {
+					ip := header.IPv6(reply)
+					require.True(t, ip.IsValid(len(reply)))
+					assert.Equal(t, tcpip.Address(tt.dst), ip.SourceAddress())
+					assert.Equal(t, tcpip.Address(tt.src), ip.DestinationAddress())
+					msg := header.ICMPv6(ip.Payload())
+					assert.Equal(t, header.ICMPv6EchoReply, msg.Type())
+					assert.Equal(t, uint16(0x1234), msg.Ident())
+					assert.Equal(t, seq, msg.Sequence())
+					assert.Equal(t, data, msg.Payload())
+					assert.Equal(t, msg.Checksum(), header.ICMPv6Checksum(header.ICMPv6ChecksumParams{
+						Header: msg,
+						Src:    ip.SourceAddress(),
+						Dst:    ip.DestinationAddress(),
+					}))
+				}
+			}
+
+			// The stack doesn't reply to the requests itself, and all requests with the same identifier
+			// share one stream.
+			rctx, rcancel := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer rcancel()
+			assert.True(t, vifEp.ReadContext(rctx).IsNil())
+			assert.Equal(t, int32(1), created.Load())
+		})
+	}
+}
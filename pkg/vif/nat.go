@@ -0,0 +1,186 @@
+package vif
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+	"github.com/telepresenceio/telepresence/v2/pkg/subnet"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+	"github.com/telepresenceio/telepresence/v2/pkg/vif/routing"
+)
+
+// NAT maps IPs of cluster subnets that conflict with the local network onto IPs of a virtual subnet. The
+// virtual subnet is routed to the TUN device in place of the conflicting subnets, and the NAT translates
+// the destination of each connection made to a virtual IP back to the real IP before it is sent to the
+// cluster.
+//
+// Virtual IPs are allocated on demand, typically when a DNS lookup returns an IP in a conflicting subnet.
+type NAT struct {
+	virtual *net.IPNet
+
+	sync.Mutex
+	conflicting []*net.IPNet
+	toVirtual   map[iputil.IPKey]net.IP
+	toReal      map[iputil.IPKey]net.IP
+
+	// next is the offset from the start of the virtual subnet of the next IP to allocate
+	next uint64
+
+	// last is the largest offset that can be allocated
+	last uint64
+}
+
+// NewNAT returns a NAT that allocates virtual IPs from the given subnet.
+func NewNAT(virtual *net.IPNet) *NAT {
+	if ip4 := virtual.IP.To4(); ip4 != nil {
+		mask := virtual.Mask
+		if len(mask) == net.IPv6len {
+			mask = mask[12:]
+		}
+		virtual = &net.IPNet{IP: ip4, Mask: mask}
+	}
+	ones, bits := virtual.Mask.Size()
+	last := ^uint64(0)
+	if hostBits := bits - ones; hostBits < 64 {
+		// Exclude the network and broadcast addresses.
+		last = (uint64(1) << hostBits) - 2
+	}
+	return &NAT{
+		virtual:   virtual,
+		toVirtual: make(map[iputil.IPKey]net.IP),
+		toReal:    make(map[iputil.IPKey]net.IP),
+		next:      1,
+		last:      last,
+	}
+}
+
+// VirtualSubnet returns the subnet that virtual IPs are allocated from.
+func (n *NAT) VirtualSubnet() *net.IPNet {
+	return n.virtual
+}
+
+// SetConflicting sets the subnets whose IPs are translated into virtual IPs. IPs that have already been
+// translated will retain their virtual IPs.
+func (n *NAT) SetConflicting(subnets []*net.IPNet) {
+	n.Lock()
+	n.conflicting = subnets
+	n.Unlock()
+}
+
+// Conflicting returns the subnets whose IPs are translated into virtual IPs.
+func (n *NAT) Conflicting() []*net.IPNet {
+	n.Lock()
+	defer n.Unlock()
+	return n.conflicting
+}
+
+// ToVirtual returns the virtual IP that corresponds to the given IP, allocating one if necessary. The given
+// IP is returned unchanged when it isn't in a conflicting subnet, or when it's in a different IP family than
+// the virtual subnet.
+func (n *NAT) ToVirtual(ip net.IP) (net.IP, error) {
+	rip := n.sameFamily(ip)
+	if rip == nil {
+		return ip, nil
+	}
+	n.Lock()
+	defer n.Unlock()
+	if !n.conflicts(rip) {
+		return ip, nil
+	}
+	k := iputil.IPKey(rip)
+	if vip, ok := n.toVirtual[k]; ok {
+		return vip, nil
+	}
+	if n.next > n.last {
+		return ip, fmt.Errorf("unable to translate %s: all IPs in virtual subnet %s are in use", ip, n.virtual)
+	}
+	vip := n.ipAt(n.next)
+	n.next++
+	n.toVirtual[k] = vip
+	n.toReal[iputil.IPKey(vip)] = rip
+	return vip, nil
+}
+
+// ToReal returns the IP that the given virtual IP was allocated for. The given IP is returned unchanged
+// when it isn't an allocated virtual IP.
+func (n *NAT) ToReal(ip net.IP) net.IP {
+	vip := n.sameFamily(ip)
+	if vip == nil {
+		return ip
+	}
+	n.Lock()
+	defer n.Unlock()
+	if rip, ok := n.toReal[iputil.IPKey(vip)]; ok {
+		return rip
+	}
+	return ip
+}
+
+// StreamCreator returns a tunnel.StreamCreator that translates the destination of the given ConnID
+// before calling the given streamCreator.
+func (n *NAT) StreamCreator(streamCreator tunnel.StreamCreator) tunnel.StreamCreator {
+	return func(ctx context.Context, id tunnel.ConnID) (tunnel.Stream, error) {
+		dst := id.Destination()
+		if rip := n.ToReal(dst); !rip.Equal(dst) {
+			dlog.Tracef(ctx, "Translating %s to %s", dst, rip)
+			id = tunnel.NewConnID(id.Protocol(), id.Source(), rip, id.SourcePort(), id.DestinationPort())
+		}
+		return streamCreator(ctx, id)
+	}
+}
+
+// sameFamily returns the given IP in the same form as the virtual subnet IP, or nil if that isn't possible.
+func (n *NAT) sameFamily(ip net.IP) net.IP {
+	if len(n.virtual.IP) == net.IPv4len {
+		return ip.To4()
+	}
+	if ip.To4() != nil {
+		return nil
+	}
+	return ip.To16()
+}
+
+func (n *NAT) conflicts(ip net.IP) bool {
+	for _, sn := range n.conflicting {
+		if sn.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipAt returns the IP at the given offset from the start of the virtual subnet.
+func (n *NAT) ipAt(offset uint64) net.IP {
+	ip := make(net.IP, len(n.virtual.IP))
+	copy(ip, n.virtual.IP.Mask(n.virtual.Mask))
+	if len(ip) == net.IPv4len {
+		binary.BigEndian.PutUint32(ip, binary.BigEndian.Uint32(ip)+uint32(offset))
+	} else {
+		tail := ip[net.IPv6len-8:]
+		binary.BigEndian.PutUint64(tail, binary.BigEndian.Uint64(tail)+offset)
+	}
+	return ip
+}
+
+// Conflicts returns the subnets among the given clusterSubnets that overlap a subnet that is routed
+// by the given routes. Default routes and routes using the TUN device are ignored.
+func Conflicts(clusterSubnets []*net.IPNet, routes []*routing.Route, devName string) []*net.IPNet {
+	var conflicts []*net.IPNet
+	for _, sn := range clusterSubnets {
+		for _, r := range routes {
+			if r.Default || r.RoutedNet == nil || r.Interface != nil && r.Interface.Name == devName {
+				continue
+			}
+			if subnet.Overlaps(sn, r.RoutedNet) {
+				conflicts = append(conflicts, sn)
+				break
+			}
+		}
+	}
+	return conflicts
+}
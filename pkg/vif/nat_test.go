@@ -0,0 +1,106 @@
+package vif
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/ipproto"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+	"github.com/telepresenceio/telepresence/v2/pkg/vif/routing"
+)
+
+func mustParseCIDR(t *testing.T, s string) *net.IPNet {
+	_, sn, err := net.ParseCIDR(s)
+	require.NoError(t, err)
+	return sn
+}
+
+func TestNAT_Translate(t *testing.T) {
+	n := NewNAT(mustParseCIDR(t, "211.55.48.0/30"))
+	n.SetConflicting([]*net.IPNet{mustParseCIDR(t, "10.0.0.0/16")})
+
+	// Not conflicting
+	ip := iputil.Parse("10.1.0.1")
+	vip, err := n.ToVirtual(ip)
+	require.NoError(t, err)
+	assert.Equal(t, ip, vip)
+
+	// Other IP family
+	ip = iputil.Parse("fd00::1")
+	vip, err = n.ToVirtual(ip)
+	require.NoError(t, err)
+	assert.Equal(t, ip, vip)
+
+	ip1 := iputil.Parse("10.0.0.1")
+	vip1, err := n.ToVirtual(ip1)
+	require.NoError(t, err)
+	assert.Equal(t, "211.55.48.1", vip1.String())
+	assert.Equal(t, ip1.String(), n.ToReal(vip1).String())
+
+	// Same IP yields the same virtual IP
+	vip, err = n.ToVirtual(ip1)
+	require.NoError(t, err)
+	assert.Equal(t, vip1, vip)
+
+	vip2, err := n.ToVirtual(iputil.Parse("10.0.3.4"))
+	require.NoError(t, err)
+	assert.Equal(t, "211.55.48.2", vip2.String())
+
+	// The /30 only has room for two IPs
+	_, err = n.ToVirtual(iputil.Parse("10.0.3.5"))
+	assert.Error(t, err)
+
+	// Unknown virtual IPs are not translated
+	ip = iputil.Parse("211.55.48.3")
+	assert.Equal(t, ip, n.ToReal(ip))
+}
+
+func TestNAT_TranslateIPv6(t *testing.T) {
+	n := NewNAT(mustParseCIDR(t, "fd00:ab::/64"))
+	n.SetConflicting([]*net.IPNet{mustParseCIDR(t, "fd00:1::/64")})
+	ip := iputil.Parse("fd00:1::22")
+	vip, err := n.ToVirtual(ip)
+	require.NoError(t, err)
+	assert.Equal(t, "fd00:ab::1", vip.String())
+	assert.Equal(t, ip.String(), n.ToReal(vip).String())
+}
+
+func TestNAT_StreamCreator(t *testing.T) {
+	n := NewNAT(mustParseCIDR(t, "211.55.48.0/20"))
+	n.SetConflicting([]*net.IPNet{mustParseCIDR(t, "10.0.0.0/16")})
+	ip := iputil.Parse("10.0.0.1")
+	vip, err := n.ToVirtual(ip)
+	require.NoError(t, err)
+
+	var got tunnel.ConnID
+	sc := n.StreamCreator(func(_ context.Context, id tunnel.ConnID) (tunnel.Stream, error) {
+		got = id
+		return nil, nil
+	})
+	src := iputil.Parse("192.168.1.10")
+	_, _ = sc(context.Background(), tunnel.NewConnID(ipproto.TCP, src, vip, 4711, 8080))
+	assert.Equal(t, tunnel.NewConnID(ipproto.TCP, src, ip, 4711, 8080), got)
+
+	other := iputil.Parse("10.1.0.1")
+	_, _ = sc(context.Background(), tunnel.NewConnID(ipproto.TCP, src, other, 4711, 8080))
+	assert.Equal(t, tunnel.NewConnID(ipproto.TCP, src, other, 4711, 8080), got)
+}
+
+func TestConflicts(t *testing.T) {
+	routes := []*routing.Route{
+		{RoutedNet: mustParseCIDR(t, "0.0.0.0/0"), Default: true, Interface: &net.Interface{Name: "eth0"}},
+		{RoutedNet: mustParseCIDR(t, "10.0.0.0/16"), Interface: &net.Interface{Name: "eth0"}},
+		{RoutedNet: mustParseCIDR(t, "10.96.0.0/16"), Interface: &net.Interface{Name: "tel0"}},
+	}
+	clusterSubnets := []*net.IPNet{
+		mustParseCIDR(t, "10.0.128.0/17"),
+		mustParseCIDR(t, "10.96.0.0/16"),
+		mustParseCIDR(t, "172.20.0.0/16"),
+	}
+	assert.Equal(t, []*net.IPNet{clusterSubnets[0]}, Conflicts(clusterSubnets, routes, "tel0"))
+}
@@ -2,6 +2,7 @@ package vif
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"time"
@@ -55,7 +56,95 @@ func InitLogger(ctx context.Context) {
 	log.SetLevel(gl)
 }
 
-func NewStack(ctx context.Context, dev stack.LinkEndpoint, streamCreator tunnel.StreamCreator) (*stack.Stack, error) {
+// StackOptions are the tuning options of the gVisor stack. Zero values, and a nil WindowScale, are replaced by
+// their defaults.
+type StackOptions struct {
+	// MTU is the MTU of the link endpoint.
+	MTU int
+
+	// MaxInFlight is the max number of in-flight TCP connection attempts.
+	MaxInFlight int
+
+	// WindowScale is the TCP window scale. The max TCP receive window is 16KiB shifted left by this value. The
+	// default is used when it's nil, so that zero can be set explicitly.
+	WindowScale *int
+
+	// ReceiveBufferSize is the initial size of the TCP receive buffer.
+	ReceiveBufferSize int
+
+	// SendBufferSize is the initial size of the TCP send buffer.
+	SendBufferSize int
+
+	// CongestionControl is the name of the TCP congestion control algorithm, "reno" or "cubic".
+	CongestionControl string
+
+	// DisableSACK disables TCP selective acknowledgements.
+	DisableSACK bool
+}
+
+const (
+	defaultWindowScale = 6
+	maxWindowScale     = 14
+)
+
+// defaultMaxInFlight specifies the default max number of in-flight connection attempts.
+const defaultMaxInFlight = 512
+
+const defaultCongestionControl = "reno"
+
+// withDefaults returns a copy of these options where all zero values have been replaced by their defaults.
+func (o StackOptions) withDefaults() StackOptions {
+	if o.MTU == 0 {
+		o.MTU = defaultDevMtu
+	}
+	if o.MaxInFlight == 0 {
+		o.MaxInFlight = defaultMaxInFlight
+	}
+	if o.WindowScale == nil {
+		ws := defaultWindowScale
+		o.WindowScale = &ws
+	}
+	if o.ReceiveBufferSize == 0 {
+		o.ReceiveBufferSize = tcp.DefaultReceiveBufferSize
+	}
+	if o.SendBufferSize == 0 {
+		o.SendBufferSize = tcp.DefaultSendBufferSize
+	}
+	if o.CongestionControl == "" {
+		o.CongestionControl = defaultCongestionControl
+	}
+	return o
+}
+
+func (o StackOptions) validate() error {
+	if o.MTU < header.IPv6MinimumMTU {
+		return fmt.Errorf("invalid MTU %d, must be at least %d", o.MTU, header.IPv6MinimumMTU)
+	}
+	if o.MaxInFlight < 0 {
+		return fmt.Errorf("invalid max in-flight %d", o.MaxInFlight)
+	}
+	if ws := *o.WindowScale; ws < 0 || ws > maxWindowScale {
+		return fmt.Errorf("invalid window scale %d, must be between 0 and %d", ws, maxWindowScale)
+	}
+	if o.ReceiveBufferSize < tcp.MinBufferSize || o.SendBufferSize < tcp.MinBufferSize {
+		return fmt.Errorf("invalid buffer size, must be at least %d", tcp.MinBufferSize)
+	}
+	return nil
+}
+
+// maxReceiveWindow returns the max TCP receive window.
+func (o StackOptions) maxReceiveWindow() int {
+	return 1 << (*o.WindowScale + 14)
+}
+
+// NewStack creates a new gVisor stack for the given device. Each TCP or UDP connection, and each ICMP echo
+// identifier, that arrives on the device is dispatched to a stream obtained from the streamCreator, and the
+// endpoint that handles it is added to the given pool.
+func NewStack(ctx context.Context, dev stack.LinkEndpoint, streamCreator tunnel.StreamCreator, pool *tunnel.Pool, opts StackOptions) (*stack.Stack, error) {
+	opts = opts.withDefaults()
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
 	s := stack.New(stack.Options{
 		NetworkProtocols: []stack.NetworkProtocolFactory{
 			ipv4.NewProtocol,
@@ -69,25 +158,20 @@ func NewStack(ctx context.Context, dev stack.LinkEndpoint, streamCreator tunnel.
 		},
 		HandleLocal: false,
 	})
-	if err := setDefaultOptions(s); err != nil {
+	if err := setDefaultOptions(s, opts); err != nil {
 		return nil, err
 	}
-	if err := setNIC(ctx, s, dev); err != nil {
+	if err := setMTU(ctx, dev, opts.MTU); err != nil {
 		return nil, err
 	}
-	setTCPHandler(ctx, s, streamCreator)
-	setUDPHandler(ctx, s, streamCreator)
+	if err := setNIC(ctx, s, newEchoEndpoint(ctx, dev, streamCreator, pool)); err != nil {
+		return nil, err
+	}
+	setTCPHandler(ctx, s, streamCreator, pool, opts)
+	setUDPHandler(ctx, s, streamCreator, pool)
 	return s, nil
 }
 
-const (
-	myWindowScale    = 6
-	maxReceiveWindow = 1 << (myWindowScale + 14) // 1MiB
-)
-
-// maxInFlight specifies the max number of in-flight connection attempts.
-const maxInFlight = 512
-
 // keepAliveIdle is used as the very first alive interval. Subsequent intervals
 // use keepAliveInterval.
 const keepAliveIdle = 60 * time.Second
@@ -105,7 +189,7 @@ func (i idStringer) String() string {
 	return fmt.Sprintf("%s:%d -> %s:%d", i.RemoteAddress, i.RemotePort, i.LocalAddress, i.LocalPort)
 }
 
-func setDefaultOptions(s *stack.Stack) error {
+func setDefaultOptions(s *stack.Stack, opts StackOptions) error {
 	// Forwarding
 	if err := s.SetForwardingDefaultAndAllNICs(ipv4.ProtocolNumber, true); err != nil {
 		return fmt.Errorf("SetForwardingDefaultAndAllNICs(ipv4, %t): %s", true, err)
@@ -120,6 +204,64 @@ func setDefaultOptions(s *stack.Stack) error {
 	if err := s.SetNetworkProtocolOption(ipv6.ProtocolNumber, &ttl); err != nil {
 		return fmt.Errorf("SetDefaultTTL(ipv6, %d): %s", ttl, err)
 	}
+
+	// TCP buffers. The max sizes must be able to hold the max receive window.
+	rwnd := opts.maxReceiveWindow()
+	rbs := tcpip.TCPReceiveBufferSizeRangeOption{
+		Min:     tcp.MinBufferSize,
+		Default: opts.ReceiveBufferSize,
+		Max:     maxInt(tcp.MaxBufferSize, opts.ReceiveBufferSize, rwnd),
+	}
+	if err := s.SetTransportProtocolOption(tcp.ProtocolNumber, &rbs); err != nil {
+		return fmt.Errorf("SetTransportProtocolOption(tcp, %+v): %s", rbs, err)
+	}
+	sbs := tcpip.TCPSendBufferSizeRangeOption{
+		Min:     tcp.MinBufferSize,
+		Default: opts.SendBufferSize,
+		Max:     maxInt(tcp.MaxBufferSize, opts.SendBufferSize),
+	}
+	if err := s.SetTransportProtocolOption(tcp.ProtocolNumber, &sbs); err != nil {
+		return fmt.Errorf("SetTransportProtocolOption(tcp, %+v): %s", sbs, err)
+	}
+	cc := tcpip.CongestionControlOption(opts.CongestionControl)
+	if err := s.SetTransportProtocolOption(tcp.ProtocolNumber, &cc); err != nil {
+		return fmt.Errorf("SetTransportProtocolOption(tcp, congestion control %q): %s", cc, err)
+	}
+	sa := tcpip.TCPSACKEnabled(!opts.DisableSACK)
+	if err := s.SetTransportProtocolOption(tcp.ProtocolNumber, &sa); err != nil {
+		return fmt.Errorf("SetTransportProtocolOption(tcp, SACK %t): %s", sa, err)
+	}
+
+	// Enable Receive Buffer Auto-Tuning, see:
+	// https://github.com/google/gvisor/issues/1666
+	mo := tcpip.TCPModerateReceiveBufferOption(true)
+	if err := s.SetTransportProtocolOption(tcp.ProtocolNumber, &mo); err != nil {
+		return fmt.Errorf("SetTransportProtocolOption(tcp, moderate receive buffer %t): %s", mo, err)
+	}
+	return nil
+}
+
+func maxInt(v int, vs ...int) int {
+	for _, o := range vs {
+		if o > v {
+			v = o
+		}
+	}
+	return v
+}
+
+// mtuSetter is implemented by link endpoints that have a configurable MTU.
+type mtuSetter interface {
+	SetMTU(int) error
+}
+
+func setMTU(ctx context.Context, ep stack.LinkEndpoint, mtu int) error {
+	if int(ep.MTU()) != mtu {
+		if ms, ok := ep.(mtuSetter); ok {
+			return ms.SetMTU(mtu)
+		}
+		dlog.Warnf(ctx, "unable to set MTU %d on link endpoint with MTU %d", mtu, ep.MTU())
+	}
 	return nil
 }
 
@@ -147,8 +289,8 @@ func setNIC(ctx context.Context, s *stack.Stack, ep stack.LinkEndpoint) error {
 	return nil
 }
 
-func setTCPHandler(ctx context.Context, s *stack.Stack, streamCreator tunnel.StreamCreator) {
-	f := tcp.NewForwarder(s, maxReceiveWindow, maxInFlight, func(fr *tcp.ForwarderRequest) {
+func setTCPHandler(ctx context.Context, s *stack.Stack, streamCreator tunnel.StreamCreator, pool *tunnel.Pool, opts StackOptions) {
+	f := tcp.NewForwarder(s, opts.maxReceiveWindow(), opts.MaxInFlight, func(fr *tcp.ForwarderRequest) {
 		var ep tcpip.Endpoint
 		var err tcpip.Error
 		id := fr.ID()
@@ -193,46 +335,10 @@ func setTCPHandler(ctx context.Context, s *stack.Stack, streamCreator tunnel.Str
 		if err = ep.SetSockOptInt(tcpip.KeepaliveCountOption, keepAliveCount); err != nil {
 			return
 		}
+		so.SetReceiveBufferSize(int64(opts.ReceiveBufferSize), false)
+		so.SetSendBufferSize(int64(opts.SendBufferSize), false)
 
-		var rs tcpip.TCPReceiveBufferSizeRangeOption
-		if err = s.TransportProtocolOption(header.TCPProtocolNumber, &rs); err != nil {
-			return
-		}
-		so.SetReceiveBufferSize(int64(rs.Default), false)
-
-		var ss tcpip.TCPSendBufferSizeRangeOption
-		if err = s.TransportProtocolOption(header.TCPProtocolNumber, &ss); err != nil {
-			return
-		}
-		so.SetSendBufferSize(int64(ss.Default), false)
-
-		if err = s.SetTransportProtocolOption(tcp.ProtocolNumber,
-			&tcpip.TCPSendBufferSizeRangeOption{
-				Min:     tcp.MinBufferSize,
-				Default: tcp.DefaultSendBufferSize,
-				Max:     tcp.MaxBufferSize,
-			}); err != nil {
-			return
-		}
-
-		if err = s.SetTransportProtocolOption(tcp.ProtocolNumber,
-			&tcpip.TCPReceiveBufferSizeRangeOption{
-				Min:     tcp.MinBufferSize,
-				Default: tcp.DefaultSendBufferSize,
-				Max:     tcp.MaxBufferSize,
-			}); err != nil {
-			return
-		}
-
-		sa := tcpip.TCPSACKEnabled(true)
-		s.SetTransportProtocolOption(tcp.ProtocolNumber, &sa)
-
-		// Enable Receive Buffer Auto-Tuning, see:
-		// https://github.com/google/gvisor/issues/1666
-		mo := tcpip.TCPModerateReceiveBufferOption(true)
-		s.SetTransportProtocolOption(tcp.ProtocolNumber, &mo)
-
-		dispatchToStream(ctx, newConnID(header.TCPProtocolNumber, id), gonet.NewTCPConn(&wq, ep), streamCreator)
+		dispatchToStream(ctx, newConnID(header.TCPProtocolNumber, id), gonet.NewTCPConn(&wq, ep), streamCreator, pool)
 	})
 	s.SetTransportProtocolHandler(tcp.ProtocolNumber, f.HandlePacket)
 }
@@ -243,7 +349,7 @@ var blockedUDPPorts = map[uint16]bool{ //nolint:gochecknoglobals // constant
 	139: true, // NETBIOS
 }
 
-func setUDPHandler(ctx context.Context, s *stack.Stack, streamCreator tunnel.StreamCreator) {
+func setUDPHandler(ctx context.Context, s *stack.Stack, streamCreator tunnel.StreamCreator, pool *tunnel.Pool) {
 	f := udp.NewForwarder(s, func(fr *udp.ForwarderRequest) {
 		id := fr.ID()
 		ctx, span := otel.GetTracerProvider().Tracer("").Start(ctx, "UDPHandler",
@@ -270,7 +376,7 @@ func setUDPHandler(ctx context.Context, s *stack.Stack, streamCreator tunnel.Str
 			dlog.Errorf(ctx, msg)
 			return
 		}
-		dispatchToStream(ctx, newConnID(udp.ProtocolNumber, id), gonet.NewUDPConn(s, &wq, ep), streamCreator)
+		dispatchToStream(ctx, newConnID(udp.ProtocolNumber, id), gonet.NewUDPConn(s, &wq, ep), streamCreator, pool)
 	})
 	s.SetTransportProtocolHandler(udp.ProtocolNumber, f.HandlePacket)
 }
@@ -279,16 +385,25 @@ func newConnID(proto tcpip.TransportProtocolNumber, id stack.TransportEndpointID
 	return tunnel.NewConnID(int(proto), ([]byte)(id.RemoteAddress), ([]byte)(id.LocalAddress), id.RemotePort, id.LocalPort)
 }
 
-func dispatchToStream(ctx context.Context, id tunnel.ConnID, conn net.Conn, streamCreator tunnel.StreamCreator) {
-	ctx, cancel := context.WithCancel(ctx)
-	stream, err := streamCreator(ctx, id)
-	if err != nil {
+func dispatchToStream(ctx context.Context, id tunnel.ConnID, conn net.Conn, streamCreator tunnel.StreamCreator, pool *tunnel.Pool) {
+	_, found, err := pool.GetOrCreate(ctx, id, func(ctx context.Context, release func()) (tunnel.Handler, error) {
+		stream, err := streamCreator(ctx, id)
 		if err != nil {
+			return nil, err
+		}
+		return tunnel.NewConnEndpoint(stream, conn, release).(tunnel.Handler), nil
+	})
+	switch {
+	case err != nil:
+		if errors.Is(err, tunnel.ErrChaosDropped) {
+			dlog.Debugf(ctx, "forward %s: %s", id, err)
+		} else {
 			dlog.Errorf(ctx, "forward %s: %s", id, err)
 		}
-		cancel()
-		return
+		_ = conn.Close()
+	case found:
+		// This is not expected to happen since the stack forwards each connection only once.
+		dlog.Errorf(ctx, "forward %s: connection is already active", id)
+		_ = conn.Close()
 	}
-	ep := tunnel.NewConnEndpoint(stream, conn, cancel)
-	ep.Start(ctx)
 }
@@ -0,0 +1,220 @@
+package vif
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gvisor.dev/gvisor/pkg/bufferv2"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/adapters/gonet"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/link/channel"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+	"gvisor.dev/gvisor/pkg/tcpip/transport/tcp"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/tunnel"
+)
+
+// quietContext returns a context with a logger that discards everything. The goroutines of the stack and the
+// tunnel endpoints keep logging for a short while after a test or benchmark has completed.
+func quietContext() context.Context {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return dlog.WithLogger(context.Background(), dlog.WrapLogrus(logger))
+}
+
+// pumpPackets moves the packets written by the stack that owns the from endpoint to the stack that owns the
+// to endpoint, thereby emulating the TUN device.
+func pumpPackets(ctx context.Context, from, to *channel.Endpoint) {
+	for {
+		pkt := from.ReadContext(ctx)
+		if pkt.IsNil() {
+			return
+		}
+		pb := stack.NewPacketBuffer(stack.PacketBufferOptions{
+			Payload: bufferv2.MakeWithView(pkt.ToView()),
+		})
+		pkt.DecRef()
+		to.InjectInbound(header.IPv4ProtocolNumber, pb)
+		pb.DecRef()
+	}
+}
+
+// sinkStreamCreator returns a tunnel.StreamCreator that discards all data sent to the streams that it creates and
+// adds the number of discarded bytes to the given counter.
+func sinkStreamCreator(received *atomic.Int64) tunnel.StreamCreator {
+	return func(ctx context.Context, id tunnel.ConnID) (tunnel.Stream, error) {
+		local, peer := tunnel.NewPipe(id, "session")
+		ctx, cancel := context.WithCancel(ctx)
+		in, out := net.Pipe()
+		tunnel.NewConnEndpoint(peer, in, cancel).Start(ctx)
+		go func() {
+			buf := make([]byte, 0x10000)
+			for {
+				n, err := out.Read(buf)
+				received.Add(int64(n))
+				if err != nil {
+					return
+				}
+			}
+		}()
+		return local, nil
+	}
+}
+
+// sourceStreamCreator returns a tunnel.StreamCreator that creates streams that send the given number of bytes to
+// the client.
+func sourceStreamCreator(size int64) tunnel.StreamCreator {
+	return func(ctx context.Context, id tunnel.ConnID) (tunnel.Stream, error) {
+		local, peer := tunnel.NewPipe(id, "session")
+		ctx, cancel := context.WithCancel(ctx)
+		in, out := net.Pipe()
+		tunnel.NewConnEndpoint(peer, in, cancel).Start(ctx)
+		go func() {
+			buf := make([]byte, 0x10000)
+			for size > 0 {
+				n := int64(len(buf))
+				if n > size {
+					n = size
+				}
+				if _, err := out.Write(buf[:n]); err != nil {
+					return
+				}
+				size -= n
+			}
+		}()
+		return local, nil
+	}
+}
+
+// dialThroughStack creates a stack for an in-memory link endpoint using NewStack, and a client stack that is
+// connected to it. It returns a TCP connection, created by the client stack, that is dispatched to streams
+// created by the given streamCreator.
+func dialThroughStack(ctx context.Context, t testing.TB, opts StackOptions, streamCreator tunnel.StreamCreator) net.Conn {
+	mtu := opts.withDefaults().MTU
+	vifEp := channel.New(1024, uint32(mtu), "")
+	clientEp := channel.New(1024, uint32(mtu), "")
+	go pumpPackets(ctx, vifEp, clientEp)
+	go pumpPackets(ctx, clientEp, vifEp)
+
+	pool := tunnel.NewPool()
+	vs, err := NewStack(ctx, vifEp, streamCreator, pool, opts)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		pool.CloseAll(ctx)
+		vs.Close()
+	})
+
+	cs := stack.New(stack.Options{
+		NetworkProtocols:   []stack.NetworkProtocolFactory{ipv4.NewProtocol},
+		TransportProtocols: []stack.TransportProtocolFactory{tcp.NewProtocol},
+	})
+	t.Cleanup(cs.Close)
+	require.Nil(t, cs.CreateNIC(1, clientEp))
+	require.Nil(t, cs.AddProtocolAddress(1, tcpip.ProtocolAddress{
+		Protocol:          ipv4.ProtocolNumber,
+		AddressWithPrefix: tcpip.Address(net.IP{192, 168, 0, 2}).WithPrefix(),
+	}, stack.AddressProperties{}))
+	cs.SetRouteTable([]tcpip.Route{{Destination: header.IPv4EmptySubnet, NIC: 1}})
+
+	conn, err := gonet.DialContextTCP(ctx, cs, tcpip.FullAddress{
+		NIC:  1,
+		Addr: tcpip.Address(net.IP{10, 0, 0, 1}),
+		Port: 8080,
+	}, ipv4.ProtocolNumber)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestStackOptions_validate(t *testing.T) {
+	assert.NoError(t, StackOptions{}.withDefaults().validate())
+	assert.Error(t, StackOptions{MTU: 576}.withDefaults().validate())
+	assert.Error(t, StackOptions{WindowScale: windowScale(15)}.withDefaults().validate())
+	assert.Equal(t, 1<<14, StackOptions{WindowScale: windowScale(0)}.withDefaults().maxReceiveWindow())
+	assert.Error(t, StackOptions{ReceiveBufferSize: 1}.withDefaults().validate())
+	assert.Equal(t, 1<<20, StackOptions{}.withDefaults().maxReceiveWindow())
+}
+
+func TestNewStack(t *testing.T) {
+	ctx, cancel := context.WithCancel(quietContext())
+	defer cancel()
+
+	var received atomic.Int64
+	conn := dialThroughStack(ctx, t, StackOptions{CongestionControl: "cubic", DisableSACK: true}, sinkStreamCreator(&received))
+	data := make([]byte, 0x40000)
+	_, err := conn.Write(data)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return received.Load() == int64(len(data)) }, 5*time.Second, 10*time.Millisecond)
+}
+
+func windowScale(ws int) *int {
+	return &ws
+}
+
+// BenchmarkStack measures the throughput of TCP connections that pass through the stack using different options,
+// both for uploads, where the client sends data into the cluster, and for downloads, where it receives data.
+func BenchmarkStack(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		opts StackOptions
+	}{
+		{"default", StackOptions{}},
+		{"cubic", StackOptions{CongestionControl: "cubic"}},
+		{"no-sack", StackOptions{DisableSACK: true}},
+		{"window-scale-4", StackOptions{WindowScale: windowScale(4)}},
+		{"window-scale-8", StackOptions{WindowScale: windowScale(8)}},
+		{"large-buffers", StackOptions{ReceiveBufferSize: 4 << 20, SendBufferSize: 4 << 20}},
+		{"mtu-9000", StackOptions{MTU: 9000}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name+"/upload", func(b *testing.B) {
+			ctx, cancel := context.WithCancel(quietContext())
+			defer cancel()
+
+			var received atomic.Int64
+			conn := dialThroughStack(ctx, b, bm.opts, sinkStreamCreator(&received))
+			data := make([]byte, 0x10000)
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := conn.Write(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+			total := int64(b.N * len(data))
+			for received.Load() < total {
+				time.Sleep(time.Millisecond)
+			}
+			b.StopTimer()
+		})
+		b.Run(bm.name+"/download", func(b *testing.B) {
+			ctx, cancel := context.WithCancel(quietContext())
+			defer cancel()
+
+			const chunk = 0x10000
+			total := int64(b.N * chunk)
+			conn := dialThroughStack(ctx, b, bm.opts, sourceStreamCreator(total))
+			buf := make([]byte, chunk)
+			b.SetBytes(chunk)
+			b.ResetTimer()
+			for n := int64(0); n < total; {
+				r, err := conn.Read(buf)
+				if err != nil {
+					b.Fatal(err)
+				}
+				n += int64(r)
+			}
+			b.StopTimer()
+		})
+	}
+}
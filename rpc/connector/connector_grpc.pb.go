@@ -9,6 +9,7 @@ package connector
 import (
 	context "context"
 	common "github.com/telepresenceio/telepresence/rpc/v2/common"
+	daemon "github.com/telepresenceio/telepresence/rpc/v2/daemon"
 	manager "github.com/telepresenceio/telepresence/rpc/v2/manager"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
@@ -34,6 +35,10 @@ const (
 	Connector_CreateIntercept_FullMethodName         = "/telepresence.connector.Connector/CreateIntercept"
 	Connector_RemoveIntercept_FullMethodName         = "/telepresence.connector.Connector/RemoveIntercept"
 	Connector_UpdateIntercept_FullMethodName         = "/telepresence.connector.Connector/UpdateIntercept"
+	Connector_Expose_FullMethodName                  = "/telepresence.connector.Connector/Expose"
+	Connector_Unexpose_FullMethodName                = "/telepresence.connector.Connector/Unexpose"
+	Connector_GetAuditLog_FullMethodName             = "/telepresence.connector.Connector/GetAuditLog"
+	Connector_Diagnose_FullMethodName                = "/telepresence.connector.Connector/Diagnose"
 	Connector_Helm_FullMethodName                    = "/telepresence.connector.Connector/Helm"
 	Connector_Uninstall_FullMethodName               = "/telepresence.connector.Connector/Uninstall"
 	Connector_List_FullMethodName                    = "/telepresence.connector.Connector/List"
@@ -52,6 +57,17 @@ const (
 	Connector_GetNamespaces_FullMethodName           = "/telepresence.connector.Connector/GetNamespaces"
 	Connector_RemoteMountAvailability_FullMethodName = "/telepresence.connector.Connector/RemoteMountAvailability"
 	Connector_GetConfig_FullMethodName               = "/telepresence.connector.Connector/GetConfig"
+	Connector_GetConnections_FullMethodName          = "/telepresence.connector.Connector/GetConnections"
+	Connector_Capture_FullMethodName                 = "/telepresence.connector.Connector/Capture"
+	Connector_AddChaosRule_FullMethodName            = "/telepresence.connector.Connector/AddChaosRule"
+	Connector_RemoveChaosRule_FullMethodName         = "/telepresence.connector.Connector/RemoveChaosRule"
+	Connector_GetChaosRules_FullMethodName           = "/telepresence.connector.Connector/GetChaosRules"
+	Connector_GetDNSCache_FullMethodName             = "/telepresence.connector.Connector/GetDNSCache"
+	Connector_QueryDNS_FullMethodName                = "/telepresence.connector.Connector/QueryDNS"
+	Connector_GetDNSQueryLog_FullMethodName          = "/telepresence.connector.Connector/GetDNSQueryLog"
+	Connector_AddForward_FullMethodName              = "/telepresence.connector.Connector/AddForward"
+	Connector_RemoveForward_FullMethodName           = "/telepresence.connector.Connector/RemoveForward"
+	Connector_GetForwards_FullMethodName             = "/telepresence.connector.Connector/GetForwards"
 )
 
 // ConnectorClient is the client API for Connector service.
@@ -89,6 +105,21 @@ type ConnectorClient interface {
 	// Requires having already called Connect.
 	RemoveIntercept(ctx context.Context, in *manager.RemoveInterceptRequest2, opts ...grpc.CallOption) (*InterceptResult, error)
 	UpdateIntercept(ctx context.Context, in *manager.UpdateInterceptRequest, opts ...grpc.CallOption) (*manager.InterceptInfo, error)
+	// Expose makes a port on the workstation reachable from the cluster using
+	// a Service. The Service is removed using Unexpose with its name.
+	// Requires having already called Connect.
+	Expose(ctx context.Context, in *manager.ExposeRequest, opts ...grpc.CallOption) (*manager.ExposeInfo, error)
+	// Unexpose removes a Service created by Expose.
+	// Requires having already called Connect.
+	Unexpose(ctx context.Context, in *manager.UnexposeRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetAuditLog returns the records of the traffic-manager's audit log.
+	// Requires having already called Connect.
+	GetAuditLog(ctx context.Context, in *manager.AuditLogRequest, opts ...grpc.CallOption) (*manager.AuditLog, error)
+	// Diagnose checks each hop between the workstation and the cluster: the
+	// connection to the traffic-manager, version compatibility, the checks of
+	// the root daemon, and the reachability of the traffic-agents.
+	// Requires having already called Connect.
+	Diagnose(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*daemon.Diagnosis, error)
 	// Installs, Upgrades, or Uninstalls the traffic-manager in the cluster.
 	Helm(ctx context.Context, in *HelmRequest, opts ...grpc.CallOption) (*common.Result, error)
 	// Uninstalls traffic-agents from the cluster.
@@ -127,6 +158,37 @@ type ConnectorClient interface {
 	RemoteMountAvailability(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*common.Result, error)
 	// GetConfig returns the current configuration
 	GetConfig(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ClientConfig, error)
+	// GetConnections returns the connections that the root daemon currently
+	// tunnels to the cluster.
+	GetConnections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*daemon.Connections, error)
+	// Capture streams the packets that the root daemon's TUN device sends
+	// and receives until the call is cancelled.
+	Capture(ctx context.Context, in *daemon.CaptureRequest, opts ...grpc.CallOption) (Connector_CaptureClient, error)
+	// AddChaosRule adds a rule to the root daemon that injects faults into the
+	// connections to a destination.
+	AddChaosRule(ctx context.Context, in *daemon.ChaosRule, opts ...grpc.CallOption) (*daemon.ChaosRule, error)
+	// RemoveChaosRule removes one or all of the root daemon's chaos rules.
+	RemoveChaosRule(ctx context.Context, in *daemon.RemoveChaosRuleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetChaosRules returns the root daemon's chaos rules.
+	GetChaosRules(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*daemon.ChaosRules, error)
+	// GetDNSCache returns the statistics and the entries of the root daemon's
+	// DNS cache, and optionally flushes it.
+	GetDNSCache(ctx context.Context, in *daemon.DNSCacheRequest, opts ...grpc.CallOption) (*daemon.DNSCache, error)
+	// QueryDNS resolves a name using the root daemon's DNS server, and reports
+	// how it was resolved.
+	QueryDNS(ctx context.Context, in *daemon.DNSQueryRequest, opts ...grpc.CallOption) (*daemon.DNSQueryResponse, error)
+	// GetDNSQueryLog returns the recent entries of the root daemon's DNS query
+	// log, and streams the new entries until the call is cancelled when follow
+	// is true.
+	GetDNSQueryLog(ctx context.Context, in *daemon.DNSQueryLogRequest, opts ...grpc.CallOption) (Connector_GetDNSQueryLogClient, error)
+	// AddForward adds a named port-forward from a local port to a port on a
+	// cluster host. The forward is persisted and restored when the session
+	// is reconnected.
+	AddForward(ctx context.Context, in *Forward, opts ...grpc.CallOption) (*Forward, error)
+	// RemoveForward removes one or all port-forwards.
+	RemoveForward(ctx context.Context, in *RemoveForwardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetForwards returns the port-forwards.
+	GetForwards(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Forwards, error)
 }
 
 type connectorClient struct {
@@ -245,6 +307,42 @@ func (c *connectorClient) UpdateIntercept(ctx context.Context, in *manager.Updat
 	return out, nil
 }
 
+func (c *connectorClient) Expose(ctx context.Context, in *manager.ExposeRequest, opts ...grpc.CallOption) (*manager.ExposeInfo, error) {
+	out := new(manager.ExposeInfo)
+	err := c.cc.Invoke(ctx, Connector_Expose_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) Unexpose(ctx context.Context, in *manager.UnexposeRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Connector_Unexpose_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) GetAuditLog(ctx context.Context, in *manager.AuditLogRequest, opts ...grpc.CallOption) (*manager.AuditLog, error) {
+	out := new(manager.AuditLog)
+	err := c.cc.Invoke(ctx, Connector_GetAuditLog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) Diagnose(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*daemon.Diagnosis, error) {
+	out := new(daemon.Diagnosis)
+	err := c.cc.Invoke(ctx, Connector_Diagnose_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *connectorClient) Helm(ctx context.Context, in *HelmRequest, opts ...grpc.CallOption) (*common.Result, error) {
 	out := new(common.Result)
 	err := c.cc.Invoke(ctx, Connector_Helm_FullMethodName, in, out, opts...)
@@ -430,6 +528,151 @@ func (c *connectorClient) GetConfig(ctx context.Context, in *emptypb.Empty, opts
 	return out, nil
 }
 
+func (c *connectorClient) GetConnections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*daemon.Connections, error) {
+	out := new(daemon.Connections)
+	err := c.cc.Invoke(ctx, Connector_GetConnections_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) Capture(ctx context.Context, in *daemon.CaptureRequest, opts ...grpc.CallOption) (Connector_CaptureClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Connector_ServiceDesc.Streams[1], Connector_Capture_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &connectorCaptureClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Connector_CaptureClient interface {
+	Recv() (*daemon.CapturedPacket, error)
+	grpc.ClientStream
+}
+
+type connectorCaptureClient struct {
+	grpc.ClientStream
+}
+
+func (x *connectorCaptureClient) Recv() (*daemon.CapturedPacket, error) {
+	m := new(daemon.CapturedPacket)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *connectorClient) AddChaosRule(ctx context.Context, in *daemon.ChaosRule, opts ...grpc.CallOption) (*daemon.ChaosRule, error) {
+	out := new(daemon.ChaosRule)
+	err := c.cc.Invoke(ctx, Connector_AddChaosRule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) RemoveChaosRule(ctx context.Context, in *daemon.RemoveChaosRuleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Connector_RemoveChaosRule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) GetChaosRules(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*daemon.ChaosRules, error) {
+	out := new(daemon.ChaosRules)
+	err := c.cc.Invoke(ctx, Connector_GetChaosRules_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) GetDNSCache(ctx context.Context, in *daemon.DNSCacheRequest, opts ...grpc.CallOption) (*daemon.DNSCache, error) {
+	out := new(daemon.DNSCache)
+	err := c.cc.Invoke(ctx, Connector_GetDNSCache_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) QueryDNS(ctx context.Context, in *daemon.DNSQueryRequest, opts ...grpc.CallOption) (*daemon.DNSQueryResponse, error) {
+	out := new(daemon.DNSQueryResponse)
+	err := c.cc.Invoke(ctx, Connector_QueryDNS_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) GetDNSQueryLog(ctx context.Context, in *daemon.DNSQueryLogRequest, opts ...grpc.CallOption) (Connector_GetDNSQueryLogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Connector_ServiceDesc.Streams[2], Connector_GetDNSQueryLog_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &connectorGetDNSQueryLogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Connector_GetDNSQueryLogClient interface {
+	Recv() (*daemon.DNSQueryLogEntry, error)
+	grpc.ClientStream
+}
+
+type connectorGetDNSQueryLogClient struct {
+	grpc.ClientStream
+}
+
+func (x *connectorGetDNSQueryLogClient) Recv() (*daemon.DNSQueryLogEntry, error) {
+	m := new(daemon.DNSQueryLogEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *connectorClient) AddForward(ctx context.Context, in *Forward, opts ...grpc.CallOption) (*Forward, error) {
+	out := new(Forward)
+	err := c.cc.Invoke(ctx, Connector_AddForward_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) RemoveForward(ctx context.Context, in *RemoveForwardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Connector_RemoveForward_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *connectorClient) GetForwards(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Forwards, error) {
+	out := new(Forwards)
+	err := c.cc.Invoke(ctx, Connector_GetForwards_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ConnectorServer is the server API for Connector service.
 // All implementations must embed UnimplementedConnectorServer
 // for forward compatibility
@@ -465,6 +708,21 @@ type ConnectorServer interface {
 	// Requires having already called Connect.
 	RemoveIntercept(context.Context, *manager.RemoveInterceptRequest2) (*InterceptResult, error)
 	UpdateIntercept(context.Context, *manager.UpdateInterceptRequest) (*manager.InterceptInfo, error)
+	// Expose makes a port on the workstation reachable from the cluster using
+	// a Service. The Service is removed using Unexpose with its name.
+	// Requires having already called Connect.
+	Expose(context.Context, *manager.ExposeRequest) (*manager.ExposeInfo, error)
+	// Unexpose removes a Service created by Expose.
+	// Requires having already called Connect.
+	Unexpose(context.Context, *manager.UnexposeRequest) (*emptypb.Empty, error)
+	// GetAuditLog returns the records of the traffic-manager's audit log.
+	// Requires having already called Connect.
+	GetAuditLog(context.Context, *manager.AuditLogRequest) (*manager.AuditLog, error)
+	// Diagnose checks each hop between the workstation and the cluster: the
+	// connection to the traffic-manager, version compatibility, the checks of
+	// the root daemon, and the reachability of the traffic-agents.
+	// Requires having already called Connect.
+	Diagnose(context.Context, *emptypb.Empty) (*daemon.Diagnosis, error)
 	// Installs, Upgrades, or Uninstalls the traffic-manager in the cluster.
 	Helm(context.Context, *HelmRequest) (*common.Result, error)
 	// Uninstalls traffic-agents from the cluster.
@@ -503,6 +761,37 @@ type ConnectorServer interface {
 	RemoteMountAvailability(context.Context, *emptypb.Empty) (*common.Result, error)
 	// GetConfig returns the current configuration
 	GetConfig(context.Context, *emptypb.Empty) (*ClientConfig, error)
+	// GetConnections returns the connections that the root daemon currently
+	// tunnels to the cluster.
+	GetConnections(context.Context, *emptypb.Empty) (*daemon.Connections, error)
+	// Capture streams the packets that the root daemon's TUN device sends
+	// and receives until the call is cancelled.
+	Capture(*daemon.CaptureRequest, Connector_CaptureServer) error
+	// AddChaosRule adds a rule to the root daemon that injects faults into the
+	// connections to a destination.
+	AddChaosRule(context.Context, *daemon.ChaosRule) (*daemon.ChaosRule, error)
+	// RemoveChaosRule removes one or all of the root daemon's chaos rules.
+	RemoveChaosRule(context.Context, *daemon.RemoveChaosRuleRequest) (*emptypb.Empty, error)
+	// GetChaosRules returns the root daemon's chaos rules.
+	GetChaosRules(context.Context, *emptypb.Empty) (*daemon.ChaosRules, error)
+	// GetDNSCache returns the statistics and the entries of the root daemon's
+	// DNS cache, and optionally flushes it.
+	GetDNSCache(context.Context, *daemon.DNSCacheRequest) (*daemon.DNSCache, error)
+	// QueryDNS resolves a name using the root daemon's DNS server, and reports
+	// how it was resolved.
+	QueryDNS(context.Context, *daemon.DNSQueryRequest) (*daemon.DNSQueryResponse, error)
+	// GetDNSQueryLog returns the recent entries of the root daemon's DNS query
+	// log, and streams the new entries until the call is cancelled when follow
+	// is true.
+	GetDNSQueryLog(*daemon.DNSQueryLogRequest, Connector_GetDNSQueryLogServer) error
+	// AddForward adds a named port-forward from a local port to a port on a
+	// cluster host. The forward is persisted and restored when the session
+	// is reconnected.
+	AddForward(context.Context, *Forward) (*Forward, error)
+	// RemoveForward removes one or all port-forwards.
+	RemoveForward(context.Context, *RemoveForwardRequest) (*emptypb.Empty, error)
+	// GetForwards returns the port-forwards.
+	GetForwards(context.Context, *emptypb.Empty) (*Forwards, error)
 	mustEmbedUnimplementedConnectorServer()
 }
 
@@ -546,6 +835,18 @@ func (UnimplementedConnectorServer) RemoveIntercept(context.Context, *manager.Re
 func (UnimplementedConnectorServer) UpdateIntercept(context.Context, *manager.UpdateInterceptRequest) (*manager.InterceptInfo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateIntercept not implemented")
 }
+func (UnimplementedConnectorServer) Expose(context.Context, *manager.ExposeRequest) (*manager.ExposeInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Expose not implemented")
+}
+func (UnimplementedConnectorServer) Unexpose(context.Context, *manager.UnexposeRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unexpose not implemented")
+}
+func (UnimplementedConnectorServer) GetAuditLog(context.Context, *manager.AuditLogRequest) (*manager.AuditLog, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditLog not implemented")
+}
+func (UnimplementedConnectorServer) Diagnose(context.Context, *emptypb.Empty) (*daemon.Diagnosis, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Diagnose not implemented")
+}
 func (UnimplementedConnectorServer) Helm(context.Context, *HelmRequest) (*common.Result, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Helm not implemented")
 }
@@ -600,6 +901,39 @@ func (UnimplementedConnectorServer) RemoteMountAvailability(context.Context, *em
 func (UnimplementedConnectorServer) GetConfig(context.Context, *emptypb.Empty) (*ClientConfig, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
 }
+func (UnimplementedConnectorServer) GetConnections(context.Context, *emptypb.Empty) (*daemon.Connections, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConnections not implemented")
+}
+func (UnimplementedConnectorServer) Capture(*daemon.CaptureRequest, Connector_CaptureServer) error {
+	return status.Errorf(codes.Unimplemented, "method Capture not implemented")
+}
+func (UnimplementedConnectorServer) AddChaosRule(context.Context, *daemon.ChaosRule) (*daemon.ChaosRule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddChaosRule not implemented")
+}
+func (UnimplementedConnectorServer) RemoveChaosRule(context.Context, *daemon.RemoveChaosRuleRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveChaosRule not implemented")
+}
+func (UnimplementedConnectorServer) GetChaosRules(context.Context, *emptypb.Empty) (*daemon.ChaosRules, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChaosRules not implemented")
+}
+func (UnimplementedConnectorServer) GetDNSCache(context.Context, *daemon.DNSCacheRequest) (*daemon.DNSCache, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDNSCache not implemented")
+}
+func (UnimplementedConnectorServer) QueryDNS(context.Context, *daemon.DNSQueryRequest) (*daemon.DNSQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryDNS not implemented")
+}
+func (UnimplementedConnectorServer) GetDNSQueryLog(*daemon.DNSQueryLogRequest, Connector_GetDNSQueryLogServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetDNSQueryLog not implemented")
+}
+func (UnimplementedConnectorServer) AddForward(context.Context, *Forward) (*Forward, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddForward not implemented")
+}
+func (UnimplementedConnectorServer) RemoveForward(context.Context, *RemoveForwardRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveForward not implemented")
+}
+func (UnimplementedConnectorServer) GetForwards(context.Context, *emptypb.Empty) (*Forwards, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetForwards not implemented")
+}
 func (UnimplementedConnectorServer) mustEmbedUnimplementedConnectorServer() {}
 
 // UnsafeConnectorServer may be embedded to opt out of forward compatibility for this service.
@@ -829,6 +1163,78 @@ func _Connector_UpdateIntercept_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Connector_Expose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(manager.ExposeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).Expose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_Expose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).Expose(ctx, req.(*manager.ExposeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_Unexpose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(manager.UnexposeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).Unexpose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_Unexpose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).Unexpose(ctx, req.(*manager.UnexposeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_GetAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(manager.AuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).GetAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_GetAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).GetAuditLog(ctx, req.(*manager.AuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_Diagnose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).Diagnose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_Diagnose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).Diagnose(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Connector_Helm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HelmRequest)
 	if err := dec(in); err != nil {
@@ -1156,6 +1562,210 @@ func _Connector_GetConfig_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Connector_GetConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).GetConnections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_GetConnections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).GetConnections(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_Capture_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(daemon.CaptureRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConnectorServer).Capture(m, &connectorCaptureServer{stream})
+}
+
+type Connector_CaptureServer interface {
+	Send(*daemon.CapturedPacket) error
+	grpc.ServerStream
+}
+
+type connectorCaptureServer struct {
+	grpc.ServerStream
+}
+
+func (x *connectorCaptureServer) Send(m *daemon.CapturedPacket) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Connector_AddChaosRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(daemon.ChaosRule)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).AddChaosRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_AddChaosRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).AddChaosRule(ctx, req.(*daemon.ChaosRule))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_RemoveChaosRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(daemon.RemoveChaosRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).RemoveChaosRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_RemoveChaosRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).RemoveChaosRule(ctx, req.(*daemon.RemoveChaosRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_GetChaosRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).GetChaosRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_GetChaosRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).GetChaosRules(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_GetDNSCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(daemon.DNSCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).GetDNSCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_GetDNSCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).GetDNSCache(ctx, req.(*daemon.DNSCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_QueryDNS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(daemon.DNSQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).QueryDNS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_QueryDNS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).QueryDNS(ctx, req.(*daemon.DNSQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_GetDNSQueryLog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(daemon.DNSQueryLogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConnectorServer).GetDNSQueryLog(m, &connectorGetDNSQueryLogServer{stream})
+}
+
+type Connector_GetDNSQueryLogServer interface {
+	Send(*daemon.DNSQueryLogEntry) error
+	grpc.ServerStream
+}
+
+type connectorGetDNSQueryLogServer struct {
+	grpc.ServerStream
+}
+
+func (x *connectorGetDNSQueryLogServer) Send(m *daemon.DNSQueryLogEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Connector_AddForward_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Forward)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).AddForward(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_AddForward_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).AddForward(ctx, req.(*Forward))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_RemoveForward_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveForwardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).RemoveForward(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_RemoveForward_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).RemoveForward(ctx, req.(*RemoveForwardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Connector_GetForwards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).GetForwards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Connector_GetForwards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).GetForwards(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Connector_ServiceDesc is the grpc.ServiceDesc for Connector service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1211,6 +1821,22 @@ var Connector_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateIntercept",
 			Handler:    _Connector_UpdateIntercept_Handler,
 		},
+		{
+			MethodName: "Expose",
+			Handler:    _Connector_Expose_Handler,
+		},
+		{
+			MethodName: "Unexpose",
+			Handler:    _Connector_Unexpose_Handler,
+		},
+		{
+			MethodName: "GetAuditLog",
+			Handler:    _Connector_GetAuditLog_Handler,
+		},
+		{
+			MethodName: "Diagnose",
+			Handler:    _Connector_Diagnose_Handler,
+		},
 		{
 			MethodName: "Helm",
 			Handler:    _Connector_Helm_Handler,
@@ -1279,6 +1905,42 @@ var Connector_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetConfig",
 			Handler:    _Connector_GetConfig_Handler,
 		},
+		{
+			MethodName: "GetConnections",
+			Handler:    _Connector_GetConnections_Handler,
+		},
+		{
+			MethodName: "AddChaosRule",
+			Handler:    _Connector_AddChaosRule_Handler,
+		},
+		{
+			MethodName: "RemoveChaosRule",
+			Handler:    _Connector_RemoveChaosRule_Handler,
+		},
+		{
+			MethodName: "GetChaosRules",
+			Handler:    _Connector_GetChaosRules_Handler,
+		},
+		{
+			MethodName: "GetDNSCache",
+			Handler:    _Connector_GetDNSCache_Handler,
+		},
+		{
+			MethodName: "QueryDNS",
+			Handler:    _Connector_QueryDNS_Handler,
+		},
+		{
+			MethodName: "AddForward",
+			Handler:    _Connector_AddForward_Handler,
+		},
+		{
+			MethodName: "RemoveForward",
+			Handler:    _Connector_RemoveForward_Handler,
+		},
+		{
+			MethodName: "GetForwards",
+			Handler:    _Connector_GetForwards_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1286,6 +1948,16 @@ var Connector_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Connector_WatchWorkloads_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Capture",
+			Handler:       _Connector_Capture_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetDNSQueryLog",
+			Handler:       _Connector_GetDNSQueryLog_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "connector/connector.proto",
 }
@@ -31,6 +31,15 @@ const (
 	Daemon_SetDnsSearchPath_FullMethodName = "/telepresence.daemon.Daemon/SetDnsSearchPath"
 	Daemon_SetLogLevel_FullMethodName      = "/telepresence.daemon.Daemon/SetLogLevel"
 	Daemon_WaitForNetwork_FullMethodName   = "/telepresence.daemon.Daemon/WaitForNetwork"
+	Daemon_GetConnections_FullMethodName   = "/telepresence.daemon.Daemon/GetConnections"
+	Daemon_Capture_FullMethodName          = "/telepresence.daemon.Daemon/Capture"
+	Daemon_AddChaosRule_FullMethodName     = "/telepresence.daemon.Daemon/AddChaosRule"
+	Daemon_RemoveChaosRule_FullMethodName  = "/telepresence.daemon.Daemon/RemoveChaosRule"
+	Daemon_GetChaosRules_FullMethodName    = "/telepresence.daemon.Daemon/GetChaosRules"
+	Daemon_Diagnose_FullMethodName         = "/telepresence.daemon.Daemon/Diagnose"
+	Daemon_GetDNSCache_FullMethodName      = "/telepresence.daemon.Daemon/GetDNSCache"
+	Daemon_QueryDNS_FullMethodName         = "/telepresence.daemon.Daemon/QueryDNS"
+	Daemon_GetDNSQueryLog_FullMethodName   = "/telepresence.daemon.Daemon/GetDNSQueryLog"
 )
 
 // DaemonClient is the client API for Daemon service.
@@ -55,6 +64,30 @@ type DaemonClient interface {
 	SetLogLevel(ctx context.Context, in *manager.LogLevelRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// WaitForNetwork waits for the network of the currently connected session to become ready.
 	WaitForNetwork(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetConnections returns the connections that are currently tunneled to the cluster.
+	GetConnections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Connections, error)
+	// Capture streams the packets that pass through the TUN device until the
+	// call is cancelled.
+	Capture(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (Daemon_CaptureClient, error)
+	// AddChaosRule adds a rule that injects faults into the connections to a
+	// destination, and returns the rule with its assigned ID.
+	AddChaosRule(ctx context.Context, in *ChaosRule, opts ...grpc.CallOption) (*ChaosRule, error)
+	// RemoveChaosRule removes one or all chaos rules.
+	RemoveChaosRule(ctx context.Context, in *RemoveChaosRuleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetChaosRules returns the current chaos rules.
+	GetChaosRules(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ChaosRules, error)
+	// Diagnose checks the virtual network interface, its routes, the DNS
+	// configuration, and the connectivity to the cluster.
+	Diagnose(ctx context.Context, in *DiagnoseRequest, opts ...grpc.CallOption) (*Diagnosis, error)
+	// GetDNSCache returns the statistics and the entries of the DNS cache, and
+	// optionally flushes it.
+	GetDNSCache(ctx context.Context, in *DNSCacheRequest, opts ...grpc.CallOption) (*DNSCache, error)
+	// QueryDNS resolves a name using the DNS server, and reports how it was
+	// resolved.
+	QueryDNS(ctx context.Context, in *DNSQueryRequest, opts ...grpc.CallOption) (*DNSQueryResponse, error)
+	// GetDNSQueryLog returns the recent entries of the DNS query log. The new
+	// entries are streamed until the call is cancelled when follow is true.
+	GetDNSQueryLog(ctx context.Context, in *DNSQueryLogRequest, opts ...grpc.CallOption) (Daemon_GetDNSQueryLogClient, error)
 }
 
 type daemonClient struct {
@@ -146,6 +179,133 @@ func (c *daemonClient) WaitForNetwork(ctx context.Context, in *emptypb.Empty, op
 	return out, nil
 }
 
+func (c *daemonClient) GetConnections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Connections, error) {
+	out := new(Connections)
+	err := c.cc.Invoke(ctx, Daemon_GetConnections_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonClient) Capture(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (Daemon_CaptureClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Daemon_ServiceDesc.Streams[0], Daemon_Capture_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &daemonCaptureClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Daemon_CaptureClient interface {
+	Recv() (*CapturedPacket, error)
+	grpc.ClientStream
+}
+
+type daemonCaptureClient struct {
+	grpc.ClientStream
+}
+
+func (x *daemonCaptureClient) Recv() (*CapturedPacket, error) {
+	m := new(CapturedPacket)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *daemonClient) AddChaosRule(ctx context.Context, in *ChaosRule, opts ...grpc.CallOption) (*ChaosRule, error) {
+	out := new(ChaosRule)
+	err := c.cc.Invoke(ctx, Daemon_AddChaosRule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonClient) RemoveChaosRule(ctx context.Context, in *RemoveChaosRuleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Daemon_RemoveChaosRule_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonClient) GetChaosRules(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ChaosRules, error) {
+	out := new(ChaosRules)
+	err := c.cc.Invoke(ctx, Daemon_GetChaosRules_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonClient) Diagnose(ctx context.Context, in *DiagnoseRequest, opts ...grpc.CallOption) (*Diagnosis, error) {
+	out := new(Diagnosis)
+	err := c.cc.Invoke(ctx, Daemon_Diagnose_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonClient) GetDNSCache(ctx context.Context, in *DNSCacheRequest, opts ...grpc.CallOption) (*DNSCache, error) {
+	out := new(DNSCache)
+	err := c.cc.Invoke(ctx, Daemon_GetDNSCache_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonClient) QueryDNS(ctx context.Context, in *DNSQueryRequest, opts ...grpc.CallOption) (*DNSQueryResponse, error) {
+	out := new(DNSQueryResponse)
+	err := c.cc.Invoke(ctx, Daemon_QueryDNS_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonClient) GetDNSQueryLog(ctx context.Context, in *DNSQueryLogRequest, opts ...grpc.CallOption) (Daemon_GetDNSQueryLogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Daemon_ServiceDesc.Streams[1], Daemon_GetDNSQueryLog_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &daemonGetDNSQueryLogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Daemon_GetDNSQueryLogClient interface {
+	Recv() (*DNSQueryLogEntry, error)
+	grpc.ClientStream
+}
+
+type daemonGetDNSQueryLogClient struct {
+	grpc.ClientStream
+}
+
+func (x *daemonGetDNSQueryLogClient) Recv() (*DNSQueryLogEntry, error) {
+	m := new(DNSQueryLogEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DaemonServer is the server API for Daemon service.
 // All implementations must embed UnimplementedDaemonServer
 // for forward compatibility
@@ -168,6 +328,30 @@ type DaemonServer interface {
 	SetLogLevel(context.Context, *manager.LogLevelRequest) (*emptypb.Empty, error)
 	// WaitForNetwork waits for the network of the currently connected session to become ready.
 	WaitForNetwork(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// GetConnections returns the connections that are currently tunneled to the cluster.
+	GetConnections(context.Context, *emptypb.Empty) (*Connections, error)
+	// Capture streams the packets that pass through the TUN device until the
+	// call is cancelled.
+	Capture(*CaptureRequest, Daemon_CaptureServer) error
+	// AddChaosRule adds a rule that injects faults into the connections to a
+	// destination, and returns the rule with its assigned ID.
+	AddChaosRule(context.Context, *ChaosRule) (*ChaosRule, error)
+	// RemoveChaosRule removes one or all chaos rules.
+	RemoveChaosRule(context.Context, *RemoveChaosRuleRequest) (*emptypb.Empty, error)
+	// GetChaosRules returns the current chaos rules.
+	GetChaosRules(context.Context, *emptypb.Empty) (*ChaosRules, error)
+	// Diagnose checks the virtual network interface, its routes, the DNS
+	// configuration, and the connectivity to the cluster.
+	Diagnose(context.Context, *DiagnoseRequest) (*Diagnosis, error)
+	// GetDNSCache returns the statistics and the entries of the DNS cache, and
+	// optionally flushes it.
+	GetDNSCache(context.Context, *DNSCacheRequest) (*DNSCache, error)
+	// QueryDNS resolves a name using the DNS server, and reports how it was
+	// resolved.
+	QueryDNS(context.Context, *DNSQueryRequest) (*DNSQueryResponse, error)
+	// GetDNSQueryLog returns the recent entries of the DNS query log. The new
+	// entries are streamed until the call is cancelled when follow is true.
+	GetDNSQueryLog(*DNSQueryLogRequest, Daemon_GetDNSQueryLogServer) error
 	mustEmbedUnimplementedDaemonServer()
 }
 
@@ -202,6 +386,33 @@ func (UnimplementedDaemonServer) SetLogLevel(context.Context, *manager.LogLevelR
 func (UnimplementedDaemonServer) WaitForNetwork(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method WaitForNetwork not implemented")
 }
+func (UnimplementedDaemonServer) GetConnections(context.Context, *emptypb.Empty) (*Connections, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConnections not implemented")
+}
+func (UnimplementedDaemonServer) Capture(*CaptureRequest, Daemon_CaptureServer) error {
+	return status.Errorf(codes.Unimplemented, "method Capture not implemented")
+}
+func (UnimplementedDaemonServer) AddChaosRule(context.Context, *ChaosRule) (*ChaosRule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddChaosRule not implemented")
+}
+func (UnimplementedDaemonServer) RemoveChaosRule(context.Context, *RemoveChaosRuleRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveChaosRule not implemented")
+}
+func (UnimplementedDaemonServer) GetChaosRules(context.Context, *emptypb.Empty) (*ChaosRules, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChaosRules not implemented")
+}
+func (UnimplementedDaemonServer) Diagnose(context.Context, *DiagnoseRequest) (*Diagnosis, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Diagnose not implemented")
+}
+func (UnimplementedDaemonServer) GetDNSCache(context.Context, *DNSCacheRequest) (*DNSCache, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDNSCache not implemented")
+}
+func (UnimplementedDaemonServer) QueryDNS(context.Context, *DNSQueryRequest) (*DNSQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryDNS not implemented")
+}
+func (UnimplementedDaemonServer) GetDNSQueryLog(*DNSQueryLogRequest, Daemon_GetDNSQueryLogServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetDNSQueryLog not implemented")
+}
 func (UnimplementedDaemonServer) mustEmbedUnimplementedDaemonServer() {}
 
 // UnsafeDaemonServer may be embedded to opt out of forward compatibility for this service.
@@ -377,6 +588,174 @@ func _Daemon_WaitForNetwork_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Daemon_GetConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).GetConnections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_GetConnections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).GetConnections(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daemon_Capture_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CaptureRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DaemonServer).Capture(m, &daemonCaptureServer{stream})
+}
+
+type Daemon_CaptureServer interface {
+	Send(*CapturedPacket) error
+	grpc.ServerStream
+}
+
+type daemonCaptureServer struct {
+	grpc.ServerStream
+}
+
+func (x *daemonCaptureServer) Send(m *CapturedPacket) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Daemon_AddChaosRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChaosRule)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).AddChaosRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_AddChaosRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).AddChaosRule(ctx, req.(*ChaosRule))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daemon_RemoveChaosRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveChaosRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).RemoveChaosRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_RemoveChaosRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).RemoveChaosRule(ctx, req.(*RemoveChaosRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daemon_GetChaosRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).GetChaosRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_GetChaosRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).GetChaosRules(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daemon_Diagnose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiagnoseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).Diagnose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_Diagnose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).Diagnose(ctx, req.(*DiagnoseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daemon_GetDNSCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DNSCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).GetDNSCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_GetDNSCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).GetDNSCache(ctx, req.(*DNSCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daemon_QueryDNS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DNSQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).QueryDNS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daemon_QueryDNS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).QueryDNS(ctx, req.(*DNSQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daemon_GetDNSQueryLog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DNSQueryLogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DaemonServer).GetDNSQueryLog(m, &daemonGetDNSQueryLogServer{stream})
+}
+
+type Daemon_GetDNSQueryLogServer interface {
+	Send(*DNSQueryLogEntry) error
+	grpc.ServerStream
+}
+
+type daemonGetDNSQueryLogServer struct {
+	grpc.ServerStream
+}
+
+func (x *daemonGetDNSQueryLogServer) Send(m *DNSQueryLogEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Daemon_ServiceDesc is the grpc.ServiceDesc for Daemon service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -420,7 +799,46 @@ var Daemon_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "WaitForNetwork",
 			Handler:    _Daemon_WaitForNetwork_Handler,
 		},
+		{
+			MethodName: "GetConnections",
+			Handler:    _Daemon_GetConnections_Handler,
+		},
+		{
+			MethodName: "AddChaosRule",
+			Handler:    _Daemon_AddChaosRule_Handler,
+		},
+		{
+			MethodName: "RemoveChaosRule",
+			Handler:    _Daemon_RemoveChaosRule_Handler,
+		},
+		{
+			MethodName: "GetChaosRules",
+			Handler:    _Daemon_GetChaosRules_Handler,
+		},
+		{
+			MethodName: "Diagnose",
+			Handler:    _Daemon_Diagnose_Handler,
+		},
+		{
+			MethodName: "GetDNSCache",
+			Handler:    _Daemon_GetDNSCache_Handler,
+		},
+		{
+			MethodName: "QueryDNS",
+			Handler:    _Daemon_QueryDNS_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Capture",
+			Handler:       _Daemon_Capture_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetDNSQueryLog",
+			Handler:       _Daemon_GetDNSQueryLog_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "daemon/daemon.proto",
 }
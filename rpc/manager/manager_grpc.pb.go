@@ -58,6 +58,10 @@ const (
 	Manager_WatchLogLevel_FullMethodName             = "/telepresence.manager.Manager/WatchLogLevel"
 	Manager_Tunnel_FullMethodName                    = "/telepresence.manager.Manager/Tunnel"
 	Manager_WatchDial_FullMethodName                 = "/telepresence.manager.Manager/WatchDial"
+	Manager_SetEgressRules_FullMethodName            = "/telepresence.manager.Manager/SetEgressRules"
+	Manager_Expose_FullMethodName                    = "/telepresence.manager.Manager/Expose"
+	Manager_Unexpose_FullMethodName                  = "/telepresence.manager.Manager/Unexpose"
+	Manager_GetAuditLog_FullMethodName               = "/telepresence.manager.Manager/GetAuditLog"
 )
 
 // ManagerClient is the client API for Manager service.
@@ -173,6 +177,22 @@ type ManagerClient interface {
 	// connection and responds with a Tunnel. The manager then connects the
 	// two tunnels.
 	WatchDial(ctx context.Context, in *SessionInfo, opts ...grpc.CallOption) (Manager_WatchDialClient, error)
+	// SetEgressRules replaces the egress rules of a client session. Tunnels
+	// that the client creates for destinations that match a rule are extended
+	// to the traffic-agent of the rule's workload instead of being dialed by
+	// the traffic-manager.
+	SetEgressRules(ctx context.Context, in *EgressRulesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Expose creates a Service and Endpoints that point to the traffic-manager.
+	// Connections to the Service are accepted by the traffic-manager and
+	// extended to the client using a DialRequest. The Service is removed when
+	// Unexpose is called or when the client session ends.
+	Expose(ctx context.Context, in *ExposeRequest, opts ...grpc.CallOption) (*ExposeInfo, error)
+	// Unexpose removes a Service created by Expose.
+	Unexpose(ctx context.Context, in *UnexposeRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetAuditLog returns the records of the audit log that match the
+	// request, oldest first. Fails with FAILED_PRECONDITION when the audit
+	// log isn't enabled.
+	GetAuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLog, error)
 }
 
 type managerClient struct {
@@ -730,6 +750,42 @@ func (x *managerWatchDialClient) Recv() (*DialRequest, error) {
 	return m, nil
 }
 
+func (c *managerClient) SetEgressRules(ctx context.Context, in *EgressRulesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Manager_SetEgressRules_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managerClient) Expose(ctx context.Context, in *ExposeRequest, opts ...grpc.CallOption) (*ExposeInfo, error) {
+	out := new(ExposeInfo)
+	err := c.cc.Invoke(ctx, Manager_Expose_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managerClient) Unexpose(ctx context.Context, in *UnexposeRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Manager_Unexpose_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *managerClient) GetAuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLog, error) {
+	out := new(AuditLog)
+	err := c.cc.Invoke(ctx, Manager_GetAuditLog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ManagerServer is the server API for Manager service.
 // All implementations must embed UnimplementedManagerServer
 // for forward compatibility
@@ -843,6 +899,22 @@ type ManagerServer interface {
 	// connection and responds with a Tunnel. The manager then connects the
 	// two tunnels.
 	WatchDial(*SessionInfo, Manager_WatchDialServer) error
+	// SetEgressRules replaces the egress rules of a client session. Tunnels
+	// that the client creates for destinations that match a rule are extended
+	// to the traffic-agent of the rule's workload instead of being dialed by
+	// the traffic-manager.
+	SetEgressRules(context.Context, *EgressRulesRequest) (*emptypb.Empty, error)
+	// Expose creates a Service and Endpoints that point to the traffic-manager.
+	// Connections to the Service are accepted by the traffic-manager and
+	// extended to the client using a DialRequest. The Service is removed when
+	// Unexpose is called or when the client session ends.
+	Expose(context.Context, *ExposeRequest) (*ExposeInfo, error)
+	// Unexpose removes a Service created by Expose.
+	Unexpose(context.Context, *UnexposeRequest) (*emptypb.Empty, error)
+	// GetAuditLog returns the records of the audit log that match the
+	// request, oldest first. Fails with FAILED_PRECONDITION when the audit
+	// log isn't enabled.
+	GetAuditLog(context.Context, *AuditLogRequest) (*AuditLog, error)
 	mustEmbedUnimplementedManagerServer()
 }
 
@@ -949,6 +1021,18 @@ func (UnimplementedManagerServer) Tunnel(Manager_TunnelServer) error {
 func (UnimplementedManagerServer) WatchDial(*SessionInfo, Manager_WatchDialServer) error {
 	return status.Errorf(codes.Unimplemented, "method WatchDial not implemented")
 }
+func (UnimplementedManagerServer) SetEgressRules(context.Context, *EgressRulesRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetEgressRules not implemented")
+}
+func (UnimplementedManagerServer) Expose(context.Context, *ExposeRequest) (*ExposeInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Expose not implemented")
+}
+func (UnimplementedManagerServer) Unexpose(context.Context, *UnexposeRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unexpose not implemented")
+}
+func (UnimplementedManagerServer) GetAuditLog(context.Context, *AuditLogRequest) (*AuditLog, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditLog not implemented")
+}
 func (UnimplementedManagerServer) mustEmbedUnimplementedManagerServer() {}
 
 // UnsafeManagerServer may be embedded to opt out of forward compatibility for this service.
@@ -1604,6 +1688,78 @@ func (x *managerWatchDialServer) Send(m *DialRequest) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Manager_SetEgressRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EgressRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagerServer).SetEgressRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Manager_SetEgressRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagerServer).SetEgressRules(ctx, req.(*EgressRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Manager_Expose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExposeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagerServer).Expose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Manager_Expose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagerServer).Expose(ctx, req.(*ExposeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Manager_Unexpose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnexposeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagerServer).Unexpose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Manager_Unexpose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagerServer).Unexpose(ctx, req.(*UnexposeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Manager_GetAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ManagerServer).GetAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Manager_GetAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ManagerServer).GetAuditLog(ctx, req.(*AuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Manager_ServiceDesc is the grpc.ServiceDesc for Manager service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1699,6 +1855,22 @@ var Manager_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AgentLookupDNSResponse",
 			Handler:    _Manager_AgentLookupDNSResponse_Handler,
 		},
+		{
+			MethodName: "SetEgressRules",
+			Handler:    _Manager_SetEgressRules_Handler,
+		},
+		{
+			MethodName: "Expose",
+			Handler:    _Manager_Expose_Handler,
+		},
+		{
+			MethodName: "Unexpose",
+			Handler:    _Manager_Unexpose_Handler,
+		},
+		{
+			MethodName: "GetAuditLog",
+			Handler:    _Manager_GetAuditLog_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{